@@ -55,7 +55,6 @@ type User interface {
 	// Chain provide handlers chain for gin
 	Chain() gin.HandlersChain
 
-	Logout() mir.Error
 	Login(*LoginReq) (*LoginResp, mir.Error)
 
 	mustEmbedUnimplementedUserServant()
@@ -69,15 +68,6 @@ func RegisterUserServant(e *gin.Engine, s User) {
 	router.Use(middlewares...)
 
 	// register routes info to router
-	router.Handle("POST", "/user/logout/", func(c *gin.Context) {
-		select {
-		case <-c.Request.Context().Done():
-			return
-		default:
-		}
-
-		s.Render(c, nil, s.Logout())
-	})
 	router.Handle("POST", "/user/login/", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -101,10 +91,6 @@ func (UnimplementedUserServant) Chain() gin.HandlersChain {
 	return nil
 }
 
-func (UnimplementedUserServant) Logout() mir.Error {
-	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
-}
-
 func (UnimplementedUserServant) Login(req *LoginReq) (*LoginResp, mir.Error) {
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -34,6 +34,10 @@ type Admin interface {
 
 	SiteInfo(*web.SiteInfoReq) (*web.SiteInfoResp, mir.Error)
 	ChangeUserStatus(*web.ChangeUserStatusReq) mir.Error
+	ListReports(*web.ListReportsReq) (*web.ListReportsResp, mir.Error)
+	HandleReport(*web.HandleReportReq) (*web.HandleReportResp, mir.Error)
+	ChangeRoomStatus(*web.ChangeRoomStatusReq) mir.Error
+	ListModerationLogs(*web.ListModerationLogsReq) (*web.ListModerationLogsResp, mir.Error)
 
 	mustEmbedUnimplementedAdminServant()
 }
@@ -73,6 +77,61 @@ func RegisterAdminServant(e *gin.Engine, s Admin) {
 		}
 		s.Render(c, nil, s.ChangeUserStatus(req))
 	})
+	router.Handle("GET", "/admin/reports", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ListReportsReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ListReports(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/admin/report/handle", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.HandleReportReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.HandleReport(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/admin/room/status", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ChangeRoomStatusReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.ChangeRoomStatus(req))
+	})
+	router.Handle("GET", "/admin/moderation/logs", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ListModerationLogsReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ListModerationLogs(req)
+		s.Render(c, resp, err)
+	})
 }
 
 // UnimplementedAdminServant can be embedded to have forward compatible implementations.
@@ -90,4 +149,20 @@ func (UnimplementedAdminServant) ChangeUserStatus(req *web.ChangeUserStatusReq)
 	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedAdminServant) ListReports(req *web.ListReportsReq) (*web.ListReportsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedAdminServant) HandleReport(req *web.HandleReportReq) (*web.HandleReportResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedAdminServant) ChangeRoomStatus(req *web.ChangeRoomStatusReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedAdminServant) ListModerationLogs(req *web.ListModerationLogsReq) (*web.ListModerationLogsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedAdminServant) mustEmbedUnimplementedAdminServant() {}
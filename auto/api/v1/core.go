@@ -23,13 +23,27 @@ type Core interface {
 	TweetStarStatus(*web.TweetStarStatusReq) (*web.TweetStarStatusResp, mir.Error)
 	SuggestTags(*web.SuggestTagsReq) (*web.SuggestTagsResp, mir.Error)
 	SuggestUsers(*web.SuggestUsersReq) (*web.SuggestUsersResp, mir.Error)
+	SuggestMentions(*web.SuggestMentionsReq) (*web.SuggestMentionsResp, mir.Error)
 	ChangeAvatar(*web.ChangeAvatarReq) mir.Error
 	ChangeNickname(*web.ChangeNicknameReq) mir.Error
 	ChangePassword(*web.ChangePasswordReq) mir.Error
 	UserPhoneBind(*web.UserPhoneBindReq) mir.Error
 	GetStars(*web.GetStarsReq) (*web.GetStarsResp, mir.Error)
 	GetCollections(*web.GetCollectionsReq) (*web.GetCollectionsResp, mir.Error)
+	GetDrafts(*web.GetDraftsReq) (*web.GetDraftsResp, mir.Error)
 	SendUserWhisper(*web.SendWhisperReq) mir.Error
+	GetDmConversation(*web.GetDmConversationReq) (*web.GetDmConversationResp, mir.Error)
+	ListDmConversations(*web.ListDmConversationsReq) (*web.ListDmConversationsResp, mir.Error)
+	GetDmMessages(*web.GetDmMessagesReq) (*web.GetDmMessagesResp, mir.Error)
+	SendDmMessage(*web.SendDmMessageReq) (*web.SendDmMessageResp, mir.Error)
+	ReadDmConversation(*web.ReadDmConversationReq) mir.Error
+	GetDmUnreadCount(*web.GetDmUnreadCountReq) (*web.GetDmUnreadCountResp, mir.Error)
+	ChangeDmPolicy(*web.ChangeDmPolicyReq) mir.Error
+	BlockUser(*web.BlockUserReq) mir.Error
+	UnblockUser(*web.UnblockUserReq) mir.Error
+	ListBlockedUsers(*web.ListBlockedUsersReq) (*web.ListBlockedUsersResp, mir.Error)
+	ListFriendSuggestions(*web.ListFriendSuggestionsReq) (*web.ListFriendSuggestionsResp, mir.Error)
+	SuggestionFeedback(*web.SuggestionFeedbackReq) mir.Error
 	ReadAllMessage(*web.ReadAllMessageReq) mir.Error
 	ReadMessage(*web.ReadMessageReq) mir.Error
 	GetMessages(*web.GetMessagesReq) (*web.GetMessagesResp, mir.Error)
@@ -46,6 +60,9 @@ type Core interface {
 	GetUserRoom(*web.GetUserRoomReq) (*web.Room, mir.Error)
 	GetRoomByID(*web.GetRoomByIDReq) (*web.Room, mir.Error)
 	GetRoomByHostID(*web.GetRoomByHostIDReq) (*web.Room, mir.Error)
+	NearbyRooms(*web.NearbyRoomsReq) (*web.NearbyRoomsResp, mir.Error)
+	InviteRoom(*web.InviteRoomReq) (*web.InviteRoomResp, mir.Error)
+	AcceptRoomInvite(*web.AcceptRoomInviteReq) (*web.Room, mir.Error)
 
 	// Category endpoints
 	GetAllCategories() web.CategoryListResp
@@ -62,6 +79,11 @@ type Core interface {
 	GetReactionsToTwoUsers(*web.GetReactionsToTwoUsersReq) (*web.GetReactionsToTwoUsersResp, mir.Error)
 	GetGlobalReactionTimeline(*web.GetGlobalReactionTimelineReq) (*web.GetGlobalReactionTimelineResp, mir.Error)
 	GetUserReactionTimeline(*web.GetUserReactionTimelineReq) (*web.GetUserReactionTimelineResp, mir.Error)
+	ExportAccount(*web.ExportAccountReq) (*web.ExportAccountResp, mir.Error)
+	GetAccountExport(*web.GetAccountExportReq) (*web.GetAccountExportResp, mir.Error)
+	DeleteAccount(*web.DeleteAccountReq) (*web.DeleteAccountResp, mir.Error)
+	CancelAccountDeletion(*web.CancelAccountDeletionReq) mir.Error
+	GetAccountDeletion(*web.GetAccountDeletionReq) (*web.GetAccountDeletionResp, mir.Error)
 
 	mustEmbedUnimplementedCoreServant()
 }
@@ -134,6 +156,21 @@ func RegisterCoreServant(e *gin.Engine, s Core) {
 		resp, err := s.SuggestUsers(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("GET", "/suggest/mentions", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.SuggestMentionsReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.SuggestMentions(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("POST", "/user/avatar", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -216,6 +253,21 @@ func RegisterCoreServant(e *gin.Engine, s Core) {
 		resp, err := s.GetCollections(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("GET", "/user/drafts", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.GetDraftsReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.GetDrafts(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("POST", "/user/whisper", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -229,6 +281,173 @@ func RegisterCoreServant(e *gin.Engine, s Core) {
 		}
 		s.Render(c, nil, s.SendUserWhisper(req))
 	})
+	router.Handle("POST", "/dm/conversation", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.GetDmConversationReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.GetDmConversation(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("GET", "/dm/conversations", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ListDmConversationsReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ListDmConversations(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("GET", "/dm/messages", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.GetDmMessagesReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.GetDmMessages(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/dm/message", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.SendDmMessageReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.SendDmMessage(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/dm/read", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ReadDmConversationReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.ReadDmConversation(req))
+	})
+	router.Handle("GET", "/dm/unread", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.GetDmUnreadCountReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.GetDmUnreadCount(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/user/dm_policy", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ChangeDmPolicyReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.ChangeDmPolicy(req))
+	})
+	router.Handle("POST", "/user/block", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.BlockUserReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.BlockUser(req))
+	})
+	router.Handle("POST", "/user/unblock", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.UnblockUserReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.UnblockUser(req))
+	})
+	router.Handle("GET", "/user/blocks", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ListBlockedUsersReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ListBlockedUsers(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("GET", "/user/suggestions", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ListFriendSuggestionsReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ListFriendSuggestions(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/user/suggestion/feedback", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.SuggestionFeedbackReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.SuggestionFeedback(req))
+	})
 	router.Handle("POST", "/user/message/readall", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -444,6 +663,50 @@ func RegisterCoreServant(e *gin.Engine, s Core) {
 		resp, err := s.GetRoomByHostID(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("GET", "/rooms/nearby", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.NearbyRoomsReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.NearbyRooms(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/rooms/:id/invite", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.InviteRoomReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.InviteRoom(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/rooms/:id/accept", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.AcceptRoomInviteReq)
+		var bv _binding_ = req
+		if err := bv.Bind(c); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.AcceptRoomInvite(req)
+		s.Render(c, resp, err)
+	})
 
 	// Category endpoints
 	router.Handle("GET", "/categories", func(c *gin.Context) {
@@ -593,6 +856,75 @@ func RegisterCoreServant(e *gin.Engine, s Core) {
 		resp, err := s.GetUserReactionTimeline(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("POST", "/user/account/export", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ExportAccountReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ExportAccount(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("GET", "/user/account/export", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.GetAccountExportReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.GetAccountExport(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/user/account/delete", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.DeleteAccountReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.DeleteAccount(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/user/account/delete/cancel", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.CancelAccountDeletionReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.CancelAccountDeletion(req))
+	})
+	router.Handle("GET", "/user/account/deletion", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.GetAccountDeletionReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.GetAccountDeletion(req)
+		s.Render(c, resp, err)
+	})
 }
 
 // UnimplementedCoreServant can be embedded to have forward compatible implementations.
@@ -618,6 +950,10 @@ func (UnimplementedCoreServant) SuggestUsers(req *web.SuggestUsersReq) (*web.Sug
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedCoreServant) SuggestMentions(req *web.SuggestMentionsReq) (*web.SuggestMentionsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedCoreServant) ChangeAvatar(req *web.ChangeAvatarReq) mir.Error {
 	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -642,10 +978,62 @@ func (UnimplementedCoreServant) GetCollections(req *web.GetCollectionsReq) (*web
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedCoreServant) GetDrafts(req *web.GetDraftsReq) (*web.GetDraftsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedCoreServant) SendUserWhisper(req *web.SendWhisperReq) mir.Error {
 	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedCoreServant) GetDmConversation(req *web.GetDmConversationReq) (*web.GetDmConversationResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) ListDmConversations(req *web.ListDmConversationsReq) (*web.ListDmConversationsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) GetDmMessages(req *web.GetDmMessagesReq) (*web.GetDmMessagesResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) SendDmMessage(req *web.SendDmMessageReq) (*web.SendDmMessageResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) ReadDmConversation(req *web.ReadDmConversationReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) GetDmUnreadCount(req *web.GetDmUnreadCountReq) (*web.GetDmUnreadCountResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) ChangeDmPolicy(req *web.ChangeDmPolicyReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) BlockUser(req *web.BlockUserReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) UnblockUser(req *web.UnblockUserReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) ListBlockedUsers(req *web.ListBlockedUsersReq) (*web.ListBlockedUsersResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) ListFriendSuggestions(req *web.ListFriendSuggestionsReq) (*web.ListFriendSuggestionsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) SuggestionFeedback(req *web.SuggestionFeedbackReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedCoreServant) ReadAllMessage(req *web.ReadAllMessageReq) mir.Error {
 	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -704,6 +1092,18 @@ func (UnimplementedCoreServant) GetRoomByHostID(req *web.GetRoomByHostIDReq) (*w
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedCoreServant) NearbyRooms(req *web.NearbyRoomsReq) (*web.NearbyRoomsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) InviteRoom(req *web.InviteRoomReq) (*web.InviteRoomResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) AcceptRoomInvite(req *web.AcceptRoomInviteReq) (*web.Room, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 // Category endpoints
 func (UnimplementedCoreServant) GetAllCategories() web.CategoryListResp {
 	return web.CategoryListResp{Categories: []*web.Category{}}
@@ -746,4 +1146,24 @@ func (UnimplementedCoreServant) GetUserReactionTimeline(req *web.GetUserReaction
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedCoreServant) ExportAccount(req *web.ExportAccountReq) (*web.ExportAccountResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) GetAccountExport(req *web.GetAccountExportReq) (*web.GetAccountExportResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) DeleteAccount(req *web.DeleteAccountReq) (*web.DeleteAccountResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) CancelAccountDeletion(req *web.CancelAccountDeletionReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedCoreServant) GetAccountDeletion(req *web.GetAccountDeletionReq) (*web.GetAccountDeletionResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedCoreServant) mustEmbedUnimplementedCoreServant() {}
@@ -18,10 +18,16 @@ type Followship interface {
 	// Chain provide handlers chain for gin
 	Chain() gin.HandlersChain
 
+	ChangePrivateAccount(*web.ChangePrivateAccountReq) mir.Error
+	ListFollowRequests(*web.ListFollowRequestsReq) (*web.ListFollowRequestsResp, mir.Error)
+	ApproveFollowRequest(*web.ApproveFollowRequestReq) mir.Error
+	DeclineFollowRequest(*web.DeclineFollowRequestReq) mir.Error
+	RemoveFollower(*web.RemoveFollowerReq) mir.Error
+	ChangeRoomPing(*web.ChangeRoomPingReq) mir.Error
 	ListFollowings(*web.ListFollowingsReq) (*web.ListFollowingsResp, mir.Error)
 	ListFollows(*web.ListFollowsReq) (*web.ListFollowsResp, mir.Error)
 	UnfollowUser(*web.UnfollowUserReq) mir.Error
-	FollowUser(*web.FollowUserReq) mir.Error
+	FollowUser(*web.FollowUserReq) (*web.FollowUserResp, mir.Error)
 
 	mustEmbedUnimplementedFollowshipServant()
 }
@@ -34,6 +40,85 @@ func RegisterFollowshipServant(e *gin.Engine, s Followship) {
 	router.Use(middlewares...)
 
 	// register routes info to router
+	router.Handle("POST", "/user/private", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ChangePrivateAccountReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.ChangePrivateAccount(req))
+	})
+	router.Handle("GET", "/user/follow/requests", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ListFollowRequestsReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.ListFollowRequests(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/user/follow/approve", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ApproveFollowRequestReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.ApproveFollowRequest(req))
+	})
+	router.Handle("POST", "/user/follow/decline", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.DeclineFollowRequestReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.DeclineFollowRequest(req))
+	})
+	router.Handle("POST", "/user/follower/remove", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.RemoveFollowerReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.RemoveFollower(req))
+	})
+	router.Handle("POST", "/user/follow/room_ping", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.ChangeRoomPingReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		s.Render(c, nil, s.ChangeRoomPing(req))
+	})
 	router.Handle("GET", "/user/followings", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -86,7 +171,8 @@ func RegisterFollowshipServant(e *gin.Engine, s Followship) {
 			s.Render(c, nil, err)
 			return
 		}
-		s.Render(c, nil, s.FollowUser(req))
+		resp, err := s.FollowUser(req)
+		s.Render(c, resp, err)
 	})
 }
 
@@ -97,6 +183,30 @@ func (UnimplementedFollowshipServant) Chain() gin.HandlersChain {
 	return nil
 }
 
+func (UnimplementedFollowshipServant) ChangePrivateAccount(req *web.ChangePrivateAccountReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedFollowshipServant) ListFollowRequests(req *web.ListFollowRequestsReq) (*web.ListFollowRequestsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedFollowshipServant) ApproveFollowRequest(req *web.ApproveFollowRequestReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedFollowshipServant) DeclineFollowRequest(req *web.DeclineFollowRequestReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedFollowshipServant) RemoveFollower(req *web.RemoveFollowerReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedFollowshipServant) ChangeRoomPing(req *web.ChangeRoomPingReq) mir.Error {
+	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedFollowshipServant) ListFollowings(req *web.ListFollowingsReq) (*web.ListFollowingsResp, mir.Error) {
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -109,8 +219,8 @@ func (UnimplementedFollowshipServant) UnfollowUser(req *web.UnfollowUserReq) mir
 	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
-func (UnimplementedFollowshipServant) FollowUser(req *web.FollowUserReq) mir.Error {
-	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+func (UnimplementedFollowshipServant) FollowUser(req *web.FollowUserReq) (*web.FollowUserResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
 func (UnimplementedFollowshipServant) mustEmbedUnimplementedFollowshipServant() {}
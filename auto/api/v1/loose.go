@@ -19,12 +19,17 @@ type Loose interface {
 	Chain() gin.HandlersChain
 
 	TweetDetail(*web.TweetDetailReq) (*web.TweetDetailResp, mir.Error)
+	TweetThread(*web.TweetThreadReq) (*web.TweetThreadResp, mir.Error)
 	TweetComments(*web.TweetCommentsReq) (*web.TweetCommentsResp, mir.Error)
+	TweetCommentReplies(*web.TweetCommentRepliesReq) (*web.TweetCommentRepliesResp, mir.Error)
+	TweetRevisions(*web.TweetRevisionsReq) (*web.TweetRevisionsResp, mir.Error)
 	TopicList(*web.TopicListReq) (*web.TopicListResp, mir.Error)
 	GetUserProfile(*web.GetUserProfileReq) (*web.GetUserProfileResp, mir.Error)
 	GetUserTweets(*web.GetUserTweetsReq) (*web.GetUserTweetsResp, mir.Error)
 	Timeline(*web.TimelineReq) (*web.TimelineResp, mir.Error)
 	GetPostLocation(*web.PostLocationReq) (*web.PostLocationResp, mir.Error)
+	NearbyTweets(*web.NearbyTweetsReq) (*web.NearbyTweetsResp, mir.Error)
+	Search(*web.SearchReq) (*web.SearchResp, mir.Error)
 
 	mustEmbedUnimplementedLooseServant()
 }
@@ -51,6 +56,20 @@ func RegisterLooseServant(e *gin.Engine, s Loose) {
 		resp, err := s.TweetDetail(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("GET", "/post/thread", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.TweetThreadReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.TweetThread(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("GET", "/post/comments", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -70,6 +89,34 @@ func RegisterLooseServant(e *gin.Engine, s Loose) {
 		var rv _render_ = resp
 		rv.Render(c)
 	})
+	router.Handle("GET", "/post/comment/replies", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.TweetCommentRepliesReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.TweetCommentReplies(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("GET", "/post/revisions", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.TweetRevisionsReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.TweetRevisions(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("GET", "/tags", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -152,6 +199,34 @@ func RegisterLooseServant(e *gin.Engine, s Loose) {
 		resp, err := s.GetPostLocation(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("GET", "/posts/nearby", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.NearbyTweetsReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.NearbyTweets(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("GET", "/search", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.SearchReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.Search(req)
+		s.Render(c, resp, err)
+	})
 }
 
 // UnimplementedLooseServant can be embedded to have forward compatible implementations.
@@ -165,10 +240,22 @@ func (UnimplementedLooseServant) TweetDetail(req *web.TweetDetailReq) (*web.Twee
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedLooseServant) TweetThread(req *web.TweetThreadReq) (*web.TweetThreadResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedLooseServant) TweetComments(req *web.TweetCommentsReq) (*web.TweetCommentsResp, mir.Error) {
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedLooseServant) TweetCommentReplies(req *web.TweetCommentRepliesReq) (*web.TweetCommentRepliesResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedLooseServant) TweetRevisions(req *web.TweetRevisionsReq) (*web.TweetRevisionsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedLooseServant) TopicList(req *web.TopicListReq) (*web.TopicListResp, mir.Error) {
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -189,4 +276,12 @@ func (UnimplementedLooseServant) GetPostLocation(req *web.PostLocationReq) (*web
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedLooseServant) NearbyTweets(req *web.NearbyTweetsReq) (*web.NearbyTweetsResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedLooseServant) Search(req *web.SearchReq) (*web.SearchResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedLooseServant) mustEmbedUnimplementedLooseServant() {}
@@ -29,19 +29,25 @@ type Priv interface {
 	DeleteCommentReply(*web.DeleteCommentReplyReq) mir.Error
 	CreateCommentReply(*web.CreateCommentReplyReq) (*web.CreateCommentReplyResp, mir.Error)
 	HighlightComment(*web.HighlightCommentReq) (*web.HighlightCommentResp, mir.Error)
+	PinComment(*web.PinCommentReq) (*web.PinCommentResp, mir.Error)
+	EditComment(*web.EditCommentReq) (*web.EditCommentResp, mir.Error)
 	DeleteComment(*web.DeleteCommentReq) mir.Error
 	CreateComment(*web.CreateCommentReq) (*web.CreateCommentResp, mir.Error)
 	VisibleTweet(*web.VisibleTweetReq) (*web.VisibleTweetResp, mir.Error)
 	HighlightTweet(*web.HighlightTweetReq) (*web.HighlightTweetResp, mir.Error)
 	StickTweet(*web.StickTweetReq) (*web.StickTweetResp, mir.Error)
 	LockTweet(*web.LockTweetReq) (*web.LockTweetResp, mir.Error)
+	RepostTweet(*web.RepostTweetReq) (*web.RepostTweetResp, mir.Error)
 	CollectionTweet(*web.CollectionTweetReq) (*web.CollectionTweetResp, mir.Error)
 	StarTweet(*web.StarTweetReq) (*web.StarTweetResp, mir.Error)
 	DeleteTweet(*web.DeleteTweetReq) mir.Error
 	CreateTweet(*web.CreateTweetReq) (*web.CreateTweetResp, mir.Error)
+	UpdateDraft(*web.UpdateDraftReq) (*web.UpdateDraftResp, mir.Error)
+	EditTweet(*web.EditTweetReq) (*web.EditTweetResp, mir.Error)
 	DownloadAttachment(*web.DownloadAttachmentReq) (*web.DownloadAttachmentResp, mir.Error)
 	DownloadAttachmentPrecheck(*web.DownloadAttachmentPrecheckReq) (*web.DownloadAttachmentPrecheckResp, mir.Error)
 	UploadAttachment(*web.UploadAttachmentReq) (*web.UploadAttachmentResp, mir.Error)
+	CreateReport(*web.CreateReportReq) (*web.CreateReportResp, mir.Error)
 
 	mustEmbedUnimplementedPrivServant()
 }
@@ -214,6 +220,34 @@ func RegisterPrivServant(e *gin.Engine, s Priv, m ...PrivChain) {
 		resp, err := s.HighlightComment(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("POST", "/post/comment/pin", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.PinCommentReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.PinComment(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/post/comment/edit", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.EditCommentReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.EditComment(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("DELETE", "/post/comment", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -298,6 +332,20 @@ func RegisterPrivServant(e *gin.Engine, s Priv, m ...PrivChain) {
 		resp, err := s.LockTweet(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("POST", "/post/repost", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.RepostTweetReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.RepostTweet(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("POST", "/post/collection", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -359,6 +407,34 @@ func RegisterPrivServant(e *gin.Engine, s Priv, m ...PrivChain) {
 		var rv _render_ = resp
 		rv.Render(c)
 	})...)
+	router.Handle("PUT", "/post/draft", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.UpdateDraftReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.UpdateDraft(req)
+		s.Render(c, resp, err)
+	})
+	router.Handle("POST", "/post/edit", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.EditTweetReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.EditTweet(req)
+		s.Render(c, resp, err)
+	})
 	router.Handle("GET", "/attachment", func(c *gin.Context) {
 		select {
 		case <-c.Request.Context().Done():
@@ -404,6 +480,20 @@ func RegisterPrivServant(e *gin.Engine, s Priv, m ...PrivChain) {
 		resp, err := s.UploadAttachment(req)
 		s.Render(c, resp, err)
 	})
+	router.Handle("POST", "/report", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		default:
+		}
+		req := new(web.CreateReportReq)
+		if err := s.Bind(c, req); err != nil {
+			s.Render(c, nil, err)
+			return
+		}
+		resp, err := s.CreateReport(req)
+		s.Render(c, resp, err)
+	})
 }
 
 // UnimplementedPrivServant can be embedded to have forward compatible implementations.
@@ -457,6 +547,14 @@ func (UnimplementedPrivServant) HighlightComment(req *web.HighlightCommentReq) (
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedPrivServant) PinComment(req *web.PinCommentReq) (*web.PinCommentResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedPrivServant) EditComment(req *web.EditCommentReq) (*web.EditCommentResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedPrivServant) DeleteComment(req *web.DeleteCommentReq) mir.Error {
 	return mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -481,6 +579,10 @@ func (UnimplementedPrivServant) LockTweet(req *web.LockTweetReq) (*web.LockTweet
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedPrivServant) RepostTweet(req *web.RepostTweetReq) (*web.RepostTweetResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedPrivServant) CollectionTweet(req *web.CollectionTweetReq) (*web.CollectionTweetResp, mir.Error) {
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -497,6 +599,14 @@ func (UnimplementedPrivServant) CreateTweet(req *web.CreateTweetReq) (*web.Creat
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedPrivServant) UpdateDraft(req *web.UpdateDraftReq) (*web.UpdateDraftResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
+func (UnimplementedPrivServant) EditTweet(req *web.EditTweetReq) (*web.EditTweetResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedPrivServant) DownloadAttachment(req *web.DownloadAttachmentReq) (*web.DownloadAttachmentResp, mir.Error) {
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
@@ -509,6 +619,10 @@ func (UnimplementedPrivServant) UploadAttachment(req *web.UploadAttachmentReq) (
 	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
 }
 
+func (UnimplementedPrivServant) CreateReport(req *web.CreateReportReq) (*web.CreateReportResp, mir.Error) {
+	return nil, mir.Errorln(http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented))
+}
+
 func (UnimplementedPrivServant) mustEmbedUnimplementedPrivServant() {}
 
 // UnimplementedPrivChain can be embedded to have forward compatible implementations.
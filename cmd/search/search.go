@@ -0,0 +1,97 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alimy/tryst/cfg"
+	"github.com/rocboss/paopao-ce/cmd"
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/dao"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noDefaultFeatures bool
+	features          []string
+	indexes           []string
+	batchSize         int
+)
+
+func init() {
+	searchCmd := &cobra.Command{
+		Use:   "search",
+		Short: "manage search indexes",
+		Long:  "manage posts/users/rooms/topics search indexes",
+	}
+	reindexCmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "rebuild search indexes from database",
+		Long:  "rebuild search indexes into shadow indexes from database then swap them in",
+		Run:   reindexRun,
+	}
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "check search indexes drift",
+		Long:  "compare documents count between database and search indexes",
+		Run:   checkRun,
+	}
+
+	allIndexes := make([]string, 0, len(cs.SearchIndexes))
+	for _, index := range cs.SearchIndexes {
+		allIndexes = append(allIndexes, string(index))
+	}
+	searchCmd.PersistentFlags().BoolVar(&noDefaultFeatures, "no-default-features", false, "whether not use default features")
+	searchCmd.PersistentFlags().StringSliceVarP(&features, "features", "f", []string{}, "use special features")
+	searchCmd.PersistentFlags().StringSliceVarP(&indexes, "index", "i", allIndexes, "indexes to operate")
+	reindexCmd.Flags().IntVar(&batchSize, "batch", 1000, "documents per batch")
+
+	searchCmd.AddCommand(reindexCmd, checkCmd)
+	cmd.Register(searchCmd)
+}
+
+func reindexRun(_cmd *cobra.Command, _args []string) {
+	conf.Initial(features, noDefaultFeatures)
+	defer conf.CloseDB()
+	mustNotBleve("POST /m/v1/search/reindex")
+
+	sms := dao.SearchIndexManageService()
+	for _, index := range indexes {
+		count, err := sms.Reindex(cs.SearchIndex(index), batchSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "reindex %s failed after %d documents: %s\n", index, count, err)
+			os.Exit(1)
+		}
+		fmt.Printf("reindex %s done: %d documents\n", index, count)
+	}
+}
+
+func checkRun(_cmd *cobra.Command, _args []string) {
+	conf.Initial(features, noDefaultFeatures)
+	defer conf.CloseDB()
+	mustNotBleve("GET /m/v1/search/indexes")
+
+	sms := dao.SearchIndexManageService()
+	for _, index := range indexes {
+		drift, err := sms.CheckDrift(cs.SearchIndex(index))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "check %s failed: %s\n", index, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s[%s] db: %d index: %d drift: %d pending: %d failed: %d\n", index, drift.IndexName,
+			drift.DbCount, drift.IndexCount, drift.Drift(), drift.PendingCount, drift.FailedCount)
+	}
+}
+
+// mustNotBleve bleve索引目录被运行中的服务独占，命令行无法打开，需通过管理后台接口操作
+func mustNotBleve(api string) {
+	if cfg.If("Bleve") {
+		fmt.Fprintf(os.Stderr, "bleve index is held by the running server, use admin api %s instead\n", api)
+		os.Exit(1)
+	}
+}
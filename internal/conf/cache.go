@@ -20,6 +20,7 @@ const (
 	InfixCommentDefault      = "default"
 	InfixCommentHots         = "hots"
 	InfixCommentNewest       = "newest"
+	InfixCommentTop          = "top"
 	PrefixNewestTweets       = "paopao:newesttweets:"
 	PrefixHotsTweets         = "paopao:hotstweets:"
 	PrefixFollowingTweets    = "paopao:followingtweets:"
@@ -27,10 +28,16 @@ const (
 	PrefixUnreadmsg          = "paopao:unreadmsg:"
 	PrefixOnlineUser         = "paopao:onlineuser:"
 	PrefixUserLocation       = "paopao:userlocation:"
+	PrefixDailyActiveUser    = "paopao:dau:"
+	PrefixAdminSession       = "paopao:adminsession:"
+	PrefixFeatureToggle      = "paopao:feature:"
 	PrefixIdxTweetsNewest    = "paopao:index:tweets:newest:"
 	PrefixIdxTweetsHots      = "paopao:index:tweets:hots:"
 	PrefixIdxTweetsFollowing = "paopao:index:tweets:following:"
+	PrefixIdxTweetsForYou    = "paopao:index:tweets:foryou:"
 	PrefixIdxTrends          = "paopao:index:trends:"
+	PrefixForYouViewer       = "paopao:foryou:viewer:"
+	PrefixForYouFeed         = "paopao:foryou:feed:"
 	PrefixMessages           = "paopao:messages:"
 	PrefixUserInfo           = "paopao:user:info:"
 	PrefixUserProfile        = "paopao:user:profile:"
@@ -41,6 +48,8 @@ const (
 	PrefixMyFollowIds        = "paopao:myfollowids:"
 	PrefixTweetComment       = "paopao:comment:"
 	KeySiteStatus            = "paopao:sitestatus"
+	KeyOnlineUsers           = "paopao:onlineusers"
+	KeyForYouCandidates      = "paopao:foryou:candidates"
 	KeyHistoryMaxOnline      = "history.max.online"
 )
 
@@ -51,12 +60,15 @@ var (
 	KeyFollowingTweets   cache.KeyPool[string]
 	KeyUnreadMsg         cache.KeyPool[int64]
 	KeyOnlineUser        cache.KeyPool[int64]
+	KeyAdminSession      cache.KeyPool[int64]
 	KeyUserLocation      cache.KeyPool[int64]
 	KeyUserInfoById      cache.KeyPool[int64]
 	KeyUserInfoByName    cache.KeyPool[string]
 	KeyUserProfileByName cache.KeyPool[string]
 	KeyMyFriendIds       cache.KeyPool[int64]
 	KeyMyFollowIds       cache.KeyPool[int64]
+	KeyForYouViewer      cache.KeyPool[int64]
+	KeyForYouFeed        cache.KeyPool[int64]
 )
 
 func initCacheKeyPool() {
@@ -69,12 +81,15 @@ func initCacheKeyPool() {
 	KeyFollowingTweets = strKeyPool(poolSize, PrefixFollowingTweets)
 	KeyUnreadMsg = intKeyPool[int64](poolSize, PrefixUnreadmsg)
 	KeyOnlineUser = intKeyPool[int64](poolSize, PrefixOnlineUser)
+	KeyAdminSession = intKeyPool[int64](poolSize, PrefixAdminSession)
 	KeyUserLocation = intKeyPool[int64](poolSize, PrefixUserLocation)
 	KeyUserInfoById = intKeyPool[int64](poolSize, PrefixUserInfoById)
 	KeyUserInfoByName = strKeyPool(poolSize, PrefixUserInfoByName)
 	KeyUserProfileByName = strKeyPool(poolSize, prefixUserProfileByName)
 	KeyMyFriendIds = intKeyPool[int64](poolSize, PrefixMyFriendIds)
 	KeyMyFollowIds = intKeyPool[int64](poolSize, PrefixMyFollowIds)
+	KeyForYouViewer = intKeyPool[int64](poolSize, PrefixForYouViewer)
+	KeyForYouFeed = intKeyPool[int64](poolSize, PrefixForYouFeed)
 }
 
 func strKeyPool(size int, prefix string) cache.KeyPool[string] {
@@ -0,0 +1,28 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package conf
+
+import (
+	"sync"
+
+	"github.com/rocboss/paopao-ce/pkg/centrifugo"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_centrifugoClient *centrifugo.Client
+	_onceCentrifugo   sync.Once
+)
+
+func MustCentrifugoClient() *centrifugo.Client {
+	_onceCentrifugo.Do(func() {
+		s := CentrifugoSetting
+		if s.TokenSecret == "" {
+			logrus.Fatal("Centrifugo.TokenSecret must be configured when feature Centrifugo is enabled")
+		}
+		_centrifugoClient = centrifugo.New(s.ApiAddr, s.ApiKey, s.Timeout)
+	})
+	return _centrifugoClient
+}
@@ -21,44 +21,54 @@ var (
 	sentrySetting            *sentryConf
 	redisSetting             *redisConf
 
-	PyroscopeSetting        *pyroscopeConf
-	DatabaseSetting         *databaseConf
-	MysqlSetting            *mysqlConf
-	PostgresSetting         *postgresConf
-	Sqlite3Setting          *sqlite3Conf
-	PprofServerSetting      *httpServerConf
-	MetricsServerSetting    *httpServerConf
-	WebServerSetting        *httpServerConf
-	AdminServerSetting      *httpServerConf
-	SpaceXServerSetting     *httpServerConf
-	BotServerSetting        *httpServerConf
-	LocalossServerSetting   *httpServerConf
-	FrontendWebSetting      *httpServerConf
-	DocsServerSetting       *httpServerConf
-	MobileServerSetting     *grpcServerConf
-	AppSetting              *appConf
-	CacheSetting            *cacheConf
-	EventManagerSetting     *eventManagerConf
-	MetricManagerSetting    *metricManagerConf
-	JobManagerSetting       *jobManagerConf
-	CacheIndexSetting       *cacheIndexConf
-	SimpleCacheIndexSetting *simpleCacheIndexConf
-	BigCacheIndexSetting    *bigCacheIndexConf
-	RedisCacheIndexSetting  *redisCacheIndexConf
-	SmsJuheSetting          *smsJuheConf
-	AlipaySetting           *alipayConf
-	TweetSearchSetting      *tweetSearchConf
-	ZincSetting             *zincConf
-	MeiliSetting            *meiliConf
-	ObjectStorage           *objectStorageConf
-	AliOSSSetting           *aliOSSConf
-	COSSetting              *cosConf
-	HuaweiOBSSetting        *huaweiOBSConf
-	MinIOSetting            *minioConf
-	S3Setting               *s3Conf
-	LocalOSSSetting         *localossConf
-	JWTSetting              *jwtConf
-	WebProfileSetting       *WebProfileConf
+	PyroscopeSetting         *pyroscopeConf
+	DatabaseSetting          *databaseConf
+	MysqlSetting             *mysqlConf
+	PostgresSetting          *postgresConf
+	Sqlite3Setting           *sqlite3Conf
+	PprofServerSetting       *httpServerConf
+	MetricsServerSetting     *httpServerConf
+	WebServerSetting         *httpServerConf
+	AdminServerSetting       *httpServerConf
+	SpaceXServerSetting      *httpServerConf
+	BotServerSetting         *httpServerConf
+	LocalossServerSetting    *httpServerConf
+	FrontendWebSetting       *httpServerConf
+	DocsServerSetting        *httpServerConf
+	MobileServerSetting      *grpcServerConf
+	AppSetting               *appConf
+	CacheSetting             *cacheConf
+	EventManagerSetting      *eventManagerConf
+	MetricManagerSetting     *metricManagerConf
+	JobManagerSetting        *jobManagerConf
+	CacheIndexSetting        *cacheIndexConf
+	SimpleCacheIndexSetting  *simpleCacheIndexConf
+	BigCacheIndexSetting     *bigCacheIndexConf
+	RedisCacheIndexSetting   *redisCacheIndexConf
+	SmsJuheSetting           *smsJuheConf
+	AlipaySetting            *alipayConf
+	TweetSearchSetting       *tweetSearchConf
+	ContentModerationSetting *contentModerationConf
+	TranscriptionSetting     *transcriptionConf
+	VoiceNoteSetting         *voiceNoteConf
+	RoomInviteSetting        *roomInviteConf
+	CentrifugoSetting        *centrifugoConf
+	ForYouSetting            *forYouConf
+	SuggestSetting           *suggestConf
+	EditSetting              *editConf
+	NearbySetting            *nearbyConf
+	ZincSetting              *zincConf
+	MeiliSetting             *meiliConf
+	BleveSetting             *bleveConf
+	ObjectStorage            *objectStorageConf
+	AliOSSSetting            *aliOSSConf
+	COSSetting               *cosConf
+	HuaweiOBSSetting         *huaweiOBSConf
+	MinIOSetting             *minioConf
+	S3Setting                *s3Conf
+	LocalOSSSetting          *localossConf
+	JWTSetting               *jwtConf
+	WebProfileSetting        *WebProfileConf
 )
 
 func setupSetting(suite []string, noDefault bool) error {
@@ -109,8 +119,18 @@ func setupSetting(suite []string, noDefault bool) error {
 		"Postgres":          &PostgresSetting,
 		"Sqlite3":           &Sqlite3Setting,
 		"TweetSearch":       &TweetSearchSetting,
+		"ContentModeration": &ContentModerationSetting,
+		"Transcription":     &TranscriptionSetting,
+		"VoiceNote":         &VoiceNoteSetting,
+		"RoomInvite":        &RoomInviteSetting,
+		"Centrifugo":        &CentrifugoSetting,
+		"ForYou":            &ForYouSetting,
+		"Suggest":           &SuggestSetting,
+		"Edit":              &EditSetting,
+		"Nearby":            &NearbySetting,
 		"Zinc":              &ZincSetting,
 		"Meili":             &MeiliSetting,
+		"Bleve":             &BleveSetting,
 		"Redis":             &redisSetting,
 		"JWT":               &JWTSetting,
 		"ObjectStorage":     &ObjectStorage,
@@ -133,11 +153,18 @@ func setupSetting(suite []string, noDefault bool) error {
 	EventManagerSetting.MaxIdleTime *= time.Second
 	MetricManagerSetting.MaxIdleTime *= time.Second
 	JWTSetting.Expire *= time.Second
+	JWTSetting.AdminExpire *= time.Second
 	SimpleCacheIndexSetting.CheckTickDuration *= time.Second
 	SimpleCacheIndexSetting.ExpireTickDuration *= time.Second
 	BigCacheIndexSetting.ExpireInSecond *= time.Second
 	RedisCacheIndexSetting.ExpireInSecond *= time.Second
 	redisSetting.ConnWriteTimeout *= time.Second
+	ContentModerationSetting.ReloadInterval *= time.Second
+	ContentModerationSetting.Timeout *= time.Second
+	TranscriptionSetting.Timeout *= time.Second
+	VoiceNoteSetting.Timeout *= time.Second
+	CentrifugoSetting.Timeout *= time.Second
+	TweetSearchSetting.OutboxInterval *= time.Second
 
 	return nil
 }
@@ -106,6 +106,9 @@ type appConf struct {
 	DefaultContextTimeout time.Duration
 	DefaultPageSize       int
 	MaxPageSize           int
+	AccountDeletionGrace  int
+	AccountExportExpire   int64
+	AccountExportTimeout  int64
 }
 
 type cacheConf struct {
@@ -145,6 +148,12 @@ type jobManagerConf struct {
 	MaxOnlineInterval        string
 	UpdateMetricsInterval    string
 	ContactMatchingInterval  string
+	AccountDeletionInterval  string
+	ForYouCandidatesInterval string
+	SearchDriftInterval      string
+	ScheduledTweetsInterval  string
+	FriendSuggestInterval    string
+	ProcessingTweetsTimeout  int64
 }
 
 type cacheIndexConf struct {
@@ -187,8 +196,84 @@ type smsJuheConf struct {
 }
 
 type tweetSearchConf struct {
-	MaxUpdateQPS int
-	MinWorker    int
+	MaxUpdateQPS     int
+	MinWorker        int
+	OutboxInterval   time.Duration
+	OutboxBatch      int
+	OutboxMaxRetries int
+	OutboxRetention  int64
+}
+
+type contentModerationConf struct {
+	WordsFile      string
+	RejectWords    []string
+	FlagWords      []string
+	ReloadInterval time.Duration
+	Endpoint       string
+	Token          string
+	Timeout        time.Duration
+}
+
+type transcriptionConf struct {
+	Binary       string
+	Model        string
+	Language     string
+	Threads      int
+	FFmpeg       string
+	WorkDir      string
+	MaxAudioSize int64
+	Timeout      time.Duration
+}
+
+type voiceNoteConf struct {
+	FFmpeg       string
+	WorkDir      string
+	MaxDuration  int64
+	MaxSize      int64
+	WaveformBars int
+	Timeout      time.Duration
+}
+
+type roomInviteConf struct {
+	MaxInvitees  int
+	MaxFanout    int
+	InviteExpire int64
+	PingInterval int64
+}
+
+type centrifugoConf struct {
+	ApiAddr     string
+	ApiKey      string
+	TokenSecret string
+	Timeout     time.Duration
+}
+
+type forYouConf struct {
+	Ranker          string
+	CandidateWindow int64
+	CandidateSize   int
+	CandidateExpire int64
+	ViewerExpire    int64
+	FeedExpire      int64
+	HalfLife        int64
+}
+
+type suggestConf struct {
+	MaxSuggestions int
+	MaxCandidates  int
+	BatchSize      int
+	DismissExpire  int64
+}
+
+type editConf struct {
+	Window int64
+}
+
+type nearbyConf struct {
+	DefaultRadius    float64
+	MaxRadius        float64
+	MaxCandidates    int
+	CoarsenPrecision int
 }
 
 type zincConf struct {
@@ -206,6 +291,11 @@ type meiliConf struct {
 	Secure bool
 }
 
+type bleveConf struct {
+	Path  string
+	Index string
+}
+
 type databaseConf struct {
 	TablePrefix string
 	LogLevel    string
@@ -291,9 +381,10 @@ type redisConf struct {
 }
 
 type jwtConf struct {
-	Secret string
-	Issuer string
-	Expire time.Duration
+	Secret      string
+	Issuer      string
+	Expire      time.Duration
+	AdminExpire time.Duration
 }
 
 type WebProfileConf struct {
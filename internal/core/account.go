@@ -0,0 +1,22 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// AccountService 账户数据导出与注销服务
+type AccountService interface {
+	CreateAccountExport(userId int64, staleBefore int64) (*ms.AccountExport, error)
+	GetAccountExport(userId int64, id int64) (*ms.AccountExport, error)
+	UpdateAccountExport(export *ms.AccountExport) error
+	GetAccountArchive(userId int64) (*ms.AccountArchive, error)
+	ScheduleAccountDeletion(userId int64, scheduledOn int64) (*ms.AccountDeletion, error)
+	GetAccountDeletion(userId int64) (*ms.AccountDeletion, error)
+	UpdateAccountDeletion(deletion *ms.AccountDeletion) error
+	ListDueAccountDeletions(now int64, limit int) ([]*ms.AccountDeletion, error)
+	PurgeAccount(userId int64) ([]*ms.Post, []int64, []string, []string, error)
+}
@@ -0,0 +1,23 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// AdminManageService 管理后台服务
+type AdminManageService interface {
+	ListUsers(filter *cs.UserFilter, limit, offset int) ([]*ms.User, int64, error)
+	ListUserRooms(userId int64) ([]*ms.Room, error)
+	ListUserReports(userId int64, limit, offset int) ([]*ms.Report, int64, error)
+	GetPostWithDeleted(id int64) (*ms.Post, error)
+	SoftDeletePost(post *ms.Post, operatorId int64) error
+	RestorePost(post *ms.Post) error
+	CountRegistersDaily(since int64) ([]*cs.DailyCount, error)
+	CountRecordingsDaily(since int64) ([]*cs.DailyCount, error)
+	CountLiveRooms(onlineUserIds []int64) (int64, error)
+}
@@ -5,13 +5,25 @@
 package core
 
 import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 )
 
 // AuthorizationManageService 授权管理服务
 type AuthorizationManageService interface {
 	IsAllow(user *ms.User, action *ms.Action) bool
+	HasPermission(user *ms.User, perm cs.Permission) bool
+	UserPermissions(user *ms.User) cs.PermissionSet
+	CanManageUser(operator *ms.User, target *ms.User) bool
 	BeFriendFilter(userId int64) ms.FriendFilter
 	BeFriendIds(userId int64) ([]int64, error)
 	MyFriendSet(userId int64) ms.FriendSet
 }
+
+// RoleManageService 角色管理服务
+type RoleManageService interface {
+	ListRoles() ([]*ms.Role, error)
+	GetUserRoles(userId int64) ([]*ms.Role, error)
+	AssignUserRole(userId int64, role cs.RoleName) error
+	RevokeUserRole(userId int64, role cs.RoleName) error
+}
@@ -106,6 +106,10 @@ type AppCache interface {
 	DelAny(pattern string) error
 	Exist(key string) bool
 	Keys(pattern string) ([]string, error)
+	PfAdd(key string, ex int64, elements ...string) error
+	PfCount(key string) (int64, error)
+	MarkOnlineUser(userId int64, ex int64) error
+	OnlineUserIds() ([]int64, error)
 	
 	// BatchCheckOnlineUsers checks multiple user online statuses in a single Redis call
 	BatchCheckOnlineUsers(userIDs []int64) (map[int64]bool, error)
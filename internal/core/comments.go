@@ -16,17 +16,19 @@ type CommentService interface {
 	GetCommentReplyByID(id int64) (*ms.CommentReply, error)
 	GetCommentContentsByIDs(ids []int64) ([]*ms.CommentContent, error)
 	GetCommentRepliesByID(ids []int64) ([]*ms.CommentReplyFormated, error)
+	ListCommentReplies(commentId int64, limit int, offset int) ([]*ms.CommentReplyFormated, int64, error)
 	GetCommentThumbsMap(userId int64, tweetId int64) (cs.CommentThumbsMap, cs.CommentThumbsMap, error)
 }
 
 // CommentManageService 评论管理服务
 type CommentManageService interface {
 	HighlightComment(userId, commentId int64) (int8, error)
+	PinComment(userId, commentId int64) (bool, error)
 	DeleteComment(comment *ms.Comment) error
 	CreateComment(comment *ms.Comment) (*ms.Comment, error)
 	CreateCommentReply(reply *ms.CommentReply) (*ms.CommentReply, error)
 	DeleteCommentReply(reply *ms.CommentReply) error
-	CreateCommentContent(content *ms.CommentContent) (*ms.CommentContent, error)
+	CreateCommentContent(post *ms.Post, content *ms.CommentContent) (*ms.CommentContent, error)
 	ThumbsUpComment(userId int64, tweetId, commentId int64) error
 	ThumbsDownComment(userId int64, tweetId, commentId int64) error
 	ThumbsUpReply(userId int64, tweetId, commentId, replyId int64) error
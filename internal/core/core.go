@@ -39,15 +39,51 @@ type DataService interface {
 	FollowingManageService
 	UserRelationService
 
+	// 私信会话与用户屏蔽服务
+	DirectMessageService
+	UserBlockService
+
 	// 安全服务
 	SecurityService
 	AttachmentCheckService
+	ContentModerationService
 
 	// 房间服务
 	RoomService
+	RoomInviteService
+	FriendSuggestService
+	MentionService
+	EditService
 	
 	// 分类服务
 	CategoryService
+
+	// 举报审核服务
+	ReportService
+
+	// 账户数据导出与注销服务
+	AccountService
+
+	// 管理后台服务
+	AdminManageService
+
+	// 授权与角色管理服务
+	AuthorizationManageService
+	RoleManageService
+
+	// 个性化推荐服务
+	FeedRankService
+
+	// 附近的动态与房间服务
+	NearbyService
+
+	// 搜索索引数据与变更发件箱服务
+	EntitySearchSyncService
+	SearchOutboxService
+
+	// 音频转写服务
+	TranscriptionService
+	TranscriptService
 	
 	// Session mapping methods
 	SaveSessionMapping(roomID, sessionID, peerID, userID string) error
@@ -0,0 +1,20 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 账户数据导出状态
+	AccountExportPending int8 = iota + 1
+	AccountExportProcessing
+	AccountExportFinished
+	AccountExportFailed
+)
+
+const (
+	// 账户注销状态
+	AccountDeletionScheduled int8 = iota + 1
+	AccountDeletionCanceled
+	AccountDeletionDone
+)
@@ -0,0 +1,34 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 管理后台用户角色筛选
+	UserRoleAll   UserRole = ""
+	UserRoleAdmin UserRole = "admin"
+	UserRoleUser  UserRole = "user"
+)
+
+type UserRole string
+
+// UserFilter 管理后台用户搜索条件
+type UserFilter struct {
+	Keyword string
+	Status  int
+	Role    UserRole
+}
+
+// FeatureToggle 运行时功能开关，Default 为配置文件的设定
+type FeatureToggle struct {
+	Feature string `json:"feature"`
+	Default bool   `json:"default"`
+	Enabled bool   `json:"enabled"`
+}
+
+// DailyCount 按天聚合的统计数
+type DailyCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
@@ -8,6 +8,7 @@ const (
 	StyleCommentDefault StyleCommentType = iota
 	StyleCommentHots
 	StyleCommentNewest
+	StyleCommentTop
 )
 
 type StyleCommentType uint8
@@ -11,6 +11,12 @@ const (
 	ContactStatusDeleted
 )
 
+const (
+	// 关注状态，关注私密账户时为待对方通过的关注请求
+	FollowStatusRequesting int8 = iota + 1
+	FollowStatusAgree
+)
+
 type Contact struct {
 	ID           int64  `db:"id" json:"id"`
 	UserId       int64  `db:"user_id" json:"user_id"`
@@ -0,0 +1,51 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid page cursor")
+)
+
+// PageCursor 游标分页位置，列表按 (Top, Value, ID) 倒序排列，
+// 下一页取严格小于该位置的记录，新数据插入不会导致翻页重复或遗漏
+type PageCursor struct {
+	Top   int   // 置顶标记，不区分置顶的列表恒为0
+	Value int64 // 排序时间，如 latest_replied_on / created_on
+	ID    int64
+}
+
+// Encode 编码为不透明的游标字符串
+func (c *PageCursor) Encode() string {
+	if c == nil {
+		return ""
+	}
+	raw := fmt.Sprintf("%d.%d.%d", c.Top, c.Value, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParsePageCursor 解析游标字符串，空字符串表示从第一页开始
+func ParsePageCursor(s string) (*PageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &PageCursor{}
+	if n, err := fmt.Sscanf(string(raw), "%d.%d.%d", &c.Top, &c.Value, &c.ID); err != nil || n != 3 {
+		return nil, ErrInvalidCursor
+	}
+	if c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
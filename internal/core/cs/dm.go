@@ -0,0 +1,33 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 私信消息类型
+	DmMsgText DmMsgT = iota + 1
+	DmMsgAudio
+	DmMsgImage
+)
+
+const (
+	// 私信权限，DmPolicyFollowers 只接收关注者以及自己关注的人的私信
+	DmPolicyAnyone DmPolicyT = iota
+	DmPolicyFollowers
+)
+
+type (
+	DmMsgT    int8
+	DmPolicyT int8
+)
+
+// Valid 是否为支持的消息类型
+func (t DmMsgT) Valid() bool {
+	return t >= DmMsgText && t <= DmMsgImage
+}
+
+// Valid 是否为支持的私信权限
+func (p DmPolicyT) Valid() bool {
+	return p == DmPolicyAnyone || p == DmPolicyFollowers
+}
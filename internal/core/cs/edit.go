@@ -0,0 +1,13 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 编辑历史所属的对象，对应 TargetID 为动态ID或评论ID
+	EditTargetPost EditTargetT = iota + 1
+	EditTargetComment
+)
+
+type EditTargetT int8
@@ -0,0 +1,15 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+import "github.com/rocboss/paopao-ce/pkg/rank"
+
+type (
+	// FeedViewer 个性化推荐的用户画像，包括关注的分类/话题/用户及互动过的用户
+	FeedViewer = rank.Viewer
+
+	// FeedCandidate 个性化推荐的候选动态
+	FeedCandidate = rank.Candidate
+)
@@ -0,0 +1,22 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 提及所在的内容，对应 SourceID 为动态内容、评论内容或回复的ID
+	MentionSourcePost MentionSourceT = iota + 1
+	MentionSourceComment
+	MentionSourceReply
+)
+
+type MentionSourceT int8
+
+// MentionCandidate @提及自动补全的候选用户，InteractedOn 为与作者最近一次互动或私信的时间
+type MentionCandidate struct {
+	UserID       int64
+	IsFollowing  bool
+	HasTalked    bool
+	InteractedOn int64
+}
@@ -14,3 +14,34 @@ const (
 )
 
 type MessageStyle string
+
+const (
+	// 通知分类
+	MsgCategoryMention    MessageCategory = "mention"
+	MsgCategoryComment    MessageCategory = "comment"
+	MsgCategoryReaction   MessageCategory = "reaction"
+	MsgCategoryFollow     MessageCategory = "follow"
+	MsgCategoryFriend     MessageCategory = "friend"
+	MsgCategoryRoomInvite MessageCategory = "room_invite"
+	MsgCategorySystem     MessageCategory = "system"
+	MsgCategoryWhisper    MessageCategory = "whisper"
+)
+
+// MessageCategory 通知分类，空值表示全部分类
+type MessageCategory string
+
+// MessageUnreadCount 未读通知数，同一分组的通知只计一条
+type MessageUnreadCount struct {
+	Total      int64                     `json:"total"`
+	Categories map[MessageCategory]int64 `json:"categories"`
+}
+
+func (c MessageCategory) Valid() bool {
+	switch c {
+	case "", MsgCategoryMention, MsgCategoryComment, MsgCategoryReaction, MsgCategoryFollow,
+		MsgCategoryFriend, MsgCategoryRoomInvite, MsgCategorySystem, MsgCategoryWhisper:
+		return true
+	default:
+		return false
+	}
+}
@@ -22,6 +22,7 @@ type TweetMetric struct {
 type CommentMetric struct {
 	CommentId       int64
 	ReplyCount      int32
+	AudioReplyCount int32
 	ThumbsUpCount   int32
 	ThumbsDownCount int32
 }
@@ -37,5 +38,6 @@ func (m *CommentMetric) RankScore(motivationFactor int) int64 {
 	if motivationFactor == 0 {
 		motivationFactor = 1
 	}
-	return int64(m.ReplyCount*2+m.ThumbsUpCount*4-m.ThumbsDownCount) * int64(motivationFactor)
+	// 语音回复已计入回复数，再额外加权
+	return int64(m.ReplyCount*2+m.AudioReplyCount*2+m.ThumbsUpCount*4-m.ThumbsDownCount) * int64(motivationFactor)
 }
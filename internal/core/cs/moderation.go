@@ -0,0 +1,68 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+import (
+	"strings"
+)
+
+const (
+	// 内容审核结论
+	ModerationAllow ModerationVerdict = iota
+	ModerationFlag
+	ModerationReject
+)
+
+const (
+	// 内容审核场景
+	ModerationSceneTweet    ModerationScene = "tweet"
+	ModerationSceneComment  ModerationScene = "comment"
+	ModerationSceneReply    ModerationScene = "reply"
+	ModerationSceneNickname ModerationScene = "nickname"
+	ModerationSceneUsername ModerationScene = "username"
+	ModerationSceneMessage  ModerationScene = "message"
+)
+
+type (
+	ModerationVerdict uint8
+	ModerationScene   string
+)
+
+// ModerationResult result of a content moderation check
+type ModerationResult struct {
+	Verdict ModerationVerdict `json:"verdict"`
+	Labels  []string          `json:"labels"`
+}
+
+func (v ModerationVerdict) String() string {
+	switch v {
+	case ModerationFlag:
+		return "flag"
+	case ModerationReject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// ModerationVerdictFrom parse verdict from text, unknown text is treated as allow
+func ModerationVerdictFrom(s string) ModerationVerdict {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "flag", "review":
+		return ModerationFlag
+	case "reject", "block":
+		return ModerationReject
+	default:
+		return ModerationAllow
+	}
+}
+
+// Merge merge a verdict with labels into result, the strictest verdict wins
+func (r *ModerationResult) Merge(verdict ModerationVerdict, labels ...string) {
+	if verdict > r.Verdict {
+		r.Verdict = verdict
+	}
+	r.Labels = append(r.Labels, labels...)
+}
@@ -0,0 +1,10 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+import "github.com/rocboss/paopao-ce/pkg/geo"
+
+// GeoBox 附近查询的经纬度范围，MinLng > MaxLng 表示跨越180度经线
+type GeoBox = geo.Box
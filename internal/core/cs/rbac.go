@@ -0,0 +1,77 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 内置角色
+	RoleAdmin     RoleName = "admin"
+	RoleModerator RoleName = "moderator"
+	RoleSupport   RoleName = "support"
+)
+
+const (
+	// 超级管理员(is_admin)在管理层级中的等级，高于所有内置角色
+	RankSuperAdmin = 4
+)
+
+const (
+	// 权限
+	PermAdminConsole   Permission = "admin.console"
+	PermUserView       Permission = "user.view"
+	PermUserSuspend    Permission = "user.suspend"
+	PermRoleManage     Permission = "role.manage"
+	PermContentManage  Permission = "content.manage"
+	PermTweetStick     Permission = "tweet.stick"
+	PermTweetViewAll   Permission = "tweet.view_all"
+	PermReportHandle   Permission = "report.handle"
+	PermRoomManage     Permission = "room.manage"
+	PermSiteStats      Permission = "site.stats"
+	PermSearchManage   Permission = "search.manage"
+	PermActivationCode Permission = "activation.create"
+	PermUserCreate     Permission = "user.create"
+	PermTweetCreate    Permission = "tweet.create"
+	PermCommentCreate  Permission = "comment.create"
+	// 不授予内置角色，仅超级管理员拥有
+	PermFeatureManage Permission = "feature.manage"
+)
+
+type (
+	RoleName   string
+	Permission string
+
+	// PermissionSet 用户拥有的权限集合
+	PermissionSet map[Permission]struct{}
+)
+
+func (r RoleName) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleModerator, RoleSupport:
+		return true
+	}
+	return false
+}
+
+// Rank 角色在管理层级中的等级，非运营角色为0
+func (r RoleName) Rank() int {
+	switch r {
+	case RoleAdmin:
+		return 3
+	case RoleModerator:
+		return 2
+	case RoleSupport:
+		return 1
+	}
+	return 0
+}
+
+func (s PermissionSet) Has(perm Permission) bool {
+	_, yeah := s[perm]
+	return yeah
+}
+
+// IsStaff 是否拥有任意运营管理权限，内置角色的权限都是运营管理权限
+func (s PermissionSet) IsStaff() bool {
+	return len(s) > 0
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 举报对象类型
+	ReportTargetPost    ReportTargetType = "post"
+	ReportTargetComment ReportTargetType = "comment"
+	ReportTargetReply   ReportTargetType = "reply"
+	ReportTargetRoom    ReportTargetType = "room"
+	ReportTargetUser    ReportTargetType = "user"
+)
+
+const (
+	// 举报理由
+	ReportReasonSpam          ReportReason = "spam"
+	ReportReasonHarassment    ReportReason = "harassment"
+	ReportReasonHateSpeech    ReportReason = "hate_speech"
+	ReportReasonNudity        ReportReason = "nudity"
+	ReportReasonViolence      ReportReason = "violence"
+	ReportReasonSelfHarm      ReportReason = "self_harm"
+	ReportReasonImpersonation ReportReason = "impersonation"
+	ReportReasonUnderage      ReportReason = "underage"
+	ReportReasonOther         ReportReason = "other"
+	ReportReasonAutoFlagged   ReportReason = "auto_flagged"
+)
+
+const (
+	// 举报处理状态
+	ReportStatusPending int8 = iota + 1
+	ReportStatusTriaged
+	ReportStatusDismissed
+	ReportStatusActioned
+)
+
+const (
+	// 审核处理动作
+	ModerationActTriage      ModerationAction = "triage"
+	ModerationActDismiss     ModerationAction = "dismiss"
+	ModerationActHidePost    ModerationAction = "hide_post"
+	ModerationActLockComment ModerationAction = "lock_comments"
+	ModerationActSuspendUser ModerationAction = "suspend_user"
+	ModerationActBanFromRoom ModerationAction = "ban_from_rooms"
+	ModerationActRestoreUser ModerationAction = "restore_user"
+
+	// 管理后台操作，仅记录审核日志，不能用于处理举报
+	ModerationActGrantAdmin  ModerationAction = "grant_admin"
+	ModerationActRevokeAdmin ModerationAction = "revoke_admin"
+	ModerationActForceLogout ModerationAction = "force_logout"
+	ModerationActDeletePost  ModerationAction = "delete_post"
+	ModerationActRestorePost ModerationAction = "restore_post"
+	ModerationActAssignRole  ModerationAction = "assign_role"
+	ModerationActRevokeRole  ModerationAction = "revoke_role"
+	ModerationActBlockRoom   ModerationAction = "block_room"
+	ModerationActUnblockRoom ModerationAction = "unblock_room"
+)
+
+type (
+	ReportTargetType string
+	ReportReason     string
+	ModerationAction string
+)
+
+// ReportEvidence evidence attached to a report by the reporter, or the labels
+// given by content moderation for auto flagged content
+type ReportEvidence struct {
+	Description string   `json:"description"`
+	Attachments []string `json:"attachments"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+func (t ReportTargetType) IsValid() bool {
+	switch t {
+	case ReportTargetPost, ReportTargetComment, ReportTargetReply, ReportTargetRoom, ReportTargetUser:
+		return true
+	}
+	return false
+}
+
+func (r ReportReason) IsValid() bool {
+	switch r {
+	case ReportReasonSpam,
+		ReportReasonHarassment,
+		ReportReasonHateSpeech,
+		ReportReasonNudity,
+		ReportReasonViolence,
+		ReportReasonSelfHarm,
+		ReportReasonImpersonation,
+		ReportReasonUnderage,
+		ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// IsResolved whether the action close a report
+func (a ModerationAction) IsResolved() bool {
+	switch a {
+	case ModerationActTriage:
+		return false
+	}
+	return true
+}
+
+// IsValid whether the action can be used to handle a report
+func (a ModerationAction) IsValid() bool {
+	switch a {
+	case ModerationActTriage,
+		ModerationActDismiss,
+		ModerationActHidePost,
+		ModerationActLockComment,
+		ModerationActSuspendUser,
+		ModerationActBanFromRoom:
+		return true
+	}
+	return false
+}
@@ -4,6 +4,14 @@
 
 package cs
 
+const (
+	// 房间邀请来源，RoomInvitePing 为向开启提醒的关注者群发的"叫人来"
+	RoomInviteDirect RoomInviteSourceT = iota + 1
+	RoomInvitePing
+)
+
+type RoomInviteSourceT int8
+
 // RoomInfo represents room information for common use
 type RoomInfo struct {
 	ID                int64     `json:"id"`
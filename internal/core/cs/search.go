@@ -36,3 +36,77 @@ type TsDocItem struct {
 	Post    *TweetInfo
 	Content string
 }
+
+const (
+	// 搜索索引
+	SearchIndexPost  SearchIndex = "posts"
+	SearchIndexUser  SearchIndex = "users"
+	SearchIndexRoom  SearchIndex = "rooms"
+	SearchIndexTopic SearchIndex = "topics"
+
+	// 搜索索引变更操作，upsert/delete 携带文档，sync 按数据库当前状态同步
+	SearchOpUpsert SearchOp = "upsert"
+	SearchOpDelete SearchOp = "delete"
+	SearchOpSync   SearchOp = "sync"
+
+	// 搜索发件箱状态
+	SearchOutboxPending int8 = 0
+	SearchOutboxDone    int8 = 1
+	SearchOutboxFailed  int8 = 2
+)
+
+// SearchIndexes 全部搜索索引
+var SearchIndexes = []SearchIndex{SearchIndexPost, SearchIndexUser, SearchIndexRoom, SearchIndexTopic}
+
+type (
+	// SearchIndex 搜索索引类型
+	SearchIndex string
+
+	// SearchOp 搜索索引变更操作
+	SearchOp string
+)
+
+// SearchIndexDrift 数据库与搜索索引的文档数比较
+type SearchIndexDrift struct {
+	Index        SearchIndex `json:"index"`
+	IndexName    string      `json:"index_name"`
+	DbCount      int64       `json:"db_count"`
+	IndexCount   int64       `json:"index_count"`
+	PendingCount int64       `json:"pending_count"`
+	FailedCount  int64       `json:"failed_count"`
+}
+
+// Drift 索引比数据库多出(正数)或缺少(负数)的文档数
+func (d *SearchIndexDrift) Drift() int64 {
+	return d.IndexCount - d.DbCount
+}
+
+// UserSearchDoc 用户搜索文档，按用户名、昵称、分类名检索
+type UserSearchDoc struct {
+	ID            int64    `json:"id"`
+	Username      string   `json:"username"`
+	Nickname      string   `json:"nickname"`
+	Avatar        string   `json:"avatar"`
+	Categories    []int64  `json:"categories"`
+	CategoryNames []string `json:"category_names"`
+}
+
+// RoomSearchDoc 房间搜索文档，按话题、分类名、房主检索
+type RoomSearchDoc struct {
+	ID            int64    `json:"id"`
+	HostID        int64    `json:"host_id"`
+	HostUsername  string   `json:"host_username"`
+	HostNickname  string   `json:"host_nickname"`
+	HostAvatar    string   `json:"host_avatar"`
+	Topics        []string `json:"topics"`
+	Categories    []int64  `json:"categories"`
+	CategoryNames []string `json:"category_names"`
+	CreatedOn     int64    `json:"created_on"`
+}
+
+// TopicSearchDoc 话题搜索文档
+type TopicSearchDoc struct {
+	ID       int64  `json:"id"`
+	Tag      string `json:"tag"`
+	QuoteNum int64  `json:"quote_num"`
+}
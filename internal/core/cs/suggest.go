@@ -0,0 +1,19 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+const (
+	// 推荐反馈，SuggestDismiss 暂时忽略，过期后可再次推荐；SuggestHide 永久不再推荐
+	SuggestDismiss SuggestFeedbackT = iota + 1
+	SuggestHide
+)
+
+type SuggestFeedbackT int8
+
+// UserSignalCount 候选用户及其信号计数
+type UserSignalCount struct {
+	UserID int64
+	Total  int
+}
@@ -44,6 +44,15 @@ type TagItem struct {
 	IsPin       int8      `json:"is_pin"`
 }
 
+// IDs 标签ID列表
+func (t TagInfoList) IDs() []int64 {
+	ids := make([]int64, 0, len(t))
+	for _, info := range t {
+		ids = append(ids, info.ID)
+	}
+	return ids
+}
+
 func (t *TagInfo) Format() *TagItem {
 	return &TagItem{
 		ID:          t.ID,
@@ -0,0 +1,28 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package cs
+
+import "strings"
+
+// TranscriptSegment 带时间码的转写片段，Start/End 为相对录音开始的毫秒数，
+// SpeakerID 为该音轨对应的用户
+type TranscriptSegment struct {
+	SpeakerID int64  `json:"speaker_id"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Text      string `json:"text"`
+}
+
+// TranscriptSegments 转写片段列表
+type TranscriptSegments []*TranscriptSegment
+
+// Text 拼接全部片段文本，用于写入搜索文档
+func (s TranscriptSegments) Text() string {
+	texts := make([]string, 0, len(s))
+	for _, seg := range s {
+		texts = append(texts, seg.Text)
+	}
+	return strings.Join(texts, "\n")
+}
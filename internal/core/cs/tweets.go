@@ -32,6 +32,7 @@ const (
 	AttachmentTypeImage AttachmentType = iota + 1
 	AttachmentTypeVideo
 	AttachmentTypeOther
+	AttachmentTypeAudio
 )
 
 type (
@@ -42,7 +43,7 @@ type (
 	// TweetVisibleType 推文可见性: 0私密 10充电可见 20订阅可见 30保留 40保留 50好友可见 60关注可见 70保留 80保留 90公开',
 	TweetVisibleType uint8
 
-	// AttachmentType 附件类型， 1图片， 2视频， 3其他
+	// AttachmentType 附件类型， 1图片， 2视频， 3其他， 4语音
 	// TODO: 优化一下类型为 uint8， 需要底层数据库同步修改
 	AttachmentType int
 
@@ -120,6 +121,8 @@ type Attachment struct {
 	ImgHeight int            `json:"img_height"`
 	Type      AttachmentType `json:"type"`
 	Content   string         `json:"content"`
+	Duration  int64          `json:"duration"`
+	Waveform  []int          `json:"waveform"`
 }
 
 // Favorite 收藏
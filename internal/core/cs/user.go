@@ -163,6 +163,8 @@ type UserProfile struct {
 	CreatedOn   int64            `json:"created_on"`
 	TweetsCount int              `json:"tweets_count"`
 	Categories  Int64Array       `json:"categories" db:"categories"`
+	DmPolicy    DmPolicyT        `json:"dm_policy"`
+	IsPrivate   bool             `json:"is_private"`
 	ReactionCounts map[int64]int64 `json:"reaction_counts"` // reaction_type_id -> count (reactions received)
 }
 
@@ -356,9 +358,15 @@ func GetReactionIcon(reactionTypeID int64) string {
 	}
 }
 
+// PositiveReactionRange returns the first and last positive reaction type
+func PositiveReactionRange() (int64, int64) {
+	return ReactionTypeLike, ReactionTypeCreative
+}
+
 // IsPositiveReaction returns true if the reaction type is positive
 func IsPositiveReaction(reactionTypeID int64) bool {
-	return reactionTypeID >= 1 && reactionTypeID <= 15
+	first, last := PositiveReactionRange()
+	return reactionTypeID >= first && reactionTypeID <= last
 }
 
 // IsNegativeReaction returns true if the reaction type is negative
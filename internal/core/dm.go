@@ -0,0 +1,30 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// DirectMessageService 私信会话服务，每对用户只有一个会话
+type DirectMessageService interface {
+	GetOrCreateDmConversation(userId int64, peerId int64) (*ms.DmParticipant, error)
+	GetDmParticipant(conversationId int64, userId int64) (*ms.DmParticipant, error)
+	GetDmParticipantByPeer(userId int64, peerId int64) (*ms.DmParticipant, error)
+	ListDmConversations(userId int64, limit int, offset int) ([]*ms.DmConversationFormated, int64, error)
+	CreateDmMessage(msg *ms.DmMessage) (*ms.DmMessage, error)
+	ListDmMessages(conversationId int64, cursor *cs.PageCursor, limit int) ([]*ms.DmMessage, *cs.PageCursor, error)
+	ReadDmConversation(conversationId int64, userId int64, messageId int64) error
+	CountDmUnread(userId int64) (int64, error)
+}
+
+// UserBlockService 用户屏蔽服务
+type UserBlockService interface {
+	BlockUser(userId int64, blockedId int64) error
+	UnblockUser(userId int64, blockedId int64) error
+	IsBlockedEither(userId int64, otherId int64) bool
+	ListBlockedUsers(userId int64, limit int, offset int) ([]*ms.User, int64, error)
+}
@@ -0,0 +1,17 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// EditService 动态与评论编辑服务
+type EditService interface {
+	EditPost(post *ms.Post, contents []*ms.PostContent, tags []string) (cs.TagInfoList, error)
+	EditComment(comment *ms.Comment, contents []*ms.CommentContent) error
+	ListEditRevisions(target cs.EditTargetT, targetId int64) ([]*ms.EditRevision, error)
+}
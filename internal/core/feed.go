@@ -0,0 +1,15 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// FeedRankService 个性化推荐("为你推荐")动态的候选集与用户画像服务
+type FeedRankService interface {
+	ListFeedCandidates(since int64, limit int) ([]*cs.FeedCandidate, error)
+	GetFeedViewer(userId int64) (*cs.FeedViewer, error)
+}
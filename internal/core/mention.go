@@ -0,0 +1,18 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// MentionService @提及服务
+type MentionService interface {
+	CreateMentions(mentions []*ms.Mention) error
+	ListMentionedUserIds(source cs.MentionSourceT, targetId int64) ([]int64, error)
+	DeleteMentions(source cs.MentionSourceT, targetId int64) error
+	ListMentionCandidates(userId int64, keyword string, limit int) ([]*cs.MentionCandidate, error)
+}
@@ -12,9 +12,10 @@ import (
 // MessageService 消息服务
 type MessageService interface {
 	CreateMessage(msg *ms.Message) (*ms.Message, error)
-	GetUnreadCount(userID int64) (int64, error)
+	GetUnreadCount(userID int64) (*cs.MessageUnreadCount, error)
 	GetMessageByID(id int64) (*ms.Message, error)
 	ReadMessage(message *ms.Message) error
-	ReadAllMessage(userId int64) error
-	GetMessages(userId int64, style cs.MessageStyle, limit, offset int) ([]*ms.MessageFormated, int64, error)
+	ReadAllMessage(userId int64, category cs.MessageCategory, cursor *cs.PageCursor) error
+	GetMessages(userId int64, style cs.MessageStyle, category cs.MessageCategory, limit, offset int) ([]*ms.MessageFormated, int64, error)
+	GetMessagesByCursor(userId int64, style cs.MessageStyle, category cs.MessageCategory, cursor *cs.PageCursor, limit int) ([]*ms.MessageFormated, *cs.PageCursor, error)
 }
@@ -5,6 +5,7 @@
 package ms
 
 import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/pkg/types"
 )
 
@@ -34,6 +35,9 @@ const (
 	ActVisibleTweet
 	ActDeleteTweet
 	ActCreateActivationCode
+
+	// actCount 动作总数，新增动作须加在其前面并在 Permission 中映射权限
+	actCount
 )
 
 type (
@@ -53,6 +57,41 @@ func (f FriendFilter) IsFriend(userId int64) bool {
 	return yeah
 }
 
+// Permission 不受归属、好友关系与手机绑定限制执行该动作所需的权限，为空表示只能按默认规则授权
+func (a act) Permission() cs.Permission {
+	switch a {
+	case ActRegisterUser:
+		return cs.PermUserCreate
+	case ActCreatePublicTweet,
+		ActCreatePublicAttachment,
+		ActCreatePublicPicture,
+		ActCreatePublicVideo,
+		ActCreatePrivateTweet,
+		ActCreatePrivateAttachment,
+		ActCreatePrivatePicture,
+		ActCreatePrivateVideo,
+		ActCreateFriendTweet,
+		ActCreateFriendAttachment,
+		ActCreateFriendPicture,
+		ActCreateFriendVideo:
+		return cs.PermTweetCreate
+	case ActCreatePublicComment,
+		ActCreatePublicPicureComment,
+		ActCreateFriendComment,
+		ActCreateFriendPicureComment,
+		ActCreatePrivateComment,
+		ActCreatePrivatePicureComment:
+		return cs.PermCommentCreate
+	case ActStickTweet, ActTopTweet:
+		return cs.PermTweetStick
+	case ActLockTweet, ActVisibleTweet, ActDeleteTweet:
+		return cs.PermContentManage
+	case ActCreateActivationCode:
+		return cs.PermActivationCode
+	}
+	return ""
+}
+
 // IsAllow default true if user is admin
 func (a act) IsAllow(user *User, userId int64, isFriend bool, isActivation bool) bool {
 	if user.IsAdmin {
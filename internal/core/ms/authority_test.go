@@ -0,0 +1,39 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ms
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+var _ = ginkgo.Describe("Authority", func() {
+	ginkgo.It("every action is mapped to a permission", func() {
+		for a := act(0); a < actCount; a++ {
+			Expect(a.Permission()).NotTo(BeEmpty(), "action %d is not mapped to a permission", a)
+		}
+	})
+
+	ginkgo.DescribeTable("permission of action",
+		func(a act, perm cs.Permission) {
+			Expect(a.Permission()).To(Equal(perm))
+		},
+		ginkgo.Entry("register user", ActRegisterUser, cs.PermUserCreate),
+		ginkgo.Entry("create public tweet", ActCreatePublicTweet, cs.PermTweetCreate),
+		ginkgo.Entry("create private video", ActCreatePrivateVideo, cs.PermTweetCreate),
+		ginkgo.Entry("create friend attachment", ActCreateFriendAttachment, cs.PermTweetCreate),
+		ginkgo.Entry("create public comment", ActCreatePublicComment, cs.PermCommentCreate),
+		ginkgo.Entry("create friend picture comment", ActCreateFriendPicureComment, cs.PermCommentCreate),
+		ginkgo.Entry("create private comment", ActCreatePrivateComment, cs.PermCommentCreate),
+		ginkgo.Entry("stick tweet", ActStickTweet, cs.PermTweetStick),
+		ginkgo.Entry("top tweet", ActTopTweet, cs.PermTweetStick),
+		ginkgo.Entry("lock tweet", ActLockTweet, cs.PermContentManage),
+		ginkgo.Entry("visible tweet", ActVisibleTweet, cs.PermContentManage),
+		ginkgo.Entry("delete tweet", ActDeleteTweet, cs.PermContentManage),
+		ginkgo.Entry("create activation code", ActCreateActivationCode, cs.PermActivationCode),
+	)
+})
@@ -16,6 +16,9 @@ const (
 	MsgTypeRequestingFriend = dbr.MsgTypeRequestingFriend
 	MsgTypeFollow           = dbr.MsgTypeFollow
 	MsgTypeUserReaction     = dbr.MsgTypeUserReaction
+	MsgTypeRoomInvite       = dbr.MsgTypeRoomInvite
+	MsgTypeFollowRequest    = dbr.MsgTypeFollowRequest
+	MsgTypeFollowApproved   = dbr.MsgTypeFollowApproved
 	MsgTypeSystem           = dbr.MsgTypeSystem
 
 	MsgStatusUnread = dbr.MsgStatusUnread
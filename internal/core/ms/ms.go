@@ -29,4 +29,22 @@ type (
 	Category            = dbr.Category
 	UserCategory        = dbr.UserCategory
 	UserReaction        = dbr.UserReaction
+	Report              = dbr.Report
+	ModerationLog       = dbr.ModerationLog
+	AccountExport       = dbr.AccountExport
+	AccountDeletion     = dbr.AccountDeletion
+	AccountArchive      = dbr.AccountArchive
+	Role                = dbr.Role
+	PostTranscript      = dbr.PostTranscript
+	SearchOutbox        = dbr.SearchOutbox
+	DmConversation      = dbr.DmConversation
+	DmParticipant       = dbr.DmParticipant
+	DmMessage           = dbr.DmMessage
+	UserBlock           = dbr.UserBlock
+	RoomInvite          = dbr.RoomInvite
+	UserSuggestion      = dbr.UserSuggestion
+	Mention             = dbr.Mention
+	EditRevision        = dbr.EditRevision
+
+	DmConversationFormated = dbr.DmConversationFormated
 )
@@ -12,6 +12,7 @@ const (
 	AttachmentTypeImage = dbr.AttachmentTypeImage
 	AttachmentTypeVideo = dbr.AttachmentTypeVideo
 	AttachmentTypeOther = dbr.AttachmentTypeOther
+	AttachmentTypeAudio = dbr.AttachmentTypeAudio
 
 	// 类型，1标题，2文字段落，3图片地址，4视频地址，5语音地址，6链接地址，7附件资源
 	ContentTypeTitle            = dbr.ContentTypeTitle
@@ -31,6 +32,19 @@ const (
 	PostVisitFollowing = dbr.PostVisitFollowing
 )
 
+const (
+	PostRepostNone  = dbr.PostRepostNone
+	PostRepostPlain = dbr.PostRepostPlain
+	PostRepostQuote = dbr.PostRepostQuote
+)
+
+const (
+	PostStatusPublished  = dbr.PostStatusPublished
+	PostStatusDraft      = dbr.PostStatusDraft
+	PostStatusProcessing = dbr.PostStatusProcessing
+	PostStatusScheduled  = dbr.PostStatusScheduled
+)
+
 type (
 	PostStar           = dbr.PostStar
 	PostCollection     = dbr.PostCollection
@@ -40,5 +54,7 @@ type (
 	AttachmentType     = dbr.AttachmentType
 	PostContentT       = dbr.PostContentT
 	PostVisibleT       = dbr.PostVisibleT
+	PostRepostT        = dbr.PostRepostT
+	PostStatusT        = dbr.PostStatusT
 	LocationData       = dbr.LocationData
 )
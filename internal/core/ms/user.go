@@ -6,13 +6,17 @@ package ms
 
 type (
 	ContactItem struct {
-		UserId      int64  `json:"user_id"`
-		Username    string `db:"username" json:"username"`
-		Nickname    string `json:"nickname"`
-		Avatar      string `json:"avatar"`
-		Phone       string `json:"phone,omitempty"`
-		IsFollowing bool   `json:"is_following"`
-		CreatedOn   int64  `json:"created_on"`
+		UserId       int64  `json:"user_id"`
+		Username     string `db:"username" json:"username"`
+		Nickname     string `json:"nickname"`
+		Avatar       string `json:"avatar"`
+		Phone        string `json:"phone,omitempty"`
+		IsFollowing  bool   `json:"is_following"`
+		FollowsYou   bool   `json:"follows_you"`
+		IsFriend     bool   `json:"is_friend"`
+		IsOnline     bool   `json:"is_online,omitempty"`
+		CreatedOn    int64  `json:"created_on"`
+		InteractedOn int64  `json:"interacted_on,omitempty"`
 	}
 
 	ContactList struct {
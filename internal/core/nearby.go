@@ -0,0 +1,16 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// NearbyService 附近的动态与房间服务，按经纬度范围粗筛并按近似距离截断候选，精确距离由调用方计算
+type NearbyService interface {
+	ListNearbyPosts(box cs.GeoBox, lat, lng float64, limit int) ([]*ms.Post, error)
+	ListNearbyRooms(box cs.GeoBox, lat, lng float64, limit int) ([]*ms.Room, error)
+}
@@ -0,0 +1,20 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// ReportService 举报与审核服务
+type ReportService interface {
+	CreateReport(report *ms.Report) (*ms.Report, error)
+	GetReportByID(id int64) (*ms.Report, error)
+	ListReports(status int8, targetType cs.ReportTargetType, limit, offset int) ([]*ms.Report, int64, error)
+	UpdateReport(report *ms.Report) error
+	CreateModerationLog(log *ms.ModerationLog) error
+	ListModerationLogs(reportID int64, limit, offset int) ([]*ms.ModerationLog, int64, error)
+}
@@ -36,4 +36,7 @@ type RoomService interface {
 	
 	// Update room categories for a host
 	UpdateCategoriesByHostID(hostID int64, categoryIDs []int64) error
+
+	// Update room coordinates for a host, used by nearby rooms
+	UpdateLocationByHostID(hostID int64, lat, lng float64) error
 } 
\ No newline at end of file
@@ -0,0 +1,20 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// RoomInviteService 房间邀请服务
+type RoomInviteService interface {
+	CreateRoomInvites(invites []*ms.RoomInvite) error
+	AcceptRoomInvite(roomId int64, inviteeId int64) (*ms.RoomInvite, error)
+}
+
+// RoomInvitePushService 房间邀请推送服务
+type RoomInvitePushService interface {
+	SendRoomInviteNotification(inviteeIds []int64, roomId int64, hostName string, ping bool) error
+}
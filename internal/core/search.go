@@ -5,6 +5,7 @@
 package core
 
 import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 )
@@ -51,3 +52,46 @@ type TweetSearchService interface {
 	DeleteDocuments(identifiers []string) error
 	Search(user *ms.User, q *QueryReq, offset, limit int) (*QueryResp, error)
 }
+
+// EntitySearchService 用户、房间、话题搜索服务
+type EntitySearchService interface {
+	UpsertUsers(docs []*cs.UserSearchDoc) error
+	UpsertRooms(docs []*cs.RoomSearchDoc) error
+	UpsertTopics(docs []*cs.TopicSearchDoc) error
+	DeleteDocuments(index cs.SearchIndex, ids []int64) error
+	SearchUsers(query string, offset, limit int) ([]*cs.UserSearchDoc, int64, error)
+	SearchRooms(query string, offset, limit int) ([]*cs.RoomSearchDoc, int64, error)
+	SearchTopics(query string, offset, limit int) ([]*cs.TopicSearchDoc, int64, error)
+}
+
+// EntitySearchSyncService 为用户、房间、话题搜索索引提供数据，
+// ids 不为空时只获取指定的记录(已删除/封禁的不返回)，否则按id分页获取全部
+type EntitySearchSyncService interface {
+	ListUserSearchDocs(ids []int64, limit, offset int) ([]*cs.UserSearchDoc, error)
+	ListRoomSearchDocs(ids []int64, limit, offset int) ([]*cs.RoomSearchDoc, error)
+	ListTopicSearchDocs(ids []int64, limit, offset int) ([]*cs.TopicSearchDoc, error)
+	CountSearchDocs(index cs.SearchIndex) (int64, error)
+}
+
+// SearchOutboxService 搜索索引变更发件箱，变更先落库再由后台按重试策略写入搜索引擎
+type SearchOutboxService interface {
+	AppendSearchOutbox(items ...*ms.SearchOutbox) error
+	// ClaimSearchOutbox 领取到期的待处理记录，领取后lease秒内不会被再次领取
+	ClaimSearchOutbox(now int64, lease int64, limit int) ([]*ms.SearchOutbox, error)
+	FinishSearchOutbox(ids ...int64) error
+	RetrySearchOutbox(item *ms.SearchOutbox) error
+	// IsSearchOutboxSuperseded 同一文档更新版本的变更是否已经写入
+	IsSearchOutboxSuperseded(item *ms.SearchOutbox) (bool, error)
+	// ReplaySearchOutbox 把since之后已处理的记录重新置为待处理，用于索引切换后补写
+	ReplaySearchOutbox(index cs.SearchIndex, since int64) (int64, error)
+	PurgeSearchOutbox(before int64) error
+	CountSearchOutbox(index cs.SearchIndex, status int8) (int64, error)
+}
+
+// SearchIndexManageService 搜索索引重建与一致性检查
+type SearchIndexManageService interface {
+	// Reindex 在新索引中重建全部文档后原子切换为在用索引，返回写入的文档数
+	Reindex(index cs.SearchIndex, batchSize int) (int, error)
+	// CheckDrift 比较数据库与在用索引的文档数
+	CheckDrift(index cs.SearchIndex) (*cs.SearchIndexDrift, error)
+}
@@ -7,6 +7,7 @@ package core
 import (
 	"time"
 
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 )
 
@@ -26,3 +27,8 @@ type AttachmentCheckService interface {
 type PhoneVerifyService interface {
 	SendPhoneCaptcha(phone string, captcha string, expire time.Duration) error
 }
+
+// ContentModerationService 内容审核服务
+type ContentModerationService interface {
+	Moderate(scene cs.ModerationScene, texts ...string) (*cs.ModerationResult, error)
+}
@@ -0,0 +1,22 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// FriendSuggestService "可能认识的人"推荐服务
+type FriendSuggestService interface {
+	ListSuggestUserIds(afterId int64, limit int) ([]int64, error)
+	ListContactMatchedIds(userId int64) ([]int64, error)
+	ListSharedCategoryUsers(userId int64, limit int) ([]*cs.UserSignalCount, error)
+	ListPositiveReactionUsers(userId int64, limit int) ([]*cs.UserSignalCount, error)
+	ListSuggestFeedbackIds(userId int64, dismissSince int64) ([]int64, error)
+	ReplaceUserSuggestions(userId int64, items []*ms.UserSuggestion) error
+	ListUserSuggestions(userId int64, limit int, offset int) ([]*ms.UserSuggestion, int64, error)
+	CreateSuggestFeedback(userId int64, suggestedId int64, action cs.SuggestFeedbackT) error
+}
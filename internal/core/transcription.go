@@ -0,0 +1,25 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// TranscriptionService 音频转写服务
+type TranscriptionService interface {
+	// Transcribe 转写音频，未开启转写功能时返回空结果
+	Transcribe(audioUrl string) (cs.TranscriptSegments, error)
+}
+
+// TranscriptService 动态音频转写文本数据服务
+type TranscriptService interface {
+	// SaveTranscript 保存某条音轨的转写片段，覆盖该音轨之前的转写
+	SaveTranscript(postId int64, speakerId int64, segments cs.TranscriptSegments) error
+	// GetTranscript 获取动态的转写片段，按时间码排序
+	GetTranscript(postId int64) (cs.TranscriptSegments, error)
+	// GetTranscriptTexts 批量获取动态的转写文本
+	GetTranscriptTexts(postIds []int64) (map[int64]string, error)
+}
@@ -13,6 +13,10 @@ import (
 type TweetService interface {
 	GetPostByID(id int64) (*ms.Post, error)
 	GetPosts(conditions ms.ConditionsT, offset, limit int) ([]*ms.Post, error)
+	GetUserRepost(userId int64, originId int64) (*ms.Post, error)
+	ListThreadTweets(rootId int64) ([]*ms.Post, error)
+	ListUserDraftTweets(userId int64, limit, offset int) ([]*ms.Post, int64, error)
+	ListDueScheduledTweets(now int64, limit int) ([]*ms.Post, error)
 	GetPostCount(conditions ms.ConditionsT) (int64, error)
 	GetUserPostStar(postID, userID int64) (*ms.PostStar, error)
 	GetUserPostStars(userID int64, limit int, offset int) ([]*ms.PostStar, error)
@@ -38,6 +42,14 @@ type TweetService interface {
 	ListIndexNewestTweets(limit, offset int) ([]*ms.Post, int64, error)
 	ListIndexHotsTweets(limit, offset int) ([]*ms.Post, int64, error)
 	ListSyncSearchTweets(limit, offset int) ([]*ms.Post, int64, error)
+
+	// 游标分页，返回下一页游标，没有更多数据时为nil
+	ListUserStarTweetsByCursor(user *cs.VistUser, cursor *cs.PageCursor, limit int) ([]*ms.PostStar, *cs.PageCursor, error)
+	ListUserMediaTweetsByCursor(user *cs.VistUser, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error)
+	ListUserCommentTweetsByCursor(user *cs.VistUser, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error)
+	ListUserTweetsByCursor(userId int64, style uint8, justEssence bool, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error)
+	ListFollowingTweetsByCursor(userId int64, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error)
+	ListIndexNewestTweetsByCursor(cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error)
 }	
 
 // TweetManageService 推文管理服务，包括创建/删除/更新推文
@@ -48,14 +60,21 @@ type TweetManageService interface {
 	StickPost(post *ms.Post) error
 	HighlightPost(userId, postId int64) (int, error)
 	VisiblePost(post *ms.Post, visibility cs.TweetVisibleType) error
+	HidePost(post *ms.Post) error
+	ModeratorLockPost(post *ms.Post) error
 	UpdatePost(post *ms.Post) error
+	UpdatePostShareCount(post *ms.Post, delta int64) error
 	CreatePostStar(postID, userID int64) (*ms.PostStar, error)
 	DeletePostStar(p *ms.PostStar) error
 	CreatePostCollection(postID, userID int64) (*ms.PostCollection, error)
 	DeletePostCollection(p *ms.PostCollection) error
 	CreatePostContent(content *ms.PostContent) (*ms.PostContent, error)
 	CreateAttachment(obj *ms.Attachment) (int64, error)
+	GetAttachmentByContent(content string) (*ms.Attachment, error)
 	UpdatePostContent(content *ms.PostContent) error
+	ReplacePostContents(postId int64, contents []*ms.PostContent) ([]string, error)
+	ClaimPostStatus(postId int64, from ms.PostStatusT, to ms.PostStatusT, dueOn int64) (bool, error)
+	ExpireProcessingPosts(before int64) (int64, error)
 }
 
 // TweetHelpService 推文辅助服务
@@ -36,6 +36,8 @@ type UserManageService interface {
 	// Global and user-specific reaction timelines
 	GetGlobalReactionTimeline(limit, offset int) ([]*cs.UserReactionWithBothUsers, int64, error)
 	GetUserReactionTimeline(userID int64, limit, offset int) ([]*cs.UserReactionWithBothUsers, int64, error)
+	GetGlobalReactionTimelineByCursor(cursor *cs.PageCursor, limit int) ([]*cs.UserReactionWithBothUsers, *cs.PageCursor, error)
+	GetUserReactionTimelineByCursor(userID int64, cursor *cs.PageCursor, limit int) ([]*cs.UserReactionWithBothUsers, *cs.PageCursor, error)
 }
 
 // ContactManageService 联系人管理服务
@@ -62,11 +64,21 @@ type DeviceManageService interface {
 // FollowingManageService 关注管理服务
 type FollowingManageService interface {
 	FollowUser(userId int64, followId int64) error
+	RequestFollow(userId int64, followId int64) error
 	UnfollowUser(userId int64, followId int64) error
-	ListFollows(userId int64, limit, offset int) (*ms.ContactList, error)
-	ListFollowings(userId int64, limit, offset int) (*ms.ContactList, error)
+	ListFollows(userId int64, keyword string, cursor *cs.PageCursor, limit, offset int) (*ms.ContactList, *cs.PageCursor, error)
+	ListFollowings(userId int64, keyword string, cursor *cs.PageCursor, limit, offset int) (*ms.ContactList, *cs.PageCursor, error)
+	RemoveFollower(userId int64, followerId int64) error
+	TouchFollowInteraction(userId int64, otherId int64) error
 	GetFollowCount(userId int64) (int64, int64, error)
 	IsFollow(userId int64, followId int64) bool
+	IsFollowRequested(userId int64, followId int64) bool
+	ListFollowRequests(userId int64, limit, offset int) (*ms.ContactList, error)
+	ApproveFollowRequest(userId int64, followerId int64) error
+	DeclineFollowRequest(userId int64, followerId int64) error
+	ApproveAllFollowRequests(userId int64) ([]int64, error)
+	SetRoomPing(userId int64, followId int64, enable bool) error
+	ListRoomPingFollowerIds(followId int64, limit int) ([]int64, error)
 }
 
 // UserRelationService 用户关系服务
@@ -75,4 +87,5 @@ type UserRelationService interface {
 	MyFollowIds(userId int64) ([]int64, error)
 	IsMyFriend(userId int64, friendIds ...int64) (map[int64]bool, error)
 	IsMyFollow(userId int64, followIds ...int64) (map[int64]bool, error)
+	IsMyFollower(userId int64, followerIds ...int64) (map[int64]bool, error)
 }
@@ -123,6 +123,37 @@ func (s *cacheDataService) IsMyFollow(userId int64, followIds ...int64) (res map
 	return s.DataService.IsMyFollow(userId, followIds...)
 }
 
+func (s *cacheDataService) MyFriendIds(userId int64) ([]int64, error) {
+	if data, err := s.ac.Get(conf.KeyMyFriendIds.Get(userId)); err == nil {
+		bitmap := roaring64.New()
+		if err = bitmap.UnmarshalBinary(data); err == nil {
+			return bitmapIds(bitmap), nil
+		}
+	}
+	OnCacheMyFriendIdsEvent(s.DataService, userId)
+	return s.DataService.MyFriendIds(userId)
+}
+
+func (s *cacheDataService) MyFollowIds(userId int64) ([]int64, error) {
+	key := conf.KeyMyFollowIds.Get(userId)
+	if data, err := s.ac.Get(key); err == nil {
+		bitmap := roaring64.New()
+		if err = bitmap.UnmarshalBinary(data); err == nil {
+			return bitmapIds(bitmap), nil
+		}
+	}
+	OnCacheMyFollowIdsEvent(s.DataService, userId, key)
+	return s.DataService.MyFollowIds(userId)
+}
+
+func bitmapIds(bitmap *roaring64.Bitmap) []int64 {
+	res := make([]int64, 0, bitmap.GetCardinality())
+	for it := bitmap.Iterator(); it.HasNext(); {
+		res = append(res, int64(it.Next()))
+	}
+	return res
+}
+
 // IsUserOnline checks if a user is online by checking the cache for the online user key
 func (s *cacheDataService) IsUserOnline(userID int64) bool {
 	key := conf.KeyOnlineUser.Get(userID)
@@ -133,3 +164,30 @@ func (s *cacheDataService) IsUserOnline(userID int64) bool {
 func (s *cacheDataService) BatchCheckOnlineUsers(userIDs []int64) (map[int64]bool, error) {
 	return s.ac.BatchCheckOnlineUsers(userIDs)
 }
+
+func (s *cacheDataService) ListFeedCandidates(since int64, limit int) (res []*cs.FeedCandidate, err error) {
+	// 候选集为全站共享的预计算结果，缓存有效期内直接复用
+	if data, xerr := s.ac.Get(conf.KeyForYouCandidates); xerr == nil {
+		buf := bytes.NewBuffer(data)
+		err = gob.NewDecoder(buf).Decode(&res)
+		return
+	}
+	if res, err = s.DataService.ListFeedCandidates(since, limit); err == nil {
+		onCacheObjectEvent(conf.KeyForYouCandidates, res, conf.ForYouSetting.CandidateExpire)
+	}
+	return
+}
+
+func (s *cacheDataService) GetFeedViewer(userId int64) (res *cs.FeedViewer, err error) {
+	key := conf.KeyForYouViewer.Get(userId)
+	if data, xerr := s.ac.Get(key); xerr == nil {
+		buf := bytes.NewBuffer(data)
+		res = &cs.FeedViewer{}
+		err = gob.NewDecoder(buf).Decode(res)
+		return
+	}
+	if res, err = s.DataService.GetFeedViewer(userId); err == nil {
+		onCacheObjectEvent(key, res, conf.ForYouSetting.ViewerExpire)
+	}
+	return
+}
@@ -3,12 +3,13 @@
 // license that can be found in the LICENSE file.
 
 // Notification cache system with configurable TTLs
-package service
+package cache
 
 import (
 	"fmt"
 	"time"
 
+	"github.com/redis/rueidis"
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/sirupsen/logrus"
@@ -20,6 +21,8 @@ type NotificationType string
 const (
 	NotificationTypeContactMatched NotificationType = "contact_matched"
 	NotificationTypeContactOnline  NotificationType = "contact_online"
+	NotificationTypeRoomInvite     NotificationType = "room_invite"
+	NotificationTypeRoomPing       NotificationType = "room_ping"
 )
 
 // NotificationCache tracks sent notifications to prevent spam using Redis
@@ -41,17 +44,15 @@ func (nc *NotificationCache) ShouldSendNotification(
 	notificationType NotificationType,
 ) bool {
 	key := nc.generateKey(userID, targetID, notificationType)
+	ttl := nc.getTTLForNotificationType(notificationType)
 	
-	// Check if key exists in cache
-	if nc.cache.Exist(key) {
+	// SET NX claims the key atomically, concurrent callers can't both send
+	err := nc.cache.SetNx(key, []byte("1"), int64(ttl.Seconds()))
+	if rueidis.IsRedisNil(err) {
 		// Key exists, notification was sent recently
 		logrus.Debugf("Skipping notification for key %s (type: %s) - sent recently", key, notificationType)
 		return false
 	}
-	
-	// Key doesn't exist, set it with TTL
-	ttl := nc.getTTLForNotificationType(notificationType)
-	err := nc.cache.Set(key, []byte("1"), int64(ttl.Seconds()))
 	if err != nil {
 		logrus.Errorf("Failed to set cache key %s: %v", key, err)
 		return true // If cache fails, allow notification (fail-safe)
@@ -61,6 +62,19 @@ func (nc *NotificationCache) ShouldSendNotification(
 	return true
 }
 
+// ReleaseNotification releases a claimed notification so it can be sent again,
+// used when the notification fails to be created after claimed
+func (nc *NotificationCache) ReleaseNotification(
+	userID int64,
+	targetID int64,
+	notificationType NotificationType,
+) {
+	key := nc.generateKey(userID, targetID, notificationType)
+	if err := nc.cache.Delete(key); err != nil {
+		logrus.Errorf("Failed to delete cache key %s: %v", key, err)
+	}
+}
+
 // generateKey creates a unique Redis key for notification tracking
 func (nc *NotificationCache) generateKey(userID, targetID int64, notificationType NotificationType) string {
 	return fmt.Sprintf("notif:%d:%d:%s", userID, targetID, notificationType)
@@ -75,6 +89,12 @@ func (nc *NotificationCache) getTTLForNotificationType(notificationType Notifica
 	case NotificationTypeContactOnline:
 		// Contact online: use configured TTL from cache settings
 		return time.Duration(conf.CacheSetting.ContactOnlineExpire) * time.Second
+	case NotificationTypeRoomInvite:
+		// Room invite: one invite per invitee while the invite is valid
+		return time.Duration(conf.RoomInviteSetting.InviteExpire) * time.Second
+	case NotificationTypeRoomPing:
+		// Room ping: minimal interval between two pings of the same room
+		return time.Duration(conf.RoomInviteSetting.PingInterval) * time.Second
 	default:
 		// Default: use contact matched TTL as fallback
 		return time.Duration(conf.CacheSetting.ContactMatchedExpire) * time.Second
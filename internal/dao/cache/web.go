@@ -97,6 +97,63 @@ func (s *appCache) Exist(key string) bool {
 	return count > 0
 }
 
+// PfAdd 添加元素到HyperLogLog，ex大于0时同时刷新过期时间
+func (s *appCache) PfAdd(key string, ex int64, elements ...string) error {
+	ctx := context.Background()
+	cmds := rueidis.Commands{s.c.B().Pfadd().Key(key).Element(elements...).Build()}
+	if ex > 0 {
+		cmds = append(cmds, s.c.B().Expire().Key(key).Seconds(ex).Build())
+	}
+	for _, res := range s.c.DoMulti(ctx, cmds...) {
+		if err := res.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PfCount HyperLogLog的基数估计
+func (s *appCache) PfCount(key string) (int64, error) {
+	return s.c.Do(context.Background(), s.c.B().Pfcount().Key(key).Build()).AsInt64()
+}
+
+// MarkOnlineUser 在线用户记入有序集合，分值为在线状态的过期时间，统计在线用户时无需扫描键空间
+func (s *appCache) MarkOnlineUser(userId int64, ex int64) error {
+	ctx := context.Background()
+	expireOn := time.Now().Unix() + ex
+	cmds := rueidis.Commands{
+		s.c.B().Zadd().Key(conf.KeyOnlineUsers).ScoreMember().ScoreMember(float64(expireOn), strconv.FormatInt(userId, 10)).Build(),
+		s.c.B().Expire().Key(conf.KeyOnlineUsers).Seconds(ex).Build(),
+	}
+	for _, res := range s.c.DoMulti(ctx, cmds...) {
+		if err := res.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnlineUserIds 获取在线用户，顺带清理在线状态已过期的成员
+func (s *appCache) OnlineUserIds() ([]int64, error) {
+	ctx := context.Background()
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	cmd := s.c.B().Zremrangebyscore().Key(conf.KeyOnlineUsers).Min("-inf").Max("(" + now).Build()
+	if err := s.c.Do(ctx, cmd).Error(); err != nil {
+		return nil, err
+	}
+	members, err := s.c.Do(ctx, s.c.B().Zrangebyscore().Key(conf.KeyOnlineUsers).Min(now).Max("+inf").Build()).AsStrSlice()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		if id, err := strconv.ParseInt(member, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 func (s *appCache) Keys(pattern string) (res []string, err error) {
 	ctx, cursor := context.Background(), uint64(0)
 	for {
@@ -19,6 +19,8 @@ import (
 
 var (
 	ts     core.TweetSearchService
+	es     core.EntitySearchService
+	sms    core.SearchIndexManageService
 	ds     core.DataService
 	oss    core.ObjectStorageService
 	webDsa core.WebDataServantA
@@ -46,6 +48,16 @@ func TweetSearchService() core.TweetSearchService {
 	return ts
 }
 
+func EntitySearchService() core.EntitySearchService {
+	lazyInitial()
+	return es
+}
+
+func SearchIndexManageService() core.SearchIndexManageService {
+	lazyInitial()
+	return sms
+}
+
 func newAuthorizationManageService() (ams core.AuthorizationManageService) {
 	if cfg.If("Gorm") {
 		ams = jinzhu.NewAuthorizationManageService()
@@ -118,14 +130,25 @@ func initTsX() {
 	ams := newAuthorizationManageService()
 	cfg.On(cfg.Actions{
 		"Zinc": func() {
-			ts, v = search.NewZincTweetSearchService(ams)
+			ts, v = search.NewZincTweetSearchService(ams, ds)
+			es = search.NewZincEntitySearchService()
+			sms = search.NewZincSearchIndexManageService(ds)
 		},
 		"Meili": func() {
-			ts, v = search.NewMeiliTweetSearchService(ams)
+			ts, v = search.NewMeiliTweetSearchService(ams, ds)
+			es = search.NewMeiliEntitySearchService()
+			sms = search.NewMeiliSearchIndexManageService(ds)
+		},
+		"Bleve": func() {
+			ts, v = search.NewBleveTweetSearchService(ams, ds)
+			es = search.NewBleveEntitySearchService()
+			sms = search.NewBleveSearchIndexManageService(ds)
 		},
 	}, func() {
-		ts, v = search.NewZincTweetSearchService(ams)
+		ts, v = search.NewZincTweetSearchService(ams, ds)
+		es = search.NewZincEntitySearchService()
+		sms = search.NewZincSearchIndexManageService(ds)
 	})
 	logrus.Infof("use %s as tweet search serice by version %s", v.Name(), v.Version())
-	ts = search.NewBridgeTweetSearchService(ts)
+	ts = search.NewBridgeTweetSearchService(ds, ts, es)
 }
@@ -0,0 +1,396 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.AccountService = (*accountSrv)(nil)
+)
+
+type accountSrv struct {
+	db         *gorm.DB
+	cacheIndex core.CacheIndexService
+}
+
+func newAccountService(db *gorm.DB, cacheIndex core.CacheIndexService) core.AccountService {
+	return &accountSrv{
+		db:         db,
+		cacheIndex: cacheIndex,
+	}
+}
+
+// CreateAccountExport create an export job, return the running one if existed.
+// Running jobs not updated since staleBefore are lost (e.g. process restarted) and marked as failed.
+func (s *accountSrv) CreateAccountExport(userId int64, staleBefore int64) (*ms.AccountExport, error) {
+	export := &dbr.AccountExport{
+		UserID: userId,
+	}
+	if err := export.FailStale(s.db, staleBefore, "export timed out"); err != nil {
+		return nil, err
+	}
+	if running, err := export.GetRunning(s.db, staleBefore); err == nil {
+		return running, nil
+	}
+	export.Status = cs.AccountExportPending
+	return export.Create(s.db)
+}
+
+func (s *accountSrv) GetAccountExport(userId int64, id int64) (*ms.AccountExport, error) {
+	export := &dbr.AccountExport{
+		Model: &dbr.Model{
+			ID: id,
+		},
+		UserID: userId,
+	}
+	return export.Get(s.db)
+}
+
+func (s *accountSrv) UpdateAccountExport(export *ms.AccountExport) error {
+	return export.Update(s.db)
+}
+
+// GetAccountArchive collect all data of user for account export
+func (s *accountSrv) GetAccountArchive(userId int64) (*ms.AccountArchive, error) {
+	user, err := (&dbr.User{Model: &dbr.Model{ID: userId}}).Get(s.db)
+	if err != nil {
+		return nil, err
+	}
+	archive := &dbr.AccountArchive{
+		ExportedOn: time.Now().Unix(),
+		Profile: &dbr.AccountProfile{
+			ID:         user.ID,
+			Nickname:   user.Nickname,
+			Username:   user.Username,
+			Phone:      user.Phone,
+			Avatar:     user.Avatar,
+			Balance:    user.Balance,
+			Status:     user.Status,
+			IsAdmin:    user.IsAdmin,
+			Categories: user.Categories,
+			CreatedOn:  user.CreatedOn,
+		},
+	}
+	db := s.db
+	if err = db.Where("(CAST(user_id->0 AS bigint) = ? OR CAST(user_id->1 AS bigint) = ?) AND is_del = ?", userId, userId, 0).
+		Order("id ASC").Find(&archive.Posts).Error; err != nil {
+		return nil, err
+	}
+	if len(archive.Posts) > 0 {
+		postIds := make([]int64, 0, len(archive.Posts))
+		for _, post := range archive.Posts {
+			postIds = append(postIds, post.ID)
+		}
+		if err = db.Where("post_id IN ? AND is_del = ?", postIds, 0).Order("post_id ASC, sort ASC").
+			Find(&archive.PostContents).Error; err != nil {
+			return nil, err
+		}
+		for _, content := range archive.PostContents {
+			if content.Type == dbr.ContentTypeAudio && content.Content != "" {
+				archive.Recordings = append(archive.Recordings, content.Content)
+			}
+		}
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.Comments).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.CommentContents).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.CommentReplies).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.PostStars).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.PostCollections).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("reactor_user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.ReactionsGiven).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("target_user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.ReactionsReceived).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Omit("User").Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.Followings).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Omit("User").Where("follow_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.Followers).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Omit("User").Where("user_id = ? AND status = ? AND is_del = ?", userId, dbr.ContactStatusAgree, 0).
+		Order("id ASC").Find(&archive.Friends).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.PhoneContacts).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.Devices).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.WalletStatements).Error; err != nil {
+		return nil, err
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.DmConversations).Error; err != nil {
+		return nil, err
+	}
+	if len(archive.DmConversations) > 0 {
+		conversationIds := make([]int64, 0, len(archive.DmConversations))
+		for _, participant := range archive.DmConversations {
+			conversationIds = append(conversationIds, participant.ConversationID)
+		}
+		if err = db.Where("conversation_id IN ? AND is_del = ?", conversationIds, 0).Order("conversation_id ASC, id ASC").
+			Find(&archive.DmMessages).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err = db.Where("user_id = ? AND is_del = ?", userId, 0).Order("id ASC").Find(&archive.Blocks).Error; err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// ScheduleAccountDeletion schedule an account deletion, return the scheduled one if existed
+func (s *accountSrv) ScheduleAccountDeletion(userId int64, scheduledOn int64) (*ms.AccountDeletion, error) {
+	deletion := &dbr.AccountDeletion{
+		UserID: userId,
+	}
+	if scheduled, err := deletion.GetScheduled(s.db); err == nil {
+		return scheduled, nil
+	}
+	deletion.Status = cs.AccountDeletionScheduled
+	deletion.ScheduledOn = scheduledOn
+	return deletion.Create(s.db)
+}
+
+func (s *accountSrv) GetAccountDeletion(userId int64) (*ms.AccountDeletion, error) {
+	deletion := &dbr.AccountDeletion{
+		UserID: userId,
+	}
+	return deletion.GetScheduled(s.db)
+}
+
+func (s *accountSrv) UpdateAccountDeletion(deletion *ms.AccountDeletion) error {
+	return deletion.Update(s.db)
+}
+
+func (s *accountSrv) ListDueAccountDeletions(now int64, limit int) ([]*ms.AccountDeletion, error) {
+	return (&dbr.AccountDeletion{}).ListDue(s.db, now, limit)
+}
+
+// PurgeAccount delete owned content of user, purge relations/devices/contacts/exports and
+// anonymize the user, user in shared conversation posts is shown as anonymized user.
+// It returns deleted posts, media contents and object keys of account exports that need
+// to be deleted from object storage.
+func (s *accountSrv) PurgeAccount(userId int64) (posts []*ms.Post, commentedPostIds []int64, mediaContents []string, exportKeys []string, err error) {
+	// 内容删除与账户匿名化在同一事务中，失败时整体回滚后可重新执行
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("CAST(user_id->0 AS bigint) = ? AND is_del = ?", userId, 0).Find(&posts).Error; err != nil {
+			return err
+		}
+		for _, post := range posts {
+			contents, err := deletePost(tx, post)
+			if err != nil {
+				return err
+			}
+			mediaContents = append(mediaContents, contents...)
+		}
+		contents, postIds, err := s.deleteUserComments(tx, userId)
+		if err != nil {
+			return err
+		}
+		mediaContents = append(mediaContents, contents...)
+		commentedPostIds = postIds
+		if contents, err = s.deleteUserDms(tx, userId); err != nil {
+			return err
+		}
+		mediaContents = append(mediaContents, contents...)
+		if err = tx.Model(&dbr.AccountExport{}).Where("user_id = ? AND object_key <> ''", userId).
+			Pluck("object_key", &exportKeys).Error; err != nil {
+			return err
+		}
+		return s.purgeUserData(tx, userId)
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for _, post := range posts {
+		s.cacheIndex.SendAction(core.IdxActDeletePost, post)
+	}
+	return posts, commentedPostIds, mediaContents, exportKeys, nil
+}
+
+// deleteUserComments delete comments/replies of user on other's posts and fix comment/reply count,
+// return media contents to delete and posts whose comments changed
+func (s *accountSrv) deleteUserComments(tx *gorm.DB, userId int64) ([]string, []int64, error) {
+	var comments []*dbr.Comment
+	if err := tx.Where("user_id = ? AND is_del = ?", userId, 0).Find(&comments).Error; err != nil {
+		return nil, nil, err
+	}
+	now := time.Now().Unix()
+	deleted := map[string]any{
+		"deleted_on": now,
+		"is_del":     1,
+	}
+	// 用户自己的回复，语音回复收集媒体内容，并按评论扣减回复数
+	var replies []*dbr.CommentReply
+	if err := tx.Select("id", "comment_id", "type", "content").Where("user_id = ? AND is_del = ?", userId, 0).
+		Find(&replies).Error; err != nil {
+		return nil, nil, err
+	}
+	var replyContents []string
+	replyCounts := make(map[int64][2]int, len(replies))
+	for _, reply := range replies {
+		counts := replyCounts[reply.CommentID]
+		counts[0]++
+		if reply.Type == dbr.ContentTypeAudio {
+			replyContents = append(replyContents, reply.Content)
+			counts[1]++
+		}
+		replyCounts[reply.CommentID] = counts
+	}
+	if err := tx.Model(&dbr.CommentReply{}).Where("user_id = ? AND is_del = ?", userId, 0).Updates(deleted).Error; err != nil {
+		return nil, nil, err
+	}
+	for commentId, counts := range replyCounts {
+		updates := map[string]any{
+			"reply_count": gorm.Expr("reply_count - ?", counts[0]),
+		}
+		if counts[1] > 0 {
+			updates["audio_reply_count"] = gorm.Expr("audio_reply_count - ?", counts[1])
+		}
+		if err := tx.Model(&dbr.Comment{}).Where("id = ? AND reply_count >= ? AND audio_reply_count >= ?", commentId, counts[0], counts[1]).
+			Updates(updates).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(comments) == 0 {
+		return replyContents, nil, nil
+	}
+	commentIds := make([]int64, 0, len(comments))
+	postComments := make(map[int64]int64, len(comments))
+	for _, comment := range comments {
+		commentIds = append(commentIds, comment.ID)
+		postComments[comment.PostID]++
+	}
+	commentContent := &dbr.CommentContent{}
+	mediaContents, err := commentContent.MediaContentsByCommentId(tx, commentIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	mediaContents = append(mediaContents, replyContents...)
+	// 他人在用户评论下的语音回复随评论一并删除，用户自己的回复上面已收集
+	if replyContents, err = (&dbr.CommentReply{}).AudioContentsByCommentId(tx.Where("is_del = 0"), commentIds); err != nil {
+		return nil, nil, err
+	}
+	mediaContents = append(mediaContents, replyContents...)
+	if err = tx.Model(&dbr.Comment{}).Where("id IN ?", commentIds).Updates(deleted).Error; err != nil {
+		return nil, nil, err
+	}
+	if err = commentContent.DeleteByCommentIds(tx, commentIds); err != nil {
+		return nil, nil, err
+	}
+	// 复制到动态中的评论内容
+	if err = tx.Model(&dbr.PostContent{}).Where("comment_id IN ? AND is_del = 0", commentIds).Updates(deleted).Error; err != nil {
+		return nil, nil, err
+	}
+	if err = (&dbr.CommentReply{}).DeleteByCommentIds(tx, commentIds); err != nil {
+		return nil, nil, err
+	}
+	if err = deleteMentionsOf(tx, cs.MentionSourceComment, commentIds...); err != nil {
+		return nil, nil, err
+	}
+	postIds := make([]int64, 0, len(postComments))
+	for postId, count := range postComments {
+		postIds = append(postIds, postId)
+		if err = tx.Model(&dbr.Post{}).Where("id = ? AND comment_count >= ?", postId, count).
+			Update("comment_count", gorm.Expr("comment_count - ?", count)).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	return mediaContents, postIds, nil
+}
+
+// deleteUserDms delete direct message conversations of user with all their messages,
+// a conversation can not go on once one of the two users is anonymized
+func (s *accountSrv) deleteUserDms(tx *gorm.DB, userId int64) (mediaContents []string, err error) {
+	var conversationIds []int64
+	if err = tx.Model(&dbr.DmParticipant{}).Where("user_id = ?", userId).
+		Pluck("conversation_id", &conversationIds).Error; err != nil || len(conversationIds) == 0 {
+		return
+	}
+	if err = tx.Model(&dbr.DmMessage{}).Where("conversation_id IN ? AND type IN ? AND content <> ''",
+		conversationIds, []cs.DmMsgT{cs.DmMsgAudio, cs.DmMsgImage}).Pluck("content", &mediaContents).Error; err != nil {
+		return
+	}
+	for _, model := range []any{&dbr.DmMessage{}, &dbr.DmParticipant{}} {
+		if err = tx.Unscoped().Where("conversation_id IN ?", conversationIds).Delete(model).Error; err != nil {
+			return
+		}
+	}
+	err = tx.Unscoped().Where("id IN ?", conversationIds).Delete(&dbr.DmConversation{}).Error
+	return
+}
+
+// purgeUserData purge relations, devices, contacts, messages and the room of user then anonymize the user
+func (s *accountSrv) purgeUserData(tx *gorm.DB, userId int64) error {
+	id := sql.Named("id", userId)
+	for _, it := range []struct {
+		model any
+		query string
+	}{
+		{&dbr.PostStar{}, "user_id = @id"},
+		{&dbr.PostCollection{}, "user_id = @id"},
+		{&dbr.UserReaction{}, "reactor_user_id = @id OR target_user_id = @id"},
+		{&dbr.Following{}, "user_id = @id OR follow_id = @id"},
+		{&dbr.Contact{}, "user_id = @id OR friend_id = @id"},
+		{&dbr.UserPhoneContact{}, "user_id = @id"},
+		{&dbr.UserDevice{}, "user_id = @id"},
+		{&dbr.EditRevision{}, "user_id = @id"},
+		{&dbr.UserBlock{}, "user_id = @id OR blocked_id = @id"},
+		{&dbr.PostTranscript{}, "speaker_id = @id"},
+		{&dbr.RoomInvite{}, "inviter_id = @id OR invitee_id = @id"},
+		{&dbr.UserSuggestion{}, "user_id = @id OR suggested_id = @id"},
+		{&dbr.UserSuggestionFeedback{}, "user_id = @id OR suggested_id = @id"},
+		{&dbr.Mention{}, "user_id = @id OR mentioned_user_id = @id"},
+		// 消息内容中带有用户名
+		{&dbr.Message{}, "sender_user_id = @id OR receiver_user_id = @id"},
+		{&dbr.MessageGroupActor{}, "actor_id = @id"},
+		{&dbr.Room{}, "host_id = @id"},
+		// 导出包中包含全部个人数据
+		{&dbr.AccountExport{}, "user_id = @id"},
+	} {
+		if err := tx.Unscoped().Where(it.query, id).Delete(it.model).Error; err != nil {
+			return err
+		}
+	}
+	// 其他用户上传的通讯录中不再匹配到该用户
+	if err := tx.Model(&dbr.UserPhoneContact{}).Where("matched_user_id = ?", userId).
+		Updates(map[string]any{
+			"is_matched":      false,
+			"matched_user_id": nil,
+		}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&dbr.User{}).Where("id = ?", userId).Updates(map[string]any{
+		"nickname": "已注销用户",
+		"username": fmt.Sprintf("deleted_%d", userId),
+		"phone":    "",
+		"password": "",
+		"salt":     "",
+		"avatar":   "",
+		"status":   dbr.UserStatusClosed,
+	}).Error
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+const (
+	// 按天聚合统计时使用的日期表达式
+	_sqlDayOfCreatedOn = "to_char(to_timestamp(created_on), 'YYYY-MM-DD')"
+)
+
+var (
+	_ core.AdminManageService = (*adminManageSrv)(nil)
+)
+
+type adminManageSrv struct {
+	db *gorm.DB
+}
+
+func newAdminManageService(db *gorm.DB) core.AdminManageService {
+	return &adminManageSrv{
+		db: db,
+	}
+}
+
+func (s *adminManageSrv) ListUsers(filter *cs.UserFilter, limit, offset int) (res []*ms.User, total int64, err error) {
+	db := s.db.Model(&dbr.User{})
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		db = db.Where("username LIKE ? OR nickname LIKE ? OR phone LIKE ?", keyword, keyword, keyword)
+	}
+	if filter.Status > 0 {
+		db = db.Where("status = ?", filter.Status)
+	}
+	switch filter.Role {
+	case cs.UserRoleAdmin:
+		db = db.Where("is_admin = ?", true)
+	case cs.UserRoleUser:
+		db = db.Where("is_admin = ?", false)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Order("id DESC").Offset(offset).Limit(limit).Find(&res).Error
+	return
+}
+
+// ListUserRooms 获取用户主持或作为嘉宾参与的房间
+func (s *adminManageSrv) ListUserRooms(userId int64) (res []*ms.Room, err error) {
+	err = s.db.Where("host_id = ? OR speaker_ids @> ?::jsonb", userId, "["+strconv.FormatInt(userId, 10)+"]").
+		Order("id DESC").Find(&res).Error
+	return
+}
+
+func (s *adminManageSrv) ListUserReports(userId int64, limit, offset int) (res []*ms.Report, total int64, err error) {
+	conditions := dbr.ConditionsT{
+		"target_user_id = ?": userId,
+		"ORDER":              "id DESC",
+	}
+	report := &dbr.Report{}
+	if total, err = report.Count(s.db, conditions); err != nil {
+		return
+	}
+	res, err = report.List(s.db, conditions, offset, limit)
+	return
+}
+
+// GetPostWithDeleted 获取动态，包含已删除的动态
+func (s *adminManageSrv) GetPostWithDeleted(id int64) (*ms.Post, error) {
+	post := &dbr.Post{}
+	if err := s.db.Unscoped().Where("id = ?", id).First(post).Error; err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// SoftDeletePost 仅标记动态为已删除并记录删除的管理员，保留动态内容、评论等数据以便恢复；
+// 标签引用数与@提及同删除动态时一样处理，恢复时还原
+func (s *adminManageSrv) SoftDeletePost(post *ms.Post, operatorId int64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		res := tx.Model(&dbr.Post{}).Where("id = ? AND is_del = ?", post.ID, 0).Updates(map[string]any{
+			"deleted_on": now,
+			"deleted_by": operatorId,
+			"is_del":     1,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		post.DeletedOn, post.DeletedBy, post.IsDel = now, operatorId, 1
+		if err := deleteMentionsOf(tx, cs.MentionSourcePost, post.ID); err != nil {
+			return err
+		}
+		if post.Tags != "" && post.Visibility != dbr.PostVisitPrivate {
+			// 宽松处理错误，同删除动态
+			deleteTags(tx, strings.Split(post.Tags, ","))
+		}
+		return nil
+	})
+}
+
+// RestorePost 恢复管理员删除的动态，作者自己删除的动态不能恢复
+func (s *adminManageSrv) RestorePost(post *ms.Post) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Model(&dbr.Post{}).Where("id = ? AND is_del = ? AND deleted_by > 0", post.ID, 1).Updates(map[string]any{
+			"deleted_on": 0,
+			"deleted_by": 0,
+			"is_del":     0,
+		})
+		if res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		// 只恢复随动态一起删除的@提及
+		if err := tx.Unscoped().Model(&dbr.Mention{}).Where("post_id = ? AND is_del = 1 AND deleted_on = ?", post.ID, post.DeletedOn).
+			Updates(map[string]any{
+				"deleted_on": 0,
+				"is_del":     0,
+			}).Error; err != nil {
+			return err
+		}
+		if post.Tags != "" && post.Visibility != dbr.PostVisitPrivate {
+			createTags(tx, post.GetHostID(), strings.Split(post.Tags, ","))
+		}
+		post.DeletedOn, post.DeletedBy, post.IsDel = 0, 0, 0
+		return nil
+	})
+}
+
+func (s *adminManageSrv) CountRegistersDaily(since int64) (res []*cs.DailyCount, err error) {
+	err = s.db.Model(&dbr.User{}).
+		Select(_sqlDayOfCreatedOn+" AS day, COUNT(*) AS count").
+		Where("created_on >= ?", since).
+		Group("day").Order("day ASC").Scan(&res).Error
+	return
+}
+
+// CountRecordingsDaily 按天统计上传的录音数
+func (s *adminManageSrv) CountRecordingsDaily(since int64) (res []*cs.DailyCount, err error) {
+	err = s.db.Model(&dbr.PostContent{}).
+		Select(_sqlDayOfCreatedOn+" AS day, COUNT(*) AS count").
+		Where("type = ? AND created_on >= ?", dbr.ContentTypeAudio, since).
+		Group("day").Order("day ASC").Scan(&res).Error
+	return
+}
+
+// CountLiveRooms 统计主持人在线且未被屏蔽的房间数
+func (s *adminManageSrv) CountLiveRooms(onlineUserIds []int64) (count int64, err error) {
+	if len(onlineUserIds) == 0 {
+		return 0, nil
+	}
+	err = s.db.Model(&dbr.Room{}).
+		Where("host_id IN ? AND is_blocked_from_space = ?", onlineUserIds, 0).
+		Count(&count).Error
+	return
+}
@@ -5,35 +5,164 @@
 package jinzhu
 
 import (
+	"sync"
+	"time"
+
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 	"github.com/rocboss/paopao-ce/pkg/types"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+const (
+	// 用户权限在进程内缓存的时长，角色变更时主动失效
+	_userPermissionsExpire = time.Minute
+)
+
 var (
 	_ core.AuthorizationManageService = (*authorizationManageSrv)(nil)
+	_ core.RoleManageService          = (*roleManageSrv)(nil)
+
+	// 同一进程内的授权服务实例共享用户权限缓存
+	_userPermissions = &userPermissionsCache{
+		items: make(map[int64]*userPermissionsItem),
+	}
 )
 
 type authorizationManageSrv struct {
 	db *gorm.DB
 }
 
+type roleManageSrv struct {
+	db *gorm.DB
+}
+
+type userPermissionsItem struct {
+	perms    cs.PermissionSet
+	expireOn time.Time
+}
+
+type userPermissionsCache struct {
+	sync.RWMutex
+	items map[int64]*userPermissionsItem
+}
+
+func (c *userPermissionsCache) get(userId int64) (cs.PermissionSet, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if item, ok := c.items[userId]; ok && time.Now().Before(item.expireOn) {
+		return item.perms, true
+	}
+	return nil, false
+}
+
+func (c *userPermissionsCache) put(userId int64, perms cs.PermissionSet) {
+	c.Lock()
+	defer c.Unlock()
+	c.items[userId] = &userPermissionsItem{
+		perms:    perms,
+		expireOn: time.Now().Add(_userPermissionsExpire),
+	}
+}
+
+func (c *userPermissionsCache) expire(userId int64) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.items, userId)
+}
+
 func newAuthorizationManageService(db *gorm.DB) core.AuthorizationManageService {
 	return &authorizationManageSrv{
 		db: db,
 	}
 }
 
+func newRoleManageService(db *gorm.DB) core.RoleManageService {
+	return &roleManageSrv{
+		db: db,
+	}
+}
+
+// IsAllow 拥有动作对应的权限时直接放行，否则按默认规则检查归属与好友关系
 func (s *authorizationManageSrv) IsAllow(user *ms.User, action *ms.Action) bool {
+	if perm := action.Act.Permission(); perm != "" && s.HasPermission(user, perm) {
+		return true
+	}
 	// user is activation if had bind phone
 	isActivation := (len(user.Phone) != 0)
 	isFriend := s.isFriend(user.ID, action.UserId)
-	// TODO: just use defaut act authorization chek rule now
 	return action.Act.IsAllow(user, action.UserId, isFriend, isActivation)
 }
 
+// HasPermission 管理员拥有所有权限，其他用户按所分配角色的权限判断
+func (s *authorizationManageSrv) HasPermission(user *ms.User, perm cs.Permission) bool {
+	if user == nil {
+		return false
+	}
+	if user.IsAdmin {
+		return true
+	}
+	return s.UserPermissions(user).Has(perm)
+}
+
+// CanManageUser 只能管理等级低于自己的用户，等级取用户所有角色中的最高等级；
+// 超级管理员之间可以互相管理（如撤销管理员），但不能管理自己
+func (s *authorizationManageSrv) CanManageUser(operator *ms.User, target *ms.User) bool {
+	operatorRank, err := s.userRank(operator)
+	if err != nil {
+		logrus.Errorf("get rank of user[%d] err: %s", operator.ID, err)
+		return false
+	}
+	targetRank, err := s.userRank(target)
+	if err != nil {
+		logrus.Errorf("get rank of user[%d] err: %s", target.ID, err)
+		return false
+	}
+	if operator.IsAdmin {
+		return operator.ID != target.ID && operatorRank >= targetRank
+	}
+	return operatorRank > targetRank
+}
+
+func (s *authorizationManageSrv) userRank(user *ms.User) (rank int, err error) {
+	if user == nil || user.Model == nil {
+		return
+	}
+	if user.IsAdmin {
+		return cs.RankSuperAdmin, nil
+	}
+	roles, err := (&dbr.Role{}).ListByUserId(s.db, user.ID)
+	for _, role := range roles {
+		if r := role.Name.Rank(); r > rank {
+			rank = r
+		}
+	}
+	return
+}
+
+func (s *authorizationManageSrv) UserPermissions(user *ms.User) cs.PermissionSet {
+	if user == nil || user.Model == nil {
+		return cs.PermissionSet{}
+	}
+	if perms, ok := _userPermissions.get(user.ID); ok {
+		return perms
+	}
+	items, err := (&dbr.RolePermission{}).PermissionsByUserId(s.db, user.ID)
+	if err != nil {
+		logrus.Errorf("get permissions of user[%d] err: %s", user.ID, err)
+		return cs.PermissionSet{}
+	}
+	perms := make(cs.PermissionSet, len(items))
+	for _, perm := range items {
+		perms[perm] = struct{}{}
+	}
+	_userPermissions.put(user.ID, perms)
+	return perms
+}
+
 func (s *authorizationManageSrv) MyFriendSet(userId int64) ms.FriendSet {
 	ids, err := (&dbr.Contact{UserId: userId}).MyFriendIds(s.db)
 	if err != nil {
@@ -71,3 +200,46 @@ func (s *authorizationManageSrv) isFriend(userId int64, friendId int64) bool {
 	}
 	return false
 }
+
+func (s *roleManageSrv) ListRoles() ([]*ms.Role, error) {
+	return (&dbr.Role{}).List(s.db)
+}
+
+func (s *roleManageSrv) GetUserRoles(userId int64) ([]*ms.Role, error) {
+	return (&dbr.Role{}).ListByUserId(s.db, userId)
+}
+
+func (s *roleManageSrv) AssignUserRole(userId int64, name cs.RoleName) error {
+	role, err := (&dbr.Role{Name: name}).GetByName(s.db)
+	if err != nil {
+		return err
+	}
+	userRole := &dbr.UserRole{
+		UserID: userId,
+		RoleID: role.ID,
+	}
+	if _, err = userRole.Get(s.db); err == nil {
+		return nil
+	}
+	if _, err = userRole.Create(s.db); err != nil {
+		return err
+	}
+	_userPermissions.expire(userId)
+	return nil
+}
+
+func (s *roleManageSrv) RevokeUserRole(userId int64, name cs.RoleName) error {
+	role, err := (&dbr.Role{Name: name}).GetByName(s.db)
+	if err != nil {
+		return err
+	}
+	userRole := &dbr.UserRole{
+		UserID: userId,
+		RoleID: role.ID,
+	}
+	if err = userRole.Delete(s.db); err != nil {
+		return err
+	}
+	_userPermissions.expire(userId)
+	return nil
+}
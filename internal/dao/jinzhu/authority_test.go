@@ -0,0 +1,32 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+var _ = Describe("Authority", func() {
+	// 超级管理员的等级不需要查询角色
+	s := &authorizationManageSrv{}
+	superAdmin := func(id int64) *ms.User {
+		return &ms.User{Model: &ms.Model{ID: id}, IsAdmin: true}
+	}
+
+	It("super admin can revoke another super admin", func() {
+		Expect(s.CanManageUser(superAdmin(1), superAdmin(2))).To(BeTrue())
+	})
+
+	It("super admin can not manage self", func() {
+		Expect(s.CanManageUser(superAdmin(1), superAdmin(1))).To(BeFalse())
+	})
+
+	It("user without role can not manage super admin", func() {
+		Expect(s.CanManageUser(&ms.User{}, superAdmin(1))).To(BeFalse())
+	})
+})
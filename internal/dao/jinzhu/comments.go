@@ -6,6 +6,7 @@ package jinzhu
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/rocboss/paopao-ce/internal/core"
@@ -14,6 +15,7 @@ import (
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 	"github.com/rocboss/paopao-ce/pkg/types"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -63,14 +65,19 @@ func (s *commentSrv) GetCommentThumbsMap(userId int64, tweetId int64) (cs.Commen
 
 func (s *commentSrv) GetComments(tweetId int64, style cs.StyleCommentType, limit int, offset int) (res []*ms.Comment, total int64, err error) {
 	db := s.db.Table(_comment_)
-	sort := "is_essence DESC, id ASC"
+	// 置顶评论在所有排序下都排在最前
+	sort := "pinned_on DESC, is_essence DESC, id ASC"
 	switch style {
 	case cs.StyleCommentHots:
 		// rank_score=评论回复数*2+点赞*4-点踩, order byrank_score DESC
 		db = db.Joins(fmt.Sprintf("LEFT JOIN %s m ON %s.id=m.comment_id AND m.is_del=0", _commentMetric_, _comment_))
-		sort = fmt.Sprintf("is_essence DESC, m.rank_score DESC, %s.id DESC", _comment_)
+		sort = fmt.Sprintf("pinned_on DESC, is_essence DESC, m.rank_score DESC, %s.id DESC", _comment_)
+	case cs.StyleCommentTop:
+		// wilson_score=点赞比例的Wilson置信下界, 票数少的评论不会因一两个赞排在前面
+		db = db.Joins(fmt.Sprintf("LEFT JOIN %s m ON %s.id=m.comment_id AND m.is_del=0", _commentMetric_, _comment_))
+		sort = fmt.Sprintf("pinned_on DESC, is_essence DESC, m.wilson_score DESC, %s.id DESC", _comment_)
 	case cs.StyleCommentNewest:
-		sort = "is_essence DESC, id DESC"
+		sort = "pinned_on DESC, is_essence DESC, id DESC"
 	case cs.StyleCommentDefault:
 		fallthrough
 	default:
@@ -122,6 +129,26 @@ func (s *commentSrv) GetCommentRepliesByID(ids []int64) ([]*ms.CommentReplyForma
 	if err != nil {
 		return nil, err
 	}
+	return s.formatCommentReplies(replies)
+}
+
+// ListCommentReplies 分页获取评论的回复，按回复时间正序
+func (s *commentSrv) ListCommentReplies(commentId int64, limit int, offset int) (res []*ms.CommentReplyFormated, total int64, err error) {
+	if err = s.db.Model(&dbr.CommentReply{}).Where("comment_id = ? AND is_del = 0", commentId).Count(&total).Error; err != nil || total == 0 {
+		return
+	}
+	replies, err := (&dbr.CommentReply{}).List(s.db, &dbr.ConditionsT{
+		"comment_id = ?": commentId,
+		"ORDER":          "id ASC",
+	}, offset, limit)
+	if err != nil {
+		return
+	}
+	res, err = s.formatCommentReplies(replies)
+	return
+}
+
+func (s *commentSrv) formatCommentReplies(replies []*dbr.CommentReply) ([]*ms.CommentReplyFormated, error) {
 	userIds := []int64{}
 	for _, reply := range replies {
 		userIds = append(userIds, reply.UserID, reply.AtUserID)
@@ -169,6 +196,46 @@ func (s *commentManageSrv) HighlightComment(userId, commentId int64) (isEssence
 	return
 }
 
+// PinComment 置顶或取消置顶评论，对话动态的双方都可置顶，每条动态只保留一条置顶评论，
+// 锁定动态记录使同一动态的置顶操作串行执行
+func (s *commentManageSrv) PinComment(userId, commentId int64) (pinned bool, err error) {
+	now := time.Now().Unix()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		post := &dbr.Post{}
+		comment := &dbr.Comment{}
+		if err := tx.Select("post_id").Where("id=?", commentId).First(comment).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Table(_post_).Where("id=?", comment.PostID).First(post).Error; err != nil {
+			return err
+		}
+		if !slices.Contains(post.UserID, userId) {
+			return cs.ErrNoPermission
+		}
+		// 加锁后再读取置顶状态
+		if err := tx.Where("id=?", commentId).First(comment).Error; err != nil {
+			return err
+		}
+		if pinned = comment.PinnedOn == 0; !pinned {
+			return tx.Model(comment).UpdateColumns(map[string]any{
+				"pinned_on":   0,
+				"modified_on": now,
+			}).Error
+		}
+		if err := tx.Model(&dbr.Comment{}).Where("post_id=? AND pinned_on>0", comment.PostID).UpdateColumns(map[string]any{
+			"pinned_on":   0,
+			"modified_on": now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(comment).UpdateColumns(map[string]any{
+			"pinned_on":   now,
+			"modified_on": now,
+		}).Error
+	})
+	return pinned && err == nil, err
+}
+
 func (s *commentManageSrv) DeleteComment(comment *ms.Comment) (err error) {
 	db := s.db.Begin()
 	defer db.Rollback()
@@ -182,6 +249,17 @@ func (s *commentManageSrv) DeleteComment(comment *ms.Comment) (err error) {
 	if err != nil {
 		return
 	}
+	// 复制到动态中的评论内容随评论一并删除
+	err = db.Model(&dbr.PostContent{}).Where("post_id=? AND comment_id=? AND is_del=0", comment.PostID, comment.ID).Updates(map[string]any{
+		"deleted_on": time.Now().Unix(),
+		"is_del":     1,
+	}).Error
+	if err != nil {
+		return
+	}
+	if err = deleteMentionsOf(db, cs.MentionSourceComment, comment.ID); err != nil {
+		return
+	}
 	db.Commit()
 	return
 }
@@ -193,7 +271,7 @@ func (s *commentManageSrv) CreateComment(comment *ms.Comment) (*ms.Comment, erro
 func (s *commentManageSrv) CreateCommentReply(reply *ms.CommentReply) (res *ms.CommentReply, err error) {
 	if res, err = reply.Create(s.db); err == nil {
 		// 宽松处理错误
-		s.db.Table(_comment_).Where("id=?", reply.CommentID).Update("reply_count", gorm.Expr("reply_count+1"))
+		s.db.Table(_comment_).Where("id=?", reply.CommentID).Updates(replyCountUpdates(reply, "+1"))
 	}
 	return
 }
@@ -213,14 +291,48 @@ func (s *commentManageSrv) DeleteCommentReply(reply *ms.CommentReply) (err error
 	if err != nil {
 		return
 	}
+	if err = deleteMentionsOf(db, cs.MentionSourceReply, reply.ID); err != nil {
+		return
+	}
 	// 宽松处理错误
-	db.Table(_comment_).Where("id=?", reply.CommentID).Update("reply_count", gorm.Expr("reply_count-1"))
+	db.Table(_comment_).Where("id=?", reply.CommentID).Updates(replyCountUpdates(reply, "-1"))
 	db.Commit()
 	return
 }
 
-func (s *commentManageSrv) CreateCommentContent(content *ms.CommentContent) (*ms.CommentContent, error) {
-	return content.Create(s.db)
+// replyCountUpdates 回复数变更，语音回复同时计入语音回复数
+func replyCountUpdates(reply *ms.CommentReply, delta string) map[string]any {
+	updates := map[string]any{
+		"reply_count": gorm.Expr("reply_count" + delta),
+	}
+	if reply.Type == ms.ContentTypeAudio {
+		updates["audio_reply_count"] = gorm.Expr("audio_reply_count" + delta)
+	}
+	return updates
+}
+
+// CreateCommentContent 创建评论内容，并在同一事务中复制到动态内容，评论内容为准
+func (s *commentManageSrv) CreateCommentContent(post *ms.Post, content *ms.CommentContent) (res *ms.CommentContent, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		created, err := content.Create(tx)
+		if err != nil {
+			return err
+		}
+		copied := &dbr.PostContent{
+			PostID:    post.ID,
+			CommentID: created.CommentID,
+			UserID:    []int64{post.GetHostID(), post.GetVisitorID()},
+			Content:   created.Content,
+			Type:      created.Type,
+			Sort:      created.Sort,
+		}
+		if _, err = copied.Create(tx); err != nil {
+			return err
+		}
+		res = created
+		return nil
+	})
+	return
 }
 
 func (s *commentManageSrv) ThumbsUpComment(userId int64, tweetId, commentId int64) error {
@@ -0,0 +1,151 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"gorm.io/gorm"
+)
+
+// AccountExport a job exporting all data of a user into an archive
+type AccountExport struct {
+	*Model
+	UserID     int64  `json:"user_id"`
+	Status     int8   `json:"status"`
+	ObjectKey  string `json:"object_key"`
+	Size       int64  `json:"size"`
+	Reason     string `json:"reason"`
+	FinishedOn int64  `json:"finished_on"`
+}
+
+// AccountDeletion a scheduled account deletion that is executed after grace period
+type AccountDeletion struct {
+	*Model
+	UserID      int64 `json:"user_id"`
+	Status      int8  `json:"status"`
+	ScheduledOn int64 `json:"scheduled_on"`
+	ExecutedOn  int64 `json:"executed_on"`
+}
+
+// AccountProfile profile of user in account archive
+type AccountProfile struct {
+	ID         int64   `json:"id"`
+	Nickname   string  `json:"nickname"`
+	Username   string  `json:"username"`
+	Phone      string  `json:"phone"`
+	Avatar     string  `json:"avatar"`
+	Balance    int64   `json:"balance"`
+	Status     int     `json:"status"`
+	IsAdmin    bool    `json:"is_admin"`
+	Categories []int64 `json:"categories"`
+	CreatedOn  int64   `json:"created_on"`
+}
+
+// AccountArchive all data of a user that exported by account export
+type AccountArchive struct {
+	ExportedOn        int64               `json:"exported_on"`
+	Profile           *AccountProfile     `json:"profile"`
+	Posts             []*Post             `json:"posts"`
+	PostContents      []*PostContent      `json:"post_contents"`
+	Recordings        []string            `json:"recordings"`
+	Comments          []*Comment          `json:"comments"`
+	CommentContents   []*CommentContent   `json:"comment_contents"`
+	CommentReplies    []*CommentReply     `json:"comment_replies"`
+	PostStars         []*PostStar         `json:"post_stars"`
+	PostCollections   []*PostCollection   `json:"post_collections"`
+	ReactionsGiven    []*UserReaction     `json:"reactions_given"`
+	ReactionsReceived []*UserReaction     `json:"reactions_received"`
+	Followings        []*Following        `json:"followings"`
+	Followers         []*Following        `json:"followers"`
+	Friends           []*Contact          `json:"friends"`
+	PhoneContacts     []*UserPhoneContact `json:"phone_contacts"`
+	Devices           []*UserDevice       `json:"devices"`
+	WalletStatements  []*WalletStatement  `json:"wallet_statements"`
+	DmConversations   []*DmParticipant    `json:"dm_conversations"`
+	DmMessages        []*DmMessage        `json:"dm_messages"`
+	Blocks            []*UserBlock        `json:"blocks"`
+}
+
+// TableName specifies the table name for AccountExport
+func (AccountExport) TableName() string {
+	return "p_account_exports"
+}
+
+// TableName specifies the table name for AccountDeletion
+func (AccountDeletion) TableName() string {
+	return "p_account_deletions"
+}
+
+func (e *AccountExport) Create(db *gorm.DB) (*AccountExport, error) {
+	err := db.Create(e).Error
+	return e, err
+}
+
+func (e *AccountExport) Get(db *gorm.DB) (*AccountExport, error) {
+	var export AccountExport
+	if e.Model == nil || e.ID <= 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := db.Where("id = ? AND user_id = ? AND is_del = ?", e.ID, e.UserID, 0).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetRunning get a pending or processing export job of user that is updated after staleBefore
+func (e *AccountExport) GetRunning(db *gorm.DB, staleBefore int64) (*AccountExport, error) {
+	var export AccountExport
+	err := db.Where("user_id = ? AND status IN ? AND modified_on >= ? AND is_del = ?",
+		e.UserID, []int8{cs.AccountExportPending, cs.AccountExportProcessing}, staleBefore, 0).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FailStale mark pending or processing export jobs of user that are not updated since staleBefore as failed
+func (e *AccountExport) FailStale(db *gorm.DB, staleBefore int64, reason string) error {
+	return db.Model(&AccountExport{}).Where("user_id = ? AND status IN ? AND modified_on < ? AND is_del = ?",
+		e.UserID, []int8{cs.AccountExportPending, cs.AccountExportProcessing}, staleBefore, 0).
+		Updates(map[string]any{
+			"status":      cs.AccountExportFailed,
+			"reason":      reason,
+			"finished_on": time.Now().Unix(),
+		}).Error
+}
+
+func (e *AccountExport) Update(db *gorm.DB) error {
+	return db.Model(&AccountExport{}).Where("id = ? AND is_del = ?", e.Model.ID, 0).Save(e).Error
+}
+
+func (d *AccountDeletion) Create(db *gorm.DB) (*AccountDeletion, error) {
+	err := db.Create(d).Error
+	return d, err
+}
+
+// GetScheduled get the scheduled deletion of user
+func (d *AccountDeletion) GetScheduled(db *gorm.DB) (*AccountDeletion, error) {
+	var deletion AccountDeletion
+	err := db.Where("user_id = ? AND status = ? AND is_del = ?", d.UserID, cs.AccountDeletionScheduled, 0).
+		First(&deletion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// ListDue list scheduled deletions whose grace period is over
+func (d *AccountDeletion) ListDue(db *gorm.DB, now int64, limit int) (res []*AccountDeletion, err error) {
+	err = db.Where("status = ? AND scheduled_on <= ? AND is_del = ?", cs.AccountDeletionScheduled, now, 0).
+		Order("scheduled_on ASC").Limit(limit).Find(&res).Error
+	return
+}
+
+func (d *AccountDeletion) Update(db *gorm.DB) error {
+	return db.Model(&AccountDeletion{}).Where("id = ? AND is_del = ?", d.Model.ID, 0).Save(d).Error
+}
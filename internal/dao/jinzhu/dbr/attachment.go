@@ -12,6 +12,7 @@ const (
 	AttachmentTypeImage AttachmentType = iota + 1
 	AttachmentTypeVideo
 	AttachmentTypeOther
+	AttachmentTypeAudio
 )
 
 type Attachment struct {
@@ -22,6 +23,8 @@ type Attachment struct {
 	ImgHeight int            `json:"img_height"`
 	Type      AttachmentType `json:"type"`
 	Content   string         `json:"content"`
+	Duration  int64          `json:"duration"`
+	Waveform  []int          `json:"waveform" gorm:"type:jsonb;default:'[]';serializer:json"`
 }
 
 func (a *Attachment) Create(db *gorm.DB) (*Attachment, error) {
@@ -19,25 +19,31 @@ type Comment struct {
 	IPLoc           string `json:"ip_loc"`
 	IsEssence       int8   `json:"is_essense"`
 	ReplyCount      int32  `json:"reply_count"`
+	AudioReplyCount int32  `json:"audio_reply_count"`
 	ThumbsUpCount   int32  `json:"thumbs_up_count"`
 	ThumbsDownCount int32  `json:"-"`
+	PinnedOn        int64  `json:"pinned_on"`
+	EditedOn        int64  `json:"edited_on"`
 }
 
 type CommentFormated struct {
-	ID            int64                   `json:"id"`
-	PostID        int64                   `json:"post_id"`
-	UserID        int64                   `json:"user_id"`
-	User          *UserFormated           `json:"user"`
-	Contents      []*CommentContent       `json:"contents"`
-	Replies       []*CommentReplyFormated `json:"replies"`
-	IPLoc         string                  `json:"ip_loc"`
-	ReplyCount    int32                   `json:"reply_count"`
-	ThumbsUpCount int32                   `json:"thumbs_up_count"`
-	IsEssence     int8                    `json:"is_essence"`
-	IsThumbsUp    int8                    `json:"is_thumbs_up"`
-	IsThumbsDown  int8                    `json:"is_thumbs_down"`
-	CreatedOn     int64                   `json:"created_on"`
-	ModifiedOn    int64                   `json:"modified_on"`
+	ID              int64                   `json:"id"`
+	PostID          int64                   `json:"post_id"`
+	UserID          int64                   `json:"user_id"`
+	User            *UserFormated           `json:"user"`
+	Contents        []*CommentContent       `json:"contents"`
+	Replies         []*CommentReplyFormated `json:"replies"`
+	IPLoc           string                  `json:"ip_loc"`
+	ReplyCount      int32                   `json:"reply_count"`
+	AudioReplyCount int32                   `json:"audio_reply_count"`
+	ThumbsUpCount   int32                   `json:"thumbs_up_count"`
+	IsEssence       int8                    `json:"is_essence"`
+	IsPinned        bool                    `json:"is_pinned"`
+	IsThumbsUp      int8                    `json:"is_thumbs_up"`
+	IsThumbsDown    int8                    `json:"is_thumbs_down"`
+	CreatedOn       int64                   `json:"created_on"`
+	ModifiedOn      int64                   `json:"modified_on"`
+	EditedOn        int64                   `json:"edited_on"`
 }
 
 func (c *Comment) Format() *CommentFormated {
@@ -45,20 +51,23 @@ func (c *Comment) Format() *CommentFormated {
 		return &CommentFormated{}
 	}
 	return &CommentFormated{
-		ID:            c.Model.ID,
-		PostID:        c.PostID,
-		UserID:        c.UserID,
-		User:          &UserFormated{},
-		Contents:      []*CommentContent{},
-		Replies:       []*CommentReplyFormated{},
-		IPLoc:         c.IPLoc,
-		ReplyCount:    c.ReplyCount,
-		ThumbsUpCount: c.ThumbsUpCount,
-		IsEssence:     c.IsEssence,
-		IsThumbsUp:    types.No,
-		IsThumbsDown:  types.No,
-		CreatedOn:     c.CreatedOn,
-		ModifiedOn:    c.ModifiedOn,
+		ID:              c.Model.ID,
+		PostID:          c.PostID,
+		UserID:          c.UserID,
+		User:            &UserFormated{},
+		Contents:        []*CommentContent{},
+		Replies:         []*CommentReplyFormated{},
+		IPLoc:           c.IPLoc,
+		ReplyCount:      c.ReplyCount,
+		AudioReplyCount: c.AudioReplyCount,
+		ThumbsUpCount:   c.ThumbsUpCount,
+		IsEssence:       c.IsEssence,
+		IsPinned:        c.PinnedOn > 0,
+		IsThumbsUp:      types.No,
+		IsThumbsDown:    types.No,
+		CreatedOn:       c.CreatedOn,
+		ModifiedOn:      c.ModifiedOn,
+		EditedOn:        c.EditedOn,
 	}
 }
 
@@ -17,6 +17,8 @@ type CommentContent struct {
 	Content   string       `json:"content"`
 	Type      PostContentT `json:"type"`
 	Sort      int64        `json:"sort"`
+	Duration  int64        `json:"duration"`
+	Waveform  []int        `json:"waveform" gorm:"type:jsonb;default:'[]';serializer:json"`
 }
 
 func (c *CommentContent) List(db *gorm.DB, conditions *ConditionsT, offset, limit int) ([]*CommentContent, error) {
@@ -51,7 +53,7 @@ func (c *CommentContent) Create(db *gorm.DB) (*CommentContent, error) {
 }
 
 func (c *CommentContent) MediaContentsByCommentId(db *gorm.DB, commentIds []int64) (contents []string, err error) {
-	err = db.Model(c).Where("comment_id IN ? AND type = ?", commentIds, ContentTypeImage).Select("content").Find(&contents).Error
+	err = db.Model(c).Where("comment_id IN ? AND type IN ?", commentIds, []PostContentT{ContentTypeImage, ContentTypeAudio}).Select("content").Find(&contents).Error
 	return
 }
 
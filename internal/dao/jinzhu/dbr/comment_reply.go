@@ -7,20 +7,24 @@ package dbr
 import (
 	"time"
 
+	"github.com/rocboss/paopao-ce/pkg/rank"
 	"github.com/rocboss/paopao-ce/pkg/types"
 	"gorm.io/gorm"
 )
 
 type CommentReply struct {
 	*Model
-	CommentID       int64  `db:"comment_id" json:"comment_id"`
-	UserID          int64  `db:"user_id" json:"user_id"`
-	AtUserID        int64  `db:"at_user_id" json:"at_user_id"`
-	Content         string `json:"content"`
-	IP              string `json:"ip"`
-	IPLoc           string `json:"ip_loc"`
-	ThumbsUpCount   int32  `json:"thumbs_up_count"`
-	ThumbsDownCount int32  `json:"-"`
+	CommentID       int64        `db:"comment_id" json:"comment_id"`
+	UserID          int64        `db:"user_id" json:"user_id"`
+	AtUserID        int64        `db:"at_user_id" json:"at_user_id"`
+	Content         string       `json:"content"`
+	Type            PostContentT `json:"type"`
+	Duration        int64        `json:"duration"`
+	Waveform        []int        `json:"waveform" gorm:"type:jsonb;default:'[]';serializer:json"`
+	IP              string       `json:"ip"`
+	IPLoc           string       `json:"ip_loc"`
+	ThumbsUpCount   int32        `json:"thumbs_up_count"`
+	ThumbsDownCount int32        `json:"-"`
 }
 
 type CommentReplyFormated struct {
@@ -31,8 +35,12 @@ type CommentReplyFormated struct {
 	AtUserID      int64         `db:"at_user_id" json:"at_user_id"`
 	AtUser        *UserFormated `json:"at_user"`
 	Content       string        `json:"content"`
+	Type          PostContentT  `json:"type"`
+	Duration      int64         `json:"duration"`
+	Waveform      []int         `json:"waveform"`
 	IPLoc         string        `json:"ip_loc"`
 	ThumbsUpCount int32         `json:"thumbs_up_count"`
+	IsCollapsed   bool          `json:"is_collapsed"`
 	IsThumbsUp    int8          `json:"is_thumbs_up"`
 	IsThumbsDown  int8          `json:"is_thumbs_down"`
 	CreatedOn     int64         `json:"created_on"`
@@ -52,8 +60,12 @@ func (c *CommentReply) Format() *CommentReplyFormated {
 		AtUserID:      c.AtUserID,
 		AtUser:        &UserFormated{},
 		Content:       c.Content,
+		Type:          c.Type,
+		Duration:      c.Duration,
+		Waveform:      c.Waveform,
 		IPLoc:         c.IPLoc,
 		ThumbsUpCount: c.ThumbsUpCount,
+		IsCollapsed:   rank.Collapsed(int64(c.ThumbsUpCount), int64(c.ThumbsDownCount)),
 		IsThumbsUp:    types.No,
 		IsThumbsDown:  types.No,
 		CreatedOn:     c.CreatedOn,
@@ -115,6 +127,11 @@ func (c *CommentReply) Delete(db *gorm.DB) error {
 	}).Error
 }
 
+func (c *CommentReply) AudioContentsByCommentId(db *gorm.DB, commentIds []int64) (contents []string, err error) {
+	err = db.Model(c).Where("comment_id IN ? AND type = ?", commentIds, ContentTypeAudio).Select("content").Find(&contents).Error
+	return
+}
+
 func (c *CommentReply) DeleteByCommentIds(db *gorm.DB, commentIds []int64) error {
 	return db.Model(c).Where("comment_id IN ?", commentIds).Updates(map[string]any{
 		"deleted_on": time.Now().Unix(),
@@ -0,0 +1,70 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// DmConversation 两个用户之间的私信会话
+type DmConversation struct {
+	*Model
+	LastMessageID int64 `json:"last_message_id"`
+	LastMessageOn int64 `json:"last_message_on"`
+}
+
+// DmParticipant 会话参与者，LastReadID 为已读游标
+type DmParticipant struct {
+	*Model
+	ConversationID int64 `json:"conversation_id"`
+	UserID         int64 `json:"user_id"`
+	PeerID         int64 `json:"peer_id"`
+	LastReadID     int64 `json:"last_read_id"`
+}
+
+// DmMessage 私信消息，音频与图片消息的 Content 为附件地址
+type DmMessage struct {
+	*Model
+	ConversationID int64     `json:"conversation_id"`
+	SenderID       int64     `json:"sender_id"`
+	Type           cs.DmMsgT `json:"type"`
+	Content        string    `json:"content"`
+	Duration       int64     `json:"duration"`
+	Waveform       []int     `json:"waveform" gorm:"type:jsonb;default:'[]';serializer:json"`
+}
+
+// DmConversationFormated 会话列表项，PeerReadID 为对方的已读游标
+type DmConversationFormated struct {
+	ID            int64         `json:"id"`
+	Peer          *UserFormated `json:"peer"`
+	LastMessage   *DmMessage    `json:"last_message"`
+	LastMessageOn int64         `json:"last_message_on"`
+	LastReadID    int64         `json:"last_read_id"`
+	PeerReadID    int64         `json:"peer_read_id"`
+	UnreadCount   int64         `json:"unread_count"`
+}
+
+// UserBlock 用户屏蔽关系，双方都不能再给对方发私信
+type UserBlock struct {
+	*Model
+	UserID    int64 `json:"user_id"`
+	BlockedID int64 `json:"blocked_id"`
+}
+
+func (DmConversation) TableName() string {
+	return "p_dm_conversations"
+}
+
+func (DmParticipant) TableName() string {
+	return "p_dm_participants"
+}
+
+func (DmMessage) TableName() string {
+	return "p_dm_messages"
+}
+
+func (UserBlock) TableName() string {
+	return "p_user_blocks"
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// RevisionContent 编辑前的一段文本内容
+type RevisionContent struct {
+	Content string       `json:"content"`
+	Type    PostContentT `json:"type"`
+	Sort    int64        `json:"sort"`
+}
+
+// EditRevision 动态或评论编辑前的文本快照，Tags 仅动态有效
+type EditRevision struct {
+	*Model
+	TargetType cs.EditTargetT     `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	UserID     int64              `json:"user_id"`
+	Tags       string             `json:"tags"`
+	Contents   []*RevisionContent `json:"contents" gorm:"type:jsonb;default:'[]';serializer:json"`
+}
+
+func (EditRevision) TableName() string {
+	return "p_edit_revisions"
+}
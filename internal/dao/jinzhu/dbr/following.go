@@ -5,9 +5,9 @@
 package dbr
 
 import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type Following struct {
@@ -15,6 +15,10 @@ type Following struct {
 	User     *User `json:"-" gorm:"foreignKey:ID;references:FollowId"`
 	UserId   int64 `json:"user_id"`
 	FollowId int64 `json:"friend_id"`
+	RoomPing int8  `json:"room_ping"`
+	Status   int8  `json:"status"` // 1待通过的关注请求, 2已关注
+	// InteractedOn 两人最近一次互动(通知或私信)的时间，初始为关注时间
+	InteractedOn int64 `json:"interacted_on"`
 }
 
 func (f *Following) GetFollowing(db *gorm.DB, userId, followId int64) (*Following, error) {
@@ -31,40 +35,12 @@ func (f *Following) DelFollowing(db *gorm.DB, userId, followId int64) error {
 	return db.Omit("User").Unscoped().Where("user_id = ? AND follow_id = ?", userId, followId).Delete(f).Error
 }
 
-func (f *Following) ListFollows(db *gorm.DB, userId int64, limit int, offset int) (res []*Following, total int64, err error) {
-	db = db.Model(f).Where("user_id=?", userId)
-	if err = db.Count(&total).Error; err != nil {
-		return
-	}
-	if offset >= 0 && limit > 0 {
-		db = db.Offset(offset).Limit(limit)
-	}
-	db.Joins("User").Order(clause.OrderByColumn{Column: clause.Column{Table: "User", Name: "nickname"}, Desc: false})
-	if err = db.Find(&res).Error; err != nil {
-		return
-	}
-	return
-}
-
-func (f *Following) ListFollowingIds(db *gorm.DB, userId int64, limit, offset int) (ids []int64, total int64, err error) {
-	db = db.Model(f).Where("follow_id=?", userId)
-	if err = db.Count(&total).Error; err != nil {
-		return
-	}
-	if offset >= 0 && limit > 0 {
-		db = db.Offset(offset).Limit(limit)
-	}
-	if err = db.Omit("User").Select("user_id").Find(&ids).Error; err != nil {
-		return
-	}
-	return
-}
-
+// FollowCount 关注与粉丝计数，不包括待通过的关注请求
 func (f *Following) FollowCount(db *gorm.DB, userId int64) (follows int64, followings int64, err error) {
-	if err = db.Model(f).Where("follow_id=?", userId).Count(&follows).Error; err != nil {
+	if err = db.Model(f).Where("follow_id=? AND status=?", userId, cs.FollowStatusAgree).Count(&follows).Error; err != nil {
 		return
 	}
-	if err = db.Model(f).Where("user_id=?", userId).Count(&followings).Error; err != nil {
+	if err = db.Model(f).Where("user_id=? AND status=?", userId, cs.FollowStatusAgree).Count(&followings).Error; err != nil {
 		return
 	}
 	return
@@ -86,7 +62,7 @@ func (f *Following) UpdateFollowCount(db *gorm.DB, userId int64) error {
 }
 
 func (s *Following) IsFollow(db *gorm.DB, userId int64, followId int64) bool {
-	if _, err := s.GetFollowing(db, userId, followId); err == nil {
+	if f, err := s.GetFollowing(db, userId, followId); err == nil && f.Status == cs.FollowStatusAgree {
 		return true
 	}
 	return false
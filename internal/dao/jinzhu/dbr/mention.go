@@ -0,0 +1,27 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// Mention 内容中的一处@提及，Offset 与 Length 以 rune 计且包含 @ 符号
+type Mention struct {
+	*Model
+	SourceType      cs.MentionSourceT `json:"source_type"`
+	SourceID        int64             `json:"source_id"`
+	PostID          int64             `json:"post_id"`
+	CommentID       int64             `json:"comment_id"`
+	ReplyID         int64             `json:"reply_id"`
+	UserID          int64             `json:"user_id"`
+	MentionedUserID int64             `json:"mentioned_user_id"`
+	Offset          int               `json:"offset"`
+	Length          int               `json:"length"`
+}
+
+func (Mention) TableName() string {
+	return "p_mentions"
+}
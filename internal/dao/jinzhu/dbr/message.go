@@ -4,7 +4,12 @@
 
 package dbr
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"gorm.io/gorm"
+)
 
 type MessageT int8
 
@@ -16,6 +21,9 @@ const (
 	MsgTypeRequestingFriend
 	MsgTypeFollow
 	MsgTypeUserReaction
+	MsgTypeRoomInvite
+	MsgTypeFollowRequest
+	MsgTypeFollowApproved
 	MsgTypeSystem MessageT = 99
 
 	MsgStatusUnread = 0
@@ -24,35 +32,54 @@ const (
 
 type Message struct {
 	*Model
-	SenderUserID   int64    `json:"sender_user_id"`
-	ReceiverUserID int64    `json:"receiver_user_id"`
-	Type           MessageT `json:"type"`
-	Brief          string   `json:"brief"`
-	Content        string   `json:"content"`
-	PostID         int64    `json:"post_id"`
-	CommentID      int64    `json:"comment_id"`
-	ReplyID        int64    `json:"reply_id"`
-	IsRead         int8     `json:"is_read"`
+	SenderUserID   int64              `json:"sender_user_id"`
+	ReceiverUserID int64              `json:"receiver_user_id"`
+	Type           MessageT           `json:"type"`
+	Brief          string             `json:"brief"`
+	Content        string             `json:"content"`
+	PostID         int64              `json:"post_id"`
+	CommentID      int64              `json:"comment_id"`
+	ReplyID        int64              `json:"reply_id"`
+	RoomID         int64              `json:"room_id"`
+	IsRead         int8               `json:"is_read"`
+	Category       cs.MessageCategory `json:"category"`
+	GroupKey       string             `json:"-"`
+	ActorIDs       []int64            `json:"actor_ids" gorm:"type:jsonb;default:'[]';serializer:json"`
+	ActorCount     int                `json:"actor_count"`
+	LatestOn       int64              `json:"latest_on"`
+}
+
+// MessageGroupActor 分组通知的一位触发者，同一触发者在一组中只记录一次，用于精确统计触发人数
+type MessageGroupActor struct {
+	*Model
+	MessageID int64 `json:"message_id"`
+	ActorID   int64 `json:"actor_id"`
 }
 
 type MessageFormated struct {
-	ID             int64         `json:"id"`
-	SenderUserID   int64         `json:"sender_user_id"`
-	SenderUser     *UserFormated `json:"sender_user"`
-	ReceiverUserID int64         `json:"receiver_user_id"`
-	ReceiverUser   *UserFormated `json:"receiver_user,omitempty"`
-	Type           MessageT      `json:"type"`
-	Brief          string        `json:"brief"`
-	Content        string        `json:"content"`
-	PostID         int64         `json:"post_id"`
-	Post           *PostFormated `json:"post"`
-	CommentID      int64         `json:"comment_id"`
-	Comment        *Comment      `json:"comment"`
-	ReplyID        int64         `json:"reply_id"`
-	Reply          *CommentReply `json:"reply"`
-	IsRead         int8          `json:"is_read"`
-	CreatedOn      int64         `json:"created_on"`
-	ModifiedOn     int64         `json:"modified_on"`
+	ID             int64              `json:"id"`
+	SenderUserID   int64              `json:"sender_user_id"`
+	SenderUser     *UserFormated      `json:"sender_user"`
+	ReceiverUserID int64              `json:"receiver_user_id"`
+	ReceiverUser   *UserFormated      `json:"receiver_user,omitempty"`
+	Type           MessageT           `json:"type"`
+	Brief          string             `json:"brief"`
+	Content        string             `json:"content"`
+	PostID         int64              `json:"post_id"`
+	Post           *PostFormated      `json:"post"`
+	CommentID      int64              `json:"comment_id"`
+	Comment        *Comment           `json:"comment"`
+	ReplyID        int64              `json:"reply_id"`
+	Reply          *CommentReply      `json:"reply"`
+	RoomID         int64              `json:"room_id"`
+	IsRead         int8               `json:"is_read"`
+	Category       cs.MessageCategory `json:"category"`
+	ActorIDs       []int64            `json:"actor_ids"`
+	Actors         []*UserFormated    `json:"actors"`
+	ActorCount     int                `json:"actor_count"`
+	LatestOn       int64              `json:"latest_on"`
+	CreatedOn      int64              `json:"created_on"`
+	ModifiedOn     int64              `json:"modified_on"`
 }
 
 func (m *Message) Format() *MessageFormated {
@@ -74,7 +101,13 @@ func (m *Message) Format() *MessageFormated {
 		Comment:        &Comment{},
 		ReplyID:        m.ReplyID,
 		Reply:          &CommentReply{},
+		RoomID:         m.RoomID,
 		IsRead:         m.IsRead,
+		Category:       m.Category,
+		ActorIDs:       m.ActorIDs,
+		Actors:         []*UserFormated{},
+		ActorCount:     m.ActorCount,
+		LatestOn:       m.LatestOn,
 		CreatedOn:      m.CreatedOn,
 		ModifiedOn:     m.ModifiedOn,
 	}
@@ -82,7 +115,38 @@ func (m *Message) Format() *MessageFormated {
 	return mf
 }
 
+// Category 按消息类型推断通知分类，@提及需由创建方显式指定
+func (t MessageT) Category() cs.MessageCategory {
+	switch t {
+	case MsgTypePost:
+		return cs.MsgCategoryMention
+	case MsgtypeComment, MsgTypeReply:
+		return cs.MsgCategoryComment
+	case MsgTypeWhisper:
+		return cs.MsgCategoryWhisper
+	case MsgTypeRequestingFriend:
+		return cs.MsgCategoryFriend
+	case MsgTypeFollow, MsgTypeFollowRequest, MsgTypeFollowApproved:
+		return cs.MsgCategoryFollow
+	case MsgTypeUserReaction:
+		return cs.MsgCategoryReaction
+	case MsgTypeRoomInvite:
+		return cs.MsgCategoryRoomInvite
+	default:
+		return cs.MsgCategorySystem
+	}
+}
+
 func (m *Message) Create(db *gorm.DB) (*Message, error) {
+	if m.Category == "" {
+		m.Category = m.Type.Category()
+	}
+	if len(m.ActorIDs) == 0 {
+		m.ActorIDs, m.ActorCount = []int64{m.SenderUserID}, 1
+	}
+	if m.LatestOn == 0 {
+		m.LatestOn = time.Now().Unix()
+	}
 	err := db.Create(&m).Error
 
 	return m, err
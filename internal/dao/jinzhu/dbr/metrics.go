@@ -23,6 +23,7 @@ type CommentMetric struct {
 	*Model
 	CommentId        int64
 	RankScore        int64
+	WilsonScore      float64
 	IncentiveScore   int
 	DecayFactor      int
 	MotivationFactor int
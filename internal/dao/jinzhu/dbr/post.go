@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"gorm.io/gorm"
 	"github.com/sirupsen/logrus"
 )
@@ -22,6 +23,25 @@ const (
 	PostVisitFollowing PostVisibleT = 60
 )
 
+// PostRepostT 转发类型
+type PostRepostT int8
+
+const (
+	PostRepostNone  PostRepostT = 0
+	PostRepostPlain PostRepostT = 1
+	PostRepostQuote PostRepostT = 2
+)
+
+// PostStatusT 发布状态，未发布的动态只有作者可见，不进入时间线与搜索
+type PostStatusT int8
+
+const (
+	PostStatusPublished  PostStatusT = 0
+	PostStatusDraft      PostStatusT = 1
+	PostStatusProcessing PostStatusT = 2
+	PostStatusScheduled  PostStatusT = 3
+)
+
 type PostByMedia = Post
 
 type PostByComment = Post
@@ -55,6 +75,23 @@ type Post struct {
 	IPLoc           string       `json:"ip_loc"`
 	RoomID          string       `json:"room_id"`
 	SessionID       string       `json:"session_id"`
+	// 转发的原动态，引用转发时为被引用的动态
+	OriginID        int64       `json:"origin_id"`
+	RepostType      PostRepostT `json:"repost_type"`
+	// 串推的首条动态及上一条动态
+	ThreadRootID    int64 `json:"thread_root_id"`
+	ThreadParentID  int64 `json:"thread_parent_id"`
+	// 发布状态，定时发布时 ScheduledOn 为计划发布时间
+	Status          PostStatusT `json:"status"`
+	ScheduledOn     int64       `json:"scheduled_on"`
+	// 最近一次编辑的时间，未编辑过时为0
+	EditedOn        int64       `json:"edited_on"`
+	// 被管理员隐藏的时间，隐藏后作者不能修改可见性
+	HiddenOn        int64       `json:"hidden_on"`
+	// 被管理员锁定评论的时间，锁定后作者不能解除
+	LockedOn        int64       `json:"locked_on"`
+	// 删除动态的管理员，作者自己删除时为0，仅管理员删除的动态可以恢复
+	DeletedBy       int64       `json:"deleted_by"`
 	// Location fields
 	LocationName    string  `json:"location_name"`
 	LocationLat     float64 `json:"location_lat"`
@@ -87,6 +124,15 @@ type PostFormated struct {
 	IPLoc           string                 `json:"ip_loc"`
 	RoomID          string                 `json:"room_id"`
 	SessionID       string                 `json:"session_id"`
+	OriginID        int64                  `json:"origin_id"`
+	RepostType      PostRepostT            `json:"repost_type"`
+	ThreadRootID    int64                  `json:"thread_root_id"`
+	ThreadParentID  int64                  `json:"thread_parent_id"`
+	Status          PostStatusT            `json:"status"`
+	ScheduledOn     int64                  `json:"scheduled_on"`
+	EditedOn        int64                  `json:"edited_on"`
+	// 转发的原动态，原动态已删除或不再公开时为空
+	Origin *PostFormated `json:"origin,omitempty"`
 	// Location fields
 	LocationName    string  `json:"location_name"`
 	LocationLat     float64 `json:"location_lat"`
@@ -95,6 +141,8 @@ type PostFormated struct {
 	LocationCity    string  `json:"location_city"`
 	LocationState   string  `json:"location_state"`
 	LocationCountry string  `json:"location_country"`
+	// 音频转写字幕，仅在动态详情中按需返回
+	Captions cs.TranscriptSegments `json:"captions,omitempty"`
 }
 
 func (t PostVisibleT) ToOutValue() (res uint8) {
@@ -127,6 +175,14 @@ func (p *Post) GetVisitorID() int64 {
     return 0
 }
 
+// EffectiveID 纯转发指向原动态，其余为自身，用于时间线去重
+func (p *PostFormated) EffectiveID() int64 {
+	if p.RepostType == PostRepostPlain && p.OriginID > 0 {
+		return p.OriginID
+	}
+	return p.ID
+}
+
 func (p *Post) Format() *PostFormated {
 	if p.Model != nil {
 		tagsMap := map[string]int8{}
@@ -155,6 +211,13 @@ func (p *Post) Format() *PostFormated {
 			IPLoc:           p.IPLoc,
 			RoomID:          p.RoomID,
 			SessionID:       p.SessionID,
+			OriginID:        p.OriginID,
+			RepostType:      p.RepostType,
+			ThreadRootID:    p.ThreadRootID,
+			ThreadParentID:  p.ThreadParentID,
+			Status:          p.Status,
+			ScheduledOn:     p.ScheduledOn,
+			EditedOn:        p.EditedOn,
 			// Location fields
 			LocationName:    p.LocationName,
 			LocationLat:     p.LocationLat,
@@ -308,7 +371,7 @@ func (p *Post) GetPostLocation(db *gorm.DB, postID int64, userID int64, pageSize
 	// Count total posts for this user with visibility filtering (same as getUserPosts)
 	var total int64
 	if err = db.Model(&Post{}).
-		Where("CAST(user_id->0 AS bigint) = ? AND is_del = ? AND visibility >= ?", userID, 0, PostVisitPublic).
+		Where("CAST(user_id->0 AS bigint) = ? AND is_del = ? AND status = ? AND visibility >= ?", userID, 0, PostStatusPublished, PostVisitPublic).
 		Count(&total).Error; err != nil {
 		return 0, 0, 0, err
 	}
@@ -316,7 +379,7 @@ func (p *Post) GetPostLocation(db *gorm.DB, postID int64, userID int64, pageSize
 	// Use a simple approach: get all posts in the same order as getUserPosts and find our post
 	var allPosts []Post
 	if err = db.Model(&Post{}).
-		Where("CAST(user_id->0 AS bigint) = ? AND is_del = ? AND visibility >= ?", userID, 0, PostVisitPublic).
+		Where("CAST(user_id->0 AS bigint) = ? AND is_del = ? AND status = ? AND visibility >= ?", userID, 0, PostStatusPublished, PostVisitPublic).
 		Order("is_top DESC, latest_replied_on DESC").
 		Find(&allPosts).Error; err != nil {
 		return 0, 0, 0, err
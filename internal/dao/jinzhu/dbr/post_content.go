@@ -36,14 +36,15 @@ var (
 
 type PostContent struct {
 	*Model
-	PostID   int64        `json:"post_id"`
-	UserID   []int64      `json:"user_id" gorm:"type:jsonb;default:'[0,0]';serializer:json"`
-	Content  string       `json:"content"`
-	Type     PostContentT `json:"type"`
-	Sort     int64        `json:"sort"`
-	RoomID   string       `json:"room_id"`
-	Duration string       `json:"duration"`
-	Size     string       `json:"size"`
+	PostID    int64        `json:"post_id"`
+	CommentID int64        `json:"comment_id"`
+	UserID    []int64      `json:"user_id" gorm:"type:jsonb;default:'[0,0]';serializer:json"`
+	Content   string       `json:"content"`
+	Type      PostContentT `json:"type"`
+	Sort      int64        `json:"sort"`
+	RoomID    string       `json:"room_id"`
+	Duration  string       `json:"duration"`
+	Size      string       `json:"size"`
 }
 
 // SetUserIDs sets the UserID field from a slice of int64
@@ -0,0 +1,32 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// PostTranscript 动态音频的转写片段，每条音轨对应一个说话人
+type PostTranscript struct {
+	*Model
+	PostID    int64  `json:"post_id"`
+	SpeakerID int64  `json:"speaker_id"`
+	StartMs   int64  `json:"start_ms"`
+	EndMs     int64  `json:"end_ms"`
+	Text      string `json:"text"`
+}
+
+func (PostTranscript) TableName() string {
+	return "p_post_transcripts"
+}
+
+func (p *PostTranscript) Format() *cs.TranscriptSegment {
+	return &cs.TranscriptSegment{
+		SpeakerID: p.SpeakerID,
+		Start:     p.StartMs,
+		End:       p.EndMs,
+		Text:      p.Text,
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"gorm.io/gorm"
+)
+
+// Role a named set of permissions
+type Role struct {
+	*Model
+	Name        cs.RoleName     `json:"name"`
+	Description string          `json:"description"`
+	Permissions []cs.Permission `json:"permissions" gorm:"-"`
+}
+
+// RolePermission a permission granted by a role
+type RolePermission struct {
+	*Model
+	RoleID     int64         `json:"role_id"`
+	Permission cs.Permission `json:"permission"`
+}
+
+// UserRole a role assigned to a user
+type UserRole struct {
+	*Model
+	UserID int64 `json:"user_id"`
+	RoleID int64 `json:"role_id"`
+}
+
+// TableName specifies the table name for Role
+func (Role) TableName() string {
+	return "p_roles"
+}
+
+// TableName specifies the table name for RolePermission
+func (RolePermission) TableName() string {
+	return "p_role_permissions"
+}
+
+// TableName specifies the table name for UserRole
+func (UserRole) TableName() string {
+	return "p_user_roles"
+}
+
+func (r *Role) GetByName(db *gorm.DB) (*Role, error) {
+	var role Role
+	if err := db.Where("name = ? AND is_del = ?", r.Name, 0).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List 获取所有角色及其权限
+func (r *Role) List(db *gorm.DB) ([]*Role, error) {
+	var roles []*Role
+	if err := db.Where("is_del = ?", 0).Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, r.fillPermissions(db, roles)
+}
+
+// ListByUserId 获取用户拥有的角色及其权限
+func (r *Role) ListByUserId(db *gorm.DB, userId int64) ([]*Role, error) {
+	var roles []*Role
+	if err := db.Table(r.TableName()+" r").
+		Joins("JOIN "+(UserRole{}).TableName()+" ur ON ur.role_id = r.id AND ur.is_del = 0").
+		Where("ur.user_id = ? AND r.is_del = ?", userId, 0).
+		Order("r.id ASC").Select("r.*").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, r.fillPermissions(db, roles)
+}
+
+func (r *Role) fillPermissions(db *gorm.DB, roles []*Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	roleIds := make([]int64, 0, len(roles))
+	idxRoles := make(map[int64]*Role, len(roles))
+	for _, role := range roles {
+		roleIds = append(roleIds, role.ID)
+		idxRoles[role.ID] = role
+	}
+	var perms []*RolePermission
+	if err := db.Where("role_id IN ? AND is_del = ?", roleIds, 0).Order("id ASC").Find(&perms).Error; err != nil {
+		return err
+	}
+	for _, perm := range perms {
+		if role, ok := idxRoles[perm.RoleID]; ok {
+			role.Permissions = append(role.Permissions, perm.Permission)
+		}
+	}
+	return nil
+}
+
+// PermissionsByUserId 获取用户通过角色获得的所有权限
+func (p *RolePermission) PermissionsByUserId(db *gorm.DB, userId int64) (res []cs.Permission, err error) {
+	err = db.Model(p).Distinct("permission").
+		Joins("JOIN "+(UserRole{}).TableName()+" ur ON ur.role_id = "+p.TableName()+".role_id AND ur.is_del = 0").
+		Where("ur.user_id = ? AND "+p.TableName()+".is_del = ?", userId, 0).
+		Pluck("permission", &res).Error
+	return
+}
+
+func (u *UserRole) Create(db *gorm.DB) (*UserRole, error) {
+	err := db.Create(u).Error
+	return u, err
+}
+
+func (u *UserRole) Get(db *gorm.DB) (*UserRole, error) {
+	var userRole UserRole
+	if err := db.Where("user_id = ? AND role_id = ? AND is_del = ?", u.UserID, u.RoleID, 0).First(&userRole).Error; err != nil {
+		return nil, err
+	}
+	return &userRole, nil
+}
+
+func (u *UserRole) Delete(db *gorm.DB) error {
+	return db.Where("user_id = ? AND role_id = ?", u.UserID, u.RoleID).Delete(&UserRole{}).Error
+}
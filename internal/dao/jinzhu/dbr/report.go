@@ -0,0 +1,128 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"gorm.io/gorm"
+)
+
+// Report a report filed by a user against a post, comment, room or user
+type Report struct {
+	*Model
+	ReporterID   int64               `json:"reporter_id"`
+	TargetType   cs.ReportTargetType `json:"target_type"`
+	TargetID     int64               `json:"target_id"`
+	TargetUserID int64               `json:"target_user_id"`
+	Reason       cs.ReportReason     `json:"reason"`
+	Evidence     *cs.ReportEvidence  `json:"evidence" gorm:"type:jsonb;serializer:json"`
+	Status       int8                `json:"status"`
+	HandlerID    int64               `json:"handler_id"`
+	Resolution   cs.ModerationAction `json:"resolution"`
+	HandledOn    int64               `json:"handled_on"`
+}
+
+// ModerationLog audit log for every action taken by a moderator
+type ModerationLog struct {
+	*Model
+	OperatorID   int64               `json:"operator_id"`
+	ReportID     int64               `json:"report_id"`
+	Action       cs.ModerationAction `json:"action"`
+	TargetType   cs.ReportTargetType `json:"target_type"`
+	TargetID     int64               `json:"target_id"`
+	TargetUserID int64               `json:"target_user_id"`
+	Note         string              `json:"note"`
+}
+
+// TableName specifies the table name for Report
+func (Report) TableName() string {
+	return "p_reports"
+}
+
+// TableName specifies the table name for ModerationLog
+func (ModerationLog) TableName() string {
+	return "p_moderation_logs"
+}
+
+func (r *Report) Create(db *gorm.DB) (*Report, error) {
+	err := db.Create(r).Error
+	return r, err
+}
+
+func (r *Report) Get(db *gorm.DB) (*Report, error) {
+	var report Report
+	if r.Model == nil || r.ID <= 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := db.Where("id = ? AND is_del = ?", r.ID, 0).First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetPending get a still open report of reporter about the target
+func (r *Report) GetPending(db *gorm.DB) (*Report, error) {
+	var report Report
+	err := db.Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status IN ? AND is_del = ?",
+		r.ReporterID, r.TargetType, r.TargetID, []int8{cs.ReportStatusPending, cs.ReportStatusTriaged}, 0).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *Report) List(db *gorm.DB, conditions ConditionsT, offset, limit int) (res []*Report, err error) {
+	db = db.Model(r).Where("is_del = ?", 0)
+	for k, v := range conditions {
+		if k == "ORDER" {
+			db = db.Order(v)
+		} else {
+			db = db.Where(k, v)
+		}
+	}
+	if offset >= 0 && limit > 0 {
+		db = db.Offset(offset).Limit(limit)
+	}
+	err = db.Find(&res).Error
+	return
+}
+
+func (r *Report) Count(db *gorm.DB, conditions ConditionsT) (count int64, err error) {
+	db = db.Model(r).Where("is_del = ?", 0)
+	for k, v := range conditions {
+		if k != "ORDER" {
+			db = db.Where(k, v)
+		}
+	}
+	err = db.Count(&count).Error
+	return
+}
+
+func (r *Report) Update(db *gorm.DB) error {
+	return db.Model(&Report{}).Where("id = ? AND is_del = ?", r.Model.ID, 0).Save(r).Error
+}
+
+func (l *ModerationLog) Create(db *gorm.DB) (*ModerationLog, error) {
+	err := db.Create(l).Error
+	return l, err
+}
+
+func (l *ModerationLog) List(db *gorm.DB, conditions ConditionsT, offset, limit int) (res []*ModerationLog, total int64, err error) {
+	db = db.Model(l).Where("is_del = ?", 0)
+	for k, v := range conditions {
+		if k != "ORDER" {
+			db = db.Where(k, v)
+		}
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	if offset >= 0 && limit > 0 {
+		db = db.Offset(offset).Limit(limit)
+	}
+	err = db.Order("id DESC").Find(&res).Error
+	return
+}
@@ -27,6 +27,8 @@ type Room struct {
     IsBlockedFromSpace int16   `json:"is_blocked_from_space"`
     Topics            []string `json:"topics" gorm:"type:jsonb;default:'[]';serializer:json"`
     Categories        Int64Array `json:"categories" gorm:"type:integer[];default:'{}'"`
+    LocationLat       float64  `json:"location_lat"`
+    LocationLng       float64  `json:"location_lng"`
 }
 
 type RoomFormated struct {
@@ -158,4 +160,14 @@ func (r *Room) UpdateCategoriesByHostID(db *gorm.DB, hostID int64, categoryIDs [
 	return db.Model(&Room{}).
 		Where("host_id = ? AND is_del = ?", hostID, 0).
 		Update("categories", Int64Array(categoryIDs)).Error
-} 
\ No newline at end of file
+}
+
+// UpdateLocationByHostID updates coordinates for all rooms where the user is the host
+func (r *Room) UpdateLocationByHostID(db *gorm.DB, hostID int64, lat, lng float64) error {
+	return db.Model(&Room{}).
+		Where("host_id = ? AND is_del = ?", hostID, 0).
+		Updates(map[string]any{
+			"location_lat": lat,
+			"location_lng": lng,
+		}).Error
+}
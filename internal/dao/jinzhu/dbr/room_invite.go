@@ -0,0 +1,24 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// RoomInvite 房间邀请，AcceptedOn 不为0表示受邀者已通过邀请进入房间
+type RoomInvite struct {
+	*Model
+	RoomID     int64                `json:"room_id"`
+	InviterID  int64                `json:"inviter_id"`
+	InviteeID  int64                `json:"invitee_id"`
+	Source     cs.RoomInviteSourceT `json:"source"`
+	ExpiredOn  int64                `json:"expired_on"`
+	AcceptedOn int64                `json:"accepted_on"`
+}
+
+func (RoomInvite) TableName() string {
+	return "p_room_invites"
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// SearchOutbox 搜索索引变更记录，Payload 为 upsert 操作携带的文档json，
+// Version 为文档版本，同一文档已写入更新版本后旧记录不再写入
+type SearchOutbox struct {
+	*Model
+	IndexName   cs.SearchIndex `json:"index_name"`
+	DocID       int64          `json:"doc_id"`
+	Op          cs.SearchOp    `json:"op"`
+	Payload     string         `json:"payload"`
+	Version     int64          `json:"version"`
+	Attempts    int            `json:"attempts"`
+	NextRetryOn int64          `json:"next_retry_on"`
+	LastError   string         `json:"last_error"`
+	Status      int8           `json:"status"`
+}
+
+func (SearchOutbox) TableName() string {
+	return "p_search_outbox"
+}
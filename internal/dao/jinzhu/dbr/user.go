@@ -29,11 +29,16 @@ type User struct {
 	Phone      string        `json:"phone"`
 	Password   string        `json:"password"`
 	Salt       string        `json:"salt"`
+	SessionSalt string       `json:"-"`
 	Status     int           `json:"status"`
 	Avatar     string        `json:"avatar"`
 	Balance    int64         `json:"balance"`
 	IsAdmin    bool          `json:"is_admin"`
 	Categories Int64Array    `json:"categories" gorm:"type:integer[];default:'{}'"`
+	DmPolicy   cs.DmPolicyT  `json:"dm_policy"`
+	IsPrivate  bool          `json:"is_private"`
+	// 被禁止主持或进入房间的时间，未禁止时为0
+	RoomBannedOn int64       `json:"room_banned_on"`
 }
 
 
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"gorm.io/gorm"
 )
 
@@ -448,7 +449,7 @@ func (u *UserReaction) GetGlobalReactionTimeline(db *gorm.DB, limit, offset int)
 		JOIN p_user target ON ur.target_user_id = target.id
 		JOIN p_reactions r ON ur.reaction_type_id = r.id
 		WHERE ur.is_del = 0
-		ORDER BY ur.created_on DESC
+		ORDER BY ur.created_on DESC, ur.id DESC
 		LIMIT ? OFFSET ?
 	`
 	
@@ -506,7 +507,7 @@ func (u *UserReaction) GetUserReactionTimeline(db *gorm.DB, userID int64, limit,
 		JOIN p_user target ON ur.target_user_id = target.id
 		JOIN p_reactions r ON ur.reaction_type_id = r.id
 		WHERE ur.reactor_user_id = ? AND ur.is_del = 0
-		ORDER BY ur.created_on DESC
+		ORDER BY ur.created_on DESC, ur.id DESC
 		LIMIT ? OFFSET ?
 	`
 	
@@ -516,4 +517,48 @@ func (u *UserReaction) GetUserReactionTimeline(db *gorm.DB, userID int64, limit,
 	}
 	
 	return reactions, total, nil
-} 
\ No newline at end of file
+} 
+// ReactionTimelineByCursor gets reactions in reverse chronological order keyed on (created_on, id),
+// reactorUserID 0 means reactions given by all users
+func (u *UserReaction) ReactionTimelineByCursor(db *gorm.DB, reactorUserID int64, cursor *cs.PageCursor, limit int) ([]*ReactionWithBothUsersData, error) {
+	var reactions []*ReactionWithBothUsersData
+	conditions, args := "ur.is_del = 0", []any{}
+	if reactorUserID > 0 {
+		conditions += " AND ur.reactor_user_id = ?"
+		args = append(args, reactorUserID)
+	}
+	if cursor != nil {
+		conditions += " AND (ur.created_on, ur.id) < (?, ?)"
+		args = append(args, cursor.Value, cursor.ID)
+	}
+	reactionsQuery := `
+		SELECT 
+			ur.id as reaction_id,
+			ur.target_user_id,
+			ur.reaction_type_id,
+			ur.created_on,
+			ur.reactor_user_id,
+			reactor.nickname as reactor_nickname,
+			reactor.username as reactor_username,
+			reactor.avatar as reactor_avatar,
+			reactor.is_admin as reactor_is_admin,
+			target.nickname as target_nickname,
+			target.username as target_username,
+			target.avatar as target_avatar,
+			target.is_admin as target_is_admin,
+			r.name as reaction_name,
+			r.icon as reaction_icon
+		FROM p_user_reactions ur
+		JOIN p_user reactor ON ur.reactor_user_id = reactor.id
+		JOIN p_user target ON ur.target_user_id = target.id
+		JOIN p_reactions r ON ur.reaction_type_id = r.id
+		WHERE ` + conditions + `
+		ORDER BY ur.created_on DESC, ur.id DESC
+		LIMIT ?
+	`
+	args = append(args, limit)
+	if err := db.Raw(reactionsQuery, args...).Scan(&reactions).Error; err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
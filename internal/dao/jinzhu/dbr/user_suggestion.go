@@ -0,0 +1,35 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package dbr
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// UserSuggestion 预计算的"可能认识的人"推荐
+type UserSuggestion struct {
+	*Model
+	UserID      int64   `json:"user_id"`
+	SuggestedID int64   `json:"suggested_id"`
+	Score       float64 `json:"score"`
+	Reason      string  `json:"reason"`
+	Explanation string  `json:"explanation"`
+}
+
+// UserSuggestionFeedback 用户对推荐的忽略/隐藏反馈
+type UserSuggestionFeedback struct {
+	*Model
+	UserID      int64               `json:"user_id"`
+	SuggestedID int64               `json:"suggested_id"`
+	Action      cs.SuggestFeedbackT `json:"action"`
+}
+
+func (UserSuggestion) TableName() string {
+	return "p_user_suggestions"
+}
+
+func (UserSuggestionFeedback) TableName() string {
+	return "p_user_suggestion_feedback"
+}
@@ -0,0 +1,293 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ core.DirectMessageService = (*directMessageSrv)(nil)
+	_ core.UserBlockService     = (*userBlockSrv)(nil)
+)
+
+type directMessageSrv struct {
+	db *gorm.DB
+}
+
+type userBlockSrv struct {
+	db *gorm.DB
+}
+
+type dmUnreadCount struct {
+	ConversationID int64
+	Total          int64
+}
+
+func newDirectMessageService(db *gorm.DB) core.DirectMessageService {
+	return &directMessageSrv{
+		db: db,
+	}
+}
+
+func newUserBlockService(db *gorm.DB) core.UserBlockService {
+	return &userBlockSrv{
+		db: db,
+	}
+}
+
+// GetOrCreateDmConversation 返回用户在与对方会话中的参与者记录，会话不存在时创建，
+// 并发创建时唯一索引冲突的一方重新读取已创建的会话
+func (s *directMessageSrv) GetOrCreateDmConversation(userId int64, peerId int64) (*ms.DmParticipant, error) {
+	if p, err := s.GetDmParticipantByPeer(userId, peerId); err == nil {
+		return p, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	var res *dbr.DmParticipant
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		conversation := &dbr.DmConversation{Model: &dbr.Model{}}
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		res = &dbr.DmParticipant{
+			Model:          &dbr.Model{},
+			ConversationID: conversation.ID,
+			UserID:         userId,
+			PeerID:         peerId,
+		}
+		return tx.Create([]*dbr.DmParticipant{res, {
+			Model:          &dbr.Model{},
+			ConversationID: conversation.ID,
+			UserID:         peerId,
+			PeerID:         userId,
+		}}).Error
+	})
+	if err != nil {
+		return s.GetDmParticipantByPeer(userId, peerId)
+	}
+	return res, nil
+}
+
+func (s *directMessageSrv) GetDmParticipant(conversationId int64, userId int64) (*ms.DmParticipant, error) {
+	var p dbr.DmParticipant
+	if err := s.db.Where("conversation_id = ? AND user_id = ? AND is_del = 0", conversationId, userId).
+		First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (s *directMessageSrv) GetDmParticipantByPeer(userId int64, peerId int64) (*ms.DmParticipant, error) {
+	var p dbr.DmParticipant
+	if err := s.db.Where("user_id = ? AND peer_id = ? AND is_del = 0", userId, peerId).
+		First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListDmConversations 用户的会话列表，按最新消息时间倒序，没有消息的会话不列出
+func (s *directMessageSrv) ListDmConversations(userId int64, limit int, offset int) (res []*ms.DmConversationFormated, total int64, err error) {
+	query := func() *gorm.DB {
+		return s.db.Table("p_dm_participants p").
+			Joins("JOIN p_dm_conversations c ON c.id = p.conversation_id AND c.is_del = 0").
+			Where("p.user_id = ? AND p.is_del = 0 AND c.last_message_id > 0", userId)
+	}
+	if err = query().Count(&total).Error; err != nil || total == 0 {
+		return
+	}
+	var items []*struct {
+		ConversationID int64
+		PeerID         int64
+		LastReadID     int64
+		LastMessageID  int64
+		LastMessageOn  int64
+	}
+	if err = query().Select("p.conversation_id, p.peer_id, p.last_read_id, c.last_message_id, c.last_message_on").
+		Order("c.last_message_on DESC, c.id DESC").Limit(limit).Offset(offset).
+		Scan(&items).Error; err != nil {
+		return
+	}
+	ids := make([]int64, 0, len(items))
+	peerIds := make([]int64, 0, len(items))
+	msgIds := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ConversationID)
+		peerIds = append(peerIds, item.PeerID)
+		msgIds = append(msgIds, item.LastMessageID)
+	}
+	var (
+		peers    []*dbr.User
+		peerRead []*dbr.DmParticipant
+		messages []*dbr.DmMessage
+		unread   []*dmUnreadCount
+	)
+	if err = s.db.Where("id IN ? AND is_del = 0", peerIds).Find(&peers).Error; err != nil {
+		return
+	}
+	if err = s.db.Where("conversation_id IN ? AND user_id <> ? AND is_del = 0", ids, userId).Find(&peerRead).Error; err != nil {
+		return
+	}
+	if err = s.db.Where("id IN ?", msgIds).Find(&messages).Error; err != nil {
+		return
+	}
+	if unread, err = s.unreadCounts(userId, ids); err != nil {
+		return
+	}
+	peerMap := make(map[int64]*dbr.UserFormated, len(peers))
+	for _, peer := range peers {
+		peerMap[peer.ID] = peer.Format()
+	}
+	readMap := make(map[int64]int64, len(peerRead))
+	for _, p := range peerRead {
+		readMap[p.ConversationID] = p.LastReadID
+	}
+	msgMap := make(map[int64]*dbr.DmMessage, len(messages))
+	for _, msg := range messages {
+		msgMap[msg.ID] = msg
+	}
+	unreadMap := make(map[int64]int64, len(unread))
+	for _, u := range unread {
+		unreadMap[u.ConversationID] = u.Total
+	}
+	res = make([]*ms.DmConversationFormated, 0, len(items))
+	for _, item := range items {
+		res = append(res, &ms.DmConversationFormated{
+			ID:            item.ConversationID,
+			Peer:          peerMap[item.PeerID],
+			LastMessage:   msgMap[item.LastMessageID],
+			LastMessageOn: item.LastMessageOn,
+			LastReadID:    item.LastReadID,
+			PeerReadID:    readMap[item.ConversationID],
+			UnreadCount:   unreadMap[item.ConversationID],
+		})
+	}
+	return
+}
+
+// CreateDmMessage 保存消息并更新会话的最新消息，发送者的已读游标同时前移到这条消息
+func (s *directMessageSrv) CreateDmMessage(msg *ms.DmMessage) (*ms.DmMessage, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(msg).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&dbr.DmConversation{}).Where("id = ? AND is_del = 0", msg.ConversationID).
+			Updates(map[string]any{
+				"last_message_id": msg.ID,
+				"last_message_on": msg.CreatedOn,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&dbr.DmParticipant{}).
+			Where("conversation_id = ? AND user_id = ? AND is_del = 0", msg.ConversationID, msg.SenderID).
+			Update("last_read_id", msg.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ListDmMessages 会话消息按id倒序游标分页，cursor为nil时从最新消息开始
+func (s *directMessageSrv) ListDmMessages(conversationId int64, cursor *cs.PageCursor, limit int) ([]*ms.DmMessage, *cs.PageCursor, error) {
+	db := s.db.Where("conversation_id = ? AND is_del = 0", conversationId)
+	if cursor != nil {
+		db = db.Where("id < ?", cursor.ID)
+	}
+	var messages []*dbr.DmMessage
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		return nil, nil, err
+	}
+	var next *cs.PageCursor
+	if len(messages) > limit {
+		messages = messages[:limit]
+		last := messages[limit-1]
+		next = &cs.PageCursor{Value: last.CreatedOn, ID: last.ID}
+	}
+	return messages, next, nil
+}
+
+// ReadDmConversation 已读游标只前移且不超过会话的最新消息，messageId为0时标记到最新消息
+func (s *directMessageSrv) ReadDmConversation(conversationId int64, userId int64, messageId int64) error {
+	var conversation dbr.DmConversation
+	if err := s.db.Where("id = ? AND is_del = 0", conversationId).First(&conversation).Error; err != nil {
+		return err
+	}
+	if messageId <= 0 || messageId > conversation.LastMessageID {
+		messageId = conversation.LastMessageID
+	}
+	return s.db.Model(&dbr.DmParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_id < ? AND is_del = 0", conversationId, userId, messageId).
+		Update("last_read_id", messageId).Error
+}
+
+func (s *directMessageSrv) CountDmUnread(userId int64) (total int64, err error) {
+	err = s.unreadQuery(userId).Count(&total).Error
+	return
+}
+
+func (s *directMessageSrv) unreadCounts(userId int64, conversationIds []int64) (res []*dmUnreadCount, err error) {
+	err = s.unreadQuery(userId).Where("m.conversation_id IN ?", conversationIds).
+		Select("m.conversation_id, count(*) AS total").Group("m.conversation_id").Scan(&res).Error
+	return
+}
+
+// unreadQuery 对方发送的、位于已读游标之后的消息
+func (s *directMessageSrv) unreadQuery(userId int64) *gorm.DB {
+	return s.db.Table("p_dm_messages m").
+		Joins("JOIN p_dm_participants p ON p.conversation_id = m.conversation_id AND p.user_id = ? AND p.is_del = 0", userId).
+		Where("m.id > p.last_read_id AND m.sender_id <> ? AND m.is_del = 0", userId)
+}
+
+func (s *userBlockSrv) BlockUser(userId int64, blockedId int64) error {
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbr.UserBlock{
+		Model:     &dbr.Model{},
+		UserID:    userId,
+		BlockedID: blockedId,
+	}).Error
+}
+
+func (s *userBlockSrv) UnblockUser(userId int64, blockedId int64) error {
+	return s.db.Where("user_id = ? AND blocked_id = ?", userId, blockedId).Delete(&dbr.UserBlock{}).Error
+}
+
+func (s *userBlockSrv) IsBlockedEither(userId int64, otherId int64) bool {
+	var count int64
+	s.db.Model(&dbr.UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", userId, otherId, otherId, userId).
+		Count(&count)
+	return count > 0
+}
+
+func (s *userBlockSrv) ListBlockedUsers(userId int64, limit int, offset int) (res []*ms.User, total int64, err error) {
+	if err = s.db.Model(&dbr.UserBlock{}).Where("user_id = ?", userId).Count(&total).Error; err != nil || total == 0 {
+		return
+	}
+	var ids []int64
+	if err = s.db.Model(&dbr.UserBlock{}).Where("user_id = ?", userId).Order("id DESC").Limit(limit).Offset(offset).Pluck("blocked_id", &ids).Error; err != nil {
+		return
+	}
+	var users []*dbr.User
+	if err = s.db.Where("id IN ? AND is_del = 0", ids).Find(&users).Error; err != nil {
+		return
+	}
+	userMap := make(map[int64]*dbr.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	res = make([]*ms.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := userMap[id]; ok {
+			res = append(res, user)
+		}
+	}
+	return
+}
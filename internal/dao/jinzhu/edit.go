@@ -0,0 +1,196 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.EditService = (*editSrv)(nil)
+
+	// 可编辑的文本内容，媒体内容编辑时保持不变
+	_editableContentTypes = []dbr.PostContentT{dbr.ContentTypeTitle, dbr.ContentTypeText}
+)
+
+type editSrv struct {
+	cacheIndex core.CacheIndexService
+	db         *gorm.DB
+}
+
+func newEditService(db *gorm.DB, cacheIndex core.CacheIndexService) core.EditService {
+	return &editSrv{
+		cacheIndex: cacheIndex,
+		db:         db,
+	}
+}
+
+// EditPost 保存编辑前的文本快照后替换动态文本内容与标签，返回引用数有变化的标签，
+// 评论复制到动态内容中的文本不属于动态本身，不参与快照与替换
+func (s *editSrv) EditPost(post *ms.Post, contents []*ms.PostContent, tags []string) (res cs.TagInfoList, err error) {
+	var oldTags []string
+	if post.Tags != "" {
+		oldTags = strings.Split(post.Tags, ",")
+	}
+	addedTags, removedTags := tagsExcept(tags, oldTags), tagsExcept(oldTags, tags)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var olds []*dbr.PostContent
+		if err := tx.Where("post_id = ? AND comment_id = 0 AND type IN ? AND is_del = 0", post.ID, _editableContentTypes).
+			Order("sort ASC").Find(&olds).Error; err != nil {
+			return err
+		}
+		revision := &dbr.EditRevision{
+			Model:      &dbr.Model{},
+			TargetType: cs.EditTargetPost,
+			TargetID:   post.ID,
+			UserID:     post.GetHostID(),
+			Tags:       post.Tags,
+			Contents:   make([]*dbr.RevisionContent, 0, len(olds)),
+		}
+		for _, c := range olds {
+			revision.Contents = append(revision.Contents, &dbr.RevisionContent{Content: c.Content, Type: c.Type, Sort: c.Sort})
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&dbr.PostContent{}).
+			Where("post_id = ? AND comment_id = 0 AND type IN ? AND is_del = 0", post.ID, _editableContentTypes).
+			Updates(map[string]any{
+				"deleted_on": time.Now().Unix(),
+				"is_del":     1,
+			}).Error; err != nil {
+			return err
+		}
+		for _, content := range contents {
+			content.PostID, content.UserID = post.ID, post.UserID
+			if _, err := content.Create(tx); err != nil {
+				return err
+			}
+		}
+		// 私密动态不计入标签引用数
+		if post.Visibility != dbr.PostVisitPrivate {
+			added, err := createTags(tx, post.GetHostID(), addedTags)
+			if err != nil {
+				return err
+			}
+			if err = deleteTags(tx, removedTags); err != nil {
+				return err
+			}
+			removed, err := (&dbr.Tag{}).TagsFrom(tx, removedTags)
+			if err != nil {
+				return err
+			}
+			res = added
+			for _, tag := range removed {
+				res = append(res, &cs.TagInfo{
+					ID:       tag.ID,
+					UserID:   tag.UserID,
+					Tag:      tag.Tag,
+					QuoteNum: tag.QuoteNum,
+				})
+			}
+		}
+		post.Tags = strings.Join(tags, ",")
+		post.EditedOn = time.Now().Unix()
+		return post.Update(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cacheIndex.SendAction(core.IdxActUpdatePost, post)
+	return
+}
+
+// EditComment 保存编辑前的文本快照后替换评论文本内容，并同步复制到动态内容中的评论文本
+func (s *editSrv) EditComment(comment *ms.Comment, contents []*ms.CommentContent) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		post := &dbr.Post{}
+		if err := tx.Where("id = ?", comment.PostID).First(post).Error; err != nil {
+			return err
+		}
+		var olds []*dbr.CommentContent
+		if err := tx.Where("comment_id = ? AND type IN ? AND is_del = 0", comment.ID, _editableContentTypes).
+			Order("sort ASC").Find(&olds).Error; err != nil {
+			return err
+		}
+		revision := &dbr.EditRevision{
+			Model:      &dbr.Model{},
+			TargetType: cs.EditTargetComment,
+			TargetID:   comment.ID,
+			UserID:     comment.UserID,
+			Contents:   make([]*dbr.RevisionContent, 0, len(olds)),
+		}
+		for _, c := range olds {
+			revision.Contents = append(revision.Contents, &dbr.RevisionContent{Content: c.Content, Type: c.Type, Sort: c.Sort})
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		deleted := map[string]any{
+			"deleted_on": time.Now().Unix(),
+			"is_del":     1,
+		}
+		if err := tx.Model(&dbr.CommentContent{}).
+			Where("comment_id = ? AND type IN ? AND is_del = 0", comment.ID, _editableContentTypes).
+			Updates(deleted).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&dbr.PostContent{}).
+			Where("post_id = ? AND comment_id = ? AND type IN ? AND is_del = 0", comment.PostID, comment.ID, _editableContentTypes).
+			Updates(deleted).Error; err != nil {
+			return err
+		}
+		for _, content := range contents {
+			content.CommentID, content.UserID = comment.ID, comment.UserID
+			if _, err := content.Create(tx); err != nil {
+				return err
+			}
+			copied := &dbr.PostContent{
+				PostID:    comment.PostID,
+				CommentID: comment.ID,
+				UserID:    []int64{post.GetHostID(), post.GetVisitorID()},
+				Content:   content.Content,
+				Type:      content.Type,
+				Sort:      content.Sort,
+			}
+			if _, err := copied.Create(tx); err != nil {
+				return err
+			}
+		}
+		comment.EditedOn = time.Now().Unix()
+		return tx.Model(&dbr.Comment{}).Where("id = ? AND is_del = 0", comment.ID).
+			Update("edited_on", comment.EditedOn).Error
+	})
+}
+
+// ListEditRevisions 编辑历史，最近的编辑在前
+func (s *editSrv) ListEditRevisions(target cs.EditTargetT, targetId int64) (res []*ms.EditRevision, err error) {
+	err = s.db.Where("target_type = ? AND target_id = ?", target, targetId).
+		Order("id DESC").Find(&res).Error
+	return
+}
+
+// tagsExcept 在tags中但不在others中的标签
+func tagsExcept(tags []string, others []string) []string {
+	exists := make(map[string]struct{}, len(others))
+	for _, tag := range others {
+		exists[tag] = struct{}{}
+	}
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := exists[tag]; !ok {
+			exists[tag] = struct{}{}
+			res = append(res, tag)
+		}
+	}
+	return res
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.FeedRankService = (*feedRankSrv)(nil)
+)
+
+type feedRankSrv struct {
+	db *gorm.DB
+}
+
+type feedPost struct {
+	ID        int64
+	AuthorID  int64
+	VisitorID int64
+	Tags      string
+	CreatedOn int64
+	RankScore int64
+}
+
+func newFeedRankService(db *gorm.DB) core.FeedRankService {
+	return &feedRankSrv{
+		db: db,
+	}
+}
+
+// ListFeedCandidates 获取一段时间内的公开动态作为候选集，附带互动指标、音频时长及作者分类
+func (s *feedRankSrv) ListFeedCandidates(since int64, limit int) ([]*cs.FeedCandidate, error) {
+	var posts []*feedPost
+	err := s.db.Table(_post_+" AS p").
+		Select("p.id, CAST(p.user_id->0 AS bigint) AS author_id, CAST(p.user_id->1 AS bigint) AS visitor_id, p.tags, p.created_on, COALESCE(m.rank_score, 0) AS rank_score").
+		Joins(fmt.Sprintf("LEFT JOIN %s m ON m.post_id = p.id AND m.is_del = 0", _post_metric_)).
+		Where("p.visibility >= ? AND p.is_del = 0 AND p.status = ? AND p.created_on >= ?", cs.TweetVisitPublic, dbr.PostStatusPublished, since).
+		Where(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE post_id = p.id AND content != '')", _postContent_)).
+		Order("p.created_on DESC").Limit(limit).Scan(&posts).Error
+	if err != nil || len(posts) == 0 {
+		return nil, err
+	}
+	postIds := make([]int64, 0, len(posts))
+	authorIds := make([]int64, 0, len(posts)*2)
+	for _, p := range posts {
+		postIds = append(postIds, p.ID)
+		authorIds = append(authorIds, p.AuthorID)
+		if p.VisitorID > 0 {
+			authorIds = append(authorIds, p.VisitorID)
+		}
+	}
+	durations, err := s.audioDurations(postIds)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.authorCategories(authorIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*cs.FeedCandidate, 0, len(posts))
+	for _, p := range posts {
+		c := &cs.FeedCandidate{
+			ID:               p.ID,
+			AuthorID:         p.AuthorID,
+			VisitorID:        p.VisitorID,
+			AuthorCategories: participantCategories(categories, p.AuthorID, p.VisitorID),
+			Engagement:       p.RankScore,
+			AudioDuration:    durations[p.ID],
+			CreatedOn:        p.CreatedOn,
+		}
+		for _, tag := range strings.Split(p.Tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				c.Tags = append(c.Tags, tag)
+			}
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// GetFeedViewer 获取用户的分类偏好、关注的话题、关注的用户及互动过的用户
+func (s *feedRankSrv) GetFeedViewer(userId int64) (*cs.FeedViewer, error) {
+	v := &cs.FeedViewer{
+		UserID:     userId,
+		Categories: make(map[int64]struct{}),
+		Tags:       make(map[string]struct{}),
+		Followings: make(map[int64]struct{}),
+		Reacted:    make(map[int64]struct{}),
+	}
+	user := &dbr.User{}
+	if err := s.db.Select("id, categories").Where("id = ?", userId).First(user).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range user.Categories {
+		v.Categories[id] = struct{}{}
+	}
+	var tags []string
+	err := s.db.Table(_tag_+" AS t").
+		Joins(fmt.Sprintf("JOIN %s tu ON tu.topic_id = t.id AND tu.is_del = 0", s.db.NamingStrategy.TableName("TopicUser"))).
+		Where("tu.user_id = ? AND t.is_del = 0", userId).Pluck("t.tag", &tags).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		v.Tags[tag] = struct{}{}
+	}
+	var followIds []int64
+	if err = s.db.Table(_following_).Where("user_id = ? AND status = 2 AND is_del = 0", userId).Pluck("follow_id", &followIds).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range followIds {
+		v.Followings[id] = struct{}{}
+	}
+	var reactedIds []int64
+	if err = s.db.Model(&dbr.UserReaction{}).Where("reactor_user_id = ? AND is_del = 0", userId).Distinct().Pluck("target_user_id", &reactedIds).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range reactedIds {
+		v.Reacted[id] = struct{}{}
+	}
+	return v, nil
+}
+
+func (s *feedRankSrv) audioDurations(postIds []int64) (map[int64]float64, error) {
+	var contents []*dbr.PostContent
+	err := s.db.Select("post_id, duration").
+		Where("post_id IN ? AND type = ? AND is_del = 0", postIds, dbr.ContentTypeAudio).
+		Find(&contents).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]float64, len(contents))
+	for _, c := range contents {
+		// 时长以秒为单位的字符串存储，解析失败的忽略
+		if d, err := strconv.ParseFloat(c.Duration, 64); err == nil && d > res[c.PostID] {
+			res[c.PostID] = d
+		}
+	}
+	return res, nil
+}
+
+// participantCategories 对话动态的作者与访客的分类合并去重
+func participantCategories(categories map[int64][]int64, authorId, visitorId int64) []int64 {
+	if visitorId <= 0 || visitorId == authorId {
+		return categories[authorId]
+	}
+	res := slices.Clone(categories[authorId])
+	for _, id := range categories[visitorId] {
+		if !slices.Contains(res, id) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+func (s *feedRankSrv) authorCategories(userIds []int64) (map[int64][]int64, error) {
+	var users []*dbr.User
+	if err := s.db.Select("id, categories").Where("id IN ?", userIds).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[int64][]int64, len(users))
+	for _, u := range users {
+		res[u.ID] = []int64(u.Categories)
+	}
+	return res, nil
+}
@@ -5,7 +5,11 @@
 package jinzhu
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 	"github.com/sirupsen/logrus"
@@ -30,66 +34,202 @@ func newFollowingManageService(db *gorm.DB) core.FollowingManageService {
 	}
 }
 
+// FollowUser 直接关注，已有待通过的关注请求时转为已关注
 func (s *followingManageSrv) FollowUser(userId int64, followId int64) error {
-	if _, err := s.f.GetFollowing(s.db, userId, followId); err != nil {
+	f, err := s.f.GetFollowing(s.db, userId, followId)
+	if err != nil {
 		following := &dbr.Following{
-			UserId:   userId,
-			FollowId: followId,
+			UserId:       userId,
+			FollowId:     followId,
+			Status:       cs.FollowStatusAgree,
+			InteractedOn: time.Now().Unix(),
 		}
 		if _, err = following.Create(s.db); err != nil {
 			logrus.Errorf("contactManageSrv.fetchOrNewContact create new contact err:%s", err)
 			return err
 		}
+	} else if f.Status != cs.FollowStatusAgree {
+		return s.db.Model(f).Omit("User").Updates(map[string]any{
+			"status":        cs.FollowStatusAgree,
+			"interacted_on": time.Now().Unix(),
+		}).Error
 	}
 	return nil
 }
 
-func (s *followingManageSrv) UnfollowUser(userId int64, followId int64) error {
-	return s.f.DelFollowing(s.db, userId, followId)
+// RequestFollow 关注私密账户，创建待对方通过的关注请求，已关注或已请求时不做处理
+func (s *followingManageSrv) RequestFollow(userId int64, followId int64) error {
+	if _, err := s.f.GetFollowing(s.db, userId, followId); err == nil {
+		return nil
+	}
+	following := &dbr.Following{
+		UserId:       userId,
+		FollowId:     followId,
+		Status:       cs.FollowStatusRequesting,
+		InteractedOn: time.Now().Unix(),
+	}
+	_, err := following.Create(s.db)
+	return err
 }
 
-func (s *followingManageSrv) ListFollows(userId int64, limit, offset int) (*ms.ContactList, error) {
-	follows, totoal, err := s.f.ListFollows(s.db, userId, limit, offset)
+// ListFollowRequests 待用户通过的关注请求，最新的在前
+func (s *followingManageSrv) ListFollowRequests(userId int64, limit, offset int) (*ms.ContactList, error) {
+	db := s.db.Model(&dbr.Following{}).Where("follow_id = ? AND status = ?", userId, cs.FollowStatusRequesting)
+	res := &ms.ContactList{}
+	if err := db.Count(&res.Total).Error; err != nil || res.Total == 0 {
+		return res, err
+	}
+	var requesterIds []int64
+	if err := db.Order("id DESC").Limit(limit).Offset(offset).Pluck("user_id", &requesterIds).Error; err != nil {
+		return nil, err
+	}
+	requesters, err := s.u.ListUserInfoById(s.db, requesterIds)
 	if err != nil {
 		return nil, err
 	}
-	res := &ms.ContactList{
-		Total: totoal,
+	userMap := make(map[int64]*cs.UserInfo, len(requesters))
+	for _, user := range requesters {
+		userMap[user.ID] = user
 	}
-	for _, f := range follows {
-		res.Contacts = append(res.Contacts, ms.ContactItem{
-			UserId:    f.User.ID,
-			Username:  f.User.Username,
-			Nickname:  f.User.Nickname,
-			Avatar:    f.User.Avatar,
-			CreatedOn: f.User.CreatedOn,
-		})
+	for _, id := range requesterIds {
+		if user, ok := userMap[id]; ok {
+			res.Contacts = append(res.Contacts, ms.ContactItem{
+				UserId:    user.ID,
+				Username:  user.Username,
+				Nickname:  user.Nickname,
+				Avatar:    user.Avatar,
+				CreatedOn: user.CreatedOn,
+			})
+		}
 	}
 	return res, nil
 }
 
-func (s *followingManageSrv) ListFollowings(userId int64, limit, offset int) (*ms.ContactList, error) {
-	followingIds, totoal, err := s.f.ListFollowingIds(s.db, userId, limit, offset)
-	if err != nil {
-		return nil, err
+// ApproveFollowRequest 通过关注请求，没有待通过的请求时返回 gorm.ErrRecordNotFound
+func (s *followingManageSrv) ApproveFollowRequest(userId int64, followerId int64) error {
+	db := s.db.Model(&dbr.Following{}).
+		Where("user_id = ? AND follow_id = ? AND status = ?", followerId, userId, cs.FollowStatusRequesting).
+		Updates(map[string]any{
+			"status":        cs.FollowStatusAgree,
+			"interacted_on": time.Now().Unix(),
+		})
+	if db.Error != nil {
+		return db.Error
 	}
-	followings, err := s.u.ListUserInfoById(s.db, followingIds)
-	if err != nil {
-		return nil, err
+	if db.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeclineFollowRequest 拒绝关注请求，没有待通过的请求时返回 gorm.ErrRecordNotFound
+func (s *followingManageSrv) DeclineFollowRequest(userId int64, followerId int64) error {
+	db := s.db.Unscoped().Where("user_id = ? AND follow_id = ? AND status = ?", followerId, userId, cs.FollowStatusRequesting).
+		Delete(&dbr.Following{})
+	if db.Error != nil {
+		return db.Error
 	}
-	res := &ms.ContactList{
-		Total: totoal,
+	if db.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
-	for _, user := range followings {
+	return nil
+}
+
+// ApproveAllFollowRequests 通过所有待通过的关注请求，返回请求者id
+func (s *followingManageSrv) ApproveAllFollowRequests(userId int64) (ids []int64, err error) {
+	db := s.db.Model(&dbr.Following{}).Where("follow_id = ? AND status = ?", userId, cs.FollowStatusRequesting)
+	if err = db.Pluck("user_id", &ids).Error; err != nil || len(ids) == 0 {
+		return
+	}
+	err = s.db.Model(&dbr.Following{}).Where("follow_id = ? AND user_id IN ? AND status = ?", userId, ids, cs.FollowStatusRequesting).
+		Updates(map[string]any{
+			"status":        cs.FollowStatusAgree,
+			"interacted_on": time.Now().Unix(),
+		}).Error
+	return
+}
+
+func (s *followingManageSrv) UnfollowUser(userId int64, followId int64) error {
+	return s.f.DelFollowing(s.db, userId, followId)
+}
+
+// ListFollows 用户关注的人，按最近互动倒序，keyword 按用户名或昵称过滤
+func (s *followingManageSrv) ListFollows(userId int64, keyword string, cursor *cs.PageCursor, limit, offset int) (*ms.ContactList, *cs.PageCursor, error) {
+	return s.listFollowEdges("user_id", "follow_id", userId, keyword, cursor, limit, offset)
+}
+
+// ListFollowings 用户的粉丝，按最近互动倒序，keyword 按用户名或昵称过滤
+func (s *followingManageSrv) ListFollowings(userId int64, keyword string, cursor *cs.PageCursor, limit, offset int) (*ms.ContactList, *cs.PageCursor, error) {
+	return s.listFollowEdges("follow_id", "user_id", userId, keyword, cursor, limit, offset)
+}
+
+// listFollowEdges 列出 column 为 userId 的已通过关注关系及 peerColumn 对应的用户，按 (interacted_on, id) 倒序，
+// cursor 不为nil时为游标分页且不统计总数，偏移分页时同样返回下一页游标
+func (s *followingManageSrv) listFollowEdges(column, peerColumn string, userId int64, keyword string, cursor *cs.PageCursor, limit, offset int) (*ms.ContactList, *cs.PageCursor, error) {
+	db := s.db.Table(_following_+" AS f").
+		Joins(fmt.Sprintf("JOIN %s u ON u.id = f.%s AND u.is_del = 0", _user_, peerColumn)).
+		Where(fmt.Sprintf("f.%s = ? AND f.status = ? AND f.is_del = 0", column), userId, cs.FollowStatusAgree)
+	if keyword != "" {
+		pattern := containsPattern(keyword)
+		db = db.Where("(u.username ILIKE ? OR u.nickname ILIKE ?)", pattern, pattern)
+	}
+	res := &ms.ContactList{}
+	if cursor != nil {
+		db = db.Where("(f.interacted_on, f.id) < (?, ?)", cursor.Value, cursor.ID)
+		offset = 0
+	} else if err := db.Count(&res.Total).Error; err != nil || res.Total == 0 {
+		return res, nil, err
+	}
+	var edges []*struct {
+		ID           int64
+		InteractedOn int64
+		UserID       int64
+		Username     string
+		Nickname     string
+		Avatar       string
+		CreatedOn    int64
+	}
+	if err := db.Select("f.id, f.interacted_on, u.id AS user_id, u.username, u.nickname, u.avatar, u.created_on").
+		Order("f.interacted_on DESC, f.id DESC").Limit(limit + 1).Offset(offset).
+		Scan(&edges).Error; err != nil {
+		return nil, nil, err
+	}
+	var next *cs.PageCursor
+	if len(edges) > limit {
+		edges = edges[:limit]
+		last := edges[limit-1]
+		next = &cs.PageCursor{Value: last.InteractedOn, ID: last.ID}
+	}
+	for _, e := range edges {
 		res.Contacts = append(res.Contacts, ms.ContactItem{
-			UserId:    user.ID,
-			Username:  user.Username,
-			Nickname:  user.Nickname,
-			Avatar:    user.Avatar,
-			CreatedOn: user.CreatedOn,
+			UserId:       e.UserID,
+			Username:     e.Username,
+			Nickname:     e.Nickname,
+			Avatar:       e.Avatar,
+			CreatedOn:    e.CreatedOn,
+			InteractedOn: e.InteractedOn,
 		})
 	}
-	return res, nil
+	return res, next, nil
+}
+
+// RemoveFollower 移除粉丝，不影响自己对对方的关注，没有该粉丝时返回 gorm.ErrRecordNotFound
+func (s *followingManageSrv) RemoveFollower(userId int64, followerId int64) error {
+	db := s.db.Unscoped().Where("user_id = ? AND follow_id = ?", followerId, userId).Delete(&dbr.Following{})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchFollowInteraction 记录两个用户间的最近互动，更新双向的关注关系
+func (s *followingManageSrv) TouchFollowInteraction(userId int64, otherId int64) error {
+	return s.db.Model(&dbr.Following{}).
+		Where("((user_id = ? AND follow_id = ?) OR (user_id = ? AND follow_id = ?)) AND is_del = 0", userId, otherId, otherId, userId).
+		Update("interacted_on", time.Now().Unix()).Error
 }
 
 func (s *followingManageSrv) GetFollowCount(userId int64) (int64, int64, error) {
@@ -97,8 +237,29 @@ func (s *followingManageSrv) GetFollowCount(userId int64) (int64, int64, error)
 }
 
 func (s *followingManageSrv) IsFollow(userId int64, followId int64) bool {
-	if _, err := s.f.GetFollowing(s.db, userId, followId); err == nil {
+	return s.f.IsFollow(s.db, userId, followId)
+}
+
+func (s *followingManageSrv) IsFollowRequested(userId int64, followId int64) bool {
+	if f, err := s.f.GetFollowing(s.db, userId, followId); err == nil && f.Status == cs.FollowStatusRequesting {
 		return true
 	}
 	return false
 }
+
+// SetRoomPing 开启或关闭关注对象开房间时的"叫人来"提醒
+func (s *followingManageSrv) SetRoomPing(userId int64, followId int64, enable bool) error {
+	var ping int8
+	if enable {
+		ping = 1
+	}
+	return s.db.Model(&dbr.Following{}).Where("user_id = ? AND follow_id = ? AND status = 2 AND is_del = 0", userId, followId).
+		Update("room_ping", ping).Error
+}
+
+// ListRoomPingFollowerIds 开启了"叫人来"提醒的关注者，最早开启的在前
+func (s *followingManageSrv) ListRoomPingFollowerIds(followId int64, limit int) (ids []int64, err error) {
+	err = s.db.Model(&dbr.Following{}).Where("follow_id = ? AND room_ping = 1 AND status = 2 AND is_del = 0", followId).
+		Order("id ASC").Limit(limit).Pluck("user_id", &ids).Error
+	return
+}
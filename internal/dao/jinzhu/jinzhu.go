@@ -16,6 +16,7 @@ import (
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/dao/cache"
 	"github.com/rocboss/paopao-ce/internal/dao/security"
+	"github.com/rocboss/paopao-ce/internal/dao/transcription"
 )
 
 var (
@@ -46,10 +47,28 @@ type dataSrv struct {
 	core.DeviceManageService
 	core.FollowingManageService
 	core.UserRelationService
+	core.DirectMessageService
+	core.UserBlockService
 	core.SecurityService
 	core.AttachmentCheckService
+	core.ContentModerationService
 	core.RoomService
+	core.RoomInviteService
+	core.FriendSuggestService
+	core.MentionService
+	core.EditService
 	core.CategoryService
+	core.ReportService
+	core.AccountService
+	core.AdminManageService
+	core.AuthorizationManageService
+	core.RoleManageService
+	core.FeedRankService
+	core.NearbyService
+	core.EntitySearchSyncService
+	core.SearchOutboxService
+	core.TranscriptionService
+	core.TranscriptService
 }
 
 type webDataSrvA struct {
@@ -68,28 +87,47 @@ func NewDataService() (core.DataService, core.VersionInfo) {
 	cms := newCommentMetricServentA(db)
 	cis := cache.NewEventCacheIndexSrv(tms)
 	userManageService := newUserManageService(db, ums)
+	tweetManageService := newTweetManageService(db, cis)
 	ds := &dataSrv{
-		TweetMetricServantA:    tms,
-		CommentMetricServantA:  cms,
-		UserMetricServantA:     ums,
-		WalletService:          newWalletService(db),
-		MessageService:         newMessageService(db),
-		TopicService:           newTopicService(db),
-		TweetService:           newTweetService(db),
-		TweetManageService:     newTweetManageService(db, cis),
-		TweetHelpService:       newTweetHelpService(db),
-		CommentService:         newCommentService(db),
-		CommentManageService:   newCommentManageService(db),
-		TrendsManageServantA:   newTrendsManageServentA(db),
-		UserManageService:      userManageService,
-		ContactManageService:   newContactManageService(db),
-		DeviceManageService:    newDeviceManageService(db),
-		FollowingManageService: newFollowingManageService(db),
-		UserRelationService:    newUserRelationService(db),
-		SecurityService:        newSecurityService(db, pvs),
-		AttachmentCheckService: security.NewAttachmentCheckService(),
-		RoomService:            newRoomService(db, userManageService, newCategoryService(db)),
-		CategoryService:        newCategoryService(db),
+		TweetMetricServantA:        tms,
+		CommentMetricServantA:      cms,
+		UserMetricServantA:         ums,
+		WalletService:              newWalletService(db),
+		MessageService:             newMessageService(db),
+		TopicService:               newTopicService(db),
+		TweetService:               newTweetService(db),
+		TweetManageService:         tweetManageService,
+		TweetHelpService:           newTweetHelpService(db),
+		CommentService:             newCommentService(db),
+		CommentManageService:       newCommentManageService(db),
+		TrendsManageServantA:       newTrendsManageServentA(db),
+		UserManageService:          userManageService,
+		ContactManageService:       newContactManageService(db),
+		DeviceManageService:        newDeviceManageService(db),
+		FollowingManageService:     newFollowingManageService(db),
+		UserRelationService:        newUserRelationService(db),
+		DirectMessageService:       newDirectMessageService(db),
+		UserBlockService:           newUserBlockService(db),
+		SecurityService:            newSecurityService(db, pvs),
+		AttachmentCheckService:     security.NewAttachmentCheckService(),
+		ContentModerationService:   security.NewContentModerationService(),
+		RoomService:                newRoomService(db, userManageService, newCategoryService(db)),
+		RoomInviteService:          newRoomInviteService(db),
+		FriendSuggestService:       newFriendSuggestService(db),
+		MentionService:             newMentionService(db),
+		EditService:                newEditService(db, cis),
+		CategoryService:            newCategoryService(db),
+		ReportService:              newReportService(db),
+		AccountService:             newAccountService(db, cis),
+		AdminManageService:         newAdminManageService(db),
+		AuthorizationManageService: newAuthorizationManageService(db),
+		RoleManageService:          newRoleManageService(db),
+		FeedRankService:            newFeedRankService(db),
+		NearbyService:              newNearbyService(db),
+		EntitySearchSyncService:    newEntitySearchSyncService(db),
+		SearchOutboxService:        newSearchOutboxService(db),
+		TranscriptionService:       transcription.NewTranscriptionService(),
+		TranscriptService:          newTranscriptService(db),
 	}
 	return cache.NewCacheDataService(ds), ds
 }
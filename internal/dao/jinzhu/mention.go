@@ -0,0 +1,97 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.MentionService = (*mentionSrv)(nil)
+)
+
+type mentionSrv struct {
+	db *gorm.DB
+}
+
+func newMentionService(db *gorm.DB) core.MentionService {
+	return &mentionSrv{
+		db: db,
+	}
+}
+
+func (s *mentionSrv) CreateMentions(mentions []*ms.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	return s.db.Create(mentions).Error
+}
+
+// ListMentionedUserIds 内容中已@提及的用户
+func (s *mentionSrv) ListMentionedUserIds(source cs.MentionSourceT, targetId int64) (res []int64, err error) {
+	err = s.db.Model(&dbr.Mention{}).Where("source_type = ? AND "+mentionTargetColumn(source)+" = ? AND is_del = 0", source, targetId).
+		Distinct("mentioned_user_id").Pluck("mentioned_user_id", &res).Error
+	return
+}
+
+// DeleteMentions 内容编辑后移除原内容中的@提及
+func (s *mentionSrv) DeleteMentions(source cs.MentionSourceT, targetId int64) error {
+	return s.db.Model(&dbr.Mention{}).Where("source_type = ? AND "+mentionTargetColumn(source)+" = ? AND is_del = 0", source, targetId).
+		Updates(map[string]any{
+			"deleted_on": time.Now().Unix(),
+			"is_del":     1,
+		}).Error
+}
+
+// ListMentionCandidates 用户名或昵称以关键词开头的用户，已关注或私信过的用户优先并按最近互动排序，
+// 排除作者自己、非正常状态及与作者任一方屏蔽的用户
+func (s *mentionSrv) ListMentionCandidates(userId int64, keyword string, limit int) (res []*cs.MentionCandidate, err error) {
+	pattern := prefixPattern(keyword)
+	talked := s.db.Table(dbr.DmParticipant{}.TableName()+" AS p").
+		Joins("JOIN "+dbr.DmConversation{}.TableName()+" AS c ON c.id = p.conversation_id AND c.last_message_id > 0 AND c.is_del = 0").
+		Where("p.user_id = ? AND p.is_del = 0", userId).
+		Select("p.peer_id, MAX(c.last_message_on) AS last_message_on").Group("p.peer_id")
+	err = s.db.Table(_user_+" AS u").
+		Joins("LEFT JOIN "+_following_+" AS f ON f.user_id = ? AND f.follow_id = u.id AND f.status = ? AND f.is_del = 0", userId, cs.FollowStatusAgree).
+		Joins("LEFT JOIN (?) AS d ON d.peer_id = u.id", talked).
+		Where("u.id <> ? AND u.status = ? AND u.is_del = 0", userId, ms.UserStatusNormal).
+		Where("u.username ILIKE ? OR u.nickname ILIKE ?", pattern, pattern).
+		Where("NOT EXISTS (SELECT 1 FROM "+dbr.UserBlock{}.TableName()+" AS b WHERE b.is_del = 0 AND ((b.user_id = ? AND b.blocked_id = u.id) OR (b.user_id = u.id AND b.blocked_id = ?)))", userId, userId).
+		Select("u.id AS user_id, f.id IS NOT NULL AS is_following, d.peer_id IS NOT NULL AS has_talked, " +
+			"GREATEST(COALESCE(f.interacted_on, 0), COALESCE(d.last_message_on, 0)) AS interacted_on").
+		Order("(f.id IS NOT NULL OR d.peer_id IS NOT NULL) DESC, interacted_on DESC, u.id ASC").
+		Limit(limit).Scan(&res).Error
+	return
+}
+
+// deleteMentionsOf 内容删除时一并移除其中及其下评论、回复中的@提及
+func deleteMentionsOf(db *gorm.DB, source cs.MentionSourceT, targetIds ...int64) error {
+	if len(targetIds) == 0 {
+		return nil
+	}
+	return db.Model(&dbr.Mention{}).Where(mentionTargetColumn(source)+" IN ? AND is_del = 0", targetIds).
+		Updates(map[string]any{
+			"deleted_on": time.Now().Unix(),
+			"is_del":     1,
+		}).Error
+}
+
+// mentionTargetColumn @提及来源对应的内容列
+func mentionTargetColumn(source cs.MentionSourceT) string {
+	switch source {
+	case cs.MentionSourceComment:
+		return "comment_id"
+	case cs.MentionSourceReply:
+		return "reply_id"
+	default:
+		return "post_id"
+	}
+}
@@ -5,11 +5,20 @@
 package jinzhu
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 分组通知保留的最近触发者数
+	_msgGroupActors = 3
 )
 
 var (
@@ -26,12 +35,76 @@ func newMessageService(db *gorm.DB) core.MessageService {
 	}
 }
 
+// CreateMessage 可分组的通知与接收者同组的未读通知合并为一条，记录最近的几位触发者及总人数；
+// 同组的未读通知由唯一索引保证最多一条，并发触发时合并到同一条通知上
 func (s *messageSrv) CreateMessage(msg *ms.Message) (*ms.Message, error) {
-	return msg.Create(s.db)
+	if msg.Category == "" {
+		msg.Category = msg.Type.Category()
+	}
+	if msg.GroupKey = messageGroupKey(msg); msg.GroupKey == "" {
+		return msg.Create(s.db)
+	}
+	if msg.Model == nil {
+		msg.Model = &dbr.Model{}
+	}
+	res := msg
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 已有同组的未读通知时更新为最新的触发内容，通知行锁持有到事务结束
+		msg.ActorIDs, msg.ActorCount, msg.LatestOn = []int64{}, 0, time.Now().Unix()
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "receiver_user_id"}, {Name: "group_key"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_del = 0 AND is_read = 0 AND group_key <> ''"}}},
+			DoUpdates: clause.AssignmentColumns([]string{"sender_user_id", "brief", "content", "post_id", "comment_id", "reply_id",
+				"latest_on", "modified_on"}),
+		}).Create(msg).Error; err != nil {
+			return err
+		}
+		// 每位触发者只记录一次，首次触发时才计入总人数
+		actor := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbr.MessageGroupActor{
+			Model:     &dbr.Model{},
+			MessageID: msg.ID,
+			ActorID:   msg.SenderUserID,
+		})
+		if actor.Error != nil {
+			return actor.Error
+		}
+		var group dbr.Message
+		if err := tx.Where("id = ?", msg.ID).First(&group).Error; err != nil {
+			return err
+		}
+		actorIds := []int64{msg.SenderUserID}
+		for _, id := range group.ActorIDs {
+			if id != msg.SenderUserID && len(actorIds) < _msgGroupActors {
+				actorIds = append(actorIds, id)
+			}
+		}
+		group.ActorIDs, group.ActorCount = actorIds, group.ActorCount+int(actor.RowsAffected)
+		res = &group
+		return tx.Model(&group).Select("actor_ids", "actor_count").Updates(&group).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
-func (s *messageSrv) GetUnreadCount(userID int64) (int64, error) {
-	return (&dbr.Message{}).CountUnread(s.db, userID)
+func (s *messageSrv) GetUnreadCount(userID int64) (*cs.MessageUnreadCount, error) {
+	var items []*struct {
+		Category cs.MessageCategory
+		Total    int64
+	}
+	if err := s.db.Table(_message_).Where("receiver_user_id = ? AND is_read = 0 AND is_del = 0", userID).
+		Select("category, count(*) AS total").Group("category").Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	res := &cs.MessageUnreadCount{
+		Categories: make(map[cs.MessageCategory]int64, len(items)),
+	}
+	for _, item := range items {
+		res.Total += item.Total
+		res.Categories[item.Category] = item.Total
+	}
+	return res, nil
 }
 
 func (s *messageSrv) GetMessageByID(id int64) (*ms.Message, error) {
@@ -47,13 +120,59 @@ func (s *messageSrv) ReadMessage(message *ms.Message) error {
 	return message.Update(s.db)
 }
 
-func (s *messageSrv) ReadAllMessage(userId int64) error {
-	return s.db.Table(_message_).Where("receiver_user_id=? AND is_del=0", userId).Update("is_read", 1).Error
+// ReadAllMessage 标记分类下的通知已读，category为空时不限分类，cursor不为nil时只标记该位置及更早的通知
+func (s *messageSrv) ReadAllMessage(userId int64, category cs.MessageCategory, cursor *cs.PageCursor) error {
+	db := s.db.Table(_message_).Where("receiver_user_id = ? AND is_read = 0 AND is_del = 0", userId)
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+	if cursor != nil {
+		db = db.Where("(latest_on, id) <= (?, ?)", cursor.Value, cursor.ID)
+	}
+	return db.Update("is_read", 1).Error
 }
 
-func (s *messageSrv) GetMessages(userId int64, style cs.MessageStyle, limit int, offset int) (res []*ms.MessageFormated, total int64, err error) {
+func (s *messageSrv) GetMessages(userId int64, style cs.MessageStyle, category cs.MessageCategory, limit int, offset int) (res []*ms.MessageFormated, total int64, err error) {
 	var messages []*dbr.Message
-	db := s.db.Table(_message_)
+	if err = s.messagesQuery(userId, style, category).Count(&total).Error; err != nil || total == 0 {
+		return
+	}
+	db := s.messagesQuery(userId, style, category)
+	if offset >= 0 && limit > 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	if err = db.Order("latest_on DESC, id DESC").Find(&messages).Error; err != nil {
+		return
+	}
+	for _, message := range messages {
+		res = append(res, message.Format())
+	}
+	return
+}
+
+// GetMessagesByCursor 按 (latest_on, id) 倒序游标分页，多取一条用于判断是否还有下一页
+func (s *messageSrv) GetMessagesByCursor(userId int64, style cs.MessageStyle, category cs.MessageCategory, cursor *cs.PageCursor, limit int) (res []*ms.MessageFormated, next *cs.PageCursor, err error) {
+	var messages []*dbr.Message
+	db := s.messagesQuery(userId, style, category)
+	if cursor != nil {
+		db = db.Where("(latest_on, id) < (?, ?)", cursor.Value, cursor.ID)
+	}
+	if err = db.Order("latest_on DESC, id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		return
+	}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		last := messages[limit-1]
+		next = &cs.PageCursor{Value: last.LatestOn, ID: last.ID}
+	}
+	for _, message := range messages {
+		res = append(res, message.Format())
+	}
+	return
+}
+
+func (s *messageSrv) messagesQuery(userId int64, style cs.MessageStyle, category cs.MessageCategory) *gorm.DB {
+	db := s.db.Table(_message_).Where("is_del = 0")
 	// 1动态，2评论，3回复，4私信，5好友申请，99系统通知'
 	switch style {
 	case cs.StyleMsgSystem:
@@ -69,17 +188,30 @@ func (s *messageSrv) GetMessages(userId int64, style cs.MessageStyle, limit int,
 	default:
 		db = db.Where("receiver_user_id=? OR (sender_user_id=? AND type=4)", userId, userId)
 	}
-	if err = db.Count(&total).Error; err != nil || total == 0 {
-		return
-	}
-	if offset >= 0 && limit > 0 {
-		db = db.Limit(limit).Offset(offset)
-	}
-	if err = db.Order("id DESC").Find(&messages).Error; err != nil {
-		return
+	if category != "" {
+		db = db.Where("category = ?", category)
 	}
-	for _, message := range messages {
-		res = append(res, message.Format())
+	return db
+}
+
+// messageGroupKey 通知分组键，同一接收者同组的未读通知合并展示，
+// 如"张三等20人关注了你"，@提及、好友申请、关注请求及系统通知不分组
+func messageGroupKey(msg *ms.Message) string {
+	switch msg.Category {
+	case cs.MsgCategoryReaction:
+		return string(msg.Category)
+	case cs.MsgCategoryFollow:
+		// 关注请求需逐条处理，不与关注合并
+		if msg.Type == ms.MsgTypeFollow {
+			return string(msg.Category)
+		}
+		return ""
+	case cs.MsgCategoryComment:
+		if msg.Type == ms.MsgTypeReply {
+			return fmt.Sprintf("reply:%d", msg.CommentID)
+		}
+		return fmt.Sprintf("comment:%d", msg.PostID)
+	default:
+		return ""
 	}
-	return
 }
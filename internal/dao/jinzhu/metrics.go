@@ -10,6 +10,7 @@ import (
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"github.com/rocboss/paopao-ce/pkg/rank"
 	"gorm.io/gorm"
 )
 
@@ -48,6 +49,7 @@ func (s *commentMetricSrvA) UpdateCommentMetric(metric *cs.CommentMetric) error
 		commentMetric := &dbr.CommentMetric{CommentId: metric.CommentId}
 		tx.Model(commentMetric).Where("comment_id=?", metric.CommentId).First(commentMetric)
 		commentMetric.RankScore = metric.RankScore(commentMetric.MotivationFactor)
+		commentMetric.WilsonScore = rank.Wilson(int64(metric.ThumbsUpCount), int64(metric.ThumbsDownCount))
 		return tx.Save(commentMetric).Error
 	})
 }
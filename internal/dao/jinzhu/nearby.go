@@ -0,0 +1,68 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"math"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ core.NearbyService = (*nearbySrv)(nil)
+)
+
+type nearbySrv struct {
+	db *gorm.DB
+}
+
+func newNearbyService(db *gorm.DB) core.NearbyService {
+	return &nearbySrv{
+		db: db,
+	}
+}
+
+// ListNearbyPosts 获取经纬度范围内的公开动态，按离(lat, lng)的近似距离由近到远最多取limit条
+func (s *nearbySrv) ListNearbyPosts(box cs.GeoBox, lat, lng float64, limit int) (res []*ms.Post, err error) {
+	db := withinGeoBox(s.db.Model(&dbr.Post{}), box).
+		Where("visibility >= ? AND is_del = 0 AND status = ?", cs.TweetVisitPublic, dbr.PostStatusPublished)
+	err = nearestFirst(db, lat, lng).Limit(limit).Find(&res).Error
+	return
+}
+
+// ListNearbyRooms 获取经纬度范围内未被封禁的房间，按离(lat, lng)的近似距离由近到远最多取limit条，是否在线由调用方过滤
+func (s *nearbySrv) ListNearbyRooms(box cs.GeoBox, lat, lng float64, limit int) (res []*ms.Room, err error) {
+	db := withinGeoBox(s.db.Model(&dbr.Room{}), box).Where("is_del = 0 AND is_blocked_from_space = 0")
+	err = nearestFirst(db, lat, lng).Limit(limit).Find(&res).Error
+	return
+}
+
+// withinGeoBox 经纬度范围条件，可以命中 (location_lat, location_lng) 索引，不依赖PostGIS
+func withinGeoBox(db *gorm.DB, box cs.GeoBox) *gorm.DB {
+	db = db.Where("location_lat BETWEEN ? AND ?", box.MinLat, box.MaxLat).
+		Where("NOT (location_lat = 0 AND location_lng = 0)")
+	if box.CrossAntimeridian() {
+		return db.Where("(location_lng >= ? OR location_lng <= ?)", box.MinLng, box.MaxLng)
+	}
+	return db.Where("location_lng BETWEEN ? AND ?", box.MinLng, box.MaxLng)
+}
+
+// nearestFirst 按平方度数近似距离由近到远排序，经度差跨越180度经线时取较短的一侧并按cos²(lat)缩放，
+// 只用于候选截断，精确距离由调用方计算
+func nearestFirst(db *gorm.DB, lat, lng float64) *gorm.DB {
+	scale := math.Cos(lat * math.Pi / 180)
+	scale *= scale
+	dlng := "(CASE WHEN ABS(location_lng - ?) > 180 THEN 360 - ABS(location_lng - ?) ELSE ABS(location_lng - ?) END)"
+	return db.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "(location_lat - ?) * (location_lat - ?) + " + dlng + " * " + dlng + " * ?, created_on DESC, id DESC",
+		Vars:               []any{lat, lat, lng, lng, lng, lng, lng, lng, scale},
+		WithoutParentheses: true,
+	}})
+}
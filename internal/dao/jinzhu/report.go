@@ -0,0 +1,92 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ core.ReportService = (*reportSrv)(nil)
+)
+
+type reportSrv struct {
+	db *gorm.DB
+}
+
+func newReportService(db *gorm.DB) core.ReportService {
+	return &reportSrv{
+		db: db,
+	}
+}
+
+// CreateReport create a report, duplicate open reports of the same reporter
+// about the same target are merged into the existed one, the unique index of
+// open reports keeps concurrent submissions from creating two.
+func (s *reportSrv) CreateReport(report *ms.Report) (*ms.Report, error) {
+	report.Status = cs.ReportStatusPending
+	// 索引谓词需与唯一索引一致才能被推断，不能使用参数
+	db := s.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "reporter_id"}, {Name: "target_type"}, {Name: "target_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status IN (1, 2) AND is_del = 0"}}},
+		DoNothing:   true,
+	}).Create(report)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	if db.RowsAffected == 0 {
+		return report.GetPending(s.db)
+	}
+	return report, nil
+}
+
+func (s *reportSrv) GetReportByID(id int64) (*ms.Report, error) {
+	report := &dbr.Report{
+		Model: &dbr.Model{
+			ID: id,
+		},
+	}
+	return report.Get(s.db)
+}
+
+func (s *reportSrv) ListReports(status int8, targetType cs.ReportTargetType, limit, offset int) (res []*ms.Report, total int64, err error) {
+	conditions := ms.ConditionsT{
+		"ORDER": "id ASC",
+	}
+	if status > 0 {
+		conditions["status = ?"] = status
+	}
+	if targetType != "" {
+		conditions["target_type = ?"] = targetType
+	}
+	report := &dbr.Report{}
+	if total, err = report.Count(s.db, conditions); err != nil {
+		return
+	}
+	res, err = report.List(s.db, conditions, offset, limit)
+	return
+}
+
+func (s *reportSrv) UpdateReport(report *ms.Report) error {
+	return report.Update(s.db)
+}
+
+func (s *reportSrv) CreateModerationLog(log *ms.ModerationLog) error {
+	_, err := log.Create(s.db)
+	return err
+}
+
+func (s *reportSrv) ListModerationLogs(reportID int64, limit, offset int) ([]*ms.ModerationLog, int64, error) {
+	conditions := ms.ConditionsT{}
+	if reportID > 0 {
+		conditions["report_id = ?"] = reportID
+	}
+	return (&dbr.ModerationLog{}).List(s.db, conditions, offset, limit)
+}
@@ -297,6 +297,8 @@ func (s *roomSrv) CreateRoom(room *ms.Room) error {
         IsBlockedFromSpace: room.IsBlockedFromSpace,
         Topics: room.Topics,
         Categories: dbr.Int64Array(room.Categories), // Convert from []int64 to Int64Array
+        LocationLat: room.LocationLat,
+        LocationLng: room.LocationLng,
     }
     
     logrus.WithFields(logrus.Fields{
@@ -528,4 +530,9 @@ func (s *roomSrv) UpdateCategoriesByHostID(hostID int64, categoryIDs []int64) er
 	// Call DBR layer directly (same pattern as user service)
 	room := &dbr.Room{}
 	return room.UpdateCategoriesByHostID(s.db, hostID, categoryIDs)
-} 
+}
+
+func (s *roomSrv) UpdateLocationByHostID(hostID int64, lat, lng float64) error {
+	room := &dbr.Room{}
+	return room.UpdateLocationByHostID(s.db, hostID, lat, lng)
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.RoomInviteService = (*roomInviteSrv)(nil)
+)
+
+type roomInviteSrv struct {
+	db *gorm.DB
+}
+
+func newRoomInviteService(db *gorm.DB) core.RoomInviteService {
+	return &roomInviteSrv{
+		db: db,
+	}
+}
+
+func (s *roomInviteSrv) CreateRoomInvites(invites []*ms.RoomInvite) error {
+	if len(invites) == 0 {
+		return nil
+	}
+	return s.db.Create(invites).Error
+}
+
+// AcceptRoomInvite 受邀者接受房间最新的一条未过期邀请并记录接受时间，
+// 没有可接受的邀请时返回 gorm.ErrRecordNotFound
+func (s *roomInviteSrv) AcceptRoomInvite(roomId int64, inviteeId int64) (*ms.RoomInvite, error) {
+	now := time.Now().Unix()
+	var invite dbr.RoomInvite
+	if err := s.db.Where("room_id = ? AND invitee_id = ? AND accepted_on = 0 AND expired_on > ? AND is_del = 0", roomId, inviteeId, now).
+		Order("id DESC").First(&invite).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&invite).Update("accepted_on", now).Error; err != nil {
+		return nil, err
+	}
+	invite.AcceptedOn = now
+	return &invite, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"fmt"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.EntitySearchSyncService = (*entitySearchSyncSrv)(nil)
+)
+
+type entitySearchSyncSrv struct {
+	db *gorm.DB
+}
+
+func newEntitySearchSyncService(db *gorm.DB) core.EntitySearchSyncService {
+	return &entitySearchSyncSrv{
+		db: db,
+	}
+}
+
+// ListUserSearchDocs 获取正常状态用户的搜索文档
+func (s *entitySearchSyncSrv) ListUserSearchDocs(ids []int64, limit, offset int) ([]*cs.UserSearchDoc, error) {
+	var users []*dbr.User
+	db := s.db.Where("status = ? AND is_del = 0", dbr.UserStatusNormal)
+	if err := s.pageByIds(db, ids, limit, offset).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	var categoryIds []int64
+	for _, u := range users {
+		categoryIds = append(categoryIds, u.Categories...)
+	}
+	names, err := s.categoryNames(categoryIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*cs.UserSearchDoc, 0, len(users))
+	for _, u := range users {
+		res = append(res, &cs.UserSearchDoc{
+			ID:            u.ID,
+			Username:      u.Username,
+			Nickname:      u.Nickname,
+			Avatar:        u.Avatar,
+			Categories:    []int64(u.Categories),
+			CategoryNames: namesOf(names, u.Categories),
+		})
+	}
+	return res, nil
+}
+
+// ListRoomSearchDocs 获取未被封禁且房主状态正常的房间的搜索文档，附带房主信息
+func (s *entitySearchSyncSrv) ListRoomSearchDocs(ids []int64, limit, offset int) ([]*cs.RoomSearchDoc, error) {
+	var rooms []*dbr.Room
+	db := s.db.Where("is_del = 0 AND is_blocked_from_space = 0").
+		Where(fmt.Sprintf("host_id IN (SELECT id FROM %s WHERE status = ? AND room_banned_on = 0 AND is_del = 0)", _user_), dbr.UserStatusNormal)
+	if err := s.pageByIds(db, ids, limit, offset).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	if len(rooms) == 0 {
+		return nil, nil
+	}
+	hostIds := make([]int64, 0, len(rooms))
+	var categoryIds []int64
+	for _, r := range rooms {
+		hostIds = append(hostIds, r.HostID)
+		categoryIds = append(categoryIds, r.Categories...)
+	}
+	var hosts []*dbr.User
+	if err := s.db.Select("id, username, nickname, avatar").Where("id IN ?", hostIds).Find(&hosts).Error; err != nil {
+		return nil, err
+	}
+	hostMap := make(map[int64]*dbr.User, len(hosts))
+	for _, h := range hosts {
+		hostMap[h.ID] = h
+	}
+	names, err := s.categoryNames(categoryIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*cs.RoomSearchDoc, 0, len(rooms))
+	for _, r := range rooms {
+		doc := &cs.RoomSearchDoc{
+			ID:            r.ID,
+			HostID:        r.HostID,
+			Topics:        r.Topics,
+			Categories:    []int64(r.Categories),
+			CategoryNames: namesOf(names, r.Categories),
+			CreatedOn:     r.CreatedOn,
+		}
+		if host, ok := hostMap[r.HostID]; ok {
+			doc.HostUsername, doc.HostNickname, doc.HostAvatar = host.Username, host.Nickname, host.Avatar
+		}
+		res = append(res, doc)
+	}
+	return res, nil
+}
+
+// ListTopicSearchDocs 获取仍被引用的话题的搜索文档
+func (s *entitySearchSyncSrv) ListTopicSearchDocs(ids []int64, limit, offset int) ([]*cs.TopicSearchDoc, error) {
+	var tags []*dbr.Tag
+	db := s.db.Where("quote_num > 0 AND is_del = 0")
+	if err := s.pageByIds(db, ids, limit, offset).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	res := make([]*cs.TopicSearchDoc, 0, len(tags))
+	for _, t := range tags {
+		res = append(res, &cs.TopicSearchDoc{
+			ID:       t.ID,
+			Tag:      t.Tag,
+			QuoteNum: t.QuoteNum,
+		})
+	}
+	return res, nil
+}
+
+// CountSearchDocs 统计应当出现在索引中的记录数，条件与 List*SearchDocs 一致
+func (s *entitySearchSyncSrv) CountSearchDocs(index cs.SearchIndex) (res int64, err error) {
+	switch index {
+	case cs.SearchIndexUser:
+		err = s.db.Model(&dbr.User{}).Where("status = ? AND is_del = 0", dbr.UserStatusNormal).Count(&res).Error
+	case cs.SearchIndexRoom:
+		err = s.db.Model(&dbr.Room{}).Where("is_del = 0").Count(&res).Error
+	case cs.SearchIndexTopic:
+		err = s.db.Model(&dbr.Tag{}).Where("quote_num > 0 AND is_del = 0").Count(&res).Error
+	default:
+		err = fmt.Errorf("unsupported search index: %s", index)
+	}
+	return
+}
+
+func (s *entitySearchSyncSrv) pageByIds(db *gorm.DB, ids []int64, limit, offset int) *gorm.DB {
+	if len(ids) > 0 {
+		return db.Where("id IN ?", ids)
+	}
+	return db.Order("id ASC").Limit(limit).Offset(offset)
+}
+
+func (s *entitySearchSyncSrv) categoryNames(ids []int64) (map[int64]string, error) {
+	res := make(map[int64]string)
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var categories []*dbr.Category
+	if err := s.db.Select("id, name").Where("id IN ? AND is_del = 0", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		res[c.ID] = c.Name
+	}
+	return res, nil
+}
+
+func namesOf(names map[int64]string, ids []int64) []string {
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			res = append(res, name)
+		}
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.SearchOutboxService = (*searchOutboxSrv)(nil)
+)
+
+type searchOutboxSrv struct {
+	db *gorm.DB
+}
+
+func newSearchOutboxService(db *gorm.DB) core.SearchOutboxService {
+	return &searchOutboxSrv{
+		db: db,
+	}
+}
+
+func (s *searchOutboxSrv) AppendSearchOutbox(items ...*ms.SearchOutbox) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for _, item := range items {
+		if item.Model == nil {
+			item.Model = &dbr.Model{}
+		}
+	}
+	return s.db.Create(items).Error
+}
+
+// ClaimSearchOutbox 先查出到期记录，再逐条以 next_retry_on 做乐观锁领取，
+// 多个实例同时处理时同一条记录只会被一个实例领取
+func (s *searchOutboxSrv) ClaimSearchOutbox(now int64, lease int64, limit int) (res []*ms.SearchOutbox, err error) {
+	var items []*dbr.SearchOutbox
+	if err = s.db.Where("status = ? AND next_retry_on <= ? AND is_del = 0", cs.SearchOutboxPending, now).
+		Order("id ASC").Limit(limit).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		db := s.db.Model(&dbr.SearchOutbox{}).
+			Where("id = ? AND status = ? AND next_retry_on = ?", item.ID, cs.SearchOutboxPending, item.NextRetryOn).
+			Update("next_retry_on", now+lease)
+		if db.Error != nil {
+			return res, db.Error
+		}
+		if db.RowsAffected == 1 {
+			item.NextRetryOn = now + lease
+			res = append(res, item)
+		}
+	}
+	return res, nil
+}
+
+func (s *searchOutboxSrv) FinishSearchOutbox(ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.Model(&dbr.SearchOutbox{}).Where("id IN ?", ids).Updates(map[string]any{
+		"status":     cs.SearchOutboxDone,
+		"last_error": "",
+	}).Error
+}
+
+func (s *searchOutboxSrv) RetrySearchOutbox(item *ms.SearchOutbox) error {
+	return s.db.Model(&dbr.SearchOutbox{}).Where("id = ?", item.ID).Updates(map[string]any{
+		"attempts":      item.Attempts,
+		"next_retry_on": item.NextRetryOn,
+		"last_error":    item.LastError,
+		"status":        item.Status,
+	}).Error
+}
+
+func (s *searchOutboxSrv) IsSearchOutboxSuperseded(item *ms.SearchOutbox) (bool, error) {
+	var count int64
+	err := s.db.Model(&dbr.SearchOutbox{}).
+		Where("index_name = ? AND doc_id = ? AND status = ? AND is_del = 0", item.IndexName, item.DocID, cs.SearchOutboxDone).
+		Where("version > ? OR (version = ? AND id > ?)", item.Version, item.Version, item.ID).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *searchOutboxSrv) ReplaySearchOutbox(index cs.SearchIndex, since int64) (int64, error) {
+	db := s.db.Model(&dbr.SearchOutbox{}).
+		Where("index_name = ? AND status = ? AND modified_on >= ? AND is_del = 0", index, cs.SearchOutboxDone, since).
+		Updates(map[string]any{
+			"status":        cs.SearchOutboxPending,
+			"attempts":      0,
+			"next_retry_on": 0,
+		})
+	return db.RowsAffected, db.Error
+}
+
+// PurgeSearchOutbox 清理已处理的旧记录，失败的记录保留以便排查
+func (s *searchOutboxSrv) PurgeSearchOutbox(before int64) error {
+	return s.db.Unscoped().Where("status = ? AND modified_on < ?", cs.SearchOutboxDone, before).
+		Delete(&dbr.SearchOutbox{}).Error
+}
+
+func (s *searchOutboxSrv) CountSearchOutbox(index cs.SearchIndex, status int8) (res int64, err error) {
+	err = s.db.Model(&dbr.SearchOutbox{}).
+		Where("index_name = ? AND status = ? AND is_del = 0", index, status).Count(&res).Error
+	return
+}
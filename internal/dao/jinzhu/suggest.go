@@ -0,0 +1,112 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ core.FriendSuggestService = (*friendSuggestSrv)(nil)
+)
+
+type friendSuggestSrv struct {
+	db *gorm.DB
+}
+
+func newFriendSuggestService(db *gorm.DB) core.FriendSuggestService {
+	return &friendSuggestSrv{
+		db: db,
+	}
+}
+
+// ListSuggestUserIds 按id顺序分批列出需要计算推荐的用户
+func (s *friendSuggestSrv) ListSuggestUserIds(afterId int64, limit int) (res []int64, err error) {
+	err = s.db.Table(_user_).Where("id > ? AND is_del = 0", afterId).
+		Order("id ASC").Limit(limit).Pluck("id", &res).Error
+	return
+}
+
+// ListContactMatchedIds 用户通讯录中已匹配到的用户
+func (s *friendSuggestSrv) ListContactMatchedIds(userId int64) (res []int64, err error) {
+	err = s.db.Model(&dbr.UserPhoneContact{}).
+		Where("user_id = ? AND is_matched = true AND matched_user_id IS NOT NULL AND is_del = 0", userId).
+		Distinct().Pluck("matched_user_id", &res).Error
+	return
+}
+
+// ListSharedCategoryUsers 与用户有相同分类的用户及相同分类数，按相同分类数倒序
+func (s *friendSuggestSrv) ListSharedCategoryUsers(userId int64, limit int) (res []*cs.UserSignalCount, err error) {
+	err = s.db.Table(_user_+" AS u").
+		Joins("JOIN "+_user_+" AS me ON me.id = ? AND u.categories && me.categories", userId).
+		Where("u.id <> ? AND u.is_del = 0", userId).
+		Select("u.id AS user_id, cardinality(ARRAY(SELECT unnest(u.categories) INTERSECT SELECT unnest(me.categories))) AS total").
+		Order("total DESC, u.id ASC").Limit(limit).Scan(&res).Error
+	return
+}
+
+// ListPositiveReactionUsers 用户给出正向反应(见cs.PositiveReactionRange)的对象及反应数
+func (s *friendSuggestSrv) ListPositiveReactionUsers(userId int64, limit int) (res []*cs.UserSignalCount, err error) {
+	first, last := cs.PositiveReactionRange()
+	err = s.db.Model(&dbr.UserReaction{}).
+		Where("reactor_user_id = ? AND target_user_id <> ? AND reaction_type_id BETWEEN ? AND ? AND is_del = 0", userId, userId, first, last).
+		Select("target_user_id AS user_id, count(*) AS total").Group("target_user_id").
+		Order("total DESC, target_user_id ASC").Limit(limit).Scan(&res).Error
+	return
+}
+
+// ListSuggestFeedbackIds 不应再推荐的用户：永久隐藏的及dismissSince之后忽略的
+func (s *friendSuggestSrv) ListSuggestFeedbackIds(userId int64, dismissSince int64) (res []int64, err error) {
+	err = s.db.Model(&dbr.UserSuggestionFeedback{}).
+		Where("user_id = ? AND (action = ? OR modified_on >= ?)", userId, cs.SuggestHide, dismissSince).
+		Pluck("suggested_id", &res).Error
+	return
+}
+
+// ReplaceUserSuggestions 用新的计算结果替换用户的推荐列表
+func (s *friendSuggestSrv) ReplaceUserSuggestions(userId int64, items []*ms.UserSuggestion) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&dbr.UserSuggestion{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(items).Error
+	})
+}
+
+func (s *friendSuggestSrv) ListUserSuggestions(userId int64, limit int, offset int) (res []*ms.UserSuggestion, total int64, err error) {
+	db := s.db.Model(&dbr.UserSuggestion{}).Where("user_id = ?", userId)
+	if err = db.Count(&total).Error; err != nil || total == 0 {
+		return
+	}
+	err = db.Order("score DESC, suggested_id ASC").Limit(limit).Offset(offset).Find(&res).Error
+	return
+}
+
+// CreateSuggestFeedback 记录用户的忽略/隐藏反馈并立即从推荐列表中移除
+func (s *friendSuggestSrv) CreateSuggestFeedback(userId int64, suggestedId int64, action cs.SuggestFeedbackT) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "suggested_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"action", "modified_on"}),
+		}).Create(&dbr.UserSuggestionFeedback{
+			Model:       &dbr.Model{},
+			UserID:      userId,
+			SuggestedID: suggestedId,
+			Action:      action,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ? AND suggested_id = ?", userId, suggestedId).
+			Delete(&dbr.UserSuggestion{}).Error
+	})
+}
@@ -33,11 +33,12 @@ type simpleIndexPostsSrv struct {
 // IndexPosts 根据userId查询广场推文列表，简单做到不同用户的主页都是不同的；
 func (s *shipIndexSrv) IndexPosts(user *ms.User, offset int, limit int) (*ms.IndexTweetList, error) {
 	predicates := dbr.Predicates{
-		"ORDER": []any{"is_top DESC, latest_replied_on DESC"},
+		"status = ?": []any{dbr.PostStatusPublished},
+		"ORDER":      []any{"is_top DESC, latest_replied_on DESC"},
 	}
 	if user == nil {
 		predicates["visibility = ?"] = []any{dbr.PostVisitPublic}
-	} else if !user.IsAdmin {
+	} else if !s.ams.HasPermission(user, cs.PermTweetViewAll) {
 		friendIds, _ := s.ams.BeFriendIds(user.ID)
 		friendIds = append(friendIds, user.ID)
 		args := []any{dbr.PostVisitPublic, dbr.PostVisitPrivate, user.ID, dbr.PostVisitFriend, friendIds}
@@ -69,6 +70,7 @@ func (s *shipIndexSrv) IndexPosts(user *ms.User, offset int, limit int) (*ms.Ind
 func (s *simpleIndexPostsSrv) IndexPosts(_user *ms.User, offset int, limit int) (*ms.IndexTweetList, error) {
 	predicates := dbr.Predicates{
 		"visibility = ?": []any{dbr.PostVisitPublic},
+		"status = ?":     []any{dbr.PostStatusPublished},
 		"ORDER":          []any{"is_top DESC, latest_replied_on DESC"},
 	}
 
@@ -247,6 +247,23 @@ func (s *topicSrv) tagsFormatB(userTopicsMap map[int64]*topicInfo, tags cs.TagIn
 	return tagList, nil
 }
 
+// TagsByName 按标签名获取标签
+func (s *topicSrv) TagsByName(tags []string) (res cs.TagInfoList, err error) {
+	list, err := (&dbr.Tag{}).TagsFrom(s.db, tags)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range list {
+		res = append(res, &cs.TagInfo{
+			ID:       tag.ID,
+			UserID:   tag.UserID,
+			Tag:      tag.Tag,
+			QuoteNum: tag.QuoteNum,
+		})
+	}
+	return
+}
+
 func (s *topicSrv) TagsByKeyword(keyword string) (res cs.TagInfoList, err error) {
 	keyword = "%" + strings.Trim(keyword, " ") + "%"
 	tag := &dbr.Tag{}
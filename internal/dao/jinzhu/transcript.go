@@ -0,0 +1,86 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jinzhu
+
+import (
+	"strings"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"gorm.io/gorm"
+)
+
+var (
+	_ core.TranscriptService = (*transcriptSrv)(nil)
+)
+
+type transcriptSrv struct {
+	db *gorm.DB
+}
+
+func newTranscriptService(db *gorm.DB) core.TranscriptService {
+	return &transcriptSrv{
+		db: db,
+	}
+}
+
+// SaveTranscript 同一音轨重新录制后重新转写，旧片段直接删除
+func (s *transcriptSrv) SaveTranscript(postId int64, speakerId int64, segments cs.TranscriptSegments) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("post_id = ? AND speaker_id = ?", postId, speakerId).
+			Delete(&dbr.PostTranscript{}).Error; err != nil {
+			return err
+		}
+		if len(segments) == 0 {
+			return nil
+		}
+		items := make([]*dbr.PostTranscript, 0, len(segments))
+		for _, seg := range segments {
+			items = append(items, &dbr.PostTranscript{
+				Model:     &dbr.Model{},
+				PostID:    postId,
+				SpeakerID: speakerId,
+				StartMs:   seg.Start,
+				EndMs:     seg.End,
+				Text:      seg.Text,
+			})
+		}
+		return tx.Create(items).Error
+	})
+}
+
+func (s *transcriptSrv) GetTranscript(postId int64) (cs.TranscriptSegments, error) {
+	var items []*dbr.PostTranscript
+	if err := s.db.Where("post_id = ? AND is_del = 0", postId).
+		Order("start_ms ASC, speaker_id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	res := make(cs.TranscriptSegments, 0, len(items))
+	for _, item := range items {
+		res = append(res, item.Format())
+	}
+	return res, nil
+}
+
+func (s *transcriptSrv) GetTranscriptTexts(postIds []int64) (map[int64]string, error) {
+	res := make(map[int64]string, len(postIds))
+	if len(postIds) == 0 {
+		return res, nil
+	}
+	var items []*dbr.PostTranscript
+	if err := s.db.Select("post_id, text").Where("post_id IN ? AND is_del = 0", postIds).
+		Order("post_id ASC, start_ms ASC, speaker_id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	texts := make(map[int64][]string, len(postIds))
+	for _, item := range items {
+		texts[item.PostID] = append(texts[item.PostID], item.Text)
+	}
+	for postId, t := range texts {
+		res[postId] = strings.Join(t, "\n")
+	}
+	return res, nil
+}
@@ -28,6 +28,16 @@ var (
 	_ core.TweetHelpServantA   = (*tweetHelpSrvA)(nil)
 )
 
+// _visibleContentCond 有内容的动态，或原动态未删除且仍公开的转发，
+// 原动态删除或不再公开后转发不再出现在列表中，参数由 visibleContent 绑定
+const _visibleContentCond = "(EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '') OR " +
+	"(repost_type = 1 AND EXISTS (SELECT 1 FROM p_post origin WHERE origin.id = p_post.origin_id AND origin.visibility = ? AND origin.status = ? AND origin.is_del = 0)))"
+
+// visibleContent 追加 _visibleContentCond 条件
+func visibleContent(db *gorm.DB) *gorm.DB {
+	return db.Where(_visibleContentCond, dbr.PostVisitPublic, dbr.PostStatusPublished)
+}
+
 type tweetSrv struct {
 	db *gorm.DB
 }
@@ -92,6 +102,14 @@ func newTweetHelpServantA(db *gorm.DB) core.TweetHelpServantA {
 
 // MergePosts post数据整合
 func (s *tweetHelpSrv) MergePosts(posts []*ms.Post) ([]*ms.PostFormated, error) {
+	postsFormated, err := s.mergePosts(posts)
+	if err != nil {
+		return nil, err
+	}
+	return postsFormated, s.attachOrigins(postsFormated)
+}
+
+func (s *tweetHelpSrv) mergePosts(posts []*ms.Post) ([]*ms.PostFormated, error) {
 	postIds := make([]int64, 0, len(posts))
 	userIds := make([]int64, 0, len(posts)*2) // Double capacity for both host and visitor IDs
 	for _, post := range posts {
@@ -176,7 +194,42 @@ func (s *tweetHelpSrv) RevampPosts(posts []*ms.PostFormated) ([]*ms.PostFormated
 		}
 		post.Contents = contentMap[post.ID]
 	}
-	return posts, nil
+	return posts, s.attachOrigins(posts)
+}
+
+// attachOrigins 为转发及引用转发附上原动态，只展开一层，原动态已删除或不再公开时不展示
+func (s *tweetHelpSrv) attachOrigins(posts []*ms.PostFormated) error {
+	originIds := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		if post.OriginID > 0 {
+			originIds = append(originIds, post.OriginID)
+		}
+	}
+	if len(originIds) == 0 {
+		return nil
+	}
+	origins, err := (&dbr.Post{}).List(s.db, dbr.ConditionsT{
+		"id IN ?":        originIds,
+		"visibility = ?": dbr.PostVisitPublic,
+		"status = ?":     dbr.PostStatusPublished,
+	}, 0, 0)
+	if err != nil {
+		return err
+	}
+	originsFormated, err := s.mergePosts(origins)
+	if err != nil {
+		return err
+	}
+	originMap := make(map[int64]*dbr.PostFormated, len(originsFormated))
+	for _, origin := range originsFormated {
+		originMap[origin.ID] = origin
+	}
+	for _, post := range posts {
+		if post.OriginID > 0 {
+			post.Origin = originMap[post.OriginID]
+		}
+	}
+	return nil
 }
 
 func (s *tweetHelpSrv) getPostContentsByIDs(ids []int64) ([]*dbr.PostContent, error) {
@@ -216,6 +269,15 @@ func (s *tweetManageSrv) CreateAttachment(obj *ms.Attachment) (int64, error) {
 	return attachment.ID, err
 }
 
+// GetAttachmentByContent 按资源地址获取上传时记录的附件信息
+func (s *tweetManageSrv) GetAttachmentByContent(content string) (*ms.Attachment, error) {
+	var attachment dbr.Attachment
+	if err := s.db.Where("content = ? AND is_del = 0", content).Order("id DESC").First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
 func (s *tweetManageSrv) UpdatePostContent(content *ms.PostContent) error {
 	postContent := &dbr.PostContent{
 		Model: &dbr.Model{
@@ -247,53 +309,78 @@ func (s *tweetManageSrv) CreatePost(post *ms.Post) (*ms.Post, error) {
 	return p, nil
 }
 
-func (s *tweetManageSrv) DeletePost(post *ms.Post) ([]string, error) {
-	var mediaContents []string
+func (s *tweetManageSrv) DeletePost(post *ms.Post) (mediaContents []string, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) (err error) {
+		mediaContents, err = deletePost(tx, post)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cacheIndex.SendAction(core.IdxActDeletePost, post)
+	return mediaContents, nil
+}
+
+// deletePost 在事务中删除动态及其内容、评论与@提及，返回需要删除的媒体内容
+func deletePost(tx *gorm.DB, post *ms.Post) ([]string, error) {
 	postId := post.ID
 	postContent := &dbr.PostContent{}
-	err := s.db.Transaction(
-		func(tx *gorm.DB) error {
-			if contents, err := postContent.MediaContentsByPostId(tx, postId); err == nil {
-				mediaContents = contents
-			} else {
-				return err
-			}
-
-			// 删推文
-			if err := post.Delete(tx); err != nil {
-				return err
-			}
-
-			// 删内容
-			if err := postContent.DeleteByPostId(tx, postId); err != nil {
-				return err
-			}
-
-			// 删评论
-			if contents, err := s.deleteCommentByPostId(tx, postId); err == nil {
-				mediaContents = append(mediaContents, contents...)
-			} else {
-				return err
-			}
+	mediaContents, err := postContent.MediaContentsByPostId(tx, postId)
+	if err != nil {
+		return nil, err
+	}
 
-			if tags := strings.Split(post.Tags, ","); len(tags) > 0 {
-				// 删tag，宽松处理错误，有错误不会回滚
-				deleteTags(tx, tags)
-			}
+	// 删推文
+	if err = post.Delete(tx); err != nil {
+		return nil, err
+	}
 
-			return nil
-		},
-	)
+	// 删内容
+	if err = postContent.DeleteByPostId(tx, postId); err != nil {
+		return nil, err
+	}
 
+	// 删评论
+	contents, err := deleteCommentByPostId(tx, postId)
 	if err != nil {
 		return nil, err
 	}
+	mediaContents = append(mediaContents, contents...)
 
-	s.cacheIndex.SendAction(core.IdxActDeletePost, post)
+	// 删@提及
+	if err = deleteMentionsOf(tx, cs.MentionSourcePost, postId); err != nil {
+		return nil, err
+	}
+
+	if tags := strings.Split(post.Tags, ","); len(tags) > 0 {
+		// 删tag，宽松处理错误，有错误不会回滚
+		deleteTags(tx, tags)
+	}
 	return mediaContents, nil
 }
 
-func (s *tweetManageSrv) deleteCommentByPostId(db *gorm.DB, postId int64) ([]string, error) {
+// ReplacePostContents 替换动态内容，返回被替换内容中的媒体资源
+func (s *tweetManageSrv) ReplacePostContents(postId int64, contents []*ms.PostContent) (mediaContents []string, err error) {
+	postContent := &dbr.PostContent{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if mediaContents, err = postContent.MediaContentsByPostId(tx.Where("is_del = 0"), postId); err != nil {
+			return err
+		}
+		if err = postContent.DeleteByPostId(tx, postId); err != nil {
+			return err
+		}
+		for _, content := range contents {
+			content.PostID = postId
+			if _, err = content.Create(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func deleteCommentByPostId(db *gorm.DB, postId int64) ([]string, error) {
 	comment := &dbr.Comment{}
 	commentContent := &dbr.CommentContent{}
 
@@ -308,6 +395,11 @@ func (s *tweetManageSrv) deleteCommentByPostId(db *gorm.DB, postId int64) ([]str
 	if err != nil {
 		return nil, err
 	}
+	replyContents, err := (&dbr.CommentReply{}).AudioContentsByCommentId(db, commentIds)
+	if err != nil {
+		return nil, err
+	}
+	mediaContents = append(mediaContents, replyContents...)
 
 	// 删评论
 	if err = comment.DeleteByPostId(db, postId); err != nil {
@@ -393,6 +485,42 @@ func (s *tweetManageSrv) VisiblePost(post *ms.Post, visibility cs.TweetVisibleTy
 	return
 }
 
+// HidePost 管理员隐藏动态，隐藏后作者不能再修改可见性
+func (s *tweetManageSrv) HidePost(post *ms.Post) error {
+	post.HiddenOn = time.Now().Unix()
+	if post.Visibility == dbr.PostVisitPrivate {
+		return post.Update(s.db)
+	}
+	return s.VisiblePost(post, cs.TweetVisitPrivate)
+}
+
+// ModeratorLockPost 管理员锁定动态评论，锁定后作者不能再解除
+func (s *tweetManageSrv) ModeratorLockPost(post *ms.Post) error {
+	post.IsLock, post.LockedOn = 1, time.Now().Unix()
+	return post.Update(s.db)
+}
+
+// ClaimPostStatus 仅当动态仍处于 from 状态且计划发布时间不晚于 dueOn 时更新为 to，
+// 多个实例同时处理同一动态时只有一个能认领成功，计划时间已被推迟的动态不会被认领
+func (s *tweetManageSrv) ClaimPostStatus(postId int64, from ms.PostStatusT, to ms.PostStatusT, dueOn int64) (bool, error) {
+	res := s.db.Model(&dbr.Post{}).Where("id = ? AND status = ? AND scheduled_on <= ? AND is_del = 0", postId, from, dueOn).
+		Updates(map[string]any{
+			"status":      to,
+			"modified_on": time.Now().Unix(),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+// ExpireProcessingPosts 创建时间早于 before 仍在等待录音的动态转为草稿，返回转为草稿的动态数
+func (s *tweetManageSrv) ExpireProcessingPosts(before int64) (int64, error) {
+	res := s.db.Model(&dbr.Post{}).Where("status = ? AND created_on < ? AND is_del = 0", dbr.PostStatusProcessing, before).
+		Updates(map[string]any{
+			"status":      dbr.PostStatusDraft,
+			"modified_on": time.Now().Unix(),
+		})
+	return res.RowsAffected, res.Error
+}
+
 func (s *tweetManageSrv) UpdatePost(post *ms.Post) (err error) {
 	if err = post.Update(s.db); err != nil {
 		return
@@ -401,6 +529,21 @@ func (s *tweetManageSrv) UpdatePost(post *ms.Post) (err error) {
 	return
 }
 
+// UpdatePostShareCount 原子地增减动态的转发数，不小于0，更新后按最新数据刷新动态指标
+func (s *tweetManageSrv) UpdatePostShareCount(post *ms.Post, delta int64) error {
+	if err := s.db.Model(&dbr.Post{}).Where("id = ?", post.ID).
+		UpdateColumn("share_count", gorm.Expr("GREATEST(share_count + ?, 0)", delta)).Error; err != nil {
+		return err
+	}
+	latest, err := post.Get(s.db)
+	if err != nil {
+		return err
+	}
+	post.ShareCount = latest.ShareCount
+	s.cacheIndex.SendAction(core.IdxActUpdatePost, latest)
+	return nil
+}
+
 func (s *tweetManageSrv) CreatePostStar(postID, userID int64) (*ms.PostStar, error) {
 	star := &dbr.PostStar{
 		PostID: postID,
@@ -426,11 +569,43 @@ func (s *tweetSrv) GetPosts(conditions ms.ConditionsT, offset, limit int) ([]*ms
 	return (&dbr.Post{}).List(s.db, conditions, offset, limit)
 }
 
-func (s *tweetSrv) ListUserTweets(userId int64, style uint8, justEssence bool, limit, offset int) (res []*ms.Post, total int64, err error) {
-	logrus.Debugf("DEBUG ListUserTweets: Starting with userId=%d, style=%d, justEssence=%v, limit=%d, offset=%d", userId, style, justEssence, limit, offset)
-	
+func (s *tweetSrv) GetUserRepost(userId int64, originId int64) (*ms.Post, error) {
+	var post dbr.Post
+	err := s.db.Where("CAST(user_id->0 AS bigint) = ? AND origin_id = ? AND repost_type = ? AND is_del = 0", userId, originId, dbr.PostRepostPlain).First(&post).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// ListUserDraftTweets 用户未发布的动态，包括草稿、等待录音及定时发布的动态
+func (s *tweetSrv) ListUserDraftTweets(userId int64, limit, offset int) (res []*ms.Post, total int64, err error) {
+	db := s.db.Model(&dbr.Post{}).Where("CAST(user_id->0 AS bigint) = ? AND status > 0 AND is_del = 0", userId)
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	if offset >= 0 && limit > 0 {
+		db = db.Offset(offset).Limit(limit)
+	}
+	err = db.Order("modified_on DESC, id DESC").Find(&res).Error
+	return
+}
+
+// ListDueScheduledTweets 计划发布时间已到的定时动态
+func (s *tweetSrv) ListDueScheduledTweets(now int64, limit int) (res []*ms.Post, err error) {
+	err = s.db.Where("status = ? AND scheduled_on <= ? AND is_del = 0", dbr.PostStatusScheduled, now).Order("scheduled_on ASC, id ASC").Limit(limit).Find(&res).Error
+	return
+}
+
+func (s *tweetSrv) ListThreadTweets(rootId int64) (res []*ms.Post, err error) {
+	err = s.db.Where("(id = ? OR thread_root_id = ?) AND is_del = 0", rootId, rootId).Order("id ASC").Find(&res).Error
+	return
+}
+
+// userTweetsQuery 用户推文列表的查询条件
+func (s *tweetSrv) userTweetsQuery(userId int64, style uint8, justEssence bool) *gorm.DB {
 	// Include empty content filter in initial query setup
-	db := s.db.Model(&dbr.Post{}).Where("CAST(user_id->0 AS bigint) = ? AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", userId)
+	db := visibleContent(s.db.Model(&dbr.Post{}).Where("CAST(user_id->0 AS bigint) = ? AND status = ?", userId, dbr.PostStatusPublished))
 	logrus.Debugf("DEBUG ListUserTweets: Initial query condition user_id->0 = %d with empty content filter", userId)
 	
 	switch style {
@@ -455,6 +630,12 @@ func (s *tweetSrv) ListUserTweets(userId int64, style uint8, justEssence bool, l
 		db = db.Where("is_essence=1")
 		logrus.Debug("DEBUG ListUserTweets: Added essence condition")
 	}
+	return db
+}
+
+func (s *tweetSrv) ListUserTweets(userId int64, style uint8, justEssence bool, limit, offset int) (res []*ms.Post, total int64, err error) {
+	logrus.Debugf("DEBUG ListUserTweets: Starting with userId=%d, style=%d, justEssence=%v, limit=%d, offset=%d", userId, style, justEssence, limit, offset)
+	db := s.userTweetsQuery(userId, style, justEssence)
 	
 	if err = db.Count(&total).Error; err != nil {
 		logrus.Errorf("DEBUG ListUserTweets: Error counting total: %v", err)
@@ -466,7 +647,7 @@ func (s *tweetSrv) ListUserTweets(userId int64, style uint8, justEssence bool, l
 		db = db.Offset(offset).Limit(limit)
 		logrus.Debugf("DEBUG ListUserTweets: Added offset=%d and limit=%d", offset, limit)
 	}
-	if err = db.Order("is_top DESC, latest_replied_on DESC").Find(&res).Error; err != nil {
+	if err = db.Order("is_top DESC, latest_replied_on DESC, id DESC").Find(&res).Error; err != nil {
 		logrus.Errorf("DEBUG ListUserTweets: Error fetching results: %v", err)
 		return
 	}
@@ -474,17 +655,20 @@ func (s *tweetSrv) ListUserTweets(userId int64, style uint8, justEssence bool, l
 	return
 }
 
-func (s *tweetSrv) ListIndexNewestTweets(limit, offset int) (res []*ms.Post, total int64, err error) {
+func (s *tweetSrv) indexNewestTweetsQuery() *gorm.DB {
 	// Include empty content filter in initial query setup
-	db := s.db.Table(_post_).Where("visibility >= ? AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", cs.TweetVisitPublic)
-	
+	return visibleContent(s.db.Table(_post_).Where("visibility >= ? AND status = ?", cs.TweetVisitPublic, dbr.PostStatusPublished))
+}
+
+func (s *tweetSrv) ListIndexNewestTweets(limit, offset int) (res []*ms.Post, total int64, err error) {
+	db := s.indexNewestTweetsQuery()
 	if err = db.Count(&total).Error; err != nil {
 		return
 	}
 	if offset >= 0 && limit > 0 {
 		db = db.Offset(offset).Limit(limit)
 	}
-	if err = db.Order("is_top DESC, latest_replied_on DESC").Find(&res).Error; err != nil {
+	if err = db.Order("is_top DESC, latest_replied_on DESC, id DESC").Find(&res).Error; err != nil {
 		return
 	}
 	return
@@ -492,7 +676,7 @@ func (s *tweetSrv) ListIndexNewestTweets(limit, offset int) (res []*ms.Post, tot
 
 func (s *tweetSrv) ListIndexHotsTweets(limit, offset int) (res []*ms.Post, total int64, err error) {
 	// Include empty content filter in initial query setup
-	db := s.db.Table(_post_).Joins(fmt.Sprintf("LEFT JOIN %s metric ON %s.id=metric.post_id", _post_metric_, _post_)).Where(fmt.Sprintf("visibility >= ? AND %s.is_del=0 AND metric.is_del=0 AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", _post_), cs.TweetVisitPublic)
+	db := s.db.Table(_post_).Joins(fmt.Sprintf("LEFT JOIN %s metric ON %s.id=metric.post_id", _post_metric_, _post_)).Where(fmt.Sprintf("visibility >= ? AND %s.is_del=0 AND %s.status=? AND metric.is_del=0 AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", _post_, _post_), cs.TweetVisitPublic, dbr.PostStatusPublished)
 	
 	if err = db.Count(&total).Error; err != nil {
 		return
@@ -508,32 +692,32 @@ func (s *tweetSrv) ListIndexHotsTweets(limit, offset int) (res []*ms.Post, total
 
 func (s *tweetSrv) ListSyncSearchTweets(limit, offset int) (res []*ms.Post, total int64, err error) {
 	// Include empty content filter in initial query setup
-	db := s.db.Table(_post_).Where("visibility >= ? AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", cs.TweetVisitFriend)
+	db := s.db.Table(_post_).Where("visibility >= ? AND status = ? AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", cs.TweetVisitFriend, dbr.PostStatusPublished)
 	if err = db.Count(&total).Error; err != nil {
 		return
 	}
 	if offset >= 0 && limit > 0 {
 		db = db.Offset(offset).Limit(limit)
 	}
-	if err = db.Find(&res).Error; err != nil {
+	// 按主键排序保证分页重建索引时不重不漏
+	if err = db.Order("id ASC").Find(&res).Error; err != nil {
 		return
 	}
 	return
 }
 
-func (s *tweetSrv) ListFollowingTweets(userId int64, limit, offset int) (res []*ms.Post, total int64, err error) {
-	logrus.Debugf("DEBUG ListFollowingTweets: Starting with userId=%d, limit=%d, offset=%d", userId, limit, offset)
-	
+// followingTweetsQuery 关注/好友推文列表的查询条件
+func (s *tweetSrv) followingTweetsQuery(userId int64) (*gorm.DB, error) {
 	beFriendIds, beFollowIds, xerr := s.getUserRelation(userId)
 	if xerr != nil {
 		logrus.Errorf("DEBUG ListFollowingTweets: Error getting user relations: %v", xerr)
-		return nil, 0, xerr
+		return nil, xerr
 	}
 	logrus.Debugf("DEBUG ListFollowingTweets: Got friend IDs=%v, follow IDs=%v", beFriendIds, beFollowIds)
 	
 	beFriendCount, beFollowCount := len(beFriendIds), len(beFollowIds)
 	// Include empty content filter in initial query setup
-	db := s.db.Model(&dbr.Post{}).Where("EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')")
+	db := visibleContent(s.db.Model(&dbr.Post{}).Where("status = ?", dbr.PostStatusPublished))
 	//可见性: 0私密 10充电可见 20订阅可见 30保留 40保留 50好友可见 60关注可见 70保留 80保留 90公开',
 	switch {
 	case beFriendCount > 0 && beFollowCount > 0:
@@ -549,6 +733,16 @@ func (s *tweetSrv) ListFollowingTweets(userId int64, limit, offset int) (res []*
 		db = db.Where("CAST(user_id->0 AS bigint) = ?", userId)
 		logrus.Debug("DEBUG ListFollowingTweets: Using condition for user only")
 	}
+	return db, nil
+}
+
+func (s *tweetSrv) ListFollowingTweets(userId int64, limit, offset int) (res []*ms.Post, total int64, err error) {
+	logrus.Debugf("DEBUG ListFollowingTweets: Starting with userId=%d, limit=%d, offset=%d", userId, limit, offset)
+	
+	db, xerr := s.followingTweetsQuery(userId)
+	if xerr != nil {
+		return nil, 0, xerr
+	}
 	
 	if err = db.Count(&total).Error; err != nil {
 		logrus.Errorf("DEBUG ListFollowingTweets: Error counting total: %v", err)
@@ -560,7 +754,7 @@ func (s *tweetSrv) ListFollowingTweets(userId int64, limit, offset int) (res []*
 		db = db.Offset(offset).Limit(limit)
 		logrus.Debugf("DEBUG ListFollowingTweets: Added offset=%d and limit=%d", offset, limit)
 	}
-	if err = db.Order("is_top DESC, latest_replied_on DESC").Find(&res).Error; err != nil {
+	if err = db.Order("is_top DESC, latest_replied_on DESC, id DESC").Find(&res).Error; err != nil {
 		logrus.Errorf("DEBUG ListFollowingTweets: Error fetching results: %v", err)
 		return
 	}
@@ -572,7 +766,7 @@ func (s *tweetSrv) getUserRelation(userId int64) (beFriendIds []int64, beFollowI
 	if err = s.db.Table(_contact_).Where("friend_id=? AND status=2 AND is_del=0", userId).Select("user_id").Find(&beFriendIds).Error; err != nil {
 		return
 	}
-	if err = s.db.Table(_following_).Where("user_id=? AND is_del=0", userId).Select("follow_id").Find(&beFollowIds).Error; err != nil {
+	if err = s.db.Table(_following_).Where("user_id=? AND status=2 AND is_del=0", userId).Select("follow_id").Find(&beFollowIds).Error; err != nil {
 		return
 	}
 	// 即是好友又是关注者，保留好友去除关注者
@@ -624,9 +818,8 @@ func (s *tweetSrv) ListUserStarTweets(user *cs.VistUser, limit int, offset int)
 	return
 }
 
-func (s *tweetSrv) getUserTweets(db *gorm.DB, user *cs.VistUser, limit int, offset int) (res []*ms.Post, total int64, err error) {
-	logrus.Debugf("DEBUG getUserTweets: Starting with userId=%d, relationType=%d, limit=%d, offset=%d", user.UserId, user.RelTyp, limit, offset)
-	
+// userTweetsVisibleQuery 按访问者关系过滤用户推文的可见性
+func (s *tweetSrv) userTweetsVisibleQuery(db *gorm.DB, user *cs.VistUser) *gorm.DB {
 	visibilities := []core.PostVisibleT{core.PostVisitPublic}
 	switch user.RelTyp {
 	case cs.RelationAdmin, cs.RelationSelf:
@@ -642,8 +835,15 @@ func (s *tweetSrv) getUserTweets(db *gorm.DB, user *cs.VistUser, limit int, offs
 	}
 	
 	// Include empty content filter in initial query setup
-	db = db.Where("visibility IN ? AND is_del=0 AND EXISTS (SELECT 1 FROM p_post_content WHERE post_id = p_post.id AND content != '')", visibilities)
+	db = visibleContent(db.Where("visibility IN ? AND is_del=0 AND status=?", visibilities, dbr.PostStatusPublished))
 	logrus.Debugf("DEBUG getUserTweets: Using visibilities=%v with empty content filter", visibilities)
+	return db
+}
+
+func (s *tweetSrv) getUserTweets(db *gorm.DB, user *cs.VistUser, limit int, offset int) (res []*ms.Post, total int64, err error) {
+	logrus.Debugf("DEBUG getUserTweets: Starting with userId=%d, relationType=%d, limit=%d, offset=%d", user.UserId, user.RelTyp, limit, offset)
+	
+	db = s.userTweetsVisibleQuery(db, user)
 	
 	err = db.Count(&total).Error
 	if err != nil {
@@ -657,7 +857,7 @@ func (s *tweetSrv) getUserTweets(db *gorm.DB, user *cs.VistUser, limit int, offs
 		logrus.Debugf("DEBUG getUserTweets: Added offset=%d and limit=%d", offset, limit)
 	}
 	
-	err = db.Order("is_top DESC, latest_replied_on DESC").Find(&res).Error
+	err = db.Order("is_top DESC, latest_replied_on DESC, id DESC").Find(&res).Error
 	if err != nil {
 		logrus.Errorf("DEBUG getUserTweets: Error fetching results: %v", err)
 		return
@@ -680,6 +880,70 @@ func (s *tweetSrv) ListUserCommentTweets(user *cs.VistUser, limit int, offset in
 	return s.getUserTweets(db, user, limit, offset)
 }
 
+func (s *tweetSrv) ListUserStarTweetsByCursor(user *cs.VistUser, cursor *cs.PageCursor, limit int) (res []*ms.PostStar, next *cs.PageCursor, err error) {
+	star := &dbr.PostStar{
+		UserID: user.UserId,
+	}
+	conditions := dbr.ConditionsT{
+		"ORDER": s.db.NamingStrategy.TableName("PostStar") + ".id DESC",
+	}
+	if cursor != nil {
+		conditions["id < ?"] = cursor.ID
+	}
+	if res, err = star.List(s.db, &conditions, user.RelTyp, limit+1, 0); err != nil {
+		return
+	}
+	if len(res) > limit {
+		res = res[:limit]
+		last := res[limit-1]
+		next = &cs.PageCursor{Value: last.CreatedOn, ID: last.ID}
+	}
+	return
+}
+
+func (s *tweetSrv) ListUserMediaTweetsByCursor(user *cs.VistUser, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error) {
+	db := s.db.Table(_post_by_media_).Where("CAST(user_id->0 AS bigint) = ?", user.UserId)
+	return postsByCursor(s.userTweetsVisibleQuery(db, user), cursor, limit)
+}
+
+func (s *tweetSrv) ListUserCommentTweetsByCursor(user *cs.VistUser, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error) {
+	db := s.db.Table(_post_by_comment_).Where("CAST(user_id->0 AS bigint) = ?", user.UserId)
+	return postsByCursor(s.userTweetsVisibleQuery(db, user), cursor, limit)
+}
+
+func (s *tweetSrv) ListUserTweetsByCursor(userId int64, style uint8, justEssence bool, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error) {
+	return postsByCursor(s.userTweetsQuery(userId, style, justEssence), cursor, limit)
+}
+
+func (s *tweetSrv) ListFollowingTweetsByCursor(userId int64, cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error) {
+	db, err := s.followingTweetsQuery(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return postsByCursor(db, cursor, limit)
+}
+
+func (s *tweetSrv) ListIndexNewestTweetsByCursor(cursor *cs.PageCursor, limit int) ([]*ms.Post, *cs.PageCursor, error) {
+	return postsByCursor(s.indexNewestTweetsQuery(), cursor, limit)
+}
+
+// postsByCursor 按 (is_top, latest_replied_on, id) 倒序游标分页查询推文，
+// 多取一条用于判断是否还有下一页，省去 COUNT(*) 统计
+func postsByCursor(db *gorm.DB, cursor *cs.PageCursor, limit int) (res []*ms.Post, next *cs.PageCursor, err error) {
+	if cursor != nil {
+		db = db.Where("(is_top, latest_replied_on, id) < (?, ?, ?)", cursor.Top, cursor.Value, cursor.ID)
+	}
+	if err = db.Order("is_top DESC, latest_replied_on DESC, id DESC").Limit(limit + 1).Find(&res).Error; err != nil {
+		return
+	}
+	if len(res) > limit {
+		res = res[:limit]
+		last := res[limit-1]
+		next = &cs.PageCursor{Top: last.IsTop, Value: last.LatestRepliedOn, ID: last.ID}
+	}
+	return
+}
+
 func (s *tweetSrv) GetUserPostStarCount(userID int64) (int64, error) {
 	star := &dbr.PostStar{
 		UserID: userID,
@@ -63,6 +63,8 @@ func newUserManageService(db *gorm.DB, ums core.UserMetricServantA) core.UserMan
 			fmt.Sprintf("%s.is_admin", _user_),
 			fmt.Sprintf("%s.created_on", _user_),
 			fmt.Sprintf("%s.categories", _user_),
+			fmt.Sprintf("%s.dm_policy", _user_),
+			fmt.Sprintf("%s.is_private", _user_),
 			"m.tweets_count",
 		},
 	}
@@ -271,7 +273,7 @@ func (s *userRelationSrv) MyFriendIds(userId int64) (res []int64, err error) {
 }
 
 func (s *userRelationSrv) MyFollowIds(userId int64) (res []int64, err error) {
-	err = s.db.Table(_following_).Where("user_id=? AND is_del=0", userId).Select("follow_id").Find(&res).Error
+	err = s.db.Table(_following_).Where("user_id=? AND status=2 AND is_del=0", userId).Select("follow_id").Find(&res).Error
 	return
 }
 
@@ -319,6 +321,27 @@ func (s *userRelationSrv) IsMyFollow(userId int64, followIds ...int64) (map[int6
 	return res, nil
 }
 
+// IsMyFollower 给定用户中哪些是已通过的粉丝，只查询给定的用户以免加载全部粉丝
+func (s *userRelationSrv) IsMyFollower(userId int64, followerIds ...int64) (map[int64]bool, error) {
+	size := len(followerIds)
+	res := make(map[int64]bool, size)
+	if size == 0 {
+		return res, nil
+	}
+	var ids []int64
+	if err := s.db.Table(_following_).Where("follow_id=? AND user_id IN ? AND status=2 AND is_del=0", userId, followerIds).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, followerId := range followerIds {
+		res[followerId] = false
+	}
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
 func (s *userManageSrv) SearchUserReactions(reactorUserID, targetUserID, reactionTypeID int64, limit, offset int) ([]*ms.UserReactionWithUser, int64, error) {
 	reaction := &dbr.UserReaction{}
 	rawReactions, total, err := reaction.SearchUserReactions(s.db, reactorUserID, targetUserID, reactionTypeID, limit, offset)
@@ -356,33 +379,7 @@ func (s *userManageSrv) GetGlobalReactionTimeline(limit, offset int) ([]*cs.User
 		return nil, 0, err
 	}
 	
-	// Convert to cs.UserReactionWithBothUsers format
-	reactionList := make([]*cs.UserReactionWithBothUsers, 0, len(rawReactions))
-	for _, raw := range rawReactions {
-		reactionList = append(reactionList, &cs.UserReactionWithBothUsers{
-			ReactionID:     raw.ReactionID,
-			ReactorUser: &cs.UserFormated{
-				ID:       raw.ReactorUserID,
-				Nickname: raw.ReactorNickname,
-				Username: raw.ReactorUsername,
-				Avatar:   raw.ReactorAvatar,
-				IsAdmin:  raw.ReactorIsAdmin,
-			},
-			TargetUser: &cs.UserFormated{
-				ID:       raw.TargetUserID,
-				Nickname: raw.TargetNickname,
-				Username: raw.TargetUsername,
-				Avatar:   raw.TargetAvatar,
-				IsAdmin:  raw.TargetIsAdmin,
-			},
-			ReactionTypeID: raw.ReactionTypeID,
-			ReactionName:   raw.ReactionName,
-			ReactionIcon:   raw.ReactionIcon,
-			CreatedOn:      raw.CreatedOn,
-		})
-	}
-	
-	return reactionList, total, nil
+	return reactionsWithBothUsersFrom(rawReactions), total, nil
 }
 
 func (s *userManageSrv) GetUserReactionTimeline(userID int64, limit, offset int) ([]*cs.UserReactionWithBothUsers, int64, error) {
@@ -392,7 +389,33 @@ func (s *userManageSrv) GetUserReactionTimeline(userID int64, limit, offset int)
 		return nil, 0, err
 	}
 	
-	// Convert to cs.UserReactionWithBothUsers format
+	return reactionsWithBothUsersFrom(rawReactions), total, nil
+}
+
+func (s *userManageSrv) GetGlobalReactionTimelineByCursor(cursor *cs.PageCursor, limit int) ([]*cs.UserReactionWithBothUsers, *cs.PageCursor, error) {
+	return s.reactionTimelineByCursor(0, cursor, limit)
+}
+
+func (s *userManageSrv) GetUserReactionTimelineByCursor(userID int64, cursor *cs.PageCursor, limit int) ([]*cs.UserReactionWithBothUsers, *cs.PageCursor, error) {
+	return s.reactionTimelineByCursor(userID, cursor, limit)
+}
+
+func (s *userManageSrv) reactionTimelineByCursor(userID int64, cursor *cs.PageCursor, limit int) ([]*cs.UserReactionWithBothUsers, *cs.PageCursor, error) {
+	rawReactions, err := (&dbr.UserReaction{}).ReactionTimelineByCursor(s.db, userID, cursor, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	var next *cs.PageCursor
+	if len(rawReactions) > limit {
+		rawReactions = rawReactions[:limit]
+		last := rawReactions[limit-1]
+		next = &cs.PageCursor{Value: last.CreatedOn, ID: last.ReactionID}
+	}
+	return reactionsWithBothUsersFrom(rawReactions), next, nil
+}
+
+// reactionsWithBothUsersFrom convert to cs.UserReactionWithBothUsers format
+func reactionsWithBothUsersFrom(rawReactions []*dbr.ReactionWithBothUsersData) []*cs.UserReactionWithBothUsers {
 	reactionList := make([]*cs.UserReactionWithBothUsers, 0, len(rawReactions))
 	for _, raw := range rawReactions {
 		reactionList = append(reactionList, &cs.UserReactionWithBothUsers{
@@ -418,5 +441,5 @@ func (s *userManageSrv) GetUserReactionTimeline(userID int64, limit, offset int)
 		})
 	}
 	
-	return reactionList, total, nil
+	return reactionList
 }
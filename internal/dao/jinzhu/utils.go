@@ -5,6 +5,8 @@
 package jinzhu
 
 import (
+	"strings"
+
 	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 	"gorm.io/gorm"
@@ -78,3 +80,15 @@ func getUsersByIDs(db *gorm.DB, ids []int64) ([]*dbr.User, error) {
 		"id IN ?": ids,
 	}, 0, 0)
 }
+
+// containsPattern 转义LIKE通配符后生成包含关键词的匹配模式
+func containsPattern(keyword string) string {
+	keyword = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword)
+	return "%" + keyword + "%"
+}
+
+// prefixPattern 转义LIKE通配符后生成以关键词开头的匹配模式
+func prefixPattern(keyword string) string {
+	keyword = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword)
+	return keyword + "%"
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/pkg/blevex"
+	"github.com/rocboss/paopao-ce/pkg/json"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ core.TweetSearchService = (*bleveTweetSearchServant)(nil)
+	_ core.VersionInfo        = (*bleveTweetSearchServant)(nil)
+)
+
+type bleveTweetSearchServant struct {
+	tweetSearchFilter
+
+	store     *blevex.Store
+	indexName string
+}
+
+func (s *bleveTweetSearchServant) Name() string {
+	return "Bleve"
+}
+
+func (s *bleveTweetSearchServant) Version() *semver.Version {
+	return semver.MustParse("v0.1.0")
+}
+
+func (s *bleveTweetSearchServant) IndexName() string {
+	return s.indexName
+}
+
+func (s *bleveTweetSearchServant) AddDocuments(data []core.TsDocItem, _primaryKey ...string) (bool, error) {
+	docs := blevePostDocs(data)
+	if len(docs) == 0 {
+		return true, nil
+	}
+	if err := s.store.Write(s.indexName, docs); err != nil {
+		logrus.Errorf("bleveTweetSearchServant.AddDocuments error: %s", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *bleveTweetSearchServant) DeleteDocuments(identifiers []string) error {
+	return s.store.Remove(s.indexName, identifiers)
+}
+
+func (s *bleveTweetSearchServant) Search(user *ms.User, q *core.QueryReq, offset, limit int) (resp *core.QueryResp, err error) {
+	var match query.Query
+	if q.Type == core.SearchTypeDefault && q.Query != "" {
+		mq := bleve.NewMatchPhraseQuery(q.Query)
+		mq.SetField("content")
+		match = mq
+	} else if q.Type == core.SearchTypeTag && q.Query != "" {
+		tq := bleve.NewTermQuery(q.Query)
+		tq.SetField("tags")
+		match = tq
+	} else {
+		match = bleve.NewMatchAllQuery()
+	}
+	if filter := s.filterQuery(user); filter != nil {
+		match = bleve.NewConjunctionQuery(match, filter)
+	}
+	request := bleve.NewSearchRequestOptions(match, limit, offset, false)
+	request.SortBy([]string{"-is_top", "-latest_replied_on"})
+	request.Fields = []string{blevex.SourceField}
+
+	res, err := s.store.Search(s.indexName, request)
+	if err != nil {
+		logrus.Errorf("bleveTweetSearchServant.search searchType:%s query:%s error:%v", q.Type, q.Query, err)
+		return
+	}
+	if resp, err = s.postsFrom(res); err != nil {
+		return
+	}
+
+	logrus.Debugf("bleveTweetSearchServant.Search type:%s query:%s resp Hits:%d NbHits:%d offset: %d limit:%d ", q.Type, q.Query, len(resp.Items), resp.Total, offset, limit)
+	return
+}
+
+// filterQuery 可见性过滤条件，user_id 为参与者数组，匹配其中任一项
+func (s *bleveTweetSearchServant) filterQuery(user *ms.User) query.Query {
+	scope := s.visibleScope(user)
+	if scope == nil {
+		return nil
+	}
+	public := bleveNumericTerm("visibility", float64(core.PostVisitPublic))
+	if scope.userId == 0 {
+		return public
+	}
+	return bleve.NewDisjunctionQuery(
+		public,
+		bleve.NewConjunctionQuery(bleveNumericTerm("visibility", float64(core.PostVisitFriend)), bleveNumericTerms("host_id", scope.friendIds)),
+		bleve.NewConjunctionQuery(bleveNumericTerm("visibility", float64(core.PostVisitFollowing)), bleveNumericTerms("host_id", scope.followIds)),
+		bleve.NewConjunctionQuery(bleveNumericTerm("visibility", float64(core.PostVisitPrivate)), bleveNumericTerm("user_id", float64(scope.userId))),
+	)
+}
+
+func (s *bleveTweetSearchServant) postsFrom(res *bleve.SearchResult) (*core.QueryResp, error) {
+	posts := make([]*ms.PostFormated, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		source, _ := hit.Fields[blevex.SourceField].(string)
+		item := &ms.PostFormated{}
+		if err := json.Unmarshal([]byte(source), item); err != nil {
+			return nil, err
+		}
+		posts = append(posts, item)
+	}
+	return &core.QueryResp{
+		Items: posts,
+		Total: int64(res.Total),
+	}, nil
+}
+
+func blevePostDocs(data []core.TsDocItem) []map[string]any {
+	docs := make([]map[string]any, 0, len(data))
+	for _, d := range data {
+		source, err := json.Marshal(d.Post.Format())
+		if err != nil {
+			logrus.Warnf("marshal post[%d] bleve source error: %s", d.Post.ID, err)
+			continue
+		}
+		var tags []string
+		if d.Post.Tags != "" {
+			tags = strings.Split(d.Post.Tags, ",")
+		}
+		docs = append(docs, map[string]any{
+			"id":                d.Post.ID,
+			"user_id":           d.Post.UserID,
+			"host_id":           d.Post.GetHostID(),
+			"visibility":        d.Post.Visibility,
+			"is_top":            d.Post.IsTop,
+			"latest_replied_on": d.Post.LatestRepliedOn,
+			"content":           d.Content,
+			"tags":              tags,
+			blevex.SourceField:  string(source),
+		})
+	}
+	return docs
+}
+
+func bleveNumericTerm(field string, value float64) query.Query {
+	inclusive := true
+	q := bleve.NewNumericRangeInclusiveQuery(&value, &value, &inclusive, &inclusive)
+	q.SetField(field)
+	return q
+}
+
+func bleveNumericTerms(field string, values []int64) query.Query {
+	queries := make([]query.Query, 0, len(values))
+	for _, value := range values {
+		queries = append(queries, bleveNumericTerm(field, float64(value)))
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// bleveIndexMapping 各索引的字段映射，文本使用cjk分词以支持中文检索
+func bleveIndexMapping(index cs.SearchIndex) mapping.IndexMapping {
+	im := bleve.NewIndexMapping()
+	im.DefaultAnalyzer = cjk.AnalyzerName
+	im.StoreDynamic = false
+	im.DocValuesDynamic = false
+
+	source := bleve.NewTextFieldMapping()
+	source.Index = false
+	source.IncludeInAll = false
+	source.IncludeTermVectors = false
+	source.DocValues = false
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt(blevex.SourceField, source)
+	if index == cs.SearchIndexPost {
+		doc.Dynamic = false
+		for _, field := range []string{"id", "user_id", "host_id", "visibility", "is_top", "latest_replied_on"} {
+			doc.AddFieldMappingsAt(field, bleve.NewNumericFieldMapping())
+		}
+		content := bleve.NewTextFieldMapping()
+		content.Analyzer = cjk.AnalyzerName
+		doc.AddFieldMappingsAt("content", content)
+		tags := bleve.NewTextFieldMapping()
+		tags.Analyzer = keyword.Name
+		doc.AddFieldMappingsAt("tags", tags)
+	} else {
+		for _, field := range entitySearchableFields(index) {
+			text := bleve.NewTextFieldMapping()
+			text.Analyzer = cjk.AnalyzerName
+			doc.AddFieldMappingsAt(field, text)
+		}
+	}
+	im.DefaultMapping = doc
+	return im
+}
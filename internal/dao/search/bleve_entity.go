@@ -0,0 +1,72 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/blevex"
+	"github.com/rocboss/paopao-ce/pkg/json"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ entityIndexer = (*bleveEntityIndexer)(nil)
+)
+
+type bleveEntityIndexer struct {
+	store   *blevex.Store
+	indexes map[cs.SearchIndex]string
+}
+
+func (s *bleveEntityIndexer) upsert(index cs.SearchIndex, docs []map[string]any) error {
+	if err := s.store.Write(s.indexes[index], docs); err != nil {
+		logrus.Errorf("bleveEntityIndexer.upsert index:%s error: %s", index, err)
+		return err
+	}
+	return nil
+}
+
+func (s *bleveEntityIndexer) remove(index cs.SearchIndex, ids []string) error {
+	if err := s.store.Remove(s.indexes[index], ids); err != nil {
+		logrus.Errorf("bleveEntityIndexer.remove index:%s error: %s", index, err)
+		return err
+	}
+	return nil
+}
+
+func (s *bleveEntityIndexer) query(index cs.SearchIndex, q string, offset, limit int) ([]any, int64, error) {
+	var match query.Query
+	if q == "" {
+		match = bleve.NewMatchAllQuery()
+	} else {
+		fields := entitySearchableFields(index)
+		queries := make([]query.Query, 0, len(fields))
+		for _, field := range fields {
+			mq := bleve.NewMatchQuery(q)
+			mq.SetField(field)
+			queries = append(queries, mq)
+		}
+		match = bleve.NewDisjunctionQuery(queries...)
+	}
+	request := bleve.NewSearchRequestOptions(match, limit, offset, false)
+	request.Fields = []string{blevex.SourceField}
+	res, err := s.store.Search(s.indexes[index], request)
+	if err != nil {
+		return nil, 0, err
+	}
+	hits := make([]any, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		source, _ := hit.Fields[blevex.SourceField].(string)
+		var doc map[string]any
+		if err = json.Unmarshal([]byte(source), &doc); err != nil {
+			return nil, 0, err
+		}
+		delete(doc, blevex.SourceField)
+		hits = append(hits, doc)
+	}
+	return hits, int64(res.Total), nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/blevex"
+)
+
+var (
+	_ indexAdmin = (*bleveIndexAdmin)(nil)
+)
+
+type bleveIndexAdmin struct {
+	store  *blevex.Store
+	prefix string
+}
+
+func (s *bleveIndexAdmin) liveName(index cs.SearchIndex) string {
+	return bleveLiveName(s.prefix, index)
+}
+
+func (s *bleveIndexAdmin) create(index cs.SearchIndex, name string) error {
+	return s.store.Create(name, bleveIndexMapping(index))
+}
+
+func (s *bleveIndexAdmin) write(name string, docs []map[string]any) error {
+	return s.store.Write(name, docs)
+}
+
+func (s *bleveIndexAdmin) swap(index cs.SearchIndex, shadow string) error {
+	return s.store.Swap(s.liveName(index), shadow)
+}
+
+func (s *bleveIndexAdmin) drop(name string) error {
+	return s.store.Drop(name)
+}
+
+func (s *bleveIndexAdmin) count(name string) (int64, error) {
+	return s.store.Count(name)
+}
+
+func (s *bleveIndexAdmin) postDocs(data []core.TsDocItem) []map[string]any {
+	return blevePostDocs(data)
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"sync"
+
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/blevex"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_bleveStore     *blevex.Store
+	_onceBleveStore sync.Once
+)
+
+// mustBleveStore 动态/用户/房间/话题索引共用一个实例，索引目录被服务进程独占
+func mustBleveStore() *blevex.Store {
+	_onceBleveStore.Do(func() {
+		s := conf.BleveSetting
+		store, err := blevex.NewStore(s.Path)
+		if err != nil {
+			logrus.Fatalf("create bleve index directory %s error: %s", s.Path, err)
+		}
+		for _, index := range cs.SearchIndexes {
+			name := bleveLiveName(s.Index, index)
+			if err := store.Open(name, bleveIndexMapping(index)); err != nil {
+				logrus.Fatalf("open bleve index %s error: %s", name, err)
+			}
+		}
+		_bleveStore = store
+	})
+	return _bleveStore
+}
+
+func bleveLiveName(prefix string, index cs.SearchIndex) string {
+	if index == cs.SearchIndexPost {
+		return prefix
+	}
+	return entityIndexName(prefix, index)
+}
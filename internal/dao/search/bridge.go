@@ -5,10 +5,13 @@
 package search
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/pkg/json"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,16 +19,11 @@ var (
 	_ core.TweetSearchService = (*bridgeTweetSearchServant)(nil)
 )
 
-type documents struct {
-	primaryKey  []string
-	docItems    []core.TsDocItem
-	identifiers []string
-}
-
+// bridgeTweetSearchServant 文档变更先写入搜索发件箱，由后台处理写入搜索引擎，
+// 进程崩溃或搜索引擎不可用时变更不会丢失
 type bridgeTweetSearchServant struct {
-	ts               core.TweetSearchService
-	updateDocsCh     chan *documents
-	updateDocsTempCh chan *documents
+	ts     core.TweetSearchService
+	outbox core.SearchOutboxService
 }
 
 func (s *bridgeTweetSearchServant) IndexName() string {
@@ -33,68 +31,51 @@ func (s *bridgeTweetSearchServant) IndexName() string {
 }
 
 func (s *bridgeTweetSearchServant) AddDocuments(data []core.TsDocItem, primaryKey ...string) (bool, error) {
-	s.updateDocs(&documents{
-		primaryKey: primaryKey,
-		docItems:   data,
-	})
+	items := make([]*ms.SearchOutbox, 0, len(data))
+	for _, doc := range data {
+		payload, err := json.Marshal(doc)
+		if err != nil {
+			return false, err
+		}
+		items = append(items, &ms.SearchOutbox{
+			IndexName: cs.SearchIndexPost,
+			DocID:     doc.Post.ID,
+			Op:        cs.SearchOpUpsert,
+			Payload:   string(payload),
+			Version:   doc.Post.ModifiedOn,
+		})
+	}
+	if err := s.outbox.AppendSearchOutbox(items...); err != nil {
+		// 发件箱不可用时退回直接写入
+		logrus.Errorf("bridgeTweetSearchServant.AddDocuments append outbox occurs error: %s", err)
+		return s.ts.AddDocuments(data, primaryKey...)
+	}
 	return true, nil
 }
 
 func (s *bridgeTweetSearchServant) DeleteDocuments(identifiers []string) error {
-	s.updateDocs(&documents{
-		identifiers: identifiers,
-	})
-	return nil
-}
-
-func (s *bridgeTweetSearchServant) Search(user *ms.User, q *core.QueryReq, offset, limit int) (*core.QueryResp, error) {
-	return s.ts.Search(user, q, offset, limit)
-}
-
-func (s *bridgeTweetSearchServant) updateDocs(doc *documents) {
-	select {
-	case s.updateDocsCh <- doc:
-		logrus.Debugln("addDocuments send documents by updateDocsCh chan")
-	default:
-		select {
-		case s.updateDocsTempCh <- doc:
-			logrus.Debugln("addDocuments send documents by updateDocsTempCh chan")
-		default:
-			go func() {
-				s.handleUpdate(doc)
-
-				// watch updateDocsTempch to continue handle update if needed.
-				// cancel loop if no item had watched in 1 minute.
-				for count := 0; count < 60; count++ {
-					select {
-					case item := <-s.updateDocsTempCh:
-						// reset count to continue handle docs update
-						count = 0
-						s.handleUpdate(item)
-					default:
-						// sleeping to wait docs item pass over to handle
-						time.Sleep(1 * time.Second)
-					}
-				}
-			}()
+	// 删除晚于动态的最后一次修改
+	version := time.Now().Unix()
+	items := make([]*ms.SearchOutbox, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		id, err := strconv.ParseInt(identifier, 10, 64)
+		if err != nil {
+			return err
 		}
+		items = append(items, &ms.SearchOutbox{
+			IndexName: cs.SearchIndexPost,
+			DocID:     id,
+			Op:        cs.SearchOpDelete,
+			Version:   version,
+		})
 	}
-}
-
-func (s *bridgeTweetSearchServant) startUpdateDocs() {
-	for doc := range s.updateDocsCh {
-		s.handleUpdate(doc)
+	if err := s.outbox.AppendSearchOutbox(items...); err != nil {
+		logrus.Errorf("bridgeTweetSearchServant.DeleteDocuments append outbox occurs error: %s", err)
+		return s.ts.DeleteDocuments(identifiers)
 	}
+	return nil
 }
 
-func (s *bridgeTweetSearchServant) handleUpdate(item *documents) {
-	if len(item.docItems) > 0 {
-		if _, err := s.ts.AddDocuments(item.docItems, item.primaryKey...); err != nil {
-			logrus.Errorf("addDocuments occurs error: %v", err)
-		}
-	} else if len(item.identifiers) > 0 {
-		if err := s.ts.DeleteDocuments(item.identifiers); err != nil {
-			logrus.Errorf("deleteDocuments occurs error: %s", err)
-		}
-	}
+func (s *bridgeTweetSearchServant) Search(user *ms.User, q *core.QueryReq, offset, limit int) (*core.QueryResp, error) {
+	return s.ts.Search(user, q, offset, limit)
 }
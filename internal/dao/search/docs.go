@@ -0,0 +1,43 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+)
+
+// TweetDocItems 生成动态的搜索文档，可搜索内容为标题、文本以及音频转写文本
+func TweetDocItems(ds core.DataService, posts []*ms.Post) ([]core.TsDocItem, error) {
+	if len(posts) == 0 {
+		return nil, nil
+	}
+	postIds := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		postIds = append(postIds, post.ID)
+	}
+	contents, err := ds.GetPostContentsByIDs(postIds)
+	if err != nil {
+		return nil, err
+	}
+	transcripts, err := ds.GetTranscriptTexts(postIds)
+	if err != nil {
+		return nil, err
+	}
+	texts := make(map[int64]string, len(posts))
+	for _, content := range contents {
+		if content.Type == ms.ContentTypeText || content.Type == ms.ContentTypeTitle {
+			texts[content.PostID] += content.Content + "\n"
+		}
+	}
+	docs := make([]core.TsDocItem, 0, len(posts))
+	for _, post := range posts {
+		docs = append(docs, core.TsDocItem{
+			Post:    post,
+			Content: texts[post.ID] + transcripts[post.ID],
+		})
+	}
+	return docs, nil
+}
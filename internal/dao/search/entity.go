@@ -0,0 +1,120 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"strconv"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/json"
+)
+
+var (
+	_ core.EntitySearchService = (*entitySearchServant)(nil)
+)
+
+// entityIndexer 用户/房间/话题索引在具体搜索引擎上的读写
+type entityIndexer interface {
+	upsert(index cs.SearchIndex, docs []map[string]any) error
+	remove(index cs.SearchIndex, ids []string) error
+	query(index cs.SearchIndex, q string, offset, limit int) ([]any, int64, error)
+}
+
+type entitySearchServant struct {
+	indexer entityIndexer
+}
+
+func (s *entitySearchServant) UpsertUsers(docs []*cs.UserSearchDoc) error {
+	return s.upsert(cs.SearchIndexUser, docs)
+}
+
+func (s *entitySearchServant) UpsertRooms(docs []*cs.RoomSearchDoc) error {
+	return s.upsert(cs.SearchIndexRoom, docs)
+}
+
+func (s *entitySearchServant) UpsertTopics(docs []*cs.TopicSearchDoc) error {
+	return s.upsert(cs.SearchIndexTopic, docs)
+}
+
+func (s *entitySearchServant) DeleteDocuments(index cs.SearchIndex, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	identifiers := make([]string, 0, len(ids))
+	for _, id := range ids {
+		identifiers = append(identifiers, strconv.FormatInt(id, 10))
+	}
+	return s.indexer.remove(index, identifiers)
+}
+
+func (s *entitySearchServant) SearchUsers(q string, offset, limit int) ([]*cs.UserSearchDoc, int64, error) {
+	return searchDocs[cs.UserSearchDoc](s.indexer, cs.SearchIndexUser, q, offset, limit)
+}
+
+func (s *entitySearchServant) SearchRooms(q string, offset, limit int) ([]*cs.RoomSearchDoc, int64, error) {
+	return searchDocs[cs.RoomSearchDoc](s.indexer, cs.SearchIndexRoom, q, offset, limit)
+}
+
+func (s *entitySearchServant) SearchTopics(q string, offset, limit int) ([]*cs.TopicSearchDoc, int64, error) {
+	return searchDocs[cs.TopicSearchDoc](s.indexer, cs.SearchIndexTopic, q, offset, limit)
+}
+
+func (s *entitySearchServant) upsert(index cs.SearchIndex, docs any) error {
+	items, err := docMaps(docs)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+	return s.indexer.upsert(index, items)
+}
+
+// docMaps 文档结构体的json字段即为索引字段
+func docMaps(docs any) ([]map[string]any, error) {
+	raw, err := json.Marshal(docs)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]any
+	if err = json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func searchDocs[T any](indexer entityIndexer, index cs.SearchIndex, q string, offset, limit int) ([]*T, int64, error) {
+	hits, total, err := indexer.query(index, q, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := make([]*T, 0, len(hits))
+	for _, hit := range hits {
+		raw, err := json.Marshal(hit)
+		if err != nil {
+			return nil, 0, err
+		}
+		doc := new(T)
+		if err = json.Unmarshal(raw, doc); err != nil {
+			return nil, 0, err
+		}
+		res = append(res, doc)
+	}
+	return res, total, nil
+}
+
+// entitySearchableFields 各索引参与全文检索的字段
+func entitySearchableFields(index cs.SearchIndex) []string {
+	switch index {
+	case cs.SearchIndexUser:
+		return []string{"username", "nickname", "category_names"}
+	case cs.SearchIndexRoom:
+		return []string{"topics", "category_names", "host_username", "host_nickname"}
+	default:
+		return []string{"tag"}
+	}
+}
+
+func entityIndexName(prefix string, index cs.SearchIndex) string {
+	return prefix + "-" + string(index)
+}
@@ -6,51 +6,46 @@ package search
 
 import (
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
-	"github.com/rocboss/paopao-ce/pkg/types"
+	"github.com/sirupsen/logrus"
 )
 
 type tweetSearchFilter struct {
 	ams core.AuthorizationManageService
+	urs core.UserRelationService
 }
 
-func (s *tweetSearchFilter) filterResp(user *ms.User, resp *core.QueryResp) {
-	// 管理员不过滤
-	if user != nil && user.IsAdmin {
-		return
-	}
+// visibleScope 浏览者可见的动态范围，由搜索引擎在查询时过滤，规则与关注时间线一致:
+// 公开动态所有人可见；好友可见动态对作者及把浏览者加为好友的作者可见；
+// 关注可见动态另外对作者的关注者可见；私密动态对 user_id 中的所有参与者可见，
+// 即作者与对话动态的另一方。userId 为0表示未登录，只能看到公开动态
+type visibleScope struct {
+	userId    int64
+	friendIds []int64
+	followIds []int64
+}
 
-	var item *ms.PostFormated
-	items := resp.Items
-	latestIndex := len(items) - 1
+// visibleScope 拥有查看所有动态权限的用户不过滤，返回nil
+func (s *tweetSearchFilter) visibleScope(user *ms.User) *visibleScope {
 	if user == nil {
-		for i := 0; i <= latestIndex; i++ {
-			item = items[i]
-			if item.Visibility != core.PostVisitPublic {
-				items[i] = items[latestIndex]
-				items = items[:latestIndex]
-				resp.Total--
-				latestIndex--
-				i--
-			}
-		}
-	} else {
-		var cutFriend, cutPrivate bool
-		friendFilter := s.ams.BeFriendFilter(user.ID)
-		friendFilter[user.ID] = types.Empty{}
-		for i := 0; i <= latestIndex; i++ {
-			item = items[i]
-			cutFriend = (item.Visibility == core.PostVisitFriend && !friendFilter.IsFriend(item.GetHostID()))
-			cutPrivate = (item.Visibility == core.PostVisitPrivate && user.ID != item.GetHostID())
-			if cutFriend || cutPrivate {
-				items[i] = items[latestIndex]
-				items = items[:latestIndex]
-				resp.Total--
-				latestIndex--
-				i--
-			}
-		}
+		return &visibleScope{}
+	}
+	if s.ams.HasPermission(user, cs.PermTweetViewAll) {
+		return nil
+	}
+	friendIds, err := s.ams.BeFriendIds(user.ID)
+	if err != nil {
+		logrus.Warnf("tweetSearchFilter get be friend ids of user[%d] error: %s", user.ID, err)
+	}
+	followIds, err := s.urs.MyFollowIds(user.ID)
+	if err != nil {
+		logrus.Warnf("tweetSearchFilter get follow ids of user[%d] error: %s", user.ID, err)
+	}
+	friendIds = append(friendIds, user.ID)
+	return &visibleScope{
+		userId:    user.ID,
+		friendIds: friendIds,
+		followIds: append(followIds, friendIds...),
 	}
-
-	resp.Items = items
 }
@@ -6,6 +6,7 @@ package search
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -24,11 +25,8 @@ var (
 type meiliTweetSearchServant struct {
 	tweetSearchFilter
 
-	client        *meilisearch.Client
-	index         *meilisearch.Index
-	publicFilter  string
-	privateFilter string
-	friendFilter  string
+	client *meilisearch.Client
+	index  *meilisearch.Index
 }
 
 type postInfo struct {
@@ -96,7 +94,6 @@ func (s *meiliTweetSearchServant) Search(user *ms.User, q *core.QueryReq, offset
 	}
 
 	logrus.Debugf("meiliTweetSearchServant.Search type:%s query:%s resp Hits:%d NbHits:%d offset: %d limit:%d ", q.Type, q.Query, len(resp.Items), resp.Total, offset, limit)
-	s.filterResp(user, resp)
 	return
 }
 
@@ -166,15 +163,25 @@ func (s *meiliTweetSearchServant) queryAny(user *ms.User, offset, limit int) (*c
 }
 
 func (s *meiliTweetSearchServant) filterList(user *ms.User) string {
-	if user == nil {
-		return s.publicFilter
-	}
-
-	if user.IsAdmin {
+	scope := s.visibleScope(user)
+	if scope == nil {
 		return ""
 	}
+	public := fmt.Sprintf("visibility = %d", core.PostVisitPublic)
+	if scope.userId == 0 {
+		return public
+	}
+	// user_id 为参与者数组，等值过滤匹配其中任一项
+	return fmt.Sprintf("%s OR (visibility = %d AND host_id IN %s) OR (visibility = %d AND host_id IN %s) OR (visibility = %d AND user_id = %d)",
+		public, core.PostVisitFriend, meiliIdList(scope.friendIds), core.PostVisitFollowing, meiliIdList(scope.followIds), core.PostVisitPrivate, scope.userId)
+}
 
-	return fmt.Sprintf("%s OR %s OR (%s%d)", s.publicFilter, s.friendFilter, s.privateFilter, user.ID)
+func meiliIdList(ids []int64) string {
+	items := make([]string, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, strconv.FormatInt(id, 10))
+	}
+	return "[" + strings.Join(items, ", ") + "]"
 }
 
 func (s *meiliTweetSearchServant) postsFrom(resp *meilisearch.SearchResponse) (*core.QueryResp, error) {
@@ -212,11 +219,16 @@ func (s *meiliTweetSearchServant) postsFrom(resp *meilisearch.SearchResponse) (*
 }
 
 func (s *meiliTweetSearchServant) toDocs(data []core.TsDocItem) []map[string]any {
+	return meiliPostDocs(data)
+}
+
+func meiliPostDocs(data []core.TsDocItem) []map[string]any {
 	docs := make([]map[string]any, 0, len(data))
 	for _, d := range data {
 		docs = append(docs, map[string]any{
 			"id":                d.Post.ID,
-			"user_id":           d.Post.GetHostID(),
+			"user_id":           d.Post.UserID,
+			"host_id":           d.Post.GetHostID(),
 			"comment_count":     d.Post.CommentCount,
 			"collection_count":  d.Post.CollectionCount,
 			"upvote_count":      d.Post.UpvoteCount,
@@ -234,3 +246,27 @@ func (s *meiliTweetSearchServant) toDocs(data []core.TsDocItem) []map[string]any
 	}
 	return docs
 }
+
+// createMeiliPostIndex 创建动态索引并设置检索、排序与过滤字段
+func createMeiliPostIndex(client *meilisearch.Client, uid string) error {
+	if _, err := client.CreateIndex(&meilisearch.IndexConfig{
+		Uid:        uid,
+		PrimaryKey: "id",
+	}); err != nil {
+		return err
+	}
+	updateMeiliPostSettings(client, uid)
+	return nil
+}
+
+// updateMeiliPostSettings 已有索引启动时也更新一次，以便新增的过滤字段生效
+func updateMeiliPostSettings(client *meilisearch.Client, uid string) {
+	settings := meilisearch.Settings{
+		SearchableAttributes: []string{"content", "tags"},
+		SortableAttributes:   []string{"is_top", "latest_replied_on"},
+		FilterableAttributes: []string{"tags", "visibility", "user_id", "host_id"},
+	}
+	if _, err := client.Index(uid).UpdateSettings(&settings); err != nil {
+		logrus.Errorf("update meili settings error: %s", err)
+	}
+}
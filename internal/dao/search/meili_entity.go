@@ -0,0 +1,73 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ entityIndexer = (*meiliEntityIndexer)(nil)
+)
+
+type meiliEntityIndexer struct {
+	client  *meilisearch.Client
+	indexes map[cs.SearchIndex]*meilisearch.Index
+}
+
+func (s *meiliEntityIndexer) upsert(index cs.SearchIndex, docs []map[string]any) error {
+	if _, err := s.indexes[index].AddDocuments(docs); err != nil {
+		logrus.Errorf("meiliEntityIndexer.upsert index:%s error: %s", index, err)
+		return err
+	}
+	return nil
+}
+
+func (s *meiliEntityIndexer) remove(index cs.SearchIndex, ids []string) error {
+	if _, err := s.indexes[index].DeleteDocuments(ids); err != nil {
+		logrus.Errorf("meiliEntityIndexer.remove index:%s error: %s", index, err)
+		return err
+	}
+	return nil
+}
+
+func (s *meiliEntityIndexer) query(index cs.SearchIndex, q string, offset, limit int) ([]any, int64, error) {
+	resp, err := s.indexes[index].Search(q, &meilisearch.SearchRequest{
+		Offset: int64(offset),
+		Limit:  int64(limit),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Hits, resp.EstimatedTotalHits, nil
+}
+
+func (s *meiliEntityIndexer) createIndex(uid string, index cs.SearchIndex) {
+	if _, err := s.client.Index(uid).FetchInfo(); err == nil {
+		return
+	}
+	if err := createMeiliEntityIndex(s.client, uid, index); err != nil {
+		logrus.Errorf("create meili index %s error: %s", uid, err)
+	}
+}
+
+func createMeiliEntityIndex(client *meilisearch.Client, uid string, index cs.SearchIndex) error {
+	if _, err := client.CreateIndex(&meilisearch.IndexConfig{
+		Uid:        uid,
+		PrimaryKey: "id",
+	}); err != nil {
+		return err
+	}
+	settings := meilisearch.Settings{
+		SearchableAttributes: entitySearchableFields(index),
+		FilterableAttributes: []string{"categories"},
+	}
+	if _, err := client.Index(uid).UpdateSettings(&settings); err != nil {
+		logrus.Errorf("update meili index %s settings error: %s", uid, err)
+	}
+	return nil
+}
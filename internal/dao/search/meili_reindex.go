@@ -0,0 +1,84 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+var (
+	_ indexAdmin = (*meiliIndexAdmin)(nil)
+)
+
+type meiliIndexAdmin struct {
+	client *meilisearch.Client
+	prefix string
+}
+
+func (s *meiliIndexAdmin) liveName(index cs.SearchIndex) string {
+	if index == cs.SearchIndexPost {
+		return s.prefix
+	}
+	return entityIndexName(s.prefix, index)
+}
+
+func (s *meiliIndexAdmin) create(index cs.SearchIndex, name string) error {
+	if index == cs.SearchIndexPost {
+		return createMeiliPostIndex(s.client, name)
+	}
+	return createMeiliEntityIndex(s.client, name, index)
+}
+
+func (s *meiliIndexAdmin) write(name string, docs []map[string]any) error {
+	_, err := s.client.Index(name).AddDocuments(docs)
+	return err
+}
+
+// swap meili的索引交换是原子的，任务按入队顺序执行，交换时影子索引的文档已写入完成
+func (s *meiliIndexAdmin) swap(index cs.SearchIndex, shadow string) error {
+	task, err := s.client.SwapIndexes([]meilisearch.SwapIndexesParams{
+		{Indexes: []string{s.liveName(index), shadow}},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	res, err := s.client.WaitForTask(task.TaskUID, meilisearch.WaitParams{
+		Context:  ctx,
+		Interval: time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	if res.Status != meilisearch.TaskStatusSucceeded {
+		return fmt.Errorf("swap meili index %s failed: %s", shadow, res.Error.Message)
+	}
+	// 交换后影子索引名下为旧文档
+	return s.drop(shadow)
+}
+
+func (s *meiliIndexAdmin) drop(name string) error {
+	_, err := s.client.DeleteIndex(name)
+	return err
+}
+
+func (s *meiliIndexAdmin) count(name string) (int64, error) {
+	stats, err := s.client.Index(name).GetStats()
+	if err != nil {
+		return 0, err
+	}
+	return stats.NumberOfDocuments, nil
+}
+
+func (s *meiliIndexAdmin) postDocs(data []core.TsDocItem) []map[string]any {
+	return meiliPostDocs(data)
+}
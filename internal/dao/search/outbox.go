@@ -0,0 +1,280 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/pkg/json"
+	"github.com/sirupsen/logrus"
+)
+
+// searchOutbox 后台处理搜索发件箱，失败按指数退避重试，超过最大次数后标记为失败，
+// 文档写入由 workers 个工作者并发处理，写入速率不超过 maxQPS
+type searchOutbox struct {
+	ds         core.DataService
+	ts         core.TweetSearchService
+	es         core.EntitySearchService
+	interval   time.Duration
+	batch      int
+	maxRetries int
+	retention  int64
+	workers    int
+	maxQPS     int
+}
+
+// PushSync 记录需要按数据库当前状态同步的记录，由后台写入搜索索引
+func PushSync(ds core.SearchOutboxService, index cs.SearchIndex, ids ...int64) error {
+	items := make([]*ms.SearchOutbox, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, &ms.SearchOutbox{
+			IndexName: index,
+			DocID:     id,
+			Op:        cs.SearchOpSync,
+		})
+	}
+	return ds.AppendSearchOutbox(items...)
+}
+
+// PushDelete 记录需要从搜索索引中删除的文档，由后台删除，删除晚于文档的最后一次修改
+func PushDelete(ds core.SearchOutboxService, index cs.SearchIndex, ids ...int64) error {
+	version := time.Now().Unix()
+	items := make([]*ms.SearchOutbox, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, &ms.SearchOutbox{
+			IndexName: index,
+			DocID:     id,
+			Op:        cs.SearchOpDelete,
+			Version:   version,
+		})
+	}
+	return ds.AppendSearchOutbox(items...)
+}
+
+func (s *searchOutbox) start() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	lastPurge := time.Now()
+	for range ticker.C {
+		// 积压较多时连续处理
+		for {
+			begin := time.Now()
+			count := s.processDue()
+			if wait := time.Duration(count)*time.Second/time.Duration(s.maxQPS) - time.Since(begin); wait > 0 {
+				time.Sleep(wait)
+			}
+			if count < s.batch {
+				break
+			}
+		}
+		if time.Since(lastPurge) > time.Hour {
+			lastPurge = time.Now()
+			if err := s.ds.PurgeSearchOutbox(time.Now().Unix() - s.retention); err != nil {
+				logrus.Warnf("searchOutbox purge occurs error: %s", err)
+			}
+		}
+	}
+}
+
+// processDue 处理一批到期记录，返回领取的记录数
+func (s *searchOutbox) processDue() int {
+	now := time.Now().Unix()
+	// 领取后10个周期内未处理完成则允许其他实例重新领取
+	items, err := s.ds.ClaimSearchOutbox(now, int64(10*s.interval/time.Second)+1, s.batch)
+	if err != nil {
+		logrus.Warnf("searchOutbox claim occurs error: %s", err)
+		return 0
+	}
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		done []int64
+	)
+	syncs := make(map[cs.SearchIndex][]*ms.SearchOutbox)
+	docs, superseded := latestDocItems(items)
+	done = append(done, superseded...)
+	ch := make(chan *ms.SearchOutbox)
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range ch {
+				if err := s.handleLatest(item); err != nil {
+					s.retry(item, err)
+					continue
+				}
+				mu.Lock()
+				done = append(done, item.ID)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, item := range docs {
+		ch <- item
+	}
+	close(ch)
+	wg.Wait()
+	for _, item := range items {
+		if item.Op == cs.SearchOpSync {
+			syncs[item.IndexName] = append(syncs[item.IndexName], item)
+		}
+	}
+	for index, group := range syncs {
+		ids := make([]int64, 0, len(group))
+		for _, item := range group {
+			ids = append(ids, item.DocID)
+		}
+		if err = s.sync(index, ids); err != nil {
+			for _, item := range group {
+				s.retry(item, err)
+			}
+			continue
+		}
+		for _, item := range group {
+			done = append(done, item.ID)
+		}
+	}
+	if err = s.ds.FinishSearchOutbox(done...); err != nil {
+		logrus.Warnf("searchOutbox finish occurs error: %s", err)
+	}
+	return len(items)
+}
+
+// latestDocItems 同一文档只保留版本最新的记录，其余记录已被取代直接完成
+func latestDocItems(items []*ms.SearchOutbox) (latest []*ms.SearchOutbox, superseded []int64) {
+	type docKey struct {
+		index cs.SearchIndex
+		id    int64
+	}
+	indexes := make(map[docKey]int, len(items))
+	for _, item := range items {
+		if item.Op == cs.SearchOpSync {
+			continue
+		}
+		key := docKey{item.IndexName, item.DocID}
+		i, exist := indexes[key]
+		if !exist {
+			indexes[key] = len(latest)
+			latest = append(latest, item)
+			continue
+		}
+		if old := latest[i]; item.Version > old.Version || (item.Version == old.Version && item.ID > old.ID) {
+			latest[i] = item
+			superseded = append(superseded, old.ID)
+		} else {
+			superseded = append(superseded, item.ID)
+		}
+	}
+	return
+}
+
+// handleLatest 同一文档已写入更新版本时跳过，避免重试的旧快照覆盖新文档
+func (s *searchOutbox) handleLatest(item *ms.SearchOutbox) error {
+	superseded, err := s.ds.IsSearchOutboxSuperseded(item)
+	if err != nil || superseded {
+		return err
+	}
+	return s.handle(item)
+}
+
+func (s *searchOutbox) handle(item *ms.SearchOutbox) error {
+	if item.IndexName != cs.SearchIndexPost {
+		return fmt.Errorf("unsupported search outbox op %s for index %s", item.Op, item.IndexName)
+	}
+	id := strconv.FormatInt(item.DocID, 10)
+	switch item.Op {
+	case cs.SearchOpUpsert:
+		doc := core.TsDocItem{}
+		if err := json.Unmarshal([]byte(item.Payload), &doc); err != nil || doc.Post == nil {
+			return fmt.Errorf("invalid search outbox payload: %v", err)
+		}
+		_, err := s.ts.AddDocuments([]core.TsDocItem{doc}, id)
+		return err
+	case cs.SearchOpDelete:
+		return s.ts.DeleteDocuments([]string{id})
+	default:
+		return fmt.Errorf("unknown search outbox op: %s", item.Op)
+	}
+}
+
+func (s *searchOutbox) sync(index cs.SearchIndex, ids []int64) error {
+	switch index {
+	case cs.SearchIndexUser:
+		return SyncUsers(s.ds, s.es, ids...)
+	case cs.SearchIndexRoom:
+		return SyncRooms(s.ds, s.es, ids...)
+	case cs.SearchIndexTopic:
+		return SyncTopics(s.ds, s.es, ids...)
+	default:
+		return fmt.Errorf("unsupported search outbox sync for index %s", index)
+	}
+}
+
+func (s *searchOutbox) retry(item *ms.SearchOutbox, err error) {
+	item.Attempts++
+	item.LastError = err.Error()
+	if item.Attempts >= s.maxRetries {
+		item.Status = cs.SearchOutboxFailed
+		logrus.Errorf("searchOutbox give up %s %s[%d] after %d attempts: %s", item.Op, item.IndexName, item.DocID, item.Attempts, err)
+	} else {
+		backoff := s.interval << min(item.Attempts, 10)
+		if backoff > time.Hour {
+			backoff = time.Hour
+		}
+		item.NextRetryOn = time.Now().Add(backoff).Unix()
+	}
+	if err = s.ds.RetrySearchOutbox(item); err != nil {
+		logrus.Warnf("searchOutbox retry occurs error: %s", err)
+	}
+}
+
+func newSearchOutbox(ds core.DataService, ts core.TweetSearchService, es core.EntitySearchService) *searchOutbox {
+	s := conf.TweetSearchSetting
+	interval := s.OutboxInterval
+	if interval < time.Second {
+		interval = time.Second
+	}
+	batch := s.OutboxBatch
+	if batch < 10 {
+		batch = 10
+	} else if batch > 1000 {
+		batch = 1000
+	}
+	maxRetries := s.OutboxMaxRetries
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+	maxQPS := s.MaxUpdateQPS
+	if maxQPS < 10 {
+		maxQPS = 10
+	} else if maxQPS > 10000 {
+		maxQPS = 10000
+	}
+	workers := s.MinWorker
+	if workers < 5 {
+		workers = 5
+	} else if workers > 1000 {
+		workers = 1000
+	}
+	logrus.Debugf("use %d backend worker to update documents to search engine", workers)
+	return &searchOutbox{
+		ds:         ds,
+		ts:         ts,
+		es:         es,
+		interval:   interval,
+		batch:      batch,
+		maxRetries: maxRetries,
+		retention:  s.OutboxRetention,
+		workers:    workers,
+		maxQPS:     maxQPS,
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ core.SearchIndexManageService = (*searchIndexManager)(nil)
+)
+
+// indexAdmin 搜索引擎上的索引管理，用于在影子索引中重建后原子切换
+type indexAdmin interface {
+	liveName(index cs.SearchIndex) string
+	create(index cs.SearchIndex, name string) error
+	write(name string, docs []map[string]any) error
+	// swap 使在用索引名指向影子索引的文档，并删除旧文档
+	swap(index cs.SearchIndex, shadow string) error
+	drop(name string) error
+	count(name string) (int64, error)
+	postDocs(data []core.TsDocItem) []map[string]any
+}
+
+type searchIndexManager struct {
+	ds    core.DataService
+	admin indexAdmin
+}
+
+func (m *searchIndexManager) Reindex(index cs.SearchIndex, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	start := time.Now().Unix()
+	shadow := fmt.Sprintf("%s-%d", m.admin.liveName(index), start)
+	if err := m.admin.create(index, shadow); err != nil {
+		return 0, err
+	}
+	total, err := m.fill(index, shadow, batchSize)
+	if err == nil {
+		err = m.admin.swap(index, shadow)
+	}
+	if err != nil {
+		if xerr := m.admin.drop(shadow); xerr != nil {
+			logrus.Warnf("drop shadow index %s occurs error: %s", shadow, xerr)
+		}
+		return total, err
+	}
+	// 重建期间处理过的变更写入的是旧索引，切换后重新处理一次
+	replayed, err := m.ds.ReplaySearchOutbox(index, start)
+	if err != nil {
+		return total, err
+	}
+	logrus.Infof("reindex %s swapped to %s with %d documents, %d outbox changes replayed", index, shadow, total, replayed)
+	return total, nil
+}
+
+func (m *searchIndexManager) CheckDrift(index cs.SearchIndex) (*cs.SearchIndexDrift, error) {
+	var err error
+	drift := &cs.SearchIndexDrift{
+		Index:     index,
+		IndexName: m.admin.liveName(index),
+	}
+	if index == cs.SearchIndexPost {
+		_, drift.DbCount, err = m.ds.ListSyncSearchTweets(1, 0)
+	} else {
+		drift.DbCount, err = m.ds.CountSearchDocs(index)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if drift.IndexCount, err = m.admin.count(drift.IndexName); err != nil {
+		return nil, err
+	}
+	if drift.PendingCount, err = m.ds.CountSearchOutbox(index, cs.SearchOutboxPending); err != nil {
+		return nil, err
+	}
+	if drift.FailedCount, err = m.ds.CountSearchOutbox(index, cs.SearchOutboxFailed); err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
+
+func (m *searchIndexManager) fill(index cs.SearchIndex, name string, batchSize int) (int, error) {
+	total := 0
+	for offset := 0; ; offset += batchSize {
+		docs, size, err := m.page(index, batchSize, offset)
+		if err != nil {
+			return total, err
+		}
+		if len(docs) > 0 {
+			if err = m.admin.write(name, docs); err != nil {
+				return total, err
+			}
+		}
+		total += len(docs)
+		if size < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// page 获取一页文档，同时返回数据库记录数用于判断是否还有下一页
+func (m *searchIndexManager) page(index cs.SearchIndex, limit, offset int) ([]map[string]any, int, error) {
+	switch index {
+	case cs.SearchIndexPost:
+		posts, _, err := m.ds.ListSyncSearchTweets(limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		items, err := TweetDocItems(m.ds, posts)
+		if err != nil {
+			return nil, 0, err
+		}
+		return m.admin.postDocs(items), len(posts), nil
+	case cs.SearchIndexUser:
+		return pageDocs(m.ds.ListUserSearchDocs, limit, offset)
+	case cs.SearchIndexRoom:
+		return pageDocs(m.ds.ListRoomSearchDocs, limit, offset)
+	case cs.SearchIndexTopic:
+		return pageDocs(m.ds.ListTopicSearchDocs, limit, offset)
+	default:
+		return nil, 0, fmt.Errorf("unknown search index: %s", index)
+	}
+}
+
+func pageDocs[T any](list func(ids []int64, limit, offset int) ([]*T, error), limit, offset int) ([]map[string]any, int, error) {
+	docs, err := list(nil, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := docMaps(docs)
+	return items, len(docs), err
+}
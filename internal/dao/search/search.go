@@ -5,16 +5,15 @@
 package search
 
 import (
-	"fmt"
-
 	"github.com/meilisearch/meilisearch-go"
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/pkg/zinc"
 	"github.com/sirupsen/logrus"
 )
 
-func NewMeiliTweetSearchService(ams core.AuthorizationManageService) (core.TweetSearchService, core.VersionInfo) {
+func NewMeiliTweetSearchService(ams core.AuthorizationManageService, urs core.UserRelationService) (core.TweetSearchService, core.VersionInfo) {
 	s := conf.MeiliSetting
 	client := meilisearch.NewClient(meilisearch.ClientConfig{
 		Host:   s.Endpoint(),
@@ -23,77 +22,148 @@ func NewMeiliTweetSearchService(ams core.AuthorizationManageService) (core.Tweet
 
 	if _, err := client.Index(s.Index).FetchInfo(); err != nil {
 		logrus.Debugf("create meili index because fetch index info error: %v", err)
-		if _, err := client.CreateIndex(&meilisearch.IndexConfig{
-			Uid:        s.Index,
-			PrimaryKey: "id",
-		}); err == nil {
-			settings := meilisearch.Settings{
-				SearchableAttributes: []string{"content", "tags"},
-				SortableAttributes:   []string{"is_top", "latest_replied_on"},
-				FilterableAttributes: []string{"tags", "visibility", "user_id"},
-			}
-			if _, err = client.Index(s.Index).UpdateSettings(&settings); err != nil {
-				logrus.Errorf("update meili settings error: %s", err)
-			}
-		} else {
+		if err = createMeiliPostIndex(client, s.Index); err != nil {
 			logrus.Errorf("create meili index error: %s", err)
 		}
+	} else {
+		updateMeiliPostSettings(client, s.Index)
 	}
 
 	mts := &meiliTweetSearchServant{
 		tweetSearchFilter: tweetSearchFilter{
 			ams: ams,
+			urs: urs,
 		},
-		client:        client,
-		index:         client.Index(s.Index),
-		publicFilter:  fmt.Sprintf("visibility=%d", core.PostVisitPublic),
-		privateFilter: fmt.Sprintf("visibility=%d AND user_id=", core.PostVisitPrivate),
-		friendFilter:  fmt.Sprintf("visibility=%d", core.PostVisitFriend),
+		client: client,
+		index:  client.Index(s.Index),
 	}
 	return mts, mts
 }
 
-func NewZincTweetSearchService(ams core.AuthorizationManageService) (core.TweetSearchService, core.VersionInfo) {
+func NewZincTweetSearchService(ams core.AuthorizationManageService, urs core.UserRelationService) (core.TweetSearchService, core.VersionInfo) {
 	s := conf.ZincSetting
 	zts := &zincTweetSearchServant{
 		tweetSearchFilter: tweetSearchFilter{
 			ams: ams,
+			urs: urs,
 		},
-		indexName:     s.Index,
-		client:        zinc.NewClient(s.Endpoint(), s.User, s.Password),
-		publicFilter:  fmt.Sprintf("visibility:%d", core.PostVisitPublic),
-		privateFilter: fmt.Sprintf("visibility:%d AND user_id:%%d", core.PostVisitPrivate),
-		friendFilter:  fmt.Sprintf("visibility:%d", core.PostVisitFriend),
+		indexName: s.Index,
+		client:    zinc.NewClient(s.Endpoint(), s.User, s.Password),
 	}
-	zts.createIndex()
+	zts.createIndex(zts.indexName)
 
 	return zts, zts
 }
 
-func NewBridgeTweetSearchService(ts core.TweetSearchService) core.TweetSearchService {
-	capacity := conf.TweetSearchSetting.MaxUpdateQPS
-	if capacity < 10 {
-		capacity = 10
-	} else if capacity > 10000 {
-		capacity = 10000
+// entitySearchIndexes 用户/房间/话题搜索索引，索引名为 帖子索引名-类型
+var entitySearchIndexes = []cs.SearchIndex{
+	cs.SearchIndexUser,
+	cs.SearchIndexRoom,
+	cs.SearchIndexTopic,
+}
+
+func NewMeiliEntitySearchService() core.EntitySearchService {
+	s := conf.MeiliSetting
+	client := meilisearch.NewClient(meilisearch.ClientConfig{
+		Host:   s.Endpoint(),
+		APIKey: s.ApiKey,
+	})
+	indexer := &meiliEntityIndexer{
+		client:  client,
+		indexes: make(map[cs.SearchIndex]*meilisearch.Index, len(entitySearchIndexes)),
 	}
-	bts := &bridgeTweetSearchServant{
-		ts:               ts,
-		updateDocsCh:     make(chan *documents, capacity),
-		updateDocsTempCh: make(chan *documents, 100),
+	for _, index := range entitySearchIndexes {
+		uid := entityIndexName(s.Index, index)
+		indexer.createIndex(uid, index)
+		indexer.indexes[index] = client.Index(uid)
 	}
+	return &entitySearchServant{
+		indexer: indexer,
+	}
+}
 
-	numWorker := conf.TweetSearchSetting.MinWorker
-	if numWorker < 5 {
-		numWorker = 5
-	} else if numWorker > 1000 {
-		numWorker = 1000
+func NewZincEntitySearchService() core.EntitySearchService {
+	s := conf.ZincSetting
+	indexer := &zincEntityIndexer{
+		client:  zinc.NewClient(s.Endpoint(), s.User, s.Password),
+		indexes: make(map[cs.SearchIndex]string, len(entitySearchIndexes)),
+	}
+	for _, index := range entitySearchIndexes {
+		name := entityIndexName(s.Index, index)
+		indexer.createIndex(name, index)
+		indexer.indexes[index] = name
+	}
+	return &entitySearchServant{
+		indexer: indexer,
 	}
-	logrus.Debugf("use %d backend worker to update documents to search engine", numWorker)
-	// 启动文档更新器
-	for ; numWorker > 0; numWorker-- {
-		go bts.startUpdateDocs()
+}
+
+func NewMeiliSearchIndexManageService(ds core.DataService) core.SearchIndexManageService {
+	s := conf.MeiliSetting
+	return &searchIndexManager{
+		ds: ds,
+		admin: &meiliIndexAdmin{
+			client: meilisearch.NewClient(meilisearch.ClientConfig{
+				Host:   s.Endpoint(),
+				APIKey: s.ApiKey,
+			}),
+			prefix: s.Index,
+		},
 	}
+}
 
-	return bts
+func NewZincSearchIndexManageService(ds core.DataService) core.SearchIndexManageService {
+	s := conf.ZincSetting
+	return &searchIndexManager{
+		ds: ds,
+		admin: &zincIndexAdmin{
+			client: zinc.NewClient(s.Endpoint(), s.User, s.Password),
+			prefix: s.Index,
+		},
+	}
+}
+
+// NewBridgeTweetSearchService 动态与用户/房间/话题索引的变更都经由搜索发件箱写入，
+// 同时启动发件箱的后台处理
+func NewBridgeTweetSearchService(ds core.DataService, ts core.TweetSearchService, es core.EntitySearchService) core.TweetSearchService {
+	go newSearchOutbox(ds, ts, es).start()
+	return &bridgeTweetSearchServant{
+		ts:     ts,
+		outbox: ds,
+	}
+}
+
+func NewBleveTweetSearchService(ams core.AuthorizationManageService, urs core.UserRelationService) (core.TweetSearchService, core.VersionInfo) {
+	bts := &bleveTweetSearchServant{
+		tweetSearchFilter: tweetSearchFilter{
+			ams: ams,
+			urs: urs,
+		},
+		store:     mustBleveStore(),
+		indexName: conf.BleveSetting.Index,
+	}
+	return bts, bts
+}
+
+func NewBleveEntitySearchService() core.EntitySearchService {
+	indexer := &bleveEntityIndexer{
+		store:   mustBleveStore(),
+		indexes: make(map[cs.SearchIndex]string, len(entitySearchIndexes)),
+	}
+	for _, index := range entitySearchIndexes {
+		indexer.indexes[index] = entityIndexName(conf.BleveSetting.Index, index)
+	}
+	return &entitySearchServant{
+		indexer: indexer,
+	}
+}
+
+func NewBleveSearchIndexManageService(ds core.DataService) core.SearchIndexManageService {
+	return &searchIndexManager{
+		ds: ds,
+		admin: &bleveIndexAdmin{
+			store:  mustBleveStore(),
+			prefix: conf.BleveSetting.Index,
+		},
+	}
 }
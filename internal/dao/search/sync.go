@@ -0,0 +1,86 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// entityDocs 某类搜索文档的数据获取与索引写入
+type entityDocs[T any] struct {
+	index  cs.SearchIndex
+	list   func(ids []int64, limit, offset int) ([]*T, error)
+	idOf   func(*T) int64
+	upsert func([]*T) error
+}
+
+// SyncUsers 同步指定用户到搜索索引，已删除或封禁的用户从索引中移除
+func SyncUsers(ds core.EntitySearchSyncService, es core.EntitySearchService, ids ...int64) error {
+	return userDocs(ds, es).sync(es, ids)
+}
+
+// SyncRooms 同步指定房间到搜索索引，已删除的房间从索引中移除
+func SyncRooms(ds core.EntitySearchSyncService, es core.EntitySearchService, ids ...int64) error {
+	return roomDocs(ds, es).sync(es, ids)
+}
+
+// SyncTopics 同步指定话题到搜索索引，不再被引用的话题从索引中移除
+func SyncTopics(ds core.EntitySearchSyncService, es core.EntitySearchService, ids ...int64) error {
+	return topicDocs(ds, es).sync(es, ids)
+}
+
+func userDocs(ds core.EntitySearchSyncService, es core.EntitySearchService) *entityDocs[cs.UserSearchDoc] {
+	return &entityDocs[cs.UserSearchDoc]{
+		index:  cs.SearchIndexUser,
+		list:   ds.ListUserSearchDocs,
+		idOf:   func(d *cs.UserSearchDoc) int64 { return d.ID },
+		upsert: es.UpsertUsers,
+	}
+}
+
+func roomDocs(ds core.EntitySearchSyncService, es core.EntitySearchService) *entityDocs[cs.RoomSearchDoc] {
+	return &entityDocs[cs.RoomSearchDoc]{
+		index:  cs.SearchIndexRoom,
+		list:   ds.ListRoomSearchDocs,
+		idOf:   func(d *cs.RoomSearchDoc) int64 { return d.ID },
+		upsert: es.UpsertRooms,
+	}
+}
+
+func topicDocs(ds core.EntitySearchSyncService, es core.EntitySearchService) *entityDocs[cs.TopicSearchDoc] {
+	return &entityDocs[cs.TopicSearchDoc]{
+		index:  cs.SearchIndexTopic,
+		list:   ds.ListTopicSearchDocs,
+		idOf:   func(d *cs.TopicSearchDoc) int64 { return d.ID },
+		upsert: es.UpsertTopics,
+	}
+}
+
+func (e *entityDocs[T]) sync(es core.EntitySearchService, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	docs, err := e.list(ids, 0, 0)
+	if err != nil {
+		return err
+	}
+	found := make(map[int64]struct{}, len(docs))
+	for _, doc := range docs {
+		found[e.idOf(doc)] = struct{}{}
+	}
+	var removed []int64
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	if len(docs) > 0 {
+		if err = e.upsert(docs); err != nil {
+			return err
+		}
+	}
+	return es.DeleteDocuments(e.index, removed)
+}
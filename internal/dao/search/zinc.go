@@ -23,11 +23,8 @@ var (
 type zincTweetSearchServant struct {
 	tweetSearchFilter
 
-	indexName     string
-	client        *zinc.ZincClient
-	publicFilter  string
-	privateFilter string
-	friendFilter  string
+	indexName string
+	client    *zinc.ZincClient
 }
 
 func (s *zincTweetSearchServant) Name() string {
@@ -91,36 +88,46 @@ func (s *zincTweetSearchServant) Search(user *ms.User, q *core.QueryReq, offset,
 	}
 
 	logrus.Debugf("zincTweetSearchServant.Search type:%s query:%s resp Hits:%d NbHits:%d offset: %d limit:%d ", q.Type, q.Query, len(resp.Items), resp.Total, offset, limit)
-	s.filterResp(user, resp)
 	return
 }
 
 func (s *zincTweetSearchServant) queryByContent(user *ms.User, q *core.QueryReq, offset, limit int) (*core.QueryResp, error) {
-	resp, err := s.client.EsQuery(s.indexName, map[string]any{
-		"query": map[string]any{
-			"match_phrase": map[string]any{
-				"content": q.Query,
-			},
+	return s.query(user, map[string]any{
+		"match_phrase": map[string]any{
+			"content": q.Query,
 		},
-		"sort": []string{"-is_top", "-latest_replied_on"},
-		"from": offset,
-		"size": limit,
-	})
-	if err != nil {
-		return nil, err
-	}
-	return s.postsFrom(resp)
+	}, offset, limit)
 }
 
 func (s *zincTweetSearchServant) queryByTag(user *ms.User, q *core.QueryReq, offset, limit int) (*core.QueryResp, error) {
-	resp, err := s.client.ApiQuery(s.indexName, map[string]any{
-		"search_type": "querystring",
-		"query": map[string]any{
-			"term": "tags." + q.Query + ":1",
+	return s.query(user, map[string]any{
+		"query_string": map[string]any{
+			"query": "tags." + q.Query + ":1",
 		},
-		"sort_fields": []string{"-is_top", "-latest_replied_on"},
-		"from":        offset,
-		"max_results": limit,
+	}, offset, limit)
+}
+
+func (s *zincTweetSearchServant) queryAny(user *ms.User, offset, limit int) (*core.QueryResp, error) {
+	return s.query(user, map[string]any{
+		"match_all": map[string]string{},
+	}, offset, limit)
+}
+
+func (s *zincTweetSearchServant) query(user *ms.User, match map[string]any, offset, limit int) (*core.QueryResp, error) {
+	query := match
+	if filter := s.filterQuery(user); filter != nil {
+		query = map[string]any{
+			"bool": map[string]any{
+				"must":   []any{match},
+				"filter": []any{filter},
+			},
+		}
+	}
+	resp, err := s.client.EsQuery(s.indexName, map[string]any{
+		"query": query,
+		"sort":  []string{"-is_top", "-latest_replied_on"},
+		"from":  offset,
+		"size":  limit,
 	})
 	if err != nil {
 		return nil, err
@@ -128,20 +135,51 @@ func (s *zincTweetSearchServant) queryByTag(user *ms.User, q *core.QueryReq, off
 	return s.postsFrom(resp)
 }
 
-func (s *zincTweetSearchServant) queryAny(user *ms.User, offset, limit int) (*core.QueryResp, error) {
-	queryMap := map[string]any{
-		"query": map[string]any{
-			"match_all": map[string]string{},
+// filterQuery 可见性过滤条件，user_id 为参与者数组，term 匹配其中任一项
+func (s *zincTweetSearchServant) filterQuery(user *ms.User) map[string]any {
+	scope := s.visibleScope(user)
+	if scope == nil {
+		return nil
+	}
+	public := zincTerm("visibility", core.PostVisitPublic)
+	if scope.userId == 0 {
+		return public
+	}
+	return map[string]any{
+		"bool": map[string]any{
+			"should": []any{
+				public,
+				zincMust(zincTerm("visibility", core.PostVisitFriend), zincTerms("host_id", scope.friendIds)),
+				zincMust(zincTerm("visibility", core.PostVisitFollowing), zincTerms("host_id", scope.followIds)),
+				zincMust(zincTerm("visibility", core.PostVisitPrivate), zincTerm("user_id", scope.userId)),
+			},
+			"minimum_should_match": 1,
 		},
-		"sort": []string{"-is_top", "-latest_replied_on"},
-		"from": offset,
-		"size": limit,
 	}
-	resp, err := s.client.EsQuery(s.indexName, queryMap)
-	if err != nil {
-		return nil, err
+}
+
+func zincTerm(field string, value any) map[string]any {
+	return map[string]any{
+		"term": map[string]any{
+			field: value,
+		},
+	}
+}
+
+func zincTerms(field string, values []int64) map[string]any {
+	return map[string]any{
+		"terms": map[string]any{
+			field: values,
+		},
+	}
+}
+
+func zincMust(queries ...any) map[string]any {
+	return map[string]any{
+		"bool": map[string]any{
+			"must": queries,
+		},
 	}
-	return s.postsFrom(resp)
 }
 
 func (s *zincTweetSearchServant) postsFrom(resp *zinc.QueryResultT) (*core.QueryResp, error) {
@@ -164,9 +202,15 @@ func (s *zincTweetSearchServant) postsFrom(resp *zinc.QueryResultT) (*core.Query
 	}, nil
 }
 
-func (s *zincTweetSearchServant) createIndex() {
+func (s *zincTweetSearchServant) createIndex(name string) bool {
+	return createZincAliasIndex(s.client, name, func(index string) bool {
+		return createZincPostIndex(s.client, index)
+	})
+}
+
+func createZincPostIndex(client *zinc.ZincClient, name string) bool {
 	// 不存在则创建索引
-	s.client.CreateIndex(s.indexName, &zinc.ZincIndexProperty{
+	return client.CreateIndex(name, &zinc.ZincIndexProperty{
 		"id": &zinc.ZincIndexPropertyT{
 			Type:     "numeric",
 			Index:    true,
@@ -178,6 +222,11 @@ func (s *zincTweetSearchServant) createIndex() {
 			Index: true,
 			Store: true,
 		},
+		"host_id": &zinc.ZincIndexPropertyT{
+			Type:  "numeric",
+			Index: true,
+			Store: true,
+		},
 		"comment_count": &zinc.ZincIndexPropertyT{
 			Type:     "numeric",
 			Index:    true,
@@ -260,6 +309,10 @@ func (s *zincTweetSearchServant) createIndex() {
 }
 
 func (s *zincTweetSearchServant) toDocs(data []core.TsDocItem) []map[string]any {
+	return zincPostDocs(data)
+}
+
+func zincPostDocs(data []core.TsDocItem) []map[string]any {
 	docs := make([]map[string]any, 0, len(data))
 	for _, d := range data {
 		tagMaps := map[string]int8{}
@@ -269,6 +322,7 @@ func (s *zincTweetSearchServant) toDocs(data []core.TsDocItem) []map[string]any
 		docs = append(docs, map[string]any{
 			"id":                d.Post.ID,
 			"user_id":           d.Post.UserID,
+			"host_id":           d.Post.GetHostID(),
 			"comment_count":     d.Post.CommentCount,
 			"collection_count":  d.Post.CollectionCount,
 			"upvote_count":      d.Post.UpvoteCount,
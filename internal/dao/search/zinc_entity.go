@@ -0,0 +1,109 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/zinc"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ entityIndexer = (*zincEntityIndexer)(nil)
+)
+
+type zincEntityIndexer struct {
+	client  *zinc.ZincClient
+	indexes map[cs.SearchIndex]string
+}
+
+func (s *zincEntityIndexer) upsert(index cs.SearchIndex, docs []map[string]any) error {
+	name := s.indexes[index]
+	buf := make([]map[string]any, 0, len(docs)*2)
+	for _, doc := range docs {
+		// 指定_id，重复写入时覆盖旧文档
+		buf = append(buf, map[string]any{
+			"index": map[string]any{
+				"_index": name,
+				"_id":    doc["id"],
+			},
+		}, doc)
+	}
+	if _, err := s.client.BulkPushDoc(buf); err != nil {
+		logrus.Errorf("zincEntityIndexer.upsert index:%s error: %s", index, err)
+		return err
+	}
+	return nil
+}
+
+func (s *zincEntityIndexer) remove(index cs.SearchIndex, ids []string) error {
+	for _, id := range ids {
+		if err := s.client.DelDoc(s.indexes[index], id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *zincEntityIndexer) query(index cs.SearchIndex, q string, offset, limit int) ([]any, int64, error) {
+	query := map[string]any{
+		"match_all": map[string]string{},
+	}
+	if q != "" {
+		query = map[string]any{
+			"multi_match": map[string]any{
+				"query":  q,
+				"fields": entitySearchableFields(index),
+			},
+		}
+	}
+	resp, err := s.client.EsQuery(s.indexes[index], map[string]any{
+		"query": query,
+		"from":  offset,
+		"size":  limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	hits := make([]any, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		hits = append(hits, hit.Source)
+	}
+	return hits, resp.Hits.Total.Value, nil
+}
+
+func (s *zincEntityIndexer) createIndex(name string, index cs.SearchIndex) {
+	createZincAliasIndex(s.client, name, func(real string) bool {
+		return createZincEntityIndex(s.client, real, index)
+	})
+}
+
+func createZincEntityIndex(client *zinc.ZincClient, name string, index cs.SearchIndex) bool {
+	props := zinc.ZincIndexProperty{
+		"id": &zinc.ZincIndexPropertyT{
+			Type:     "numeric",
+			Index:    true,
+			Store:    true,
+			Sortable: true,
+		},
+		"categories": &zinc.ZincIndexPropertyT{
+			Type:  "numeric",
+			Index: true,
+			Store: true,
+		},
+	}
+	for _, field := range entitySearchableFields(index) {
+		props[field] = &zinc.ZincIndexPropertyT{
+			Type:           "text",
+			Index:          true,
+			Store:          true,
+			Highlightable:  true,
+			Analyzer:       "gse_search",
+			SearchAnalyzer: "gse_standard",
+		}
+	}
+	// 不存在则创建索引
+	return client.CreateIndex(name, &props)
+}
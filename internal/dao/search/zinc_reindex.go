@@ -0,0 +1,124 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/zinc"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ indexAdmin = (*zincIndexAdmin)(nil)
+)
+
+// zincIndexAdmin zinc没有索引交换，在用索引名作为别名指向实际的索引
+type zincIndexAdmin struct {
+	client *zinc.ZincClient
+	prefix string
+}
+
+// createZincAliasIndex 在用索引名不存在时创建实际索引并建立同名别名指向它，
+// 重建索引时只需切换别名，无需删除在用索引
+func createZincAliasIndex(client *zinc.ZincClient, live string, create func(name string) bool) bool {
+	indexes, err := client.AliasIndexes(live)
+	if err != nil {
+		logrus.Warnf("get zinc alias %s occurs error: %s, create it as index", live, err)
+		return create(live)
+	}
+	if len(indexes) > 0 || client.ExistIndex(live) {
+		return true
+	}
+	name := fmt.Sprintf("%s-%d", live, time.Now().Unix())
+	if !create(name) {
+		return false
+	}
+	if err = client.SwitchAlias(live, name); err != nil {
+		logrus.Errorf("create zinc alias %s for %s occurs error: %s", live, name, err)
+		return false
+	}
+	return true
+}
+
+func (s *zincIndexAdmin) liveName(index cs.SearchIndex) string {
+	if index == cs.SearchIndexPost {
+		return s.prefix
+	}
+	return entityIndexName(s.prefix, index)
+}
+
+func (s *zincIndexAdmin) create(index cs.SearchIndex, name string) error {
+	var ok bool
+	if index == cs.SearchIndexPost {
+		ok = createZincPostIndex(s.client, name)
+	} else {
+		ok = createZincEntityIndex(s.client, name, index)
+	}
+	if !ok {
+		return fmt.Errorf("create zinc index %s failed", name)
+	}
+	return nil
+}
+
+func (s *zincIndexAdmin) write(name string, docs []map[string]any) error {
+	buf := make([]map[string]any, 0, len(docs)*2)
+	for _, doc := range docs {
+		buf = append(buf, map[string]any{
+			"index": map[string]any{
+				"_index": name,
+				"_id":    fmt.Sprint(doc["id"]),
+			},
+		}, doc)
+	}
+	_, err := s.client.BulkPushDoc(buf)
+	return err
+}
+
+func (s *zincIndexAdmin) swap(index cs.SearchIndex, shadow string) error {
+	live := s.liveName(index)
+	olds, err := s.client.AliasIndexes(live)
+	if err != nil {
+		return err
+	}
+	if len(olds) == 0 && s.client.ExistIndex(live) {
+		// 别名机制之前创建的在用索引还是实际索引，需要先删除才能建立同名别名，期间会短暂无结果
+		logrus.Warnf("zinc index %s is not an alias yet, drop it before switching to %s", live, shadow)
+		if err = s.client.DeleteIndex(live); err != nil {
+			return err
+		}
+	}
+	if err = s.client.SwitchAlias(live, shadow, olds...); err != nil {
+		return err
+	}
+	for _, name := range olds {
+		if err = s.drop(name); err != nil {
+			logrus.Warnf("drop old zinc index %s occurs error: %s", name, err)
+		}
+	}
+	return nil
+}
+
+func (s *zincIndexAdmin) drop(name string) error {
+	return s.client.DeleteIndex(name)
+}
+
+func (s *zincIndexAdmin) count(name string) (int64, error) {
+	indexes, err := s.client.AliasIndexes(name)
+	if err != nil {
+		return 0, err
+	}
+	if len(indexes) > 0 {
+		name = indexes[0]
+	}
+	return s.client.IndexDocNum(name)
+}
+
+func (s *zincIndexAdmin) postDocs(data []core.TsDocItem) []map[string]any {
+	return zincPostDocs(data)
+}
@@ -0,0 +1,282 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package security
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alimy/tryst/cfg"
+	"github.com/cockroachdb/errors"
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/json"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+)
+
+var (
+	_ core.ContentModerationService = (*noneModerationServant)(nil)
+	_ core.ContentModerationService = (*keywordModerationServant)(nil)
+	_ core.ContentModerationService = (*httpModerationServant)(nil)
+	_ core.ContentModerationService = (*chainModerationServant)(nil)
+)
+
+type noneModerationServant struct{}
+
+type keywordRule struct {
+	verdict cs.ModerationVerdict
+	word    string
+	re      *regexp.Regexp
+	label   string
+}
+
+type keywordModerationServant struct {
+	sync.RWMutex
+	baseRules []*keywordRule
+	fileRules []*keywordRule
+	wordsFile string
+	modTime   time.Time
+}
+
+type httpModerationReq struct {
+	Scene cs.ModerationScene `json:"scene"`
+	Texts []string           `json:"texts"`
+}
+
+type httpModerationRsp struct {
+	Verdict string   `json:"verdict"`
+	Labels  []string `json:"labels"`
+}
+
+type httpModerationServant struct {
+	client   *resty.Client
+	endpoint string
+	token    string
+}
+
+type chainModerationServant struct {
+	servants []core.ContentModerationService
+}
+
+func (noneModerationServant) Moderate(_ cs.ModerationScene, _ ...string) (*cs.ModerationResult, error) {
+	return &cs.ModerationResult{Verdict: cs.ModerationAllow}, nil
+}
+
+// Moderate 按关键词/正则规则审核文本，命中reject规则直接拒绝，命中flag规则标记待审
+func (s *keywordModerationServant) Moderate(_ cs.ModerationScene, texts ...string) (*cs.ModerationResult, error) {
+	s.RLock()
+	defer s.RUnlock()
+	res := &cs.ModerationResult{Verdict: cs.ModerationAllow}
+	for _, rules := range [][]*keywordRule{s.baseRules, s.fileRules} {
+		for _, rule := range rules {
+			for _, text := range texts {
+				if rule.match(text) {
+					res.Merge(rule.verdict, rule.label)
+					break
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// watch 定时检查词库文件的修改时间，变更后重新加载
+func (s *keywordModerationServant) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.reload(); err != nil {
+			logrus.Warnf("reload moderation words file %s failed: %s", s.wordsFile, err)
+		}
+	}
+}
+
+func (s *keywordModerationServant) reload() error {
+	info, err := os.Stat(s.wordsFile)
+	if err != nil {
+		return err
+	}
+	s.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.RUnlock()
+	if unchanged {
+		return nil
+	}
+	file, err := os.Open(s.wordsFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var rules []*keywordRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// 每行格式为 "<reject|flag> <关键词或/正则/>"，省略审核结论时按reject处理
+		verdict, pattern := cs.ModerationReject, line
+		if fields := strings.SplitN(line, " ", 2); len(fields) == 2 {
+			if v := cs.ModerationVerdictFrom(fields[0]); v != cs.ModerationAllow {
+				verdict, pattern = v, strings.TrimSpace(fields[1])
+			}
+		}
+		rule, err := newKeywordRule(verdict, pattern)
+		if err != nil {
+			logrus.Warnf("skip invalid moderation rule %q: %s", line, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	s.Lock()
+	s.fileRules, s.modTime = rules, info.ModTime()
+	s.Unlock()
+	logrus.Infof("loaded %d moderation rules from %s", len(rules), s.wordsFile)
+	return nil
+}
+
+// Moderate 调用外部文本分类服务审核文本
+func (s *httpModerationServant) Moderate(scene cs.ModerationScene, texts ...string) (*cs.ModerationResult, error) {
+	req := s.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(&httpModerationReq{
+			Scene: scene,
+			Texts: texts,
+		})
+	if s.token != "" {
+		req.SetAuthToken(s.token)
+	}
+	resp, err := req.Post(s.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, errors.New(resp.Status())
+	}
+	result := &httpModerationRsp{}
+	if err = json.Unmarshal(resp.Body(), result); err != nil {
+		return nil, err
+	}
+	return &cs.ModerationResult{
+		Verdict: cs.ModerationVerdictFrom(result.Verdict),
+		Labels:  result.Labels,
+	}, nil
+}
+
+// Moderate 依次执行各审核服务，取最严格的结论；已拒绝时不再继续，
+// 某个服务异常时记录日志并跳过，保留其余服务已得出的结论
+func (s *chainModerationServant) Moderate(scene cs.ModerationScene, texts ...string) (*cs.ModerationResult, error) {
+	res := &cs.ModerationResult{Verdict: cs.ModerationAllow}
+	for _, servant := range s.servants {
+		r, err := servant.Moderate(scene, texts...)
+		if err != nil {
+			logrus.Errorf("chainModerationServant.Moderate scene[%s] servant[%T] err: %s", scene, servant, err)
+			continue
+		}
+		res.Merge(r.Verdict, r.Labels...)
+		if res.Verdict == cs.ModerationReject {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (r *keywordRule) match(text string) bool {
+	if r.re != nil {
+		return r.re.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), r.word)
+}
+
+func newKeywordRule(verdict cs.ModerationVerdict, pattern string) (*keywordRule, error) {
+	rule := &keywordRule{
+		verdict: verdict,
+		label:   "keyword:" + pattern,
+	}
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, err
+		}
+		rule.re = re
+	} else if pattern != "" {
+		rule.word = strings.ToLower(pattern)
+	} else {
+		return nil, errors.New("empty pattern")
+	}
+	return rule, nil
+}
+
+func newKeywordModerationServant() *keywordModerationServant {
+	s := conf.ContentModerationSetting
+	servant := &keywordModerationServant{
+		wordsFile: s.WordsFile,
+	}
+	for verdict, words := range map[cs.ModerationVerdict][]string{
+		cs.ModerationReject: s.RejectWords,
+		cs.ModerationFlag:   s.FlagWords,
+	} {
+		for _, word := range words {
+			if rule, err := newKeywordRule(verdict, word); err == nil {
+				servant.baseRules = append(servant.baseRules, rule)
+			} else {
+				logrus.Warnf("skip invalid moderation rule %q: %s", word, err)
+			}
+		}
+	}
+	if servant.wordsFile != "" {
+		if err := servant.reload(); err != nil {
+			logrus.Warnf("load moderation words file %s failed: %s", servant.wordsFile, err)
+		}
+		if s.ReloadInterval > 0 {
+			go servant.watch(s.ReloadInterval)
+		}
+	}
+	return servant
+}
+
+func newHttpModerationServant() *httpModerationServant {
+	s := conf.ContentModerationSetting
+	client := resty.New()
+	client.DisableWarn = true
+	if s.Timeout > 0 {
+		client.SetTimeout(s.Timeout)
+	}
+	return &httpModerationServant{
+		client:   client,
+		endpoint: s.Endpoint,
+		token:    s.Token,
+	}
+}
+
+func NewContentModerationService() core.ContentModerationService {
+	var servants []core.ContentModerationService
+	if cfg.If("Moderation") {
+		servants = append(servants, newKeywordModerationServant())
+	}
+	if cfg.If("Moderation:Http") {
+		servants = append(servants, newHttpModerationServant())
+	}
+	switch len(servants) {
+	case 0:
+		return noneModerationServant{}
+	case 1:
+		return servants[0]
+	default:
+		return &chainModerationServant{
+			servants: servants,
+		}
+	}
+}
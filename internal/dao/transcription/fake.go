@@ -0,0 +1,49 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package transcription
+
+import (
+	"net/url"
+	"path"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+var (
+	_ core.TranscriptionService = (*fakeTranscriptionServant)(nil)
+)
+
+type fakeTranscriptionServant struct {
+	segments cs.TranscriptSegments
+}
+
+// Transcribe 返回预置的片段，未预置时按音频文件名生成一个片段
+func (s *fakeTranscriptionServant) Transcribe(audioUrl string) (cs.TranscriptSegments, error) {
+	if len(s.segments) > 0 {
+		res := make(cs.TranscriptSegments, 0, len(s.segments))
+		for _, seg := range s.segments {
+			item := *seg
+			res = append(res, &item)
+		}
+		return res, nil
+	}
+	name := audioUrl
+	if u, err := url.Parse(audioUrl); err == nil {
+		name = path.Base(u.Path)
+	}
+	return cs.TranscriptSegments{{
+		Start: 0,
+		End:   1000,
+		Text:  "transcript of " + name,
+	}}, nil
+}
+
+// NewFakeTranscriptionService 不依赖外部程序的转写服务，用于开发与测试
+func NewFakeTranscriptionService(segments ...*cs.TranscriptSegment) core.TranscriptionService {
+	return &fakeTranscriptionServant{
+		segments: segments,
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package transcription
+
+import (
+	"github.com/alimy/tryst/cfg"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+var (
+	_ core.TranscriptionService = noneTranscriptionServant{}
+)
+
+type noneTranscriptionServant struct{}
+
+func (noneTranscriptionServant) Transcribe(_ string) (cs.TranscriptSegments, error) {
+	return nil, nil
+}
+
+// NewTranscriptionService 根据功能项选择转写服务，未开启时不做转写
+func NewTranscriptionService() core.TranscriptionService {
+	if cfg.If("Transcription") {
+		return newWhisperTranscriptionServant()
+	} else if cfg.If("Transcription:Fake") {
+		return NewFakeTranscriptionService()
+	}
+	return noneTranscriptionServant{}
+}
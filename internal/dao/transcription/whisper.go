@@ -0,0 +1,100 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/pkg/whisper"
+)
+
+var (
+	_ core.TranscriptionService = (*whisperTranscriptionServant)(nil)
+)
+
+type whisperTranscriptionServant struct {
+	opts         *whisper.Options
+	workDir      string
+	maxAudioSize int64
+}
+
+// Transcribe 下载录音到临时文件后调用本地 whisper 程序转写
+func (s *whisperTranscriptionServant) Transcribe(audioUrl string) (cs.TranscriptSegments, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), conf.TranscriptionSetting.Timeout)
+	defer cancel()
+	audioFile, err := s.download(ctx, audioUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(audioFile)
+	segments, err := whisper.Transcribe(ctx, s.opts, audioFile)
+	if err != nil {
+		return nil, err
+	}
+	res := make(cs.TranscriptSegments, 0, len(segments))
+	for _, seg := range segments {
+		res = append(res, &cs.TranscriptSegment{
+			Start: seg.From,
+			End:   seg.To,
+			Text:  seg.Text,
+		})
+	}
+	return res, nil
+}
+
+func (s *whisperTranscriptionServant) download(ctx context.Context, audioUrl string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, audioUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download audio failed: %s", resp.Status)
+	}
+	if resp.ContentLength > s.maxAudioSize {
+		return "", fmt.Errorf("audio too large: %d bytes", resp.ContentLength)
+	}
+	file, err := os.CreateTemp(s.workDir, "paopao-audio-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	// 多读一个字节用于判断是否超出限制
+	n, err := io.Copy(file, io.LimitReader(resp.Body, s.maxAudioSize+1))
+	if err == nil && n > s.maxAudioSize {
+		err = fmt.Errorf("audio too large: more than %d bytes", s.maxAudioSize)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+func newWhisperTranscriptionServant() *whisperTranscriptionServant {
+	s := conf.TranscriptionSetting
+	return &whisperTranscriptionServant{
+		opts: &whisper.Options{
+			Binary:   s.Binary,
+			Model:    s.Model,
+			Language: s.Language,
+			Threads:  s.Threads,
+			FFmpeg:   s.FFmpeg,
+		},
+		workDir:      s.WorkDir,
+		maxAudioSize: s.MaxAudioSize << 20,
+	}
+}
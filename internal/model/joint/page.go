@@ -11,8 +11,9 @@ type Pager struct {
 }
 
 type PageResp struct {
-	List  any   `json:"list"`
-	Pager Pager `json:"pager"`
+	List       any    `json:"list"`
+	Pager      Pager  `json:"pager"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func PageRespFrom(list any, page int, pageSize int, totalRows int64) *PageResp {
@@ -25,3 +26,14 @@ func PageRespFrom(list any, page int, pageSize int, totalRows int64) *PageResp {
 		},
 	}
 }
+
+// CursorPageRespFrom 游标分页响应，游标分页不统计总数
+func CursorPageRespFrom(list any, pageSize int, nextCursor string) *PageResp {
+	return &PageResp{
+		List: list,
+		Pager: Pager{
+			PageSize: pageSize,
+		},
+		NextCursor: nextCursor,
+	}
+}
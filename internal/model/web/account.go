@@ -0,0 +1,49 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package web
+
+type ExportAccountReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type ExportAccountResp struct {
+	ID     int64 `json:"id"`
+	Status int8  `json:"status"`
+}
+
+type GetAccountExportReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64 `form:"id" binding:"required"`
+}
+
+type GetAccountExportResp struct {
+	ID          int64  `json:"id"`
+	Status      int8   `json:"status"`
+	Size        int64  `json:"size"`
+	DownloadUrl string `json:"download_url,omitempty"`
+	FinishedOn  int64  `json:"finished_on"`
+}
+
+type DeleteAccountReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	Password string `json:"password" binding:"required"`
+}
+
+type DeleteAccountResp struct {
+	ScheduledOn int64 `json:"scheduled_on"`
+}
+
+type CancelAccountDeletionReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type GetAccountDeletionReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type GetAccountDeletionResp struct {
+	Scheduled   bool  `json:"scheduled"`
+	ScheduledOn int64 `json:"scheduled_on"`
+}
@@ -10,8 +10,15 @@ type ChangeUserStatusReq struct {
 	Status   int   `json:"status" form:"status" binding:"required,oneof=1 2"`
 }
 
+type ChangeRoomStatusReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64  `json:"id" binding:"required"`
+	Blocked  bool   `json:"blocked"`
+	Note     string `json:"note"`
+}
+
 type SiteInfoReq struct {
-	SimpleInfo `json:"-" binding:"-"`
+	BaseInfo `json:"-" binding:"-"`
 }
 
 type SiteInfoResp struct {
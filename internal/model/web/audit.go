@@ -4,11 +4,16 @@
 
 package web
 
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
 const (
 	AuditStyleUnknown AuditStyle = iota
 	AuditStyleUserTweet
 	AuditStyleUserTweetComment
 	AuditStyleUserTweetReply
+	AuditStyleUserProfile
 )
 
 const (
@@ -19,8 +24,11 @@ const (
 type AuditStyle uint8
 
 type AuditMetaInfo struct {
-	Style AuditStyle
-	Id    int64
+	Style   AuditStyle
+	Id      int64
+	UserId  int64
+	Verdict cs.ModerationVerdict
+	Labels  []string
 }
 
 func (s AuditStyle) String() (res string) {
@@ -31,6 +39,8 @@ func (s AuditStyle) String() (res string) {
 		res = "UserTweetComment"
 	case AuditStyleUserTweetReply:
 		res = "UserTweetReply"
+	case AuditStyleUserProfile:
+		res = "UserProfile"
 	case AuditStyleUnknown:
 		fallthrough
 	default:
@@ -24,6 +24,7 @@ import (
 
 
 type MessageStyle = cs.MessageStyle
+type MessageCategory = cs.MessageCategory
 type UserProfile = cs.UserProfile
 type UserProfileWithFollow = cs.UserProfileWithFollow
 type UserFormated = ms.UserFormated
@@ -57,13 +58,17 @@ type UserInfoResp struct {
 	Follows     int64  `json:"follows"`
 	Followings  int64  `json:"followings"`
 	TweetsCount int    `json:"tweets_count"`
+	DmPolicy    cs.DmPolicyT `json:"dm_policy"`
+	IsPrivate   bool   `json:"is_private"`
 	IsOnline    bool   `json:"is_online,omitempty" gorm:"-"` // User's online status (optional, not in DB)
 }
 
 type GetMessagesReq struct {
 	SimpleInfo `json:"-" binding:"-"`
 	joint.BasePageInfo
-	Style MessageStyle `form:"style" binding:"required"`
+	Style    MessageStyle    `form:"style" binding:"required"`
+	Category MessageCategory `form:"category"`
+	Cursor   string          `form:"cursor"`
 }
 
 type GetMessagesResp struct {
@@ -77,6 +82,8 @@ type ReadMessageReq struct {
 
 type ReadAllMessageReq struct {
 	SimpleInfo `json:"-" binding:"-"`
+	Category   MessageCategory `json:"category"`
+	Cursor     string          `json:"cursor"`
 }
 
 type SendWhisperReq struct {
@@ -85,12 +92,106 @@ type SendWhisperReq struct {
 	Content    string `json:"content" binding:"required"`
 }
 
+type GetDmConversationReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	UserID     int64 `json:"user_id" binding:"required"`
+}
+
+type GetDmConversationResp struct {
+	ConversationID int64 `json:"conversation_id"`
+}
+
+type ListDmConversationsReq BasePageReq
+type ListDmConversationsResp base.PageResp
+
+type GetDmMessagesReq struct {
+	SimpleInfo     `form:"-" binding:"-"`
+	ConversationID int64  `form:"conversation_id"`
+	Cursor         string `form:"cursor"`
+	PageSize       int    `form:"page_size"`
+}
+
+type GetDmMessagesResp struct {
+	List       []*ms.DmMessage `json:"list"`
+	PeerReadID int64           `json:"peer_read_id"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// SendDmMessageReq 发送私信，ConversationID 为0时发送给 UserID 并按需创建会话
+type SendDmMessageReq struct {
+	SimpleInfo     `json:"-" binding:"-"`
+	ConversationID int64     `json:"conversation_id"`
+	UserID         int64     `json:"user_id"`
+	Type           cs.DmMsgT `json:"type" binding:"required"`
+	Content        string    `json:"content" binding:"required"`
+	// Duration 兼容旧客户端仍然接收，语音时长以服务端解析的为准
+	Duration int64 `json:"duration"`
+}
+
+type SendDmMessageResp struct {
+	ConversationID int64         `json:"conversation_id"`
+	Message        *ms.DmMessage `json:"message"`
+}
+
+// ReadDmConversationReq 标记会话已读，MessageID 为0时标记到最新消息
+type ReadDmConversationReq struct {
+	SimpleInfo     `json:"-" binding:"-"`
+	ConversationID int64 `json:"conversation_id" binding:"required"`
+	MessageID      int64 `json:"message_id"`
+}
+
+type GetDmUnreadCountReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+}
+
+type GetDmUnreadCountResp struct {
+	Count int64 `json:"count"`
+}
+
+type ChangeDmPolicyReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	DmPolicy cs.DmPolicyT `json:"dm_policy"`
+}
+
+type BlockUserReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	UserID     int64 `json:"user_id" binding:"required"`
+}
+
+type UnblockUserReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	UserID     int64 `json:"user_id" binding:"required"`
+}
+
+type ListBlockedUsersReq BasePageReq
+type ListBlockedUsersResp base.PageResp
+
+type ListFriendSuggestionsReq BasePageReq
+type ListFriendSuggestionsResp base.PageResp
+
+// FriendSuggestion "可能认识的人"推荐项，Explanation 为推荐理由如"3 mutual friends"
+type FriendSuggestion struct {
+	User        *ms.UserFormated `json:"user"`
+	Reason      string           `json:"reason"`
+	Explanation string           `json:"explanation"`
+	IsFollowing bool             `json:"is_following"`
+}
+
+type SuggestionFeedbackReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	UserID     int64               `json:"user_id" binding:"required"`
+	Action     cs.SuggestFeedbackT `json:"action" binding:"required"`
+}
+
 type GetCollectionsReq BasePageReq
 type GetCollectionsResp base.PageResp
 
 type GetStarsReq BasePageReq
 type GetStarsResp base.PageResp
 
+type GetDraftsReq BasePageReq
+type GetDraftsResp base.PageResp
+
 type UserPhoneBindReq struct {
 	BaseInfo `json:"-" binding:"-"`
 	Phone    string `json:"phone" form:"phone" binding:"required"`
@@ -117,6 +218,22 @@ type SuggestUsersResp struct {
 	Suggests []UserProfileWithFollow `json:"suggests"`
 }
 
+type SuggestMentionsReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	Keyword    string
+}
+
+type SuggestMentionsResp struct {
+	Suggests []*MentionSuggestion `json:"suggests"`
+}
+
+// MentionSuggestion @提及候选用户，HasTalked 表示与其有过私信
+type MentionSuggestion struct {
+	User        *ms.UserFormated `json:"user"`
+	IsFollowing bool             `json:"is_following"`
+	HasTalked   bool             `json:"has_talked"`
+}
+
 type SuggestTagsReq struct {
 	Keyword string
 }
@@ -160,6 +277,34 @@ func (r *GetStarsReq) Bind(c *gin.Context) mir.Error {
 	return (*BasePageReq)(r).Bind(c)
 }
 
+func (r *GetDraftsReq) Bind(c *gin.Context) mir.Error {
+	return (*BasePageReq)(r).Bind(c)
+}
+
+func (r *ListDmConversationsReq) Bind(c *gin.Context) mir.Error {
+	return (*BasePageReq)(r).Bind(c)
+}
+
+func (r *ListBlockedUsersReq) Bind(c *gin.Context) mir.Error {
+	return (*BasePageReq)(r).Bind(c)
+}
+
+func (r *ListFriendSuggestionsReq) Bind(c *gin.Context) mir.Error {
+	return (*BasePageReq)(r).Bind(c)
+}
+
+func (r *GetDmMessagesReq) Bind(c *gin.Context) mir.Error {
+	uid, ok := base.UserIdFrom(c)
+	if !ok {
+		return xerror.UnauthorizedTokenError
+	}
+	r.Uid = uid
+	r.ConversationID = convert.StrTo(c.Query("conversation_id")).MustInt64()
+	r.Cursor = c.Query("cursor")
+	r.PageSize = app.GetPageSize(c)
+	return nil
+}
+
 func (r *SuggestTagsReq) Bind(c *gin.Context) mir.Error {
 	r.Keyword = c.Query("k")
 	return nil
@@ -179,6 +324,18 @@ func (r *SuggestUsersReq) Bind(c *gin.Context) mir.Error {
 	return nil
 }
 
+func (r *SuggestMentionsReq) Bind(c *gin.Context) mir.Error {
+	userId, exist := base.UserIdFrom(c)
+	if !exist {
+		return xerror.UnauthorizedAuthNotExist
+	}
+	r.SimpleInfo = SimpleInfo{
+		Uid: userId,
+	}
+	r.Keyword = c.Query("k")
+	return nil
+}
+
 func (r *TweetCollectionStatusReq) Bind(c *gin.Context) mir.Error {
 	userId, exist := base.UserIdFrom(c)
 	if !exist {
@@ -215,6 +372,8 @@ type CreateRoomReq struct {
 	HMSRoomID  string   `json:"hms_room_id,omitempty"`
 	Topics     []string `json:"topics,omitempty"`
 	Categories dbr.Int64Array `json:"categories,omitempty"`
+	// LocationData 房主当前位置，用于附近的房间，可选
+	LocationData *LocationData `json:"locationData,omitempty"`
 }
 
 type UpdateRoomReq struct {
@@ -243,6 +402,26 @@ type GetUserRoomReq struct {
 	BaseInfo `json:"-" binding:"-"`
 }
 
+// InviteRoomReq 房主邀请关注者或好友进入房间，Followers 为真时同时向开启提醒的关注者发送"叫人来"
+type InviteRoomReq struct {
+	BaseInfo  `json:"-" binding:"-"`
+	RoomID    int64   `json:"-" binding:"-"`
+	UserIDs   []int64 `json:"user_ids"`
+	Followers bool    `json:"followers"`
+}
+
+// InviteRoomResp Skipped 为非关注者/好友、已屏蔽或有效期内已邀请过而跳过的用户数
+type InviteRoomResp struct {
+	Invited int `json:"invited"`
+	Skipped int `json:"skipped"`
+}
+
+// AcceptRoomInviteReq 接受房间邀请，返回房间信息用于跳转
+type AcceptRoomInviteReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	RoomID   int64 `json:"-" binding:"-"`
+}
+
 // User Reaction API Models (User-to-User reactions) - These belong in core service
 type CreateUserReactionReq struct {
 	SimpleInfo      `json:"-" binding:"-"`
@@ -337,26 +516,30 @@ type GetReactionsToTwoUsersResp struct {
 // Global reaction timeline request/response
 type GetGlobalReactionTimelineReq struct {
 	SimpleInfo `json:"-" binding:"-"`
-	Page       int `json:"page" binding:"required,min=1"`      // Page number
-	PageSize   int `json:"page_size" binding:"required,min=1,max=50"` // Page size
+	Page       int    `json:"page" binding:"required,min=1"`              // Page number
+	PageSize   int    `json:"page_size" binding:"required,min=1,max=50"` // Page size
+	Cursor     string `json:"cursor"`                                    // Opaque cursor, takes precedence over page
 }
 
 type GetGlobalReactionTimelineResp struct {
-	List  []*cs.UserReactionWithBothUsers `json:"list"`  // Reactions with both reactor and target user data
-	Pager base.Pager                         `json:"pager"` // Pagination info
+	List       []*cs.UserReactionWithBothUsers `json:"list"`                  // Reactions with both reactor and target user data
+	Pager      base.Pager                      `json:"pager"`                 // Pagination info
+	NextCursor string                          `json:"next_cursor,omitempty"` // Cursor of the next page
 }
 
 // User-specific reaction timeline request/response
 type GetUserReactionTimelineReq struct {
 	SimpleInfo `json:"-" binding:"-"`
-	UserID     int64 `json:"user_id" binding:"required"` // User whose reactions to get
-	Page       int   `json:"page" binding:"required,min=1"`      // Page number
-	PageSize   int   `json:"page_size" binding:"required,min=1,max=50"` // Page size
+	UserID     int64  `json:"user_id" binding:"required"`                // User whose reactions to get
+	Page       int    `json:"page" binding:"required,min=1"`              // Page number
+	PageSize   int    `json:"page_size" binding:"required,min=1,max=50"` // Page size
+	Cursor     string `json:"cursor"`                                    // Opaque cursor, takes precedence over page
 }
 
 type GetUserReactionTimelineResp struct {
-	List  []*cs.UserReactionWithBothUsers `json:"list"`  // Reactions with both reactor and target user data
-	Pager base.Pager                         `json:"pager"` // Pagination info
+	List       []*cs.UserReactionWithBothUsers `json:"list"`                  // Reactions with both reactor and target user data
+	Pager      base.Pager                      `json:"pager"`                 // Pagination info
+	NextCursor string                          `json:"next_cursor,omitempty"` // Cursor of the next page
 }
 
 // SetUserCategoriesReq sets categories for a user
@@ -457,6 +640,31 @@ func (r *GetRoomByHostIDReq) Bind(c *gin.Context) mir.Error {
 	return nil
 }
 
+func (r *InviteRoomReq) Bind(c *gin.Context) mir.Error {
+	roomId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return mir.Errorln(http.StatusBadRequest, "Invalid room ID")
+	}
+	r.RoomID = roomId
+	return bindAny(c, r)
+}
+
+func (r *AcceptRoomInviteReq) Bind(c *gin.Context) mir.Error {
+	user, exist := base.UserFrom(c)
+	if !exist {
+		return xerror.UnauthorizedAuthNotExist
+	}
+	roomId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return mir.Errorln(http.StatusBadRequest, "Invalid room ID")
+	}
+	r.BaseInfo = BaseInfo{
+		User: user,
+	}
+	r.RoomID = roomId
+	return nil
+}
+
 func (r *GetUserRoomReq) Bind(c *gin.Context) mir.Error {
 	user, exist := base.UserFrom(c)
 	if !exist {
@@ -513,6 +721,7 @@ func (r *GetGlobalReactionTimelineReq) Bind(c *gin.Context) mir.Error {
 	
 	// Get pagination from query parameters using the same logic as other endpoints
 	r.Page, r.PageSize = app.GetPageInfo(c)
+	r.Cursor = c.Query("cursor")
 	
 	return nil
 }
@@ -538,6 +747,7 @@ func (r *GetUserReactionTimelineReq) Bind(c *gin.Context) mir.Error {
 	
 	// Get pagination from query parameters using the same logic as other endpoints
 	r.Page, r.PageSize = app.GetPageInfo(c)
+	r.Cursor = c.Query("cursor")
 	
 	return nil
 }
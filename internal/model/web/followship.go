@@ -14,23 +14,69 @@ type FollowUserReq struct {
 	UserId   int64 `json:"user_id" binding:"required"`
 }
 
+// FollowUserResp Status 为1时表示已向私密账户发送关注请求，2为已关注
+type FollowUserResp struct {
+	Status int8 `json:"status"`
+}
+
 type UnfollowUserReq struct {
 	BaseInfo `json:"-" binding:"-"`
 	UserId   int64 `json:"user_id" binding:"required"`
 }
 
+// ChangeRoomPingReq 开启或关闭关注对象开房间时的"叫人来"提醒
+type ChangeRoomPingReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	UserId   int64 `json:"user_id" binding:"required"`
+	Enable   bool  `json:"enable"`
+}
+
+// ChangePrivateAccountReq 私密账户的新关注需经本人通过，改为公开时通过所有待处理的关注请求
+type ChangePrivateAccountReq struct {
+	BaseInfo  `json:"-" binding:"-"`
+	IsPrivate bool `json:"is_private"`
+}
+
+type ListFollowRequestsReq struct {
+	BaseInfo `form:"-" binding:"-"`
+	joint.BasePageInfo
+}
+
+type ListFollowRequestsResp base.PageResp
+
+type ApproveFollowRequestReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	UserId   int64 `json:"user_id" binding:"required"`
+}
+
+type DeclineFollowRequestReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	UserId   int64 `json:"user_id" binding:"required"`
+}
+
+// RemoveFollowerReq 移除粉丝，不会屏蔽对方
+type RemoveFollowerReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	UserId   int64 `json:"user_id" binding:"required"`
+}
+
+// ListFollowsReq Keyword 按用户名或昵称过滤，Cursor 为空时按页码分页
 type ListFollowsReq struct {
 	BaseInfo `json:"-" binding:"-"`
 	joint.BasePageInfo
 	Username string `form:"username" binding:"required"`
+	Keyword  string `form:"keyword"`
+	Cursor   string `form:"cursor"`
 }
 
-type ListFollowsResp base.PageResp
+type ListFollowsResp joint.PageResp
 
 type ListFollowingsReq struct {
 	BaseInfo `form:"-" binding:"-"`
 	joint.BasePageInfo
 	Username string `form:"username" binding:"required"`
+	Keyword  string `form:"keyword"`
+	Cursor   string `form:"cursor"`
 }
 
-type ListFollowingsResp base.PageResp
+type ListFollowingsResp joint.PageResp
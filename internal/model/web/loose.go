@@ -37,6 +37,7 @@ const (
 	StyleTweetsNewest    = "newest"
 	StyleTweetsHots      = "hots"
 	StyleTweetsFollowing = "following"
+	StyleTweetsForYou    = "foryou"
 )
 
 type TagType = cs.TagType
@@ -55,12 +56,33 @@ type TweetCommentsResp struct {
 	joint.CachePageResp
 }
 
+// TweetCommentRepliesReq 分页获取评论的回复
+type TweetCommentRepliesReq struct {
+	SimpleInfo `form:"-" binding:"-"`
+	joint.BasePageInfo
+	CommentId int64 `form:"id" binding:"required"`
+}
+
+type TweetCommentRepliesResp base.PageResp
+
+// TweetRevisionsReq 获取动态或评论的编辑历史，Type 为1动态、2评论
+type TweetRevisionsReq struct {
+	BaseInfo `form:"-"  binding:"-"`
+	ID       int64          `form:"id" binding:"required"`
+	Type     cs.EditTargetT `form:"type"`
+}
+
+type TweetRevisionsResp struct {
+	List []*ms.EditRevision `json:"list"`
+}
+
 type TimelineReq struct {
 	BaseInfo   `form:"-"  binding:"-"`
 	Query      string              `form:"query"`
 	Visibility []core.PostVisibleT `form:"query"`
 	Type       string              `form:"type"`
 	Style      string              `form:"style"`
+	Cursor     string              `form:"cursor"`
 	Page       int                 `form:"-"  binding:"-"`
 	PageSize   int                 `form:"-"  binding:"-"`
 }
@@ -73,6 +95,7 @@ type GetUserTweetsReq struct {
 	BaseInfo `form:"-" binding:"-"`
 	Username string `form:"username" binding:"required"`
 	Style    string `form:"style"`
+	Cursor   string `form:"cursor"`
 	Page     int    `form:"-" binding:"-"`
 	PageSize int    `form:"-" binding:"-"`
 }
@@ -95,6 +118,8 @@ type GetUserProfileResp struct {
 	IsAdmin     bool             `json:"is_admin"`
 	IsFriend    bool             `json:"is_friend"`
 	IsFollowing bool             `json:"is_following"`
+	IsPrivate   bool             `json:"is_private"`
+	IsFollowRequested bool       `json:"is_follow_requested"`
 	CreatedOn   int64            `json:"created_on"`
 	Follows     int64            `json:"follows"`
 	Followings  int64            `json:"followings"`
@@ -121,10 +146,66 @@ type TopicListResp struct {
 type TweetDetailReq struct {
 	BaseInfo `form:"-"  binding:"-"`
 	TweetId  int64 `form:"id"`
+	Captions bool  `form:"captions"`
 }
 
 type TweetDetailResp ms.PostFormated
 
+// TweetThreadReq 串推，TweetId 可为串推中的任意一条动态
+type TweetThreadReq struct {
+	BaseInfo `form:"-" binding:"-"`
+	TweetId  int64 `form:"id" binding:"required"`
+}
+
+type TweetThreadResp struct {
+	List []*ms.PostFormated `json:"list"`
+}
+
+// NearbyTweetsReq 附近的动态，按距离由近到远排列，Radius 单位千米
+type NearbyTweetsReq struct {
+	BaseInfo `form:"-" binding:"-"`
+	joint.BasePageInfo
+	Lat    float64 `form:"lat" binding:"min=-90,max=90"`
+	Lng    float64 `form:"lng" binding:"min=-180,max=180"`
+	Radius float64 `form:"radius" binding:"omitempty,gt=0"`
+}
+
+type NearbyTweetsResp base.PageResp
+
+// NearbyTweet 附近的动态，Distance 单位千米
+type NearbyTweet struct {
+	*ms.PostFormated
+	Distance float64 `json:"distance"`
+}
+
+// SearchReq 统一搜索，Type 为空时返回全部分区的首页结果，否则只返回指定分区
+type SearchReq struct {
+	BaseInfo `form:"-" binding:"-"`
+	joint.BasePageInfo
+	Query string `form:"q" binding:"required"`
+	Type  string `form:"type" binding:"omitempty,oneof=posts users rooms topics"`
+}
+
+// SearchResp 统一搜索结果，按类型分区，未请求的分区为空
+type SearchResp struct {
+	Posts  *SearchSection `json:"posts,omitempty"`
+	Users  *SearchSection `json:"users,omitempty"`
+	Rooms  *SearchSection `json:"rooms,omitempty"`
+	Topics *SearchSection `json:"topics,omitempty"`
+}
+
+// SearchSection 某一类型的搜索结果
+type SearchSection struct {
+	List  any   `json:"list"`
+	Total int64 `json:"total"`
+}
+
+// SearchRoom 房间搜索结果，IsOnline 表示房主当前是否在线
+type SearchRoom struct {
+	*cs.RoomSearchDoc
+	IsOnline bool `json:"is_online"`
+}
+
 // PostLocationReq represents the request for getting post location
 type PostLocationReq struct {
 	BaseInfo `form:"-" binding:"-"`
@@ -178,6 +259,7 @@ func (r *TimelineReq) Bind(c *gin.Context) mir.Error {
 	}
 	r.Page, r.PageSize = app.GetPageInfo(c)
 	r.Query, r.Type, r.Style = c.Query("query"), "search", c.Query("style")
+	r.Cursor = c.Query("cursor")
 	return nil
 }
 
@@ -187,6 +269,8 @@ func (s CommentStyleType) ToInnerValue() (res cs.StyleCommentType) {
 		res = cs.StyleCommentHots
 	case "newest":
 		res = cs.StyleCommentNewest
+	case "top":
+		res = cs.StyleCommentTop
 	case "default":
 		fallthrough
 	default:
@@ -203,6 +287,8 @@ func (s CommentStyleType) String() (res string) {
 		res = conf.InfixCommentHots
 	case "newest":
 		res = conf.InfixCommentNewest
+	case "top":
+		res = conf.InfixCommentTop
 	default:
 		res = "_"
 	}
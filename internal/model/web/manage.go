@@ -0,0 +1,139 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/model/joint"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
+)
+
+type ManageLogoutReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type ManageUsersReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	joint.BasePageInfo
+	Keyword string      `form:"keyword"`
+	Status  int         `form:"status" binding:"omitempty,oneof=1 2"`
+	Role    cs.UserRole `form:"role" binding:"omitempty,oneof=admin user"`
+}
+
+type ManageUsersResp base.PageResp
+
+// ManageUser 管理后台用户信息
+type ManageUser struct {
+	ID        int64  `json:"id"`
+	Nickname  string `json:"nickname"`
+	Username  string `json:"username"`
+	Phone     string `json:"phone"`
+	Avatar    string `json:"avatar"`
+	Status    int    `json:"status"`
+	IsAdmin   bool   `json:"is_admin"`
+	IsOnline  bool   `json:"is_online"`
+	CreatedOn int64  `json:"created_on"`
+}
+
+type ManageUserDetailReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64 `form:"id" binding:"required"`
+}
+
+type ManageUserDetailResp struct {
+	User              *ManageUser      `json:"user"`
+	Roles             []*ms.Role       `json:"roles"`
+	Devices           []*cs.UserDevice `json:"devices"`
+	Rooms             []*ms.Room       `json:"rooms"`
+	ReactionsReceived map[int64]int64  `json:"reactions_received"`
+	ReactionsGiven    map[int64]int64  `json:"reactions_given"`
+	Reports           []*ms.Report     `json:"reports"`
+	ReportCount       int64            `json:"report_count"`
+}
+
+type ManageUserStatusReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64  `json:"id" binding:"required"`
+	Status   int    `json:"status" binding:"required,oneof=1 2"`
+	Note     string `json:"note"`
+}
+
+type ManageUserAdminReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64  `json:"id" binding:"required"`
+	IsAdmin  bool   `json:"is_admin"`
+	Note     string `json:"note"`
+}
+
+type ManageRolesReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type ManageRolesResp struct {
+	List []*ms.Role `json:"list"`
+}
+
+type ManageUserRoleReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64       `json:"id" binding:"required"`
+	Role     cs.RoleName `json:"role" binding:"required"`
+	Note     string      `json:"note"`
+}
+
+type ForceLogoutReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64  `json:"id" binding:"required"`
+	Note     string `json:"note"`
+}
+
+type ManagePostReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64  `json:"id" binding:"required"`
+	Note     string `json:"note"`
+}
+
+type DashboardReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	Days     int `form:"days" binding:"omitempty,min=1,max=30"`
+}
+
+type DashboardResp struct {
+	RegisterUserCount int64            `json:"register_user_count"`
+	OnlineUserCount   int              `json:"online_user_count"`
+	LiveRoomCount     int64            `json:"live_room_count"`
+	Registrations     []*cs.DailyCount `json:"registrations"`
+	DailyActiveUsers  []*cs.DailyCount `json:"daily_active_users"`
+	Recordings        []*cs.DailyCount `json:"recordings"`
+}
+
+type ManageFeaturesReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type ManageFeaturesResp struct {
+	List []*cs.FeatureToggle `json:"list"`
+}
+
+type ManageFeatureToggleReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	Feature  string `json:"feature" binding:"required"`
+	Enabled  bool   `json:"enabled"`
+}
+
+type ManageSearchIndexesReq struct {
+	BaseInfo `json:"-" binding:"-"`
+}
+
+type ManageSearchIndexesResp struct {
+	List       []*cs.SearchIndexDrift `json:"list"`
+	Reindexing []cs.SearchIndex       `json:"reindexing"`
+}
+
+type ManageSearchReindexReq struct {
+	BaseInfo  `json:"-" binding:"-"`
+	Indexes   []cs.SearchIndex `json:"indexes" binding:"required,min=1"`
+	BatchSize int              `json:"batch_size" binding:"omitempty,min=100,max=10000"`
+}
@@ -67,12 +67,44 @@ type PostContentItem struct {
 		ClientIP        string             `json:"-" binding:"-"`
 		RoomID          string             `json:"room_id"`
 		SessionID       string             `json:"session_id"`
+		// 引用转发的动态
+		QuoteID         int64              `json:"quote_id"`
+		// 串推中接续的上一条动态，只能是自己的动态
+		ThreadParentID  int64              `json:"thread_parent_id"`
+		// 保存为草稿，或在 ScheduledOn 指定的时间定时发布
+		Draft           bool               `json:"draft"`
+		ScheduledOn     int64              `json:"scheduled_on"`
 		// Location data from iOS
 		LocationData    *LocationData      `json:"locationData"`
 	}
 
 type CreateTweetResp ms.PostFormated
 
+// UpdateDraftReq 编辑草稿或定时发布的动态，Publish 为真时立即发布，
+// 否则 ScheduledOn 不为0时定时发布，为0时保存为草稿
+type UpdateDraftReq struct {
+	BaseInfo        `json:"-" binding:"-"`
+	ID              int64              `json:"id" binding:"required"`
+	Contents        []*PostContentItem `json:"contents" binding:"required"`
+	Tags            []string           `json:"tags" binding:"required"`
+	AttachmentPrice int64              `json:"attachment_price"`
+	Visibility      TweetVisibleType   `json:"visibility"`
+	ScheduledOn     int64              `json:"scheduled_on"`
+	Publish         bool               `json:"publish"`
+}
+
+type UpdateDraftResp ms.PostFormated
+
+// EditTweetReq 编辑已发布动态的文本与标签，媒体内容保持不变
+type EditTweetReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64              `json:"id" binding:"required"`
+	Contents []*PostContentItem `json:"contents" binding:"required"`
+	Tags     []string           `json:"tags"`
+}
+
+type EditTweetResp ms.PostFormated
+
 // UpdateUserLocationReq represents a request to update user location in Redis
 type UpdateUserLocationReq struct {
 	BaseInfo     `json:"-" binding:"-"`
@@ -122,6 +154,17 @@ type StarTweetResp struct {
 
 
 
+type RepostTweetReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64  `json:"id" binding:"required"`
+	ClientIP string `json:"-" binding:"-"`
+}
+
+type RepostTweetResp struct {
+	Status bool             `json:"status"`
+	Tweet  *ms.PostFormated `json:"tweet,omitempty"`
+}
+
 type CollectionTweetReq struct {
 	SimpleInfo `json:"-" binding:"-"`
 	ID         int64 `json:"id" binding:"required"`
@@ -180,10 +223,11 @@ type CreateCommentResp ms.Comment
 
 type CreateCommentReplyReq struct {
 	SimpleInfo `json:"-" binding:"-"`
-	CommentID  int64  `json:"comment_id" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	AtUserID   int64  `json:"at_user_id"`
-	ClientIP   string `json:"-" binding:"-"`
+	CommentID  int64           `json:"comment_id" binding:"required"`
+	Content    string          `json:"content" binding:"required"`
+	Type       ms.PostContentT `json:"type"`
+	AtUserID   int64           `json:"at_user_id"`
+	ClientIP   string          `json:"-" binding:"-"`
 }
 
 type CreateCommentReplyResp ms.CommentReply
@@ -202,6 +246,26 @@ type HighlightCommentResp struct {
 	HighlightStatus int8 `json:"highlight_status"`
 }
 
+type PinCommentReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	CommentId  int64 `json:"id" binding:"required"`
+}
+
+type PinCommentResp struct {
+	IsPinned bool `json:"is_pinned"`
+}
+
+// EditCommentReq 编辑评论的文本内容
+type EditCommentReq struct {
+	SimpleInfo `json:"-" binding:"-"`
+	CommentId  int64              `json:"id" binding:"required"`
+	Contents   []*PostContentItem `json:"contents" binding:"required"`
+}
+
+type EditCommentResp struct {
+	EditedOn int64 `json:"edited_on"`
+}
+
 type DeleteCommentReplyReq struct {
 	BaseInfo `json:"-" binding:"-"`
 	ID       int64 `json:"id" binding:"required"`
@@ -223,6 +287,8 @@ type UploadAttachmentResp struct {
 	ImgHeight int               `json:"img_height"`
 	Type      ms.AttachmentType `json:"type"`
 	Content   string            `json:"content"`
+	Duration  int64             `json:"duration"`
+	Waveform  []int             `json:"waveform"`
 }
 
 type DownloadAttachmentPrecheckReq struct {
@@ -346,6 +412,9 @@ func (r *UploadAttachmentReq) Bind(c *gin.Context) (xerr mir.Error) {
 	if xerr != nil {
 		return xerr
 	}
+	if (uploadType == "public/audio") != strings.HasPrefix(contentType, "audio/") {
+		return ErrFileInvalidExt.WithDetails("语音文件须使用 public/audio 类型上传")
+	}
 	r.SimpleInfo = SimpleInfo{
 		Uid: userId,
 	}
@@ -383,6 +452,11 @@ func (r *CreateTweetReq) Bind(c *gin.Context) mir.Error {
 	return bindAny(c, r)
 }
 
+func (r *RepostTweetReq) Bind(c *gin.Context) mir.Error {
+	r.ClientIP = c.ClientIP()
+	return bindAny(c, r)
+}
+
 func (r *CreateCommentReplyReq) Bind(c *gin.Context) mir.Error {
 	r.ClientIP = c.ClientIP()
 	return bindAny(c, r)
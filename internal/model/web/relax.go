@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/model/joint"
 )
 
@@ -17,8 +18,9 @@ type GetUnreadMsgCountReq struct {
 }
 
 type GetUnreadMsgCountResp struct {
-	Count    int64           `json:"count"`
-	JsonResp json.RawMessage `json:"-"`
+	Count      int64                        `json:"count"`
+	Categories map[cs.MessageCategory]int64 `json:"categories"`
+	JsonResp   json.RawMessage              `json:"-"`
 }
 
 func (r *GetUnreadMsgCountResp) Render(c *gin.Context) {
@@ -0,0 +1,55 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/model/joint"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
+)
+
+type CreateReportReq struct {
+	BaseInfo    `json:"-" binding:"-"`
+	TargetType  cs.ReportTargetType `json:"target_type" binding:"required"`
+	TargetID    int64               `json:"target_id" binding:"required"`
+	Reason      cs.ReportReason     `json:"reason" binding:"required"`
+	Description string              `json:"description"`
+	Attachments []string            `json:"attachments"`
+}
+
+type CreateReportResp struct {
+	ID     int64 `json:"id"`
+	Status int8  `json:"status"`
+}
+
+type ListReportsReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	joint.BasePageInfo
+	Status     int8                `form:"status"`
+	TargetType cs.ReportTargetType `form:"target_type"`
+}
+
+type ListReportsResp base.PageResp
+
+type HandleReportReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	ID       int64               `json:"id" binding:"required"`
+	Action   cs.ModerationAction `json:"action" binding:"required"`
+	Note     string              `json:"note"`
+}
+
+type HandleReportResp struct {
+	ID         int64               `json:"id"`
+	Status     int8                `json:"status"`
+	Resolution cs.ModerationAction `json:"resolution"`
+}
+
+type ListModerationLogsReq struct {
+	BaseInfo `json:"-" binding:"-"`
+	joint.BasePageInfo
+	ReportID int64 `form:"report_id"`
+}
+
+type ListModerationLogsResp base.PageResp
@@ -7,6 +7,7 @@ package web
 import (
 	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/model/joint"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
 )
 
 // Room represents a single room with enriched data
@@ -21,4 +22,21 @@ type SpaceParticipant = cs.SpaceParticipant
 // RoomListResp represents the paginated response for rooms
 type RoomListResp struct {
 	joint.CachePageResp
-} 
\ No newline at end of file
+}
+
+// NearbyRoomsReq 附近正在直播的房间，按距离由近到远排列，Radius 单位千米
+type NearbyRoomsReq struct {
+	BaseInfo `form:"-" binding:"-"`
+	joint.BasePageInfo
+	Lat    float64 `form:"lat" binding:"min=-90,max=90"`
+	Lng    float64 `form:"lng" binding:"min=-180,max=180"`
+	Radius float64 `form:"radius" binding:"omitempty,gt=0"`
+}
+
+type NearbyRoomsResp base.PageResp
+
+// NearbyRoom 附近的房间，Distance 单位千米
+type NearbyRoom struct {
+	*Room
+	Distance float64 `json:"distance"`
+}
@@ -5,7 +5,10 @@
 package web
 
 import (
+	"fmt"
+
 	"github.com/alimy/mir/v4"
+	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/pkg/xerror"
 )
 
@@ -13,9 +16,13 @@ func fileCheck(uploadType string, size int64) mir.Error {
 	if uploadType != "public/video" &&
 		uploadType != "public/image" &&
 		uploadType != "public/avatar" &&
+		uploadType != "public/audio" &&
 		uploadType != "attachment" {
 		return xerror.InvalidParams
 	}
+	if maxSize := conf.VoiceNoteSetting.MaxSize; uploadType == "public/audio" && size > 1024*1024*maxSize {
+		return ErrFileInvalidSize.WithDetails(fmt.Sprintf("最大允许%dMB", maxSize))
+	}
 	if size > 1024*1024*100 {
 		return ErrFileInvalidSize.WithDetails("最大允许100MB")
 	}
@@ -38,12 +45,22 @@ func getFileExt(s string) (string, mir.Error) {
 		return ".mp4", nil
 	case "video/quicktime":
 		return ".mov", nil
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3", nil
+	case "audio/mp4", "audio/x-m4a", "audio/aac":
+		return ".m4a", nil
+	case "audio/ogg", "audio/opus":
+		return ".ogg", nil
+	case "audio/webm":
+		return ".webm", nil
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return ".wav", nil
 	case "application/zip",
 		"application/x-zip",
 		"application/octet-stream",
 		"application/x-zip-compressed":
 		return ".zip", nil
 	default:
-		return "", ErrFileInvalidExt.WithDetails("仅允许 webp/png/jpg/gif/mp4/mov/zip 类型")
+		return "", ErrFileInvalidExt.WithDetails("仅允许 webp/png/jpg/gif/mp4/mov/mp3/m4a/ogg/webm/wav/zip 类型")
 	}
 }
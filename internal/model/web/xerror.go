@@ -52,6 +52,24 @@ var (
 	ErrGetPostsUnknowStyle     = xerror.NewError(30014, "使用未知样式参数获取动态列表")
 	ErrGetPostsNilUser         = xerror.NewError(30015, "使用游客账户获取动态详情失败")
 	ErrGetPostLocationFailed   = xerror.NewError(30016, "获取动态位置信息失败")
+	ErrInvalidPageCursor       = xerror.NewError(30017, "分页游标不合法")
+	ErrInvalidLocation         = xerror.NewError(30018, "位置坐标不合法")
+	ErrGetNearbyPostsFailed    = xerror.NewError(30019, "获取附近动态失败")
+	ErrSearchFailed            = xerror.NewError(30020, "搜索失败")
+	ErrRepostTweetFailed       = xerror.NewError(30021, "动态转发失败")
+	ErrRepostNotPublic         = xerror.NewError(30022, "仅公开动态可以转发")
+	ErrInvalidThreadParent     = xerror.NewError(30023, "只能在自己的动态之后续写串推")
+	ErrGetThreadFailed         = xerror.NewError(30024, "获取串推失败")
+	ErrInvalidScheduledTime    = xerror.NewError(30025, "定时发布时间必须晚于当前时间")
+	ErrNotDraftTweet           = xerror.NewError(30026, "只能编辑草稿或定时发布的动态")
+	ErrUpdateDraftFailed       = xerror.NewError(30027, "草稿保存失败")
+	ErrGetDraftsFailed         = xerror.NewError(30028, "获取草稿列表失败")
+	ErrEditWindowExpired       = xerror.NewError(30029, "已超过可编辑的时间")
+	ErrEditTweetFailed         = xerror.NewError(30030, "动态编辑失败")
+	ErrGetRevisionsFailed      = xerror.NewError(30031, "获取编辑历史失败")
+	ErrPostHiddenByModerator   = xerror.NewError(30032, "动态已被管理员隐藏")
+	ErrRepostBlocked           = xerror.NewError(30033, "你与原动态作者存在屏蔽关系，无法转发")
+	ErrPostLockedByModerator   = xerror.NewError(30034, "动态已被管理员锁定")
 
 	ErrGetCommentsFailed      = xerror.NewError(40001, "获取评论列表失败")
 	ErrCreateCommentFailed    = xerror.NewError(40002, "评论发布失败")
@@ -62,12 +80,26 @@ var (
 	ErrMaxCommentCount        = xerror.NewError(40007, "评论数已达最大限制")
 	ErrGetCommentThumbs       = xerror.NewError(40008, "获取评论点赞信息失败")
 	ErrHighlightCommentFailed = xerror.NewError(40009, "设置精选评论失败")
+	ErrPinCommentFailed       = xerror.NewError(40010, "设置置顶评论失败")
+	ErrGetRepliesFailed       = xerror.NewError(40011, "获取评论回复列表失败")
+	ErrEditCommentFailed      = xerror.NewError(40012, "评论编辑失败")
+	ErrPostCommentLocked      = xerror.NewError(40013, "动态已锁定，不能评论")
 
-	ErrGetMessagesFailed = xerror.NewError(50001, "获取消息列表失败")
-	ErrReadMessageFailed = xerror.NewError(50002, "标记消息已读失败")
-	ErrSendWhisperFailed = xerror.NewError(50003, "私信发送失败")
-	ErrNoWhisperToSelf   = xerror.NewError(50004, "不允许给自己发送私信")
-	ErrTooManyWhisperNum = xerror.NewError(50005, "今日私信次数已达上限")
+	ErrGetMessagesFailed     = xerror.NewError(50001, "获取消息列表失败")
+	ErrReadMessageFailed     = xerror.NewError(50002, "标记消息已读失败")
+	ErrSendWhisperFailed     = xerror.NewError(50003, "私信发送失败")
+	ErrNoWhisperToSelf       = xerror.NewError(50004, "不允许给自己发送私信")
+	ErrTooManyWhisperNum     = xerror.NewError(50005, "今日私信次数已达上限")
+	ErrGetDmConversations    = xerror.NewError(50006, "获取私信会话列表失败")
+	ErrNoExistDmConversation = xerror.NewError(50007, "私信会话不存在")
+	ErrGetDmMessagesFailed   = xerror.NewError(50008, "获取私信记录失败")
+	ErrInvalidDmMessage      = xerror.NewError(50009, "私信内容不合法")
+	ErrDmBlocked             = xerror.NewError(50010, "你与对方存在屏蔽关系，无法发送私信")
+	ErrDmFollowersOnly       = xerror.NewError(50011, "对方仅允许关注者发送私信")
+	ErrReadDmFailed          = xerror.NewError(50012, "私信标记已读失败")
+	ErrChangeDmPolicyFailed  = xerror.NewError(50013, "私信权限设置失败")
+	ErrInvalidMsgCategory    = xerror.NewError(50014, "未知的通知分类")
+	ErrRealtimeNotConfigured = xerror.NewError(50015, "实时消息服务未配置")
 
 	ErrGetCollectionsFailed = xerror.NewError(60001, "获取收藏列表失败")
 	ErrGetStarsFailed       = xerror.NewError(60002, "获取点赞列表失败")
@@ -85,6 +117,14 @@ var (
 	ErrDeleteFriendFailed         = xerror.NewError(80006, "删除好友失败")
 	ErrGetContactsFailed          = xerror.NewError(80007, "获取联系人列表失败")
 	ErrNoActionToSelf             = xerror.NewError(80008, "不允许对自己操作")
+	ErrBlockUserFailed            = xerror.NewError(80009, "屏蔽用户失败")
+	ErrUnblockUserFailed          = xerror.NewError(80010, "取消屏蔽失败")
+	ErrListBlockedUsersFailed     = xerror.NewError(80011, "获取屏蔽列表失败")
+	ErrChangeRoomPingFailed       = xerror.NewError(80012, "设置开房提醒失败")
+	ErrNotFollowedUser            = xerror.NewError(80013, "未关注该用户")
+	ErrListSuggestionsFailed      = xerror.NewError(80014, "获取推荐用户失败")
+	ErrSuggestionFeedbackFailed   = xerror.NewError(80015, "推荐反馈提交失败")
+	ErrSuggestMentionsFailed      = xerror.NewError(80016, "获取@用户候选失败")
 	ErrFolloUserFailed            = xerror.NewError(80100, "关注失败")
 	ErrUnfollowUserFailed         = xerror.NewError(80101, "取消关注失败")
 	ErrListFollowsFailed          = xerror.NewError(80102, "获取关注列表失败")
@@ -92,6 +132,14 @@ var (
 	ErrGetFollowCountFailed       = xerror.NewError(80104, "获取关注计数信息失败")
 	ErrNotAllowFollowSelf         = xerror.NewError(80105, "不能关注自己")
 	ErrNotAllowUnfollowSelf       = xerror.NewError(80106, "不能取消关注自己")
+	ErrFollowRequestNotFound      = xerror.NewError(80107, "关注请求不存在")
+	ErrApproveFollowRequestFailed = xerror.NewError(80108, "通过关注请求失败")
+	ErrDeclineFollowRequestFailed = xerror.NewError(80109, "拒绝关注请求失败")
+	ErrListFollowRequestsFailed   = xerror.NewError(80110, "获取关注请求列表失败")
+	ErrChangePrivateAccountFailed = xerror.NewError(80111, "私密账户设置失败")
+	ErrNotYourFollower            = xerror.NewError(80112, "该用户不是你的粉丝")
+	ErrRemoveFollowerFailed       = xerror.NewError(80113, "移除粉丝失败")
+	ErrFollowListIsPrivate        = xerror.NewError(80114, "私密账户的关注列表仅关注者可见")
 
 	ErrGetIndexTrendsFailed = xerror.NewError(802001, "获取动态条栏信息失败")
 
@@ -104,9 +152,12 @@ var (
 	ErrThumbsUpTweetReply     = xerror.NewError(90103, "评论回复点赞失败")
 	ErrThumbsDownTweetReply   = xerror.NewError(90104, "评论回复点踩失败")
 
-	ErrFileUploadFailed = xerror.NewError(10200, "文件上传失败")
-	ErrFileInvalidExt   = xerror.NewError(10201, "文件类型不合法")
-	ErrFileInvalidSize  = xerror.NewError(10202, "文件大小超限")
+	ErrFileUploadFailed       = xerror.NewError(10200, "文件上传失败")
+	ErrFileInvalidExt         = xerror.NewError(10201, "文件类型不合法")
+	ErrFileInvalidSize        = xerror.NewError(10202, "文件大小超限")
+	ErrFileInvalidAudio       = xerror.NewError(10203, "语音文件无法解析")
+	ErrAudioTooLong           = xerror.NewError(10204, "语音时长超限")
+	ErrInvalidAudioAttachment = xerror.NewError(10205, "语音须为本人上传的语音附件")
 
 	ErrNotImplemented = xerror.NewError(10501, "功能未实现")
 
@@ -114,4 +165,47 @@ var (
     ErrGetRoomsFailed     = xerror.NewError(10002, "获取房间列表失败")
     ErrUpdateRoomFailed   = xerror.NewError(10003, "更新房间失败")
     ErrRoomNotFound       = xerror.NewError(10004, "房间不存在")
+	ErrGetNearbyRoomsFailed = xerror.NewError(10005, "获取附近房间失败")
+	ErrNotRoomHost          = xerror.NewError(10006, "只有房主可以邀请")
+	ErrTooManyRoomInvitees  = xerror.NewError(10007, "单次邀请人数超限")
+	ErrRoomPingTooFrequent  = xerror.NewError(10008, "通知关注者过于频繁，请稍后再试")
+	ErrRoomInviteFailed     = xerror.NewError(10009, "房间邀请失败")
+	ErrRoomInviteNotFound   = xerror.NewError(10010, "邀请不存在或已过期")
+	ErrUserRoomBanned       = xerror.NewError(10011, "已被禁止使用房间")
+	ErrRoomBlocked          = xerror.NewError(10012, "房间已被封禁")
+
+	ErrCreateReportFailed      = xerror.NewError(11001, "举报提交失败")
+	ErrInvalidReportTarget     = xerror.NewError(11002, "举报对象不存在")
+	ErrNoReportToSelf          = xerror.NewError(11003, "不允许举报自己")
+	ErrGetReportsFailed        = xerror.NewError(11004, "获取举报列表失败")
+	ErrReportNotFound          = xerror.NewError(11005, "举报不存在")
+	ErrReportHasBeenResolved   = xerror.NewError(11006, "举报已处理")
+	ErrInvalidModerationAction = xerror.NewError(11007, "审核动作不适用于该举报")
+	ErrHandleReportFailed      = xerror.NewError(11008, "举报处理失败")
+	ErrGetModerationLogsFailed = xerror.NewError(11009, "获取审核日志失败")
+	ErrContentRejected         = xerror.NewError(11010, "内容包含违规信息")
+
+	ErrExportAccountFailed         = xerror.NewError(12001, "账户数据导出失败")
+	ErrAccountExportNotFound       = xerror.NewError(12002, "账户数据导出任务不存在")
+	ErrDeleteAccountFailed         = xerror.NewError(12003, "账户注销失败")
+	ErrAccountDeletionNotFound     = xerror.NewError(12004, "没有待执行的账户注销")
+	ErrCancelAccountDeletionFailed = xerror.NewError(12005, "取消账户注销失败")
+
+	ErrGetUsersFailed       = xerror.NewError(13001, "获取用户列表失败")
+	ErrGetUserDetailFailed  = xerror.NewError(13002, "获取用户详情失败")
+	ErrNoManageSelf         = xerror.NewError(13003, "不允许对自己执行该操作")
+	ErrChangeUserFailed     = xerror.NewError(13004, "更新用户失败")
+	ErrRestorePostFailed    = xerror.NewError(13005, "动态恢复失败")
+	ErrGetDashboardFailed   = xerror.NewError(13006, "获取统计数据失败")
+	ErrAdminSessionFailed   = xerror.NewError(13007, "管理后台会话创建失败")
+	ErrInvalidRole          = xerror.NewError(13008, "角色不存在")
+	ErrChangeUserRoleFailed = xerror.NewError(13009, "更新用户角色失败")
+	ErrGetRolesFailed       = xerror.NewError(13010, "获取角色列表失败")
+	ErrNoManageHigherRole   = xerror.NewError(13011, "不允许管理同级或更高级别的用户")
+	ErrInvalidSearchIndex   = xerror.NewError(13012, "搜索索引不存在")
+	ErrSearchReindexing     = xerror.NewError(13013, "搜索索引正在重建")
+	ErrGetSearchIndexFailed = xerror.NewError(13014, "获取搜索索引状态失败")
+	ErrPostNotAdminDeleted  = xerror.NewError(13015, "只能恢复管理员删除的动态")
+	ErrInvalidFeature       = xerror.NewError(13016, "功能开关不存在或不可切换")
+	ErrToggleFeatureFailed  = xerror.NewError(13017, "切换功能开关失败")
 )
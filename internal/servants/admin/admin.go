@@ -7,9 +7,14 @@ package admin
 import (
 	"github.com/gin-gonic/gin"
 	api "github.com/rocboss/paopao-ce/auto/api/m/v1"
+	"github.com/rocboss/paopao-ce/internal/dao/cache"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
 )
 
 // RouteWeb register Manager route
 func RouteManager(e *gin.Engine) {
-	api.RegisterUserServant(e, newUserSrv())
+	ds := base.NewDaoServant()
+	ac := cache.NewAppCache()
+	api.RegisterUserServant(e, newUserSrv(ds, ac))
+	api.RegisterManageServant(e, newManageSrv(ds, ac, cache.NewWebCache()))
 }
@@ -0,0 +1,33 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/dao/cache"
+	"github.com/rocboss/paopao-ce/internal/infra/events"
+)
+
+type changeUserEvent struct {
+	*cache.BaseCacheEvent
+	userId   int64
+	username string
+}
+
+func onChangeUserEvent(ac core.AppCache, id int64, name string) {
+	events.OnEvent(&changeUserEvent{
+		BaseCacheEvent: cache.NewBaseCacheEvent(ac),
+		userId:         id,
+		username:       name,
+	})
+}
+
+func (e *changeUserEvent) Name() string {
+	return "admin.changeUserEvent"
+}
+
+func (e *changeUserEvent) Action() error {
+	return e.ExpireUserData(e.userId, e.username)
+}
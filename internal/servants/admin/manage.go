@@ -0,0 +1,525 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alimy/mir/v4"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	api "github.com/rocboss/paopao-ce/auto/api/m/v1"
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao"
+	"github.com/rocboss/paopao-ce/internal/dao/cache"
+	"github.com/rocboss/paopao-ce/internal/model/web"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
+	"github.com/rocboss/paopao-ce/internal/servants/chain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	_defaultDashboardDays = 7
+	_maxUserDetailReports = 20
+)
+
+var (
+	_ api.Manage = (*manageSrv)(nil)
+)
+
+type manageSrv struct {
+	api.UnimplementedManageServant
+	*base.DaoServant
+	ac  core.AppCache
+	wc  core.WebCache
+	sms core.SearchIndexManageService
+	// 正在重建的搜索索引
+	reindexing sync.Map
+}
+
+func (s *manageSrv) Chain() gin.HandlersChain {
+	return gin.HandlersChain{chain.AdminJWT()}
+}
+
+func (s *manageSrv) Logout(req *web.ManageLogoutReq) mir.Error {
+	if err := s.ac.Delete(conf.KeyAdminSession.Get(req.User.ID)); err != nil {
+		logrus.Errorf("delete admin session err: %s", err)
+	}
+	return nil
+}
+
+func (s *manageSrv) ListUsers(req *web.ManageUsersReq) (*web.ManageUsersResp, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermUserView); xerr != nil {
+		return nil, xerr
+	}
+	filter := &cs.UserFilter{
+		Keyword: strings.TrimSpace(req.Keyword),
+		Status:  req.Status,
+		Role:    req.Role,
+	}
+	users, total, err := s.Ds.ListUsers(filter, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListUsers err: %s", err)
+		return nil, web.ErrGetUsersFailed
+	}
+	userIds := make([]int64, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
+	}
+	onlines, err := s.ac.BatchCheckOnlineUsers(userIds)
+	if err != nil {
+		logrus.Warnf("ac.BatchCheckOnlineUsers err: %s", err)
+	}
+	list := make([]*web.ManageUser, 0, len(users))
+	for _, user := range users {
+		list = append(list, manageUserFrom(user, onlines[user.ID]))
+	}
+	resp := base.PageRespFrom(list, req.Page, req.PageSize, total)
+	return (*web.ManageUsersResp)(resp), nil
+}
+
+func (s *manageSrv) GetUserDetail(req *web.ManageUserDetailReq) (*web.ManageUserDetailResp, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermUserView); xerr != nil {
+		return nil, xerr
+	}
+	user, err := s.Ds.GetUserByID(req.ID)
+	if err != nil || user.Model == nil || user.ID <= 0 {
+		return nil, web.ErrNoExistUsername
+	}
+	resp := &web.ManageUserDetailResp{
+		User: manageUserFrom(user, s.Ds.IsUserOnline(user.ID)),
+	}
+	if resp.Roles, err = s.Ds.GetUserRoles(user.ID); err != nil {
+		logrus.Errorf("Ds.GetUserRoles err: %s", err)
+		return nil, web.ErrGetUserDetailFailed
+	}
+	if resp.Devices, err = s.Ds.GetUserDevices(user.ID); err != nil {
+		logrus.Errorf("Ds.GetUserDevices err: %s", err)
+		return nil, web.ErrGetUserDetailFailed
+	}
+	if resp.Rooms, err = s.Ds.ListUserRooms(user.ID); err != nil {
+		logrus.Errorf("Ds.ListUserRooms err: %s", err)
+		return nil, web.ErrGetUserDetailFailed
+	}
+	if resp.ReactionsReceived, err = s.Ds.GetUserReactionCounts(user.ID); err != nil {
+		logrus.Errorf("Ds.GetUserReactionCounts err: %s", err)
+		return nil, web.ErrGetUserDetailFailed
+	}
+	if resp.ReactionsGiven, err = s.Ds.GetUserGivenReactionCounts(user.ID); err != nil {
+		logrus.Errorf("Ds.GetUserGivenReactionCounts err: %s", err)
+		return nil, web.ErrGetUserDetailFailed
+	}
+	if resp.Reports, resp.ReportCount, err = s.Ds.ListUserReports(user.ID, _maxUserDetailReports, 0); err != nil {
+		logrus.Errorf("Ds.ListUserReports err: %s", err)
+		return nil, web.ErrGetUserDetailFailed
+	}
+	return resp, nil
+}
+
+func (s *manageSrv) ChangeUserStatus(req *web.ManageUserStatusReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermUserSuspend); xerr != nil {
+		return xerr
+	}
+	user, xerr := s.manageableUser(req.User, req.ID)
+	if xerr != nil {
+		return xerr
+	}
+	if user.Status != req.Status {
+		user.Status = req.Status
+		if xerr := s.updateUser(user); xerr != nil {
+			return xerr
+		}
+	}
+	act := cs.ModerationActSuspendUser
+	if req.Status == ms.UserStatusNormal {
+		act = cs.ModerationActRestoreUser
+	}
+	s.logUserAction(req.User, user, act, req.Note)
+	return nil
+}
+
+func (s *manageSrv) ChangeUserAdmin(req *web.ManageUserAdminReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermRoleManage); xerr != nil {
+		return xerr
+	}
+	user, xerr := s.manageableUser(req.User, req.ID)
+	if xerr != nil {
+		return xerr
+	}
+	if user.IsAdmin != req.IsAdmin {
+		user.IsAdmin = req.IsAdmin
+		if xerr := s.updateUser(user); xerr != nil {
+			return xerr
+		}
+	}
+	act := cs.ModerationActGrantAdmin
+	if !req.IsAdmin {
+		act = cs.ModerationActRevokeAdmin
+		s.expireAdminSession(user.ID)
+	}
+	s.logUserAction(req.User, user, act, req.Note)
+	return nil
+}
+
+// ForceLogout 轮换用户的会话盐值，使其所有Web端与管理后台token失效
+func (s *manageSrv) ForceLogout(req *web.ForceLogoutReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermUserSuspend); xerr != nil {
+		return xerr
+	}
+	user, xerr := s.manageableUser(req.User, req.ID)
+	if xerr != nil {
+		return xerr
+	}
+	user.SessionSalt = uuid.Must(uuid.NewV4()).String()[:8]
+	if xerr := s.updateUser(user); xerr != nil {
+		return xerr
+	}
+	s.expireAdminSession(user.ID)
+	s.logUserAction(req.User, user, cs.ModerationActForceLogout, req.Note)
+	return nil
+}
+
+func (s *manageSrv) DeletePost(req *web.ManagePostReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermContentManage); xerr != nil {
+		return xerr
+	}
+	post, err := s.Ds.GetPostByID(req.ID)
+	if err != nil {
+		logrus.Errorf("Ds.GetPostByID err: %s", err)
+		return web.ErrGetPostFailed
+	}
+	if err = s.Ds.SoftDeletePost(post, req.User.ID); err != nil {
+		logrus.Errorf("Ds.SoftDeletePost err: %s", err)
+		return web.ErrDeletePostFailed
+	}
+	// 同作者删除动态，回退原动态的转发数并更新话题
+	s.updateOriginShareCount(post, -1)
+	if err = s.DeleteSearchPost(post); err != nil {
+		logrus.Errorf("s.DeleteSearchPost err: %s", err)
+	}
+	s.PushPostTagsToSearch(post)
+	cache.OnExpireIndexTweetEvent(post.GetHostID())
+	s.logPostAction(req.User, post, cs.ModerationActDeletePost, req.Note)
+	return nil
+}
+
+func (s *manageSrv) RestorePost(req *web.ManagePostReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermContentManage); xerr != nil {
+		return xerr
+	}
+	post, err := s.Ds.GetPostWithDeleted(req.ID)
+	if err != nil {
+		logrus.Errorf("Ds.GetPostWithDeleted err: %s", err)
+		return web.ErrGetPostFailed
+	}
+	if post.IsDel == 0 || post.DeletedBy == 0 {
+		return web.ErrPostNotAdminDeleted
+	}
+	if err = s.Ds.RestorePost(post); err != nil {
+		logrus.Errorf("Ds.RestorePost err: %s", err)
+		return web.ErrRestorePostFailed
+	}
+	s.updateOriginShareCount(post, 1)
+	s.PushPostToSearch(post)
+	s.PushPostTagsToSearch(post)
+	cache.OnExpireIndexTweetEvent(post.GetHostID())
+	s.logPostAction(req.User, post, cs.ModerationActRestorePost, req.Note)
+	return nil
+}
+
+func (s *manageSrv) Dashboard(req *web.DashboardReq) (*web.DashboardResp, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermSiteStats); xerr != nil {
+		return nil, xerr
+	}
+	days := req.Days
+	if days <= 0 {
+		days = _defaultDashboardDays
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, 1-days)
+	resp, err := &web.DashboardResp{}, error(nil)
+	if resp.RegisterUserCount, err = s.Ds.GetRegisterUserCount(); err != nil {
+		logrus.Errorf("Ds.GetRegisterUserCount err: %s", err)
+		return nil, web.ErrGetDashboardFailed
+	}
+	if resp.Registrations, err = s.Ds.CountRegistersDaily(since.Unix()); err != nil {
+		logrus.Errorf("Ds.CountRegistersDaily err: %s", err)
+		return nil, web.ErrGetDashboardFailed
+	}
+	if resp.Recordings, err = s.Ds.CountRecordingsDaily(since.Unix()); err != nil {
+		logrus.Errorf("Ds.CountRecordingsDaily err: %s", err)
+		return nil, web.ErrGetDashboardFailed
+	}
+	// 在线用户与日活来自用户在线状态标记，统计失败时宽松处理
+	onlineUserIds, err := s.wc.OnlineUserIds()
+	if err != nil {
+		logrus.Warnf("get online users err: %s", err)
+	}
+	resp.OnlineUserCount = len(onlineUserIds)
+	if resp.LiveRoomCount, err = s.Ds.CountLiveRooms(onlineUserIds); err != nil {
+		logrus.Warnf("Ds.CountLiveRooms err: %s", err)
+	}
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		count, err := s.wc.PfCount(chain.DailyActiveUserKey(day))
+		if err != nil {
+			logrus.Warnf("get daily active users of %s err: %s", day.Format(time.DateOnly), err)
+		}
+		resp.DailyActiveUsers = append(resp.DailyActiveUsers, &cs.DailyCount{
+			Day:   day.Format(time.DateOnly),
+			Count: count,
+		})
+	}
+	return resp, nil
+}
+
+func (s *manageSrv) ListRoles(req *web.ManageRolesReq) (*web.ManageRolesResp, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermRoleManage); xerr != nil {
+		return nil, xerr
+	}
+	roles, err := s.Ds.ListRoles()
+	if err != nil {
+		logrus.Errorf("Ds.ListRoles err: %s", err)
+		return nil, web.ErrGetRolesFailed
+	}
+	return &web.ManageRolesResp{
+		List: roles,
+	}, nil
+}
+
+func (s *manageSrv) AssignUserRole(req *web.ManageUserRoleReq) mir.Error {
+	user, xerr := s.userForRoleChange(req)
+	if xerr != nil {
+		return xerr
+	}
+	if err := s.Ds.AssignUserRole(user.ID, req.Role); err != nil {
+		logrus.Errorf("Ds.AssignUserRole err: %s", err)
+		return web.ErrChangeUserRoleFailed
+	}
+	s.logUserAction(req.User, user, cs.ModerationActAssignRole, roleNote(req.Role, req.Note))
+	return nil
+}
+
+func (s *manageSrv) RevokeUserRole(req *web.ManageUserRoleReq) mir.Error {
+	user, xerr := s.userForRoleChange(req)
+	if xerr != nil {
+		return xerr
+	}
+	if err := s.Ds.RevokeUserRole(user.ID, req.Role); err != nil {
+		logrus.Errorf("Ds.RevokeUserRole err: %s", err)
+		return web.ErrChangeUserRoleFailed
+	}
+	s.logUserAction(req.User, user, cs.ModerationActRevokeRole, roleNote(req.Role, req.Note))
+	return nil
+}
+
+func (s *manageSrv) userForRoleChange(req *web.ManageUserRoleReq) (*ms.User, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermRoleManage); xerr != nil {
+		return nil, xerr
+	}
+	if !req.Role.IsValid() {
+		return nil, web.ErrInvalidRole
+	}
+	return s.manageableUser(req.User, req.ID)
+}
+
+// manageableUser 获取被管理的用户，不允许管理自己及同级或更高级别的用户
+func (s *manageSrv) manageableUser(operator *ms.User, userId int64) (*ms.User, mir.Error) {
+	if userId == operator.ID {
+		return nil, web.ErrNoManageSelf
+	}
+	user, err := s.Ds.GetUserByID(userId)
+	if err != nil || user.Model == nil || user.ID <= 0 {
+		return nil, web.ErrNoExistUsername
+	}
+	if !s.Ds.CanManageUser(operator, user) {
+		return nil, web.ErrNoManageHigherRole
+	}
+	return user, nil
+}
+
+func (s *manageSrv) ListFeatures(req *web.ManageFeaturesReq) (*web.ManageFeaturesResp, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermFeatureManage); xerr != nil {
+		return nil, xerr
+	}
+	return &web.ManageFeaturesResp{
+		List: base.FeatureTogglesFrom(s.ac),
+	}, nil
+}
+
+// ToggleFeature 切换功能开关，立即对所有服务实例生效
+func (s *manageSrv) ToggleFeature(req *web.ManageFeatureToggleReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermFeatureManage); xerr != nil {
+		return xerr
+	}
+	if !slices.Contains(base.FeatureToggles, req.Feature) {
+		return web.ErrInvalidFeature
+	}
+	if err := base.ToggleFeature(s.ac, req.Feature, req.Enabled); err != nil {
+		logrus.Errorf("toggle feature %s err: %s", req.Feature, err)
+		return web.ErrToggleFeatureFailed
+	}
+	logrus.Infof("user %d toggles feature %s to %t", req.User.ID, req.Feature, req.Enabled)
+	return nil
+}
+
+func (s *manageSrv) SearchIndexes(req *web.ManageSearchIndexesReq) (*web.ManageSearchIndexesResp, mir.Error) {
+	if xerr := s.checkPermission(req.User, cs.PermSearchManage); xerr != nil {
+		return nil, xerr
+	}
+	resp := &web.ManageSearchIndexesResp{
+		List:       make([]*cs.SearchIndexDrift, 0, len(cs.SearchIndexes)),
+		Reindexing: []cs.SearchIndex{},
+	}
+	for _, index := range cs.SearchIndexes {
+		drift, err := s.sms.CheckDrift(index)
+		if err != nil {
+			logrus.Errorf("sms.CheckDrift %s err: %s", index, err)
+			return nil, web.ErrGetSearchIndexFailed
+		}
+		resp.List = append(resp.List, drift)
+		if _, running := s.reindexing.Load(index); running {
+			resp.Reindexing = append(resp.Reindexing, index)
+		}
+	}
+	return resp, nil
+}
+
+// SearchReindex 在服务进程内后台重建搜索索引，bleve索引只能被一个进程打开，
+// 服务运行期间只能通过这里重建
+func (s *manageSrv) SearchReindex(req *web.ManageSearchReindexReq) mir.Error {
+	if xerr := s.checkPermission(req.User, cs.PermSearchManage); xerr != nil {
+		return xerr
+	}
+	for _, index := range req.Indexes {
+		if !slices.Contains(cs.SearchIndexes, index) {
+			return web.ErrInvalidSearchIndex
+		}
+	}
+	indexes := make([]cs.SearchIndex, 0, len(req.Indexes))
+	for _, index := range req.Indexes {
+		if _, running := s.reindexing.LoadOrStore(index, req.User.ID); running {
+			for _, started := range indexes {
+				s.reindexing.Delete(started)
+			}
+			return web.ErrSearchReindexing
+		}
+		indexes = append(indexes, index)
+	}
+	logrus.Infof("user %d starts to reindex %v", req.User.ID, indexes)
+	go s.reindex(indexes, req.BatchSize)
+	return nil
+}
+
+func (s *manageSrv) reindex(indexes []cs.SearchIndex, batchSize int) {
+	for _, index := range indexes {
+		count, err := s.sms.Reindex(index, batchSize)
+		s.reindexing.Delete(index)
+		if err != nil {
+			logrus.Errorf("reindex %s failed after %d documents: %s", index, count, err)
+			continue
+		}
+		logrus.Infof("reindex %s done: %d documents", index, count)
+	}
+}
+
+func (s *manageSrv) checkPermission(user *ms.User, perm cs.Permission) mir.Error {
+	if !s.Ds.HasPermission(user, perm) {
+		return web.ErrNoPermission
+	}
+	return nil
+}
+
+// updateUser 更新用户并清理用户缓存
+func (s *manageSrv) updateUser(user *ms.User) mir.Error {
+	if err := s.Ds.UpdateUser(user); err != nil {
+		logrus.Errorf("Ds.UpdateUser err: %s", err)
+		return web.ErrChangeUserFailed
+	}
+	onChangeUserEvent(s.ac, user.ID, user.Username)
+	s.PushProfileToSearch(user.ID)
+	return nil
+}
+
+func (s *manageSrv) expireAdminSession(userId int64) {
+	if err := s.ac.Delete(conf.KeyAdminSession.Get(userId)); err != nil {
+		logrus.Warnf("expire admin session of user[%d] err: %s", userId, err)
+	}
+}
+
+func (s *manageSrv) logUserAction(operator *ms.User, user *ms.User, act cs.ModerationAction, note string) {
+	s.logModerationAction(&ms.ModerationLog{
+		OperatorID:   operator.ID,
+		Action:       act,
+		TargetType:   cs.ReportTargetUser,
+		TargetID:     user.ID,
+		TargetUserID: user.ID,
+		Note:         note,
+	})
+}
+
+// updateOriginShareCount 删除或恢复已发布的转发时同步原动态的转发数
+func (s *manageSrv) updateOriginShareCount(post *ms.Post, delta int64) {
+	if post.OriginID > 0 && post.Status == ms.PostStatusPublished {
+		if origin, err := s.Ds.GetPostByID(post.OriginID); err == nil {
+			s.UpdateShareCount(origin, delta)
+		}
+	}
+}
+
+func (s *manageSrv) logPostAction(operator *ms.User, post *ms.Post, act cs.ModerationAction, note string) {
+	s.logModerationAction(&ms.ModerationLog{
+		OperatorID:   operator.ID,
+		Action:       act,
+		TargetType:   cs.ReportTargetPost,
+		TargetID:     post.ID,
+		TargetUserID: post.GetHostID(),
+		Note:         note,
+	})
+}
+
+// logModerationAction 记录操作日志，失败不影响操作结果
+func (s *manageSrv) logModerationAction(log *ms.ModerationLog) {
+	if err := s.Ds.CreateModerationLog(log); err != nil {
+		logrus.Errorf("Ds.CreateModerationLog err: %s", err)
+	}
+}
+
+// roleNote 在操作日志备注中记录变更的角色
+func roleNote(role cs.RoleName, note string) string {
+	if note == "" {
+		return "role: " + string(role)
+	}
+	return "role: " + string(role) + "; " + note
+}
+
+func manageUserFrom(user *ms.User, isOnline bool) *web.ManageUser {
+	return &web.ManageUser{
+		ID:        user.ID,
+		Nickname:  user.Nickname,
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Avatar:    user.Avatar,
+		Status:    user.Status,
+		IsAdmin:   user.IsAdmin,
+		IsOnline:  isOnline,
+		CreatedOn: user.CreatedOn,
+	}
+}
+
+func newManageSrv(s *base.DaoServant, ac core.AppCache, wc core.WebCache) api.Manage {
+	return &manageSrv{
+		DaoServant: s,
+		ac:         ac,
+		wc:         wc,
+		sms:        dao.SearchIndexManageService(),
+	}
+}
@@ -5,8 +5,26 @@
 package admin
 
 import (
+	"context"
+	"strings"
+
+	"github.com/alimy/mir/v4"
+	"github.com/gofrs/uuid/v5"
 	api "github.com/rocboss/paopao-ce/auto/api/m/v1"
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
+	"github.com/rocboss/paopao-ce/pkg/app"
+	"github.com/rocboss/paopao-ce/pkg/utils"
+	"github.com/rocboss/paopao-ce/pkg/xerror"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	_maxLoginErrTimes = 10
 )
 
 var (
@@ -14,12 +32,54 @@ var (
 )
 
 type userSrv struct {
-	*base.BaseServant
 	api.UnimplementedUserServant
+	*base.DaoServant
+	ac core.AppCache
+}
+
+// Login 拥有管理后台权限的用户登录，签发管理后台专用token并记录会话
+func (s *userSrv) Login(req *api.LoginReq) (*api.LoginResp, mir.Error) {
+	ctx := context.Background()
+	user, err := s.Ds.GetUserByUsername(req.Name)
+	if err != nil || user.Model == nil || user.ID <= 0 {
+		return nil, xerror.UnauthorizedAuthNotExist
+	}
+	if count, err := s.Redis.GetCountLoginErr(ctx, user.ID); err == nil && count >= _maxLoginErrTimes {
+		return nil, web.ErrTooManyLoginError
+	}
+	if strings.Compare(user.Password, utils.EncodeMD5(utils.EncodeMD5(req.Passwd)+user.Salt)) != 0 {
+		s.Redis.IncrCountLoginErr(ctx, user.ID)
+		return nil, xerror.UnauthorizedAuthFailed
+	}
+	s.Redis.DelCountLoginErr(ctx, user.ID)
+	if user.Status != ms.UserStatusNormal || !s.Ds.HasPermission(user, cs.PermAdminConsole) {
+		return nil, web.ErrNoAdminPermission
+	}
+	// 每次登录生成新会话，同一管理员仅保留最近一次登录的会话
+	sessionId := uuid.Must(uuid.NewV4()).String()
+	if err = s.ac.Set(conf.KeyAdminSession.Get(user.ID), []byte(sessionId), int64(conf.JWTSetting.AdminExpire.Seconds())); err != nil {
+		logrus.Errorf("create admin session err: %s", err)
+		return nil, web.ErrAdminSessionFailed
+	}
+	token, err := app.GenerateAdminToken(user, sessionId)
+	if err != nil {
+		logrus.Errorf("app.GenerateAdminToken err: %s", err)
+		return nil, xerror.UnauthorizedTokenGenerate
+	}
+	return &api.LoginResp{
+		UserInfo: api.UserInfo{
+			Name: user.Username,
+		},
+		ServerInfo: api.ServerInfo{
+			ApiVer: "v1",
+		},
+		JwtToken: token,
+	}, nil
 }
 
-func newUserSrv() api.User {
+func newUserSrv(s *base.DaoServant, ac core.AppCache) api.User {
 	return &userSrv{
-		BaseServant: base.NewBaseServant(),
+		DaoServant: s,
+		ac:         ac,
 	}
 }
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/alimy/mir/v4"
 	"github.com/cockroachdb/errors"
@@ -21,11 +23,14 @@ import (
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/dao"
 	"github.com/rocboss/paopao-ce/internal/dao/cache"
+	"github.com/rocboss/paopao-ce/internal/dao/search"
 	"github.com/rocboss/paopao-ce/internal/infra/events"
 	"github.com/rocboss/paopao-ce/internal/model/joint"
 	"github.com/rocboss/paopao-ce/pkg/app"
+	"github.com/rocboss/paopao-ce/pkg/geo"
 	"github.com/rocboss/paopao-ce/pkg/types"
 	"github.com/rocboss/paopao-ce/pkg/xerror"
+	"github.com/sirupsen/logrus"
 )
 
 type BaseServant struct {
@@ -39,6 +44,7 @@ type DaoServant struct {
 	Dsa   core.WebDataServantA
 	Ds    core.DataService
 	Ts    core.TweetSearchService
+	Es    core.EntitySearchService
 	Redis core.RedisCache
 }
 
@@ -234,8 +240,12 @@ func (s *DaoServant) PrepareMessages(userId int64, messages []*ms.MessageFormate
 func (s *DaoServant) PrepareTweet(user *ms.User, tweet *ms.PostFormated) error {
 	// guest用户
 	if user == nil {
+		coarsenTweetLocation(-1, tweet)
+		prepareTweetOrigin(-1, tweet)
 		return nil
 	}
+	coarsenTweetLocation(user.ID, tweet)
+	prepareTweetOrigin(user.ID, tweet)
 	// 转换一下可见性的值
 	tweet.Visibility = ms.PostVisibleT(tweet.Visibility.ToOutValue())
 	friendMap, err := s.Ds.IsMyFriend(user.ID, tweet.GetHostID())
@@ -256,6 +266,8 @@ func (s *DaoServant) PrepareTweets(userId int64, tweets []*ms.PostFormated) erro
 		userIdSet[tweet.GetHostID()] = types.Empty{}
 		// 顺便转换一下可见性的值
 		tweet.Visibility = ms.PostVisibleT(tweet.Visibility.ToOutValue())
+		coarsenTweetLocation(userId, tweet)
+		prepareTweetOrigin(userId, tweet)
 	}
 	// guest用户的userId<0
 	if userId < 0 {
@@ -275,6 +287,28 @@ func (s *DaoServant) PrepareTweets(userId int64, tweets []*ms.PostFormated) erro
 	return nil
 }
 
+// coarsenTweetLocation 向作者以外的用户展示动态时模糊精确坐标并隐藏详细地址，未加载附近配置时不模糊
+func coarsenTweetLocation(userId int64, tweet *ms.PostFormated) {
+	if conf.NearbySetting == nil {
+		return
+	}
+	precision := conf.NearbySetting.CoarsenPrecision
+	if precision <= 0 || tweet.GetHostID() == userId {
+		return
+	}
+	tweet.LocationLat, tweet.LocationLng = geo.Coarsen(tweet.LocationLat, tweet.LocationLng, precision)
+	tweet.LocationAddress = ""
+}
+
+// prepareTweetOrigin 转发附带的原动态同样转换可见性并模糊位置
+func prepareTweetOrigin(userId int64, tweet *ms.PostFormated) {
+	if tweet.Origin == nil {
+		return
+	}
+	tweet.Origin.Visibility = ms.PostVisibleT(tweet.Origin.Visibility.ToOutValue())
+	coarsenTweetLocation(userId, tweet.Origin)
+}
+
 func (s *DaoServant) GetTweetBy(id int64) (*ms.PostFormated, error) {
 	post, err := s.Ds.GetPostByID(id)
 	if err != nil {
@@ -330,22 +364,10 @@ func (s *DaoServant) pushAllPostToSearch() error {
 		}
 		i, nums := 0, int(math.Ceil(float64(totalRows)/float64(splitNum)))
 		for {
-			postsFormated, xerr := s.Ds.MergePosts(posts)
-			if xerr != nil || len(posts) != len(postsFormated) {
-				continue
-			}
-			for i, pf := range postsFormated {
-				contentFormated := ""
-				for _, content := range pf.Contents {
-					if content.Type == ms.ContentTypeText || content.Type == ms.ContentTypeTitle {
-						contentFormated = contentFormated + content.Content + "\n"
-					}
-				}
-				docs := []core.TsDocItem{{
-					Post:    posts[i],
-					Content: contentFormated,
-				}}
-				s.Ts.AddDocuments(docs, fmt.Sprintf("%d", posts[i].ID))
+			if docs, xerr := search.TweetDocItems(s.Ds, posts); xerr == nil {
+				s.Ts.AddDocuments(docs)
+			} else {
+				logrus.Errorf("build tweets search docs failed: %s, limit[%d] offset[%d]", xerr, splitNum, i*splitNum)
 			}
 			if i++; i >= nums {
 				break
@@ -368,25 +390,116 @@ func (s *DaoServant) PushPostToSearch(post *ms.Post) {
 }
 
 func (s *DaoServant) pushPostToSearch(post *ms.Post) {
-	postFormated := post.Format()
-	postFormated.User = &ms.UserFormated{
-		ID: post.GetHostID(),
+	// 未发布的动态不进入搜索
+	if post.Status != ms.PostStatusPublished {
+		return
+	}
+	docs, err := search.TweetDocItems(s.Ds, []*ms.Post{post})
+	if err != nil {
+		logrus.Errorf("build post[%d] search doc failed: %s", post.ID, err)
+		return
+	}
+	s.Ts.AddDocuments(docs, fmt.Sprintf("%d", post.ID))
+}
+
+// PublishTweet 发布草稿、定时或等待录音的动态，按发布时间进入时间线
+func (s *DaoServant) PublishTweet(post *ms.Post) error {
+	post.Status, post.ScheduledOn = ms.PostStatusPublished, 0
+	post.LatestRepliedOn = time.Now().Unix()
+	if err := s.Ds.UpdatePost(post); err != nil {
+		return err
 	}
-	contents, _ := s.Ds.GetPostContentsByIDs([]int64{post.ID})
-	for _, content := range contents {
-		postFormated.Contents = append(postFormated.Contents, content.Format())
+	s.OnTweetPublished(post)
+	return nil
+}
+
+// OnTweetPublished 动态发布后创建标签、推送搜索并计入被引用动态的转发数
+func (s *DaoServant) OnTweetPublished(post *ms.Post) {
+	// 私密推文不创建标签
+	if post.Visibility != core.PostVisitPrivate && post.Tags != "" {
+		if tagInfos, err := s.Ds.UpsertTags(post.GetHostID(), strings.Split(post.Tags, ",")); err == nil {
+			s.PushTopicsToSearch(tagInfos.IDs()...)
+		}
 	}
-	contentFormated := ""
-	for _, content := range postFormated.Contents {
-		if content.Type == ms.ContentTypeText || content.Type == ms.ContentTypeTitle {
-			contentFormated = contentFormated + content.Content + "\n"
+	s.PushPostToSearch(post)
+	if post.RepostType == ms.PostRepostQuote && post.OriginID > 0 {
+		if origin, err := s.Ds.GetPostByID(post.OriginID); err == nil {
+			s.UpdateShareCount(origin, 1)
 		}
 	}
-	docs := []core.TsDocItem{{
-		Post:    post,
-		Content: contentFormated,
-	}}
-	s.Ts.AddDocuments(docs, fmt.Sprintf("%d", post.ID))
+}
+
+// UpdateShareCount 更新原动态的转发数，计入动态指标
+func (s *DaoServant) UpdateShareCount(origin *ms.Post, delta int64) {
+	if err := s.Ds.UpdatePostShareCount(origin, delta); err != nil {
+		logrus.Errorf("Ds.UpdatePostShareCount update share count of post[%d] failed: %s", origin.ID, err)
+		return
+	}
+	s.PushPostToSearch(origin)
+}
+
+// TranscribeRecording 异步转写某个说话人的录音，保存字幕后刷新动态的搜索文档
+func (s *DaoServant) TranscribeRecording(post *ms.Post, speakerId int64, audioUrl string) {
+	events.OnEvent(&transcribeRecordingEvent{
+		fn: func() error {
+			segments, err := s.Ds.Transcribe(audioUrl)
+			if err != nil {
+				return fmt.Errorf("transcribe post[%d] speaker[%d] recording failed: %w", post.ID, speakerId, err)
+			}
+			if len(segments) == 0 {
+				return nil
+			}
+			for _, seg := range segments {
+				seg.SpeakerID = speakerId
+			}
+			if err = s.Ds.SaveTranscript(post.ID, speakerId, segments); err != nil {
+				return err
+			}
+			// 转写期间动态可能已发布，重新获取后再推送
+			if post, err = s.Ds.GetPostByID(post.ID); err != nil {
+				return err
+			}
+			s.pushPostToSearch(post)
+			return nil
+		},
+	})
+}
+
+// PushProfileToSearch 记录用户及其房间变更到搜索发件箱，房间文档冗余了房主资料
+func (s *DaoServant) PushProfileToSearch(userId int64) {
+	s.pushSync(cs.SearchIndexUser, userId)
+	if room, err := s.Ds.GetRoomByHostID(userId); err == nil && room != nil {
+		s.pushSync(cs.SearchIndexRoom, room.ID)
+	}
+}
+
+// PushRoomsToSearch 记录房间变更到搜索发件箱
+func (s *DaoServant) PushRoomsToSearch(ids ...int64) {
+	s.pushSync(cs.SearchIndexRoom, ids...)
+}
+
+// PushTopicsToSearch 记录话题变更到搜索发件箱
+func (s *DaoServant) PushTopicsToSearch(ids ...int64) {
+	s.pushSync(cs.SearchIndexTopic, ids...)
+}
+
+// PushPostTagsToSearch 动态的标签引用数变化后记录话题变更到搜索发件箱
+func (s *DaoServant) PushPostTagsToSearch(post *ms.Post) {
+	if post.Tags == "" {
+		return
+	}
+	tagInfos, err := s.Ds.TagsByName(strings.Split(post.Tags, ","))
+	if err != nil {
+		logrus.Errorf("Ds.TagsByName post[%d] failed: %s", post.ID, err)
+		return
+	}
+	s.PushTopicsToSearch(tagInfos.IDs()...)
+}
+
+func (s *DaoServant) pushSync(index cs.SearchIndex, ids ...int64) {
+	if err := search.PushSync(s.Ds, index, ids...); err != nil {
+		logrus.Errorf("push %s%v to search outbox failed: %s", index, ids, err)
+	}
 }
 
 func (s *DaoServant) DeleteSearchPost(post *ms.Post) error {
@@ -414,10 +527,13 @@ func (s *DaoServant) RelationTypFrom(me *ms.User, username string) (res *cs.Vist
 		return
 	}
 	// visit by admin/friend/other
-	if me.IsAdmin {
+	if s.Ds.HasPermission(me, cs.PermTweetViewAll) {
 		res.RelTyp = cs.RelationAdmin
 	} else if s.Ds.IsFriend(me.ID, he.ID) {
 		res.RelTyp = cs.RelationFriend
+	} else if s.Ds.IsFollow(me.ID, he.ID) {
+		// 只有已通过的关注者可见仅关注者可见的动态
+		res.RelTyp = cs.RelationFollowing
 	} else {
 		res.RelTyp = cs.RelationGuest
 	}
@@ -452,5 +568,6 @@ func NewDaoServant() *DaoServant {
 		Dsa:         dao.WebDataServantA(),
 		Ds:          dao.DataService(),
 		Ts:          dao.TweetSearchService(),
+		Es:          dao.EntitySearchService(),
 	}
 }
@@ -46,6 +46,11 @@ type pushAllPostToSearchEvent struct {
 	fn func() error
 }
 
+type transcribeRecordingEvent struct {
+	event.UnimplementedEvent
+	fn func() error
+}
+
 func OnCacheRespEvent(ac core.AppCache, key string, data any, expire int64) {
 	events.OnEvent(&CacheRespEvent{
 		ac:     ac,
@@ -127,3 +132,11 @@ func (p *pushAllPostToSearchEvent) Name() string {
 func (p *pushAllPostToSearchEvent) Action() error {
 	return p.fn()
 }
+
+func (p *transcribeRecordingEvent) Name() string {
+	return "servants.base.transcribeRecordingEvent"
+}
+
+func (p *transcribeRecordingEvent) Action() error {
+	return p.fn()
+}
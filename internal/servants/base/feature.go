@@ -0,0 +1,51 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package base
+
+import (
+	"github.com/alimy/tryst/cfg"
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+)
+
+// FeatureToggles 可在运行时切换的功能开关，仅包含处理请求时才判断的功能，
+// 启动时装配服务的功能仍由配置文件的 Features 决定
+var FeatureToggles = []string{
+	"Web:DisallowUserRegister",
+	"Sms",
+}
+
+// FeatureEnabled 功能开关当前是否开启，切换结果保存在缓存中由所有服务实例共享，
+// 未切换过或读取缓存失败时使用配置文件的设定
+func FeatureEnabled(ac core.AppCache, feature string) bool {
+	data, err := ac.Get(conf.PrefixFeatureToggle + feature)
+	if err != nil || len(data) == 0 {
+		return cfg.If(feature)
+	}
+	return data[0] == '1'
+}
+
+// ToggleFeature 切换功能开关
+func ToggleFeature(ac core.AppCache, feature string, enabled bool) error {
+	value := []byte{'0'}
+	if enabled {
+		value[0] = '1'
+	}
+	return ac.Set(conf.PrefixFeatureToggle+feature, value, 0)
+}
+
+// FeatureTogglesFrom 所有可切换的功能开关及其当前状态
+func FeatureTogglesFrom(ac core.AppCache) []*cs.FeatureToggle {
+	res := make([]*cs.FeatureToggle, 0, len(FeatureToggles))
+	for _, feature := range FeatureToggles {
+		res = append(res, &cs.FeatureToggle{
+			Feature: feature,
+			Default: cfg.If(feature),
+			Enabled: FeatureEnabled(ac, feature),
+		})
+	}
+	return res
+}
@@ -5,16 +5,25 @@
 package chain
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/pkg/app"
+	"github.com/rocboss/paopao-ce/pkg/xerror"
 )
 
-func Admin() gin.HandlerFunc {
+// Staff 要求用户拥有任意运营管理权限，具体操作所需的权限由各接口自行检查
+func Staff() gin.HandlerFunc {
+	ams := authorizationManageService()
 	return func(c *gin.Context) {
 		if user, exist := c.Get("USER"); exist {
 			if userModel, ok := user.(*ms.User); ok {
-				if userModel.Status == ms.UserStatusNormal && userModel.IsAdmin {
+				if userModel.Status == ms.UserStatusNormal && (userModel.IsAdmin || ams.UserPermissions(userModel).IsStaff()) {
 					c.Next()
 					return
 				}
@@ -26,3 +35,47 @@ func Admin() gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// AdminJWT 管理后台专用鉴权，仅接受管理后台签发且会话仍然有效的token，
+// 并要求用户状态正常且仍拥有管理后台权限
+func AdminJWT() gin.HandlerFunc {
+	ums := userManageService()
+	ams := authorizationManageService()
+	return func(c *gin.Context) {
+		ecode := xerror.Success
+		token := c.GetHeader("Authorization")
+		if token == "" || !strings.HasPrefix(token, "Bearer ") {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(xerror.UnauthorizedTokenError)
+			c.Abort()
+			return
+		}
+		if claims, err := app.ParseAdminToken(token[7:]); err == nil {
+			// 管理后台会话，退出登录或被强制下线后失效
+			if session, err := _ac.Get(conf.KeyAdminSession.Get(claims.UID)); err != nil || string(session) != claims.ID {
+				ecode = xerror.UnauthorizedTokenTimeout
+			} else if user, err := ums.GetUserByID(claims.UID); err != nil {
+				ecode = xerror.UnauthorizedAuthNotExist
+			} else if app.AdminIssuer(user) != claims.Issuer {
+				ecode = xerror.UnauthorizedTokenTimeout
+			} else if user.Status != ms.UserStatusNormal || !ams.HasPermission(user, cs.PermAdminConsole) {
+				ecode = _errNoAdminPermission
+			} else {
+				c.Set("USER", user)
+				c.Set("UID", claims.UID)
+				c.Set("USERNAME", claims.Username)
+			}
+		} else if errors.Is(err, jwt.ErrTokenExpired) {
+			ecode = xerror.UnauthorizedTokenTimeout
+		} else {
+			ecode = xerror.UnauthorizedTokenError
+		}
+		if ecode != xerror.Success {
+			response := app.NewResponse(c)
+			response.ToErrorResponse(ecode)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
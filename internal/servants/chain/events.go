@@ -6,6 +6,9 @@ package chain
 
 import (
 	"github.com/alimy/tryst/event"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao"
 	"github.com/rocboss/paopao-ce/internal/infra/events"
 	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/sirupsen/logrus"
@@ -21,9 +24,34 @@ func (e *AuditHookEvent) Name() string {
 }
 
 func (e *AuditHookEvent) Action() error {
-	// TODO: just log event now， will add real logic in future.
-	logrus.Debugf("auditHook event action style[%s] id[%d]", e.ami.Style, e.ami.Id)
-	return nil
+	logrus.Debugf("auditHook event action style[%s] id[%d] verdict[%s]", e.ami.Style, e.ami.Id, e.ami.Verdict)
+	if e.ami.Verdict != cs.ModerationFlag {
+		return nil
+	}
+	// 内容审核标记待审的内容自动提交到举报审核队列，由管理员复核
+	var targetType cs.ReportTargetType
+	switch e.ami.Style {
+	case web.AuditStyleUserTweet:
+		targetType = cs.ReportTargetPost
+	case web.AuditStyleUserTweetComment:
+		targetType = cs.ReportTargetComment
+	case web.AuditStyleUserTweetReply:
+		targetType = cs.ReportTargetReply
+	case web.AuditStyleUserProfile:
+		targetType = cs.ReportTargetUser
+	default:
+		return nil
+	}
+	_, err := dao.DataService().CreateReport(&ms.Report{
+		TargetType:   targetType,
+		TargetID:     e.ami.Id,
+		TargetUserID: e.ami.UserId,
+		Reason:       cs.ReportReasonAutoFlagged,
+		Evidence: &cs.ReportEvidence{
+			Labels: e.ami.Labels,
+		},
+	})
+	return err
 }
 
 func OnAudiotHookEvent(ami *web.AuditMetaInfo) {
@@ -5,11 +5,19 @@
 package chain
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/infra/metrics"
 )
 
+const (
+	// 日活标记保留31天
+	_dailyActiveUserExpire = 31 * 24 * 3600
+)
+
 type OnlineUserMetric struct {
 	metrics.BaseMetric
 	ac     core.AppCache
@@ -32,6 +40,15 @@ func (m *OnlineUserMetric) Name() string {
 func (m *OnlineUserMetric) Action() (err error) {
 	// 暂时仅做标记，不存储其他相关信息
 	m.ac.SetNx(conf.KeyOnlineUser.Get(m.uid), []byte{}, m.expire)
+	// 在线用户集合，用于统计在线用户
+	m.ac.MarkOnlineUser(m.uid, m.expire)
+	// 记录当日活跃用户，用于统计日活
+	m.ac.PfAdd(DailyActiveUserKey(time.Now()), _dailyActiveUserExpire, strconv.FormatInt(m.uid, 10))
 	
 	return
 }
+
+// DailyActiveUserKey 某日活跃用户的HyperLogLog key
+func DailyActiveUserKey(day time.Time) string {
+	return conf.PrefixDailyActiveUser + day.Format("2006-01-02")
+}
@@ -7,6 +7,7 @@ package servants
 import (
 	"github.com/alimy/tryst/cfg"
 	"github.com/gin-gonic/gin"
+	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/servants/admin"
 	"github.com/rocboss/paopao-ce/internal/servants/bot"
 	"github.com/rocboss/paopao-ce/internal/servants/docs"
@@ -29,6 +30,11 @@ func RegisterWebServants(e *gin.Engine) {
 	web.RouteWeb(e)
 }
 
+// UseRoomInvitePush set the room invite push service used by web servants
+func UseRoomInvitePush(p core.RoomInvitePushService) {
+	web.UseRoomInvitePush(p)
+}
+
 // RegisterAdminServants register all the servants to gin.Engine
 func RegisterAdminServants(e *gin.Engine) {
 	admin.RouteManager(e)
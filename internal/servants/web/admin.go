@@ -8,10 +8,13 @@ import (
 	"time"
 
 	"github.com/alimy/mir/v4"
+	"github.com/cockroachdb/errors"
 	"github.com/gin-gonic/gin"
 	api "github.com/rocboss/paopao-ce/auto/api/v1"
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
 	"github.com/rocboss/paopao-ce/internal/servants/chain"
@@ -31,23 +34,42 @@ type adminSrv struct {
 }
 
 func (s *adminSrv) Chain() gin.HandlersChain {
-	return gin.HandlersChain{chain.JWT(), chain.Admin()}
+	return gin.HandlersChain{chain.JWT(), chain.Staff()}
 }
 
 func (s *adminSrv) ChangeUserStatus(req *web.ChangeUserStatusReq) mir.Error {
+	if xerr := checkRequirePermission(s.Ds, req.User, cs.PermUserSuspend); xerr != nil {
+		return xerr
+	}
 	user, err := s.Ds.GetUserByID(req.ID)
 	if err != nil || user.Model == nil || user.ID <= 0 {
 		return web.ErrNoExistUsername
 	}
+	if !s.Ds.CanManageUser(req.User, user) {
+		return web.ErrNoManageHigherRole
+	}
 	// 执行更新
-	user.Status = req.Status
-	if err := s.Ds.UpdateUser(user); err != nil {
+	if err := s.changeUserStatus(user, req.Status); err != nil {
 		return xerror.ServerError
 	}
+	act := cs.ModerationActSuspendUser
+	if req.Status == ms.UserStatusNormal {
+		act = cs.ModerationActRestoreUser
+	}
+	s.logModerationAction(&ms.ModerationLog{
+		OperatorID:   req.User.ID,
+		Action:       act,
+		TargetType:   cs.ReportTargetUser,
+		TargetID:     user.ID,
+		TargetUserID: user.ID,
+	})
 	return nil
 }
 
 func (s *adminSrv) SiteInfo(req *web.SiteInfoReq) (*web.SiteInfoResp, mir.Error) {
+	if xerr := checkRequirePermission(s.Ds, req.User, cs.PermSiteStats); xerr != nil {
+		return nil, xerr
+	}
 	res, err := &web.SiteInfoResp{ServerUpTime: s.serverUpTime}, error(nil)
 	res.RegisterUserCount, err = s.Ds.GetRegisterUserCount()
 	if err != nil {
@@ -66,6 +88,252 @@ func (s *adminSrv) SiteInfo(req *web.SiteInfoReq) (*web.SiteInfoResp, mir.Error)
 	return res, nil
 }
 
+func (s *adminSrv) ListReports(req *web.ListReportsReq) (*web.ListReportsResp, mir.Error) {
+	if xerr := checkRequirePermission(s.Ds, req.User, cs.PermReportHandle); xerr != nil {
+		return nil, xerr
+	}
+	reports, total, err := s.Ds.ListReports(req.Status, req.TargetType, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListReports err: %s", err)
+		return nil, web.ErrGetReportsFailed
+	}
+	resp := base.PageRespFrom(reports, req.Page, req.PageSize, total)
+	return (*web.ListReportsResp)(resp), nil
+}
+
+func (s *adminSrv) HandleReport(req *web.HandleReportReq) (*web.HandleReportResp, mir.Error) {
+	if !req.Action.IsValid() {
+		return nil, xerror.InvalidParams
+	}
+	if xerr := checkRequirePermission(s.Ds, req.User, cs.PermReportHandle); xerr != nil {
+		return nil, xerr
+	}
+	// 执行审核动作还需要拥有对应的权限
+	if perm := moderationActionPermission(req.Action); perm != "" {
+		if xerr := checkRequirePermission(s.Ds, req.User, perm); xerr != nil {
+			return nil, xerr
+		}
+	}
+	report, err := s.Ds.GetReportByID(req.ID)
+	if err != nil {
+		return nil, web.ErrReportNotFound
+	}
+	if report.Status == cs.ReportStatusDismissed || report.Status == cs.ReportStatusActioned {
+		return nil, web.ErrReportHasBeenResolved
+	}
+	if xerr := s.applyModerationAction(req.User, report, req.Action); xerr != nil {
+		return nil, xerr
+	}
+	switch req.Action {
+	case cs.ModerationActTriage:
+		report.Status = cs.ReportStatusTriaged
+	case cs.ModerationActDismiss:
+		report.Status = cs.ReportStatusDismissed
+	default:
+		report.Status = cs.ReportStatusActioned
+	}
+	if req.Action.IsResolved() {
+		report.Resolution = req.Action
+		report.HandledOn = time.Now().Unix()
+	}
+	report.HandlerID = req.User.ID
+	if err = s.Ds.UpdateReport(report); err != nil {
+		logrus.Errorf("Ds.UpdateReport err: %s", err)
+		return nil, web.ErrHandleReportFailed
+	}
+	s.logModerationAction(&ms.ModerationLog{
+		OperatorID:   req.User.ID,
+		ReportID:     report.ID,
+		Action:       req.Action,
+		TargetType:   report.TargetType,
+		TargetID:     report.TargetID,
+		TargetUserID: report.TargetUserID,
+		Note:         req.Note,
+	})
+	return &web.HandleReportResp{
+		ID:         report.ID,
+		Status:     report.Status,
+		Resolution: report.Resolution,
+	}, nil
+}
+
+func (s *adminSrv) ListModerationLogs(req *web.ListModerationLogsReq) (*web.ListModerationLogsResp, mir.Error) {
+	if xerr := checkRequirePermission(s.Ds, req.User, cs.PermReportHandle); xerr != nil {
+		return nil, xerr
+	}
+	logs, total, err := s.Ds.ListModerationLogs(req.ReportID, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListModerationLogs err: %s", err)
+		return nil, web.ErrGetModerationLogsFailed
+	}
+	resp := base.PageRespFrom(logs, req.Page, req.PageSize, total)
+	return (*web.ListModerationLogsResp)(resp), nil
+}
+
+// ChangeRoomStatus 下架或恢复房间，下架的房间不再出现在搜索中且不能再邀请加入
+func (s *adminSrv) ChangeRoomStatus(req *web.ChangeRoomStatusReq) mir.Error {
+	if xerr := checkRequirePermission(s.Ds, req.User, cs.PermRoomManage); xerr != nil {
+		return xerr
+	}
+	room, err := s.Ds.GetRoomByID(req.ID)
+	if err != nil {
+		return web.ErrRoomNotFound
+	}
+	host, err := s.Ds.GetUserByID(room.HostID)
+	if err != nil || host.Model == nil || host.ID <= 0 {
+		return web.ErrRoomNotFound
+	}
+	if !s.Ds.CanManageUser(req.User, host) {
+		return web.ErrNoManageHigherRole
+	}
+	blocked, act := int16(0), cs.ModerationActUnblockRoom
+	if req.Blocked {
+		blocked, act = 1, cs.ModerationActBlockRoom
+	}
+	if room.IsBlockedFromSpace != blocked {
+		if err = s.Ds.UpdateRoom(room.ID, map[string]any{"is_blocked_from_space": blocked}); err != nil {
+			logrus.Errorf("Ds.UpdateRoom err: %s", err)
+			return web.ErrUpdateRoomFailed
+		}
+		s.PushRoomsToSearch(room.ID)
+	}
+	s.logModerationAction(&ms.ModerationLog{
+		OperatorID:   req.User.ID,
+		Action:       act,
+		TargetType:   cs.ReportTargetRoom,
+		TargetID:     room.ID,
+		TargetUserID: host.ID,
+		Note:         req.Note,
+	})
+	return nil
+}
+
+// applyModerationAction 对举报对象执行审核动作
+func (s *adminSrv) applyModerationAction(operator *ms.User, report *ms.Report, act cs.ModerationAction) mir.Error {
+	switch act {
+	case cs.ModerationActHidePost, cs.ModerationActLockComment:
+		post, err := s.reportedPost(report)
+		if err != nil {
+			return web.ErrInvalidModerationAction
+		}
+		if act == cs.ModerationActHidePost && post.HiddenOn == 0 {
+			// 隐藏状态归管理员所有，作者不能再改回可见
+			if err = s.Ds.HidePost(post); err != nil {
+				logrus.Errorf("Ds.HidePost err: %s", err)
+				return web.ErrHandleReportFailed
+			}
+			s.PushPostToSearch(post)
+		} else if act == cs.ModerationActLockComment && post.LockedOn == 0 {
+			// 锁定状态同样归管理员所有，作者不能再解除
+			if err = s.Ds.ModeratorLockPost(post); err != nil {
+				logrus.Errorf("Ds.ModeratorLockPost err: %s", err)
+				return web.ErrHandleReportFailed
+			}
+		}
+	case cs.ModerationActSuspendUser:
+		user, err := s.Ds.GetUserByID(report.TargetUserID)
+		if err != nil || user.Model == nil || user.ID <= 0 {
+			return web.ErrInvalidModerationAction
+		}
+		if !s.Ds.CanManageUser(operator, user) {
+			return web.ErrNoManageHigherRole
+		}
+		if err = s.changeUserStatus(user, ms.UserStatusClosed); err != nil {
+			logrus.Errorf("changeUserStatus err: %s", err)
+			return web.ErrHandleReportFailed
+		}
+	case cs.ModerationActBanFromRoom:
+		// 封禁的是账户，被封禁的用户不能再主持或进入任何房间
+		user, err := s.Ds.GetUserByID(report.TargetUserID)
+		if err != nil || user.Model == nil || user.ID <= 0 {
+			return web.ErrInvalidModerationAction
+		}
+		if !s.Ds.CanManageUser(operator, user) {
+			return web.ErrNoManageHigherRole
+		}
+		if user.RoomBannedOn == 0 {
+			user.RoomBannedOn = time.Now().Unix()
+			if err = s.Ds.UpdateUser(user); err != nil {
+				logrus.Errorf("Ds.UpdateUser err: %s", err)
+				return web.ErrHandleReportFailed
+			}
+			onChangeUsernameEvent(user.ID, user.Username)
+		}
+		// 同时下架其主持的房间
+		if room, err := s.Ds.GetRoomByHostID(user.ID); err == nil && room.IsBlockedFromSpace == 0 {
+			if err = s.Ds.UpdateRoom(room.ID, map[string]any{"is_blocked_from_space": int16(1)}); err != nil {
+				logrus.Errorf("Ds.UpdateRoom err: %s", err)
+				return web.ErrHandleReportFailed
+			}
+			s.PushRoomsToSearch(room.ID)
+		}
+	}
+	return nil
+}
+
+// moderationActionPermission 执行审核动作所需的权限
+func moderationActionPermission(act cs.ModerationAction) cs.Permission {
+	switch act {
+	case cs.ModerationActHidePost, cs.ModerationActLockComment:
+		return cs.PermContentManage
+	case cs.ModerationActSuspendUser:
+		return cs.PermUserSuspend
+	case cs.ModerationActBanFromRoom:
+		return cs.PermRoomManage
+	}
+	return ""
+}
+
+// reportedPost 获取举报对象关联的动态
+func (s *adminSrv) reportedPost(report *ms.Report) (*ms.Post, error) {
+	postId := report.TargetID
+	switch report.TargetType {
+	case cs.ReportTargetPost:
+		// nothing
+	case cs.ReportTargetComment:
+		comment, err := s.Ds.GetCommentByID(report.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		postId = comment.PostID
+	case cs.ReportTargetReply:
+		reply, err := s.Ds.GetCommentReplyByID(report.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		comment, err := s.Ds.GetCommentByID(reply.CommentID)
+		if err != nil {
+			return nil, err
+		}
+		postId = comment.PostID
+	default:
+		return nil, errors.Newf("report target %s has no post", report.TargetType)
+	}
+	return s.Ds.GetPostByID(postId)
+}
+
+// changeUserStatus 更新用户状态并清理用户缓存
+func (s *adminSrv) changeUserStatus(user *ms.User, status int) error {
+	if user.Status == status {
+		return nil
+	}
+	user.Status = status
+	if err := s.Ds.UpdateUser(user); err != nil {
+		return err
+	}
+	onChangeUsernameEvent(user.ID, user.Username)
+	// 同时重新索引其房间，房主状态异常时房间不再出现在搜索中
+	s.PushProfileToSearch(user.ID)
+	return nil
+}
+
+// logModerationAction 记录审核日志，失败不影响审核结果
+func (s *adminSrv) logModerationAction(log *ms.ModerationLog) {
+	if err := s.Ds.CreateModerationLog(log); err != nil {
+		logrus.Errorf("Ds.CreateModerationLog err: %s", err)
+	}
+}
+
 func newAdminSrv(s *base.DaoServant, wc core.WebCache) api.Admin {
 	return &adminSrv{
 		DaoServant:   s,
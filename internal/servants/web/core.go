@@ -7,6 +7,8 @@ package web
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -17,10 +19,12 @@ import (
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/dao/cache"
 	"github.com/rocboss/paopao-ce/internal/model/joint"
 	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
 	"github.com/rocboss/paopao-ce/internal/servants/chain"
+	"github.com/rocboss/paopao-ce/pkg/geo"
 	"github.com/rocboss/paopao-ce/pkg/xerror"
 	"github.com/sirupsen/logrus"
 	"github.com/golang-jwt/jwt/v5"
@@ -28,8 +32,9 @@ import (
 
 var (
 	// _MaxWhisperNumDaily 当日单用户私信总数限制（TODO 配置化、积分兑换等）
-	_maxWhisperNumDaily int64 = 200
-	_maxCaptchaTimes    int   = 2
+	_maxWhisperNumDaily    int64 = 200
+	_maxCaptchaTimes       int   = 2
+	_maxMentionSuggestions int   = 10
 )
 
 var (
@@ -50,7 +55,7 @@ func (s *coreSrv) Chain() gin.HandlersChain {
 }
 
 func (s *coreSrv) SyncSearchIndex(req *web.SyncSearchIndexReq) mir.Error {
-	if req.User != nil && req.User.IsAdmin {
+	if req.User != nil && s.Ds.HasPermission(req.User, cs.PermSearchManage) {
 		s.PushAllPostToSearch()
 	} else {
 		logrus.Warnf("sync search index need admin permision user: %#v", req.User)
@@ -80,6 +85,8 @@ func (s *coreSrv) GetUserInfo(req *web.UserInfoReq) (*web.UserInfoResp, mir.Erro
 		Follows:     follows,
 		Followings:  followings,
 		TweetsCount: user.TweetsCount,
+		DmPolicy:    user.DmPolicy,
+		IsPrivate:   user.IsPrivate,
 		IsOnline:   s.Ds.IsUserOnline(user.ID), // Add online status
 	}
 	if user.Phone != "" && len(user.Phone) == 11 {
@@ -89,6 +96,13 @@ func (s *coreSrv) GetUserInfo(req *web.UserInfoReq) (*web.UserInfoResp, mir.Erro
 }
 
 func (s *coreSrv) GetMessages(req *web.GetMessagesReq) (res *web.GetMessagesResp, _ mir.Error) {
+	if !req.Category.Valid() {
+		return nil, web.ErrInvalidMsgCategory
+	}
+	cursor, xerr := pageCursorFrom(req.Cursor)
+	if xerr != nil {
+		return nil, xerr
+	}
 	limit, offset := req.PageSize, (req.Page-1)*req.PageSize
 	// 尝试直接从缓存中获取数据
 	key, ok := "", false
@@ -96,7 +110,17 @@ func (s *coreSrv) GetMessages(req *web.GetMessagesReq) (res *web.GetMessagesResp
 		// logrus.Debugf("coreSrv.GetMessages from cache key:%s", key)
 		return
 	}
-	messages, totalRows, err := s.Ds.GetMessages(req.Uid, req.Style, limit, offset)
+	var (
+		messages  []*ms.MessageFormated
+		totalRows int64
+		next      *cs.PageCursor
+		err       error
+	)
+	if cursor != nil {
+		messages, next, err = s.Ds.GetMessagesByCursor(req.Uid, req.Style, req.Category, cursor, limit)
+	} else {
+		messages, totalRows, err = s.Ds.GetMessages(req.Uid, req.Style, req.Category, limit, offset)
+	}
 	if err != nil {
 		logrus.Errorf("Ds.GetMessages err[1]: %s", err)
 		return nil, web.ErrGetMessagesFailed
@@ -140,11 +164,21 @@ func (s *coreSrv) GetMessages(req *web.GetMessagesReq) (res *web.GetMessagesResp
 		logrus.Errorf("Ds.GetMessages err[2]: %s", err)
 		return nil, web.ErrGetMessagesFailed
 	}
+	s.prepareMessageActors(messages)
 	if err = s.PrepareMessages(req.Uid, messages); err != nil {
 		logrus.Errorf("get messages err[3]: %s", err)
 		return nil, web.ErrGetMessagesFailed
 	}
-	resp := joint.PageRespFrom(messages, req.Page, req.PageSize, totalRows)
+	var resp *joint.PageResp
+	if cursor != nil {
+		resp = joint.CursorPageRespFrom(messages, req.PageSize, next.Encode())
+	} else {
+		resp = joint.PageRespFrom(messages, req.Page, req.PageSize, totalRows)
+		resp.NextCursor = offsetNextCursor(req.Page, req.PageSize, len(messages), totalRows, func() *cs.PageCursor {
+			last := messages[len(messages)-1]
+			return &cs.PageCursor{Value: last.LatestOn, ID: last.ID}
+		})
+	}
 	// 缓存处理
 	base.OnCacheRespEvent(s.wc, key, resp, s.messagesExpire)
 	return &web.GetMessagesResp{
@@ -172,7 +206,14 @@ func (s *coreSrv) ReadMessage(req *web.ReadMessageReq) mir.Error {
 }
 
 func (s *coreSrv) ReadAllMessage(req *web.ReadAllMessageReq) mir.Error {
-	if err := s.Ds.ReadAllMessage(req.Uid); err != nil {
+	if !req.Category.Valid() {
+		return web.ErrInvalidMsgCategory
+	}
+	cursor, xerr := pageCursorFrom(req.Cursor)
+	if xerr != nil {
+		return xerr
+	}
+	if err := s.Ds.ReadAllMessage(req.Uid, req.Category, cursor); err != nil {
 		logrus.Errorf("coreSrv.Ds.ReadAllMessage err: %s", err)
 		return web.ErrReadMessageFailed
 	}
@@ -181,36 +222,326 @@ func (s *coreSrv) ReadAllMessage(req *web.ReadAllMessageReq) mir.Error {
 	return nil
 }
 
+// SendUserWhisper 兼容旧的私信接口，私信以文本消息写入与对方的私信会话
 func (s *coreSrv) SendUserWhisper(req *web.SendWhisperReq) mir.Error {
-	// 不允许发送私信给自己
+	_, _, xerr := s.sendDmMessage(req.Uid, 0, req.UserID, cs.DmMsgText, req.Content)
+	return xerr
+}
+
+func (s *coreSrv) GetDmConversation(req *web.GetDmConversationReq) (*web.GetDmConversationResp, mir.Error) {
 	if req.Uid == req.UserID {
-		return web.ErrNoWhisperToSelf
+		return nil, web.ErrNoWhisperToSelf
 	}
-	// 今日频次限制
-	ctx := context.Background()
-	if count, _ := s.Redis.GetCountWhisper(ctx, req.Uid); count >= _maxWhisperNumDaily {
-		return web.ErrTooManyWhisperNum
-	}
-	// 创建私信
-	_, err := s.Ds.CreateMessage(&ms.Message{
-		SenderUserID:   req.Uid,
-		ReceiverUserID: req.UserID,
-		Type:           ms.MsgTypeWhisper,
-		Brief:          "给你发送新私信了",
-		Content:        req.Content,
-	})
+	p, err := s.Ds.GetDmParticipantByPeer(req.Uid, req.UserID)
 	if err != nil {
-		logrus.Errorf("Ds.CreateWhisper err: %s", err)
-		return web.ErrSendWhisperFailed
+		// 新建会话与发送私信遵循相同的屏蔽、私信权限及每日次数限制
+		if xerr := s.checkDmAllowed(req.Uid, req.UserID, true); xerr != nil {
+			return nil, xerr
+		}
+		if p, err = s.Ds.GetOrCreateDmConversation(req.Uid, req.UserID); err != nil {
+			logrus.Errorf("Ds.GetOrCreateDmConversation err: %s", err)
+			return nil, xerror.ServerError
+		}
+		// 写入当日（自然日）计数缓存
+		s.Redis.IncrCountWhisper(context.Background(), req.Uid)
 	}
-	// 缓存处理, 不需要处理错误
-	onMessageActionEvent(_messageActionSendWhisper, req.Uid, req.UserID)
-	// 写入当日（自然日）计数缓存
-	s.Redis.IncrCountWhisper(ctx, req.Uid)
+	return &web.GetDmConversationResp{
+		ConversationID: p.ConversationID,
+	}, nil
+}
 
+func (s *coreSrv) ListDmConversations(req *web.ListDmConversationsReq) (*web.ListDmConversationsResp, mir.Error) {
+	conversations, total, err := s.Ds.ListDmConversations(req.UserId, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListDmConversations err: %s", err)
+		return nil, web.ErrGetDmConversations
+	}
+	resp := base.PageRespFrom(conversations, req.Page, req.PageSize, total)
+	return (*web.ListDmConversationsResp)(resp), nil
+}
+
+func (s *coreSrv) GetDmMessages(req *web.GetDmMessagesReq) (*web.GetDmMessagesResp, mir.Error) {
+	cursor, xerr := pageCursorFrom(req.Cursor)
+	if xerr != nil {
+		return nil, xerr
+	}
+	if _, err := s.Ds.GetDmParticipant(req.ConversationID, req.Uid); err != nil {
+		return nil, web.ErrNoExistDmConversation
+	}
+	messages, next, err := s.Ds.ListDmMessages(req.ConversationID, cursor, req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListDmMessages err: %s", err)
+		return nil, web.ErrGetDmMessagesFailed
+	}
+	resp := &web.GetDmMessagesResp{
+		List:       messages,
+		NextCursor: next.Encode(),
+	}
+	if peer, err := s.dmPeerOf(req.ConversationID, req.Uid); err == nil {
+		resp.PeerReadID = peer.LastReadID
+	}
+	return resp, nil
+}
+
+func (s *coreSrv) SendDmMessage(req *web.SendDmMessageReq) (*web.SendDmMessageResp, mir.Error) {
+	msg, peerId, xerr := s.sendDmMessage(req.Uid, req.ConversationID, req.UserID, req.Type, req.Content)
+	if xerr != nil {
+		return nil, xerr
+	}
+	logrus.Debugf("user[%d] send dm message[%d] to user[%d]", req.Uid, msg.ID, peerId)
+	return &web.SendDmMessageResp{
+		ConversationID: msg.ConversationID,
+		Message:        msg,
+	}, nil
+}
+
+func (s *coreSrv) ReadDmConversation(req *web.ReadDmConversationReq) mir.Error {
+	p, err := s.Ds.GetDmParticipant(req.ConversationID, req.Uid)
+	if err != nil {
+		return web.ErrNoExistDmConversation
+	}
+	if err = s.Ds.ReadDmConversation(req.ConversationID, req.Uid, req.MessageID); err != nil {
+		logrus.Errorf("Ds.ReadDmConversation err: %s", err)
+		return web.ErrReadDmFailed
+	}
+	// 把新的已读位置推送给对方，用于展示已读状态
+	if p, err = s.Ds.GetDmParticipant(req.ConversationID, req.Uid); err == nil {
+		onDmPushEvent(&dmPushData{
+			Type:           _dmPushTypeRead,
+			ConversationID: req.ConversationID,
+			UserID:         req.Uid,
+			LastReadID:     p.LastReadID,
+		}, p.PeerID)
+	}
+	return nil
+}
+
+func (s *coreSrv) GetDmUnreadCount(req *web.GetDmUnreadCountReq) (*web.GetDmUnreadCountResp, mir.Error) {
+	count, err := s.Ds.CountDmUnread(req.Uid)
+	if err != nil {
+		logrus.Errorf("Ds.CountDmUnread err: %s", err)
+		return nil, xerror.ServerError
+	}
+	return &web.GetDmUnreadCountResp{
+		Count: count,
+	}, nil
+}
+
+func (s *coreSrv) ChangeDmPolicy(req *web.ChangeDmPolicyReq) mir.Error {
+	if !req.DmPolicy.Valid() {
+		return xerror.InvalidParams
+	}
+	user := req.User
+	user.DmPolicy = req.DmPolicy
+	if err := s.Ds.UpdateUser(user); err != nil {
+		logrus.Errorf("Ds.UpdateUser err: %s", err)
+		return web.ErrChangeDmPolicyFailed
+	}
+	// 发送私信时按缓存的用户信息检查私信权限
+	onChangeUserSettingEvent(user)
 	return nil
 }
 
+func (s *coreSrv) BlockUser(req *web.BlockUserReq) mir.Error {
+	if req.Uid == req.UserID {
+		return web.ErrNoActionToSelf
+	}
+	if _, err := s.Ds.GetUserByID(req.UserID); err != nil {
+		return web.ErrNoExistUsername
+	}
+	if err := s.Ds.BlockUser(req.Uid, req.UserID); err != nil {
+		logrus.Errorf("Ds.BlockUser err: %s userId: %d blockedId: %d", err, req.Uid, req.UserID)
+		return web.ErrBlockUserFailed
+	}
+	return nil
+}
+
+func (s *coreSrv) UnblockUser(req *web.UnblockUserReq) mir.Error {
+	if req.Uid == req.UserID {
+		return web.ErrNoActionToSelf
+	}
+	if err := s.Ds.UnblockUser(req.Uid, req.UserID); err != nil {
+		logrus.Errorf("Ds.UnblockUser err: %s userId: %d blockedId: %d", err, req.Uid, req.UserID)
+		return web.ErrUnblockUserFailed
+	}
+	return nil
+}
+
+func (s *coreSrv) ListBlockedUsers(req *web.ListBlockedUsersReq) (*web.ListBlockedUsersResp, mir.Error) {
+	users, total, err := s.Ds.ListBlockedUsers(req.UserId, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListBlockedUsers err: %s", err)
+		return nil, web.ErrListBlockedUsersFailed
+	}
+	list := make([]*ms.UserFormated, 0, len(users))
+	for _, user := range users {
+		list = append(list, user.Format())
+	}
+	resp := base.PageRespFrom(list, req.Page, req.PageSize, total)
+	return (*web.ListBlockedUsersResp)(resp), nil
+}
+
+// ListFriendSuggestions 预计算的"可能认识的人"，已注销的用户不列出
+func (s *coreSrv) ListFriendSuggestions(req *web.ListFriendSuggestionsReq) (*web.ListFriendSuggestionsResp, mir.Error) {
+	suggestions, total, err := s.Ds.ListUserSuggestions(req.UserId, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListUserSuggestions err: %s", err)
+		return nil, web.ErrListSuggestionsFailed
+	}
+	list := make([]*web.FriendSuggestion, 0, len(suggestions))
+	if len(suggestions) > 0 {
+		ids := make([]int64, 0, len(suggestions))
+		for _, item := range suggestions {
+			ids = append(ids, item.SuggestedID)
+		}
+		users, err := s.Ds.GetUsersByIDs(ids)
+		if err != nil {
+			logrus.Errorf("Ds.GetUsersByIDs err: %s", err)
+			return nil, web.ErrListSuggestionsFailed
+		}
+		userMap := make(map[int64]*ms.UserFormated, len(users))
+		for _, user := range users {
+			userMap[user.ID] = user.Format()
+		}
+		followStatus, err := s.Ds.IsMyFollow(req.UserId, ids...)
+		if err != nil {
+			logrus.Errorf("Ds.IsMyFollow err: %s", err)
+		}
+		for _, item := range suggestions {
+			if user, ok := userMap[item.SuggestedID]; ok {
+				list = append(list, &web.FriendSuggestion{
+					User:        user,
+					Reason:      item.Reason,
+					Explanation: item.Explanation,
+					IsFollowing: followStatus[item.SuggestedID],
+				})
+			}
+		}
+	}
+	resp := base.PageRespFrom(list, req.Page, req.PageSize, total)
+	return (*web.ListFriendSuggestionsResp)(resp), nil
+}
+
+func (s *coreSrv) SuggestionFeedback(req *web.SuggestionFeedbackReq) mir.Error {
+	if req.Action != cs.SuggestDismiss && req.Action != cs.SuggestHide {
+		return xerror.InvalidParams
+	}
+	if req.Uid == req.UserID {
+		return web.ErrNoActionToSelf
+	}
+	if err := s.Ds.CreateSuggestFeedback(req.Uid, req.UserID, req.Action); err != nil {
+		logrus.Errorf("Ds.CreateSuggestFeedback err: %s userId: %d suggestedId: %d", err, req.Uid, req.UserID)
+		return web.ErrSuggestionFeedbackFailed
+	}
+	return nil
+}
+
+// checkDmAllowed 检查uid能否向peerId发送私信，isNew为true时同时检查今日新建会话次数
+func (s *coreSrv) checkDmAllowed(uid int64, peerId int64, isNew bool) mir.Error {
+	peer, err := s.Ds.GetUserByID(peerId)
+	if err != nil {
+		return web.ErrNoExistUsername
+	}
+	if s.Ds.IsBlockedEither(uid, peerId) {
+		return web.ErrDmBlocked
+	}
+	if peer.DmPolicy == cs.DmPolicyFollowers && !s.Ds.IsFollow(uid, peerId) && !s.Ds.IsFollow(peerId, uid) {
+		return web.ErrDmFollowersOnly
+	}
+	if isNew {
+		// 今日发起新会话的频次限制
+		if count, _ := s.Redis.GetCountWhisper(context.Background(), uid); count >= _maxWhisperNumDaily {
+			return web.ErrTooManyWhisperNum
+		}
+	}
+	return nil
+}
+
+// sendDmMessage 发送私信，conversationId为0时发给peerId并按需创建会话。
+// 双方任一方屏蔽对方、或对方仅接收关注关系内的私信时拒绝发送，新建会话受每日次数限制；
+// 语音消息须为本人上传的语音附件，时长与波形取自上传时的解析结果
+func (s *coreSrv) sendDmMessage(uid int64, conversationId int64, peerId int64, typ cs.DmMsgT, content string) (*ms.DmMessage, int64, mir.Error) {
+	content = strings.TrimSpace(content)
+	if !typ.Valid() || content == "" {
+		return nil, 0, web.ErrInvalidDmMessage
+	}
+	var (
+		p     *ms.DmParticipant
+		voice *ms.Attachment
+		err   error
+	)
+	if conversationId > 0 {
+		if p, err = s.Ds.GetDmParticipant(conversationId, uid); err != nil {
+			return nil, 0, web.ErrNoExistDmConversation
+		}
+		peerId = p.PeerID
+	} else if uid == peerId {
+		return nil, 0, web.ErrNoWhisperToSelf
+	} else {
+		p, _ = s.Ds.GetDmParticipantByPeer(uid, peerId)
+	}
+	if xerr := s.checkDmAllowed(uid, peerId, p == nil); xerr != nil {
+		return nil, 0, xerr
+	}
+	ctx := context.Background()
+	if typ == cs.DmMsgText {
+		if _, xerr := moderateContent(s.Ds, cs.ModerationSceneMessage, content); xerr != nil {
+			return nil, 0, xerr
+		}
+	} else {
+		if typ == cs.DmMsgAudio {
+			var xerr mir.Error
+			if voice, xerr = voiceNoteFrom(s.Ds, uid, content); xerr != nil {
+				return nil, 0, xerr
+			}
+		} else if err = s.Ds.CheckAttachment(content); err != nil {
+			return nil, 0, web.ErrInvalidDmMessage
+		}
+		if err = s.oss.PersistObject(s.oss.ObjectKey(content)); err != nil {
+			logrus.Errorf("coreSrv.sendDmMessage persist object failed: %s", err)
+			return nil, 0, web.ErrSendWhisperFailed
+		}
+	}
+	if p == nil {
+		if p, err = s.Ds.GetOrCreateDmConversation(uid, peerId); err != nil {
+			logrus.Errorf("Ds.GetOrCreateDmConversation err: %s", err)
+			return nil, 0, web.ErrSendWhisperFailed
+		}
+		// 写入当日（自然日）计数缓存
+		s.Redis.IncrCountWhisper(ctx, uid)
+	}
+	msg := &ms.DmMessage{
+		Model:          &ms.Model{},
+		ConversationID: p.ConversationID,
+		SenderID:       uid,
+		Type:           typ,
+		Content:        content,
+	}
+	if voice != nil {
+		msg.Duration, msg.Waveform = voice.Duration, voice.Waveform
+	}
+	if msg, err = s.Ds.CreateDmMessage(msg); err != nil {
+		logrus.Errorf("Ds.CreateDmMessage err: %s", err)
+		return nil, 0, web.ErrSendWhisperFailed
+	}
+	onDmPushEvent(&dmPushData{
+		Type:           _dmPushTypeMsg,
+		ConversationID: msg.ConversationID,
+		Message:        msg,
+	}, uid, peerId)
+	onFollowInteractionEvent(uid, peerId)
+	return msg, peerId, nil
+}
+
+// dmPeerOf 会话中对方的参与者记录
+func (s *coreSrv) dmPeerOf(conversationId int64, uid int64) (*ms.DmParticipant, error) {
+	p, err := s.Ds.GetDmParticipant(conversationId, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.Ds.GetDmParticipant(conversationId, p.PeerID)
+}
+
 func (s *coreSrv) GetCollections(req *web.GetCollectionsReq) (*web.GetCollectionsResp, mir.Error) {
 	collections, err := s.Ds.GetUserPostCollections(req.UserId, (req.Page-1)*req.PageSize, req.PageSize)
 	if err != nil {
@@ -239,6 +570,25 @@ func (s *coreSrv) GetCollections(req *web.GetCollectionsReq) (*web.GetCollection
 	return (*web.GetCollectionsResp)(resp), nil
 }
 
+func (s *coreSrv) GetDrafts(req *web.GetDraftsReq) (*web.GetDraftsResp, mir.Error) {
+	posts, totalRows, err := s.Ds.ListUserDraftTweets(req.UserId, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListUserDraftTweets err: %s", err)
+		return nil, web.ErrGetDraftsFailed
+	}
+	postsFormated, err := s.Ds.MergePosts(posts)
+	if err != nil {
+		logrus.Errorf("Ds.MergePosts err: %s", err)
+		return nil, web.ErrGetDraftsFailed
+	}
+	if err = s.PrepareTweets(req.UserId, postsFormated); err != nil {
+		logrus.Errorf("get drafts prepare tweets err: %s", err)
+		return nil, web.ErrGetDraftsFailed
+	}
+	resp := base.PageRespFrom(postsFormated, req.Page, req.PageSize, totalRows)
+	return (*web.GetDraftsResp)(resp), nil
+}
+
 func (s *coreSrv) UserPhoneBind(req *web.UserPhoneBindReq) mir.Error {
 	// 手机重复性检查
 	u, err := s.Ds.GetUserByPhone(req.Phone)
@@ -247,7 +597,7 @@ func (s *coreSrv) UserPhoneBind(req *web.UserPhoneBindReq) mir.Error {
 	}
 
 	// 如果禁止phone verify 则允许通过任意验证码
-	if _enablePhoneVerify {
+	if base.FeatureEnabled(_ac, "Sms") {
 		c, err := s.Ds.GetLatestPhoneCaptcha(req.Phone)
 		if err != nil {
 			return web.ErrErrorPhoneCaptcha
@@ -387,18 +737,63 @@ func (s *coreSrv) SuggestUsers(req *web.SuggestUsersReq) (*web.SuggestUsersResp,
 	return resp, nil
 }
 
+// SuggestMentions @提及自动补全，已关注或私信过的用户排在前面
+func (s *coreSrv) SuggestMentions(req *web.SuggestMentionsReq) (*web.SuggestMentionsResp, mir.Error) {
+	candidates, err := s.Ds.ListMentionCandidates(req.Uid, req.Keyword, _maxMentionSuggestions)
+	if err != nil {
+		logrus.Errorf("Ds.ListMentionCandidates err: %s", err)
+		return nil, web.ErrSuggestMentionsFailed
+	}
+	resp := &web.SuggestMentionsResp{
+		Suggests: make([]*web.MentionSuggestion, 0, len(candidates)),
+	}
+	if len(candidates) == 0 {
+		return resp, nil
+	}
+	ids := make([]int64, 0, len(candidates))
+	for _, item := range candidates {
+		ids = append(ids, item.UserID)
+	}
+	users, err := s.Ds.GetUsersByIDs(ids)
+	if err != nil {
+		logrus.Errorf("Ds.GetUsersByIDs err: %s", err)
+		return nil, web.ErrSuggestMentionsFailed
+	}
+	userMap := make(map[int64]*ms.UserFormated, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user.Format()
+	}
+	for _, item := range candidates {
+		if user, ok := userMap[item.UserID]; ok {
+			resp.Suggests = append(resp.Suggests, &web.MentionSuggestion{
+				User:        user,
+				IsFollowing: item.IsFollowing,
+				HasTalked:   item.HasTalked,
+			})
+		}
+	}
+	return resp, nil
+}
+
 func (s *coreSrv) ChangeNickname(req *web.ChangeNicknameReq) mir.Error {
 	if utf8.RuneCountInString(req.Nickname) < 2 || utf8.RuneCountInString(req.Nickname) > 12 {
 		return web.ErrNicknameLengthLimit
 	}
+	// 内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneNickname, req.Nickname)
+	if xerr != nil {
+		return xerr
+	}
 	user := req.User
 	user.Nickname = req.Nickname
 	if err := s.Ds.UpdateUser(user); err != nil {
 		logrus.Errorf("Ds.UpdateUser err: %s", err)
 		return xerror.ServerError
 	}
+	onContentFlagged(verdict, web.AuditStyleUserProfile, user.ID, user.ID)
 	// 缓存处理
 	onChangeUsernameEvent(user.ID, user.Username)
+	s.PushProfileToSearch(user.ID)
 	return nil
 }
 
@@ -425,6 +820,7 @@ func (s *coreSrv) ChangeAvatar(req *web.ChangeAvatarReq) (xerr mir.Error) {
 	}
 	// 缓存处理
 	onChangeUsernameEvent(user.ID, user.Username)
+	s.PushProfileToSearch(user.ID)
 	return nil
 }
 
@@ -450,8 +846,44 @@ func (s *coreSrv) TweetStarStatus(req *web.TweetStarStatusReq) (*web.TweetStarSt
 	return resp, nil
 }
 
+// prepareMessageActors 分组通知填充最近几位触发者的信息
+func (s *coreSrv) prepareMessageActors(messages []*ms.MessageFormated) {
+	var ids []int64
+	for _, mf := range messages {
+		if mf.ActorCount > 1 {
+			ids = append(ids, mf.ActorIDs...)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	users, err := s.Ds.GetUsersByIDs(ids)
+	if err != nil {
+		logrus.Warnf("Ds.GetUsersByIDs for message actors err: %s", err)
+		return
+	}
+	userMap := make(map[int64]*ms.UserFormated, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user.Format()
+	}
+	for _, mf := range messages {
+		if mf.ActorCount <= 1 {
+			continue
+		}
+		for _, id := range mf.ActorIDs {
+			if user, ok := userMap[id]; ok {
+				mf.Actors = append(mf.Actors, user)
+			}
+		}
+	}
+}
+
 func (s *coreSrv) messagesFromCache(req *web.GetMessagesReq, limit int, offset int) (res *web.GetMessagesResp, key string, ok bool) {
-	key = fmt.Sprintf("%s%d:%s:%d:%d", s.prefixMessages, req.Uid, req.Style, limit, offset)
+	pos := strconv.Itoa(offset)
+	if req.Cursor != "" {
+		pos = "c" + req.Cursor
+	}
+	key = fmt.Sprintf("%s%d:%s:%s:%s:%d", s.prefixMessages, req.Uid, req.Style, req.Category, pos, limit)
 	if data, err := s.wc.Get(key); err == nil {
 		ok, res = true, &web.GetMessagesResp{
 			CachePageResp: joint.CachePageResp{
@@ -565,6 +997,9 @@ func (s *coreSrv) ListRooms(req *web.RoomListReq) (*web.RoomListResp, mir.Error)
 }
 
 func (s *coreSrv) CreateRoom(req *web.CreateRoomReq) (*web.Room, mir.Error) {
+	if xerr := checkRoomBanned(req.User); xerr != nil {
+		return nil, xerr
+	}
 	logrus.WithFields(logrus.Fields{
 		"hms_room_id": req.HMSRoomID,
 		"topics": req.Topics,
@@ -583,6 +1018,9 @@ func (s *coreSrv) CreateRoom(req *web.CreateRoomReq) (*web.Room, mir.Error) {
 		Topics:            req.Topics,
 		Categories:        []int64(req.Categories), // Convert from dbr.Int64Array to []int64
 	}
+	if loc := req.LocationData; loc != nil && geo.Valid(loc.Lat, loc.Lng) {
+		room.LocationLat, room.LocationLng = loc.Lat, loc.Lng
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"room": room,
@@ -596,10 +1034,182 @@ func (s *coreSrv) CreateRoom(req *web.CreateRoomReq) (*web.Room, mir.Error) {
 	}
 
 	logrus.WithField("room_id", room.ID).Info("Successfully created room")
+	s.PushRoomsToSearch(room.ID)
 
 	return s.enrichRoomData(room)
 }
 
+// NearbyRooms 获取附近房主在线的房间，按距离由近到远排列
+func (s *coreSrv) NearbyRooms(req *web.NearbyRoomsReq) (*web.NearbyRoomsResp, mir.Error) {
+	radius, xerr := nearbyRadiusFrom(req.Lat, req.Lng, req.Radius)
+	if xerr != nil {
+		return nil, xerr
+	}
+	rooms, err := s.Ds.ListNearbyRooms(geo.BoundingBox(req.Lat, req.Lng, radius), req.Lat, req.Lng, conf.NearbySetting.MaxCandidates)
+	if err != nil {
+		logrus.Errorf("coreSrv.NearbyRooms occurs error[1]: %s", err)
+		return nil, web.ErrGetNearbyRoomsFailed
+	}
+	hostIds := make([]int64, 0, len(rooms))
+	for _, room := range rooms {
+		hostIds = append(hostIds, room.HostID)
+	}
+	onlines, err := s.wc.BatchCheckOnlineUsers(hostIds)
+	if err != nil {
+		logrus.Errorf("coreSrv.NearbyRooms occurs error[2]: %s", err)
+		return nil, web.ErrGetNearbyRoomsFailed
+	}
+	liveRooms := make([]*ms.Room, 0, len(rooms))
+	for _, room := range rooms {
+		if onlines[room.HostID] {
+			liveRooms = append(liveRooms, room)
+		}
+	}
+	userId := req.User.ID
+	items, total := nearbyPage(len(liveRooms), func(i int) (int64, float64, float64) {
+		lat, lng := nearbyCoords(userId, liveRooms[i].HostID, liveRooms[i].LocationLat, liveRooms[i].LocationLng)
+		return liveRooms[i].ID, lat, lng
+	}, req.Lat, req.Lng, radius, req.Page, req.PageSize)
+	list := make([]*web.NearbyRoom, 0, len(items))
+	for _, item := range items {
+		room, err := s.enrichRoomDataWithFollowing(liveRooms[item.idx], userId)
+		if err != nil {
+			logrus.WithError(err).WithField("room_id", item.id).Error("Failed to enrich room data")
+			continue
+		}
+		list = append(list, &web.NearbyRoom{
+			Room:     room,
+			Distance: item.distance,
+		})
+	}
+	resp := base.PageRespFrom(list, req.Page, req.PageSize, total)
+	return (*web.NearbyRoomsResp)(resp), nil
+}
+
+// InviteRoom 房主邀请关注者或好友进入房间，同一房主对同一用户在邀请有效期内只邀请一次；
+// Followers 为真时同时向开启了提醒的关注者群发"叫人来"，同一房间两次群发之间有最小间隔
+func (s *coreSrv) InviteRoom(req *web.InviteRoomReq) (*web.InviteRoomResp, mir.Error) {
+	if xerr := checkRoomBanned(req.User); xerr != nil {
+		return nil, xerr
+	}
+	room, err := s.Ds.GetRoomByID(req.RoomID)
+	if err != nil {
+		return nil, web.ErrRoomNotFound
+	}
+	host := req.User
+	if room.HostID != host.ID {
+		return nil, web.ErrNotRoomHost
+	}
+	if room.IsBlockedFromSpace != 0 {
+		return nil, web.ErrRoomBlocked
+	}
+	if len(req.UserIDs) > conf.RoomInviteSetting.MaxInvitees {
+		return nil, web.ErrTooManyRoomInvitees
+	}
+	// 通知缓存先原子占用，邀请创建失败时释放，保证只有成功的邀请占用有效期
+	var pingIds, invitedIds []int64
+	release := func() {
+		if req.Followers {
+			_nc.ReleaseNotification(host.ID, room.ID, cache.NotificationTypeRoomPing)
+		}
+		for _, id := range invitedIds {
+			_nc.ReleaseNotification(host.ID, id, cache.NotificationTypeRoomInvite)
+		}
+	}
+	if req.Followers {
+		if !_nc.ShouldSendNotification(host.ID, room.ID, cache.NotificationTypeRoomPing) {
+			return nil, web.ErrRoomPingTooFrequent
+		}
+		if pingIds, err = s.Ds.ListRoomPingFollowerIds(host.ID, conf.RoomInviteSetting.MaxFanout); err != nil {
+			logrus.Errorf("coreSrv.InviteRoom occurs error[1]: %s", err)
+			release()
+			return nil, web.ErrRoomInviteFailed
+		}
+	}
+	expiredOn := time.Now().Unix() + conf.RoomInviteSetting.InviteExpire
+	invites := make([]*ms.RoomInvite, 0, len(req.UserIDs)+len(pingIds))
+	seen := make(map[int64]bool, len(req.UserIDs)+len(pingIds))
+	skipped := 0
+	// 最后才检查通知缓存，避免为跳过的用户占用邀请有效期
+	addInvite := func(inviteeId int64, source cs.RoomInviteSourceT, allowed bool) {
+		if seen[inviteeId] {
+			return
+		}
+		seen[inviteeId] = true
+		if !allowed || inviteeId == host.ID || s.Ds.IsBlockedEither(host.ID, inviteeId) {
+			skipped++
+			return
+		}
+		if !_nc.ShouldSendNotification(host.ID, inviteeId, cache.NotificationTypeRoomInvite) {
+			skipped++
+			return
+		}
+		invitedIds = append(invitedIds, inviteeId)
+		invites = append(invites, &ms.RoomInvite{
+			Model:     &ms.Model{},
+			RoomID:    room.ID,
+			InviterID: host.ID,
+			InviteeID: inviteeId,
+			Source:    source,
+			ExpiredOn: expiredOn,
+		})
+	}
+	for _, id := range req.UserIDs {
+		addInvite(id, cs.RoomInviteDirect, s.Ds.IsFollow(id, host.ID) || s.Ds.IsFriend(host.ID, id))
+	}
+	for _, id := range pingIds {
+		addInvite(id, cs.RoomInvitePing, true)
+	}
+	if err = s.Ds.CreateRoomInvites(invites); err != nil {
+		logrus.Errorf("coreSrv.InviteRoom occurs error[2]: %s", err)
+		release()
+		return nil, web.ErrRoomInviteFailed
+	}
+	var directIds, pingedIds []int64
+	for _, invite := range invites {
+		content := fmt.Sprintf("用户 %s 邀请你进入TA的房间", host.Username)
+		if invite.Source == cs.RoomInvitePing {
+			content = fmt.Sprintf("用户 %s 开了房间，快来加入吧", host.Username)
+			pingedIds = append(pingedIds, invite.InviteeID)
+		} else {
+			directIds = append(directIds, invite.InviteeID)
+		}
+		onCreateMessageEvent(&ms.Message{
+			SenderUserID:   host.ID,
+			ReceiverUserID: invite.InviteeID,
+			Type:           ms.MsgTypeRoomInvite,
+			Brief:          "邀请你进入房间",
+			Content:        content,
+			RoomID:         room.ID,
+		})
+	}
+	onRoomInvitePushEvent(room.ID, host.Nickname, false, directIds)
+	onRoomInvitePushEvent(room.ID, host.Nickname, true, pingedIds)
+	return &web.InviteRoomResp{
+		Invited: len(invites),
+		Skipped: skipped,
+	}, nil
+}
+
+// AcceptRoomInvite 接受房间邀请并记录转化，返回的房间信息用于客户端跳转进入房间
+func (s *coreSrv) AcceptRoomInvite(req *web.AcceptRoomInviteReq) (*web.Room, mir.Error) {
+	if xerr := checkRoomBanned(req.User); xerr != nil {
+		return nil, xerr
+	}
+	room, err := s.Ds.GetRoomByID(req.RoomID)
+	if err != nil {
+		return nil, web.ErrRoomNotFound
+	}
+	if room.IsBlockedFromSpace != 0 {
+		return nil, web.ErrRoomBlocked
+	}
+	if _, err = s.Ds.AcceptRoomInvite(room.ID, req.User.ID); err != nil {
+		logrus.Debugf("coreSrv.AcceptRoomInvite room[%d] user[%d] occurs error: %s", room.ID, req.User.ID, err)
+		return nil, web.ErrRoomInviteNotFound
+	}
+	return s.enrichRoomDataWithFollowing(room, req.User.ID)
+}
+
 func (s *coreSrv) UpdateRoom(req *web.UpdateRoomReq) mir.Error {
 	logrus.WithFields(logrus.Fields{
 		"room_id": req.RoomID,
@@ -624,6 +1234,9 @@ func (s *coreSrv) UpdateRoom(req *web.UpdateRoomReq) mir.Error {
 		}).Error("User is not the room host")
 		return xerror.UnauthorizedAuthFailed
 	}
+	if xerr := checkRoomBanned(req.User); xerr != nil {
+		return xerr
+	}
 
 	// Prepare updates map
 	updates := make(map[string]interface{})
@@ -674,6 +1287,7 @@ func (s *coreSrv) UpdateRoom(req *web.UpdateRoomReq) mir.Error {
 	}
 
 	logrus.WithField("room_id", req.RoomID).Info("Successfully updated room")
+	s.PushRoomsToSearch(req.RoomID)
 	return nil
 }
 
@@ -703,6 +1317,9 @@ func (s *coreSrv) GetUserRoom(req *web.GetUserRoomReq) (*web.Room, mir.Error) {
 }
 
 func (s *coreSrv) GetRoomByID(req *web.GetRoomByIDReq) (*web.Room, mir.Error) {
+	if xerr := checkRoomBanned(req.User); xerr != nil {
+		return nil, xerr
+	}
 	logrus.WithField("room_id", req.RoomID).Info("Getting room by ID")
 	
 	// Get room from database
@@ -897,6 +1514,7 @@ func (s *coreSrv) SetUserCategories(req *web.SetUserCategoriesReq) (*web.SetUser
 		"user_id": req.User.ID,
 		"category_ids": req.CategoryIDs,
 	}).Info("Successfully set user categories")
+	s.PushProfileToSearch(req.User.ID)
 
 	return &web.SetUserCategoriesResp{
 		Success: true,
@@ -1070,6 +1688,23 @@ func (s *coreSrv) GetReactionsToTwoUsers(req *web.GetReactionsToTwoUsersReq) (*w
 
 // GetGlobalReactionTimeline gets all reactions given by all users in chronological order
 func (s *coreSrv) GetGlobalReactionTimeline(req *web.GetGlobalReactionTimelineReq) (*web.GetGlobalReactionTimelineResp, mir.Error) {
+	cursor, xerr := pageCursorFrom(req.Cursor)
+	if xerr != nil {
+		return nil, xerr
+	}
+	if cursor != nil {
+		reactions, next, err := s.Ds.GetGlobalReactionTimelineByCursor(cursor, req.PageSize)
+		if err != nil {
+			logrus.Errorf("Failed to get global reaction timeline by cursor: %v", err)
+			return nil, xerror.ServerError
+		}
+		return &web.GetGlobalReactionTimelineResp{
+			List:       reactions,
+			Pager:      base.Pager{PageSize: req.PageSize},
+			NextCursor: next.Encode(),
+		}, nil
+	}
+	
 	// Calculate offset from page and page size
 	offset := (req.Page - 1) * req.PageSize
 	
@@ -1088,13 +1723,34 @@ func (s *coreSrv) GetGlobalReactionTimeline(req *web.GetGlobalReactionTimelineRe
 	}
 	
 	return &web.GetGlobalReactionTimelineResp{
-		List:  reactions,
-		Pager: pager,
+		List:       reactions,
+		Pager:      pager,
+		NextCursor: offsetNextCursor(req.Page, req.PageSize, len(reactions), total, func() *cs.PageCursor {
+			last := reactions[len(reactions)-1]
+			return &cs.PageCursor{Value: last.CreatedOn, ID: last.ReactionID}
+		}),
 	}, nil
 }
 
 // GetUserReactionTimeline gets all reactions given by a specific user in chronological order
 func (s *coreSrv) GetUserReactionTimeline(req *web.GetUserReactionTimelineReq) (*web.GetUserReactionTimelineResp, mir.Error) {
+	cursor, xerr := pageCursorFrom(req.Cursor)
+	if xerr != nil {
+		return nil, xerr
+	}
+	if cursor != nil {
+		reactions, next, err := s.Ds.GetUserReactionTimelineByCursor(req.UserID, cursor, req.PageSize)
+		if err != nil {
+			logrus.Errorf("Failed to get user reaction timeline by cursor: %v", err)
+			return nil, xerror.ServerError
+		}
+		return &web.GetUserReactionTimelineResp{
+			List:       reactions,
+			Pager:      base.Pager{PageSize: req.PageSize},
+			NextCursor: next.Encode(),
+		}, nil
+	}
+	
 	// Calculate offset from page and page size
 	offset := (req.Page - 1) * req.PageSize
 	
@@ -1113,8 +1769,12 @@ func (s *coreSrv) GetUserReactionTimeline(req *web.GetUserReactionTimelineReq) (
 	}
 	
 	return &web.GetUserReactionTimelineResp{
-		List:  reactions,
-		Pager: pager,
+		List:       reactions,
+		Pager:      pager,
+		NextCursor: offsetNextCursor(req.Page, req.PageSize, len(reactions), total, func() *cs.PageCursor {
+			last := reactions[len(reactions)-1]
+			return &cs.PageCursor{Value: last.CreatedOn, ID: last.ReactionID}
+		}),
 	}, nil
 }
 
@@ -1131,6 +1791,10 @@ func (s *coreSrv) GetUserOnlineStatus(req *web.UserOnlineStatusReq) (*web.UserOn
 
 // GetCentrifugoToken generates a JWT token for Centrifugo connection
 func (s *coreSrv) GetCentrifugoToken(req *web.CentrifugoTokenReq) (*web.CentrifugoTokenResp, mir.Error) {
+	// 签名密钥需与Centrifugo的token_hmac_secret_key一致，未配置时不签发
+	if conf.CentrifugoSetting.TokenSecret == "" {
+		return nil, web.ErrRealtimeNotConfigured
+	}
 	// Get user ID from authenticated user
 	userID := req.User.ID
 	
@@ -1141,7 +1805,7 @@ func (s *coreSrv) GetCentrifugoToken(req *web.CentrifugoTokenReq) (*web.Centrifu
 	}
 	
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte("paopao-centrifugo-secret-key-2024"))
+	tokenString, err := token.SignedString([]byte(conf.CentrifugoSetting.TokenSecret))
 	if err != nil {
 		return nil, xerror.ServerError
 	}
@@ -1177,6 +1841,13 @@ func (s *coreSrv) UpdateUserLocation(userID int64, locationData *web.LocationDat
 		logrus.WithError(err).WithField("user_id", userID).Error("Failed to update user location in Redis")
 		return err
 	}
+
+	// 带有精确坐标时同步更新房主的房间位置，用于附近的房间
+	if geo.Valid(locationData.Lat, locationData.Lng) {
+		if err = s.Ds.UpdateLocationByHostID(userID, locationData.Lat, locationData.Lng); err != nil {
+			logrus.WithError(err).WithField("user_id", userID).Warn("Failed to update room location")
+		}
+	}
 	
 	logrus.WithFields(logrus.Fields{
 		"user_id": userID,
@@ -1222,3 +1893,77 @@ func newCoreSrv(s *base.DaoServant, oss core.ObjectStorageService, wc core.WebCa
 		prefixMessages: conf.PrefixMessages,
 	}
 }
+
+func (s *coreSrv) ExportAccount(req *web.ExportAccountReq) (*web.ExportAccountResp, mir.Error) {
+	staleBefore := time.Now().Unix() - conf.AppSetting.AccountExportTimeout
+	export, err := s.Ds.CreateAccountExport(req.User.ID, staleBefore)
+	if err != nil {
+		logrus.Errorf("Ds.CreateAccountExport err: %s", err)
+		return nil, web.ErrExportAccountFailed
+	}
+	// 新建的导出任务异步打包用户数据
+	if export.Status == cs.AccountExportPending {
+		onAccountExportEvent(export)
+	}
+	return &web.ExportAccountResp{
+		ID:     export.ID,
+		Status: export.Status,
+	}, nil
+}
+
+func (s *coreSrv) GetAccountExport(req *web.GetAccountExportReq) (*web.GetAccountExportResp, mir.Error) {
+	export, err := s.Ds.GetAccountExport(req.User.ID, req.ID)
+	if err != nil {
+		logrus.Errorf("Ds.GetAccountExport err: %s", err)
+		return nil, web.ErrAccountExportNotFound
+	}
+	resp := &web.GetAccountExportResp{
+		ID:         export.ID,
+		Status:     export.Status,
+		Size:       export.Size,
+		FinishedOn: export.FinishedOn,
+	}
+	if export.Status == cs.AccountExportFinished {
+		if resp.DownloadUrl, err = s.oss.SignURL(export.ObjectKey, conf.AppSetting.AccountExportExpire); err != nil {
+			logrus.Errorf("oss.SignURL err: %s", err)
+			return nil, xerror.ServerError
+		}
+	}
+	return resp, nil
+}
+
+func (s *coreSrv) DeleteAccount(req *web.DeleteAccountReq) (*web.DeleteAccountResp, mir.Error) {
+	if !validPassword(req.User.Password, req.Password, req.User.Salt) {
+		return nil, web.ErrErrorOldPassword
+	}
+	scheduledOn := time.Now().AddDate(0, 0, conf.AppSetting.AccountDeletionGrace).Unix()
+	deletion, err := s.Ds.ScheduleAccountDeletion(req.User.ID, scheduledOn)
+	if err != nil {
+		logrus.Errorf("Ds.ScheduleAccountDeletion err: %s", err)
+		return nil, web.ErrDeleteAccountFailed
+	}
+	return &web.DeleteAccountResp{
+		ScheduledOn: deletion.ScheduledOn,
+	}, nil
+}
+
+func (s *coreSrv) CancelAccountDeletion(req *web.CancelAccountDeletionReq) mir.Error {
+	deletion, err := s.Ds.GetAccountDeletion(req.User.ID)
+	if err != nil {
+		return web.ErrAccountDeletionNotFound
+	}
+	deletion.Status = cs.AccountDeletionCanceled
+	if err = s.Ds.UpdateAccountDeletion(deletion); err != nil {
+		logrus.Errorf("Ds.UpdateAccountDeletion err: %s", err)
+		return web.ErrCancelAccountDeletionFailed
+	}
+	return nil
+}
+
+func (s *coreSrv) GetAccountDeletion(req *web.GetAccountDeletionReq) (*web.GetAccountDeletionResp, mir.Error) {
+	resp := &web.GetAccountDeletionResp{}
+	if deletion, err := s.Ds.GetAccountDeletion(req.User.ID); err == nil {
+		resp.Scheduled, resp.ScheduledOn = true, deletion.ScheduledOn
+	}
+	return resp, nil
+}
@@ -5,10 +5,16 @@
 package web
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/alimy/tryst/event"
+	"github.com/gofrs/uuid/v5"
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/core/cs"
@@ -17,6 +23,8 @@ import (
 	"github.com/rocboss/paopao-ce/internal/infra/events"
 	"github.com/rocboss/paopao-ce/internal/model/joint"
 	"github.com/rocboss/paopao-ce/internal/model/web"
+	"github.com/rocboss/paopao-ce/pkg/centrifugo"
+	"github.com/rocboss/paopao-ce/pkg/mention"
 	"github.com/sirupsen/logrus"
 )
 
@@ -35,6 +43,8 @@ const (
 	_commentActionReplyThumbsUp
 	_commentActionReplyThumbsDown
 	_commentActionHighlight
+	_commentActionPin
+	_commentActionEdit
 )
 
 const (
@@ -44,6 +54,18 @@ const (
 	_messageActionSendWhisper
 )
 
+const (
+	// 私信实时推送的频道命名空间与消息类型
+	_dmChannel      = "dm"
+	_dmPushTypeMsg  = "message"
+	_dmPushTypeRead = "read"
+)
+
+const (
+	// 单次发布最多提醒的@用户数
+	_maxMentionUsers = 20
+)
+
 const (
 	_trendsActionCreateTweet uint8 = iota
 	_trendsActionDeleteTweet
@@ -99,12 +121,73 @@ type trendsActionEvent struct {
 	userIds []int64
 }
 
+type accountExportEvent struct {
+	event.UnimplementedEvent
+	ds     core.DataService
+	oss    core.ObjectStorageService
+	export *ms.AccountExport
+}
+
+type dmPushEvent struct {
+	event.UnimplementedEvent
+	client  *centrifugo.Client
+	data    *dmPushData
+	userIds []int64
+}
+
+type roomInvitePushEvent struct {
+	event.UnimplementedEvent
+	push       core.RoomInvitePushService
+	roomId     int64
+	hostName   string
+	ping       bool
+	inviteeIds []int64
+}
+
+type followInteractionEvent struct {
+	event.UnimplementedEvent
+	ds      core.DataService
+	userId  int64
+	otherId int64
+}
+
+type mentionEvent struct {
+	event.UnimplementedEvent
+	ds        core.DataService
+	post      *ms.Post
+	message   *ms.Message
+	texts     []*mentionText
+	usernames []string
+	skipIds   []int64
+}
+
+// mentionText 待解析@提及的文本，sourceId 为其所属动态内容、评论内容或回复的ID
+type mentionText struct {
+	source   cs.MentionSourceT
+	sourceId int64
+	text     string
+}
+
+// dmPushData 推送到用户私信频道的数据
+type dmPushData struct {
+	Type           string        `json:"type"`
+	ConversationID int64         `json:"conversation_id"`
+	Message        *ms.DmMessage `json:"message,omitempty"`
+	UserID         int64         `json:"user_id,omitempty"`
+	LastReadID     int64         `json:"last_read_id,omitempty"`
+}
+
 type changeUserEvent struct {
 	*cache.BaseCacheEvent
 	userId   int64
 	username string
 }
 
+type changeUserSettingEvent struct {
+	*cache.BaseCacheEvent
+	user *ms.User
+}
+
 func onChangeUsernameEvent(id int64, name string) {
 	events.OnEvent(&changeUserEvent{
 		BaseCacheEvent: cache.NewBaseCacheEvent(_ac),
@@ -113,6 +196,73 @@ func onChangeUsernameEvent(id int64, name string) {
 	})
 }
 
+// onChangeUserSettingEvent 用户设置变更后清除用户信息缓存
+func onChangeUserSettingEvent(user *ms.User) {
+	events.OnEvent(&changeUserSettingEvent{
+		BaseCacheEvent: cache.NewBaseCacheEvent(_ac),
+		user:           user,
+	})
+}
+
+// onDmPushEvent 未开启Centrifugo功能时不推送，客户端通过拉取私信记录同步
+func onDmPushEvent(data *dmPushData, userIds ...int64) {
+	if _centrifugo == nil {
+		return
+	}
+	events.OnEvent(&dmPushEvent{
+		client:  _centrifugo,
+		data:    data,
+		userIds: userIds,
+	})
+}
+
+// onFollowInteractionEvent 记录两人间的互动，关注列表按最近互动排序
+func onFollowInteractionEvent(userId int64, otherId int64) {
+	if userId <= 0 || otherId <= 0 || userId == otherId {
+		return
+	}
+	events.OnEvent(&followInteractionEvent{
+		ds:      _ds,
+		userId:  userId,
+		otherId: otherId,
+	})
+}
+
+// onMentionEvent 解析文本中的@提及并保存，message 为提醒模板，
+// usernames 为客户端单独指定的被@用户，skipIds 为已收到其他提醒的用户
+func onMentionEvent(post *ms.Post, message *ms.Message, texts []*mentionText, usernames []string, skipIds ...int64) {
+	events.OnEvent(&mentionEvent{
+		ds:        _ds,
+		post:      post,
+		message:   message,
+		texts:     texts,
+		usernames: usernames,
+		skipIds:   skipIds,
+	})
+}
+
+// onRoomInvitePushEvent 未开启ContactPush功能时不推送，受邀者仍会收到站内的邀请消息
+func onRoomInvitePushEvent(roomId int64, hostName string, ping bool, inviteeIds []int64) {
+	if _push == nil || len(inviteeIds) == 0 {
+		return
+	}
+	events.OnEvent(&roomInvitePushEvent{
+		push:       _push,
+		roomId:     roomId,
+		hostName:   hostName,
+		ping:       ping,
+		inviteeIds: inviteeIds,
+	})
+}
+
+func onAccountExportEvent(export *ms.AccountExport) {
+	events.OnEvent(&accountExportEvent{
+		ds:     _ds,
+		oss:    _oss,
+		export: export,
+	})
+}
+
 func onTrendsActionEvent(action uint8, userIds ...int64) {
 	events.OnEvent(&trendsActionEvent{
 		ac:      _ac,
@@ -182,7 +332,8 @@ func (e *cacheUnreadMsgEvent) Action() error {
 		Code: 0,
 		Msg:  "success",
 		Data: &web.GetUnreadMsgCountResp{
-			Count: count,
+			Count:      count.Total,
+			Categories: count.Categories,
 		},
 	}
 	data, err := json.Marshal(resp)
@@ -201,7 +352,10 @@ func (e *createMessageEvent) Name() string {
 
 func (e *createMessageEvent) Action() (err error) {
 	if _, err = e.ds.CreateMessage(e.message); err == nil {
+		// 分组通知会更新已有的通知，消息列表缓存同时失效
+		e.wc.DelAny(fmt.Sprintf("%s%d:*", conf.PrefixMessages, e.message.ReceiverUserID))
 		err = e.wc.DelUnreadMsgCountResp(e.message.ReceiverUserID)
+		onFollowInteractionEvent(e.message.SenderUserID, e.message.ReceiverUserID)
 	}
 	return
 }
@@ -225,7 +379,11 @@ func (e *commentActionEvent) Action() (err error) {
 	case _commentActionThumbsUp, _commentActionThumbsDown:
 		err = e.updateCommentMetric()
 		e.expireHotsComments()
-	case _commentActionHighlight:
+		e.expireTopComments()
+	case _commentActionReplyThumbsUp, _commentActionReplyThumbsDown:
+		// 回复随评论一起缓存，点赞数与折叠状态需要刷新
+		e.expireAllStyleComments()
+	case _commentActionHighlight, _commentActionPin, _commentActionEdit:
 		e.expireAllStyleComments()
 	default:
 		// nothing
@@ -237,6 +395,10 @@ func (e *commentActionEvent) expireHotsComments() {
 	e.ac.DelAny(fmt.Sprintf("%s%d:%s:*", conf.PrefixTweetComment, e.tweetId, conf.InfixCommentHots))
 }
 
+func (e *commentActionEvent) expireTopComments() {
+	e.ac.DelAny(fmt.Sprintf("%s%d:%s:*", conf.PrefixTweetComment, e.tweetId, conf.InfixCommentTop))
+}
+
 func (e *commentActionEvent) expireAllStyleComments() {
 	e.ac.DelAny(fmt.Sprintf("%s%d:*", conf.PrefixTweetComment, e.tweetId))
 }
@@ -250,6 +412,7 @@ func (e *commentActionEvent) updateCommentMetric() error {
 	e.ds.UpdateCommentMetric(&cs.CommentMetric{
 		CommentId:       e.commentId,
 		ReplyCount:      comment.ReplyCount,
+		AudioReplyCount: comment.AudioReplyCount,
 		ThumbsUpCount:   comment.ThumbsUpCount,
 		ThumbsDownCount: comment.ThumbsDownCount,
 	})
@@ -345,3 +508,152 @@ func (e *changeUserEvent) Name() string {
 func (e *changeUserEvent) Action() error {
 	return e.ExpireUserData(e.userId, e.username)
 }
+
+func (e *changeUserSettingEvent) Name() string {
+	return "changeUserSettingEvent"
+}
+
+func (e *changeUserSettingEvent) Action() error {
+	return e.ExpireUserData(e.user.ID, e.user.Username)
+}
+
+func (e *accountExportEvent) Name() string {
+	return "accountExportEvent"
+}
+
+func (e *accountExportEvent) Action() (err error) {
+	export := e.export
+	export.Status = cs.AccountExportProcessing
+	if err = e.ds.UpdateAccountExport(export); err != nil {
+		return fmt.Errorf("accountExportEvent action update export occurs error: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			export.Status, export.Reason = cs.AccountExportFailed, err.Error()
+		} else {
+			export.Status, export.Reason = cs.AccountExportFinished, ""
+		}
+		export.FinishedOn = time.Now().Unix()
+		if xerr := e.ds.UpdateAccountExport(export); xerr != nil {
+			logrus.Errorf("accountExportEvent action update export occurs error: %s", xerr)
+		}
+	}()
+	archive, err := e.ds.GetAccountArchive(export.UserID)
+	if err != nil {
+		return fmt.Errorf("accountExportEvent action get archive occurs error: %w", err)
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("accountExportEvent action marshal archive occurs error: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, err := zw.Create("account.json")
+	if err != nil {
+		return fmt.Errorf("accountExportEvent action create zip occurs error: %w", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("accountExportEvent action write zip occurs error: %w", err)
+	}
+	if err = zw.Close(); err != nil {
+		return fmt.Errorf("accountExportEvent action close zip occurs error: %w", err)
+	}
+	objectKey := fmt.Sprintf("exports/%d/%s.zip", export.UserID, uuid.Must(uuid.NewV4()).String())
+	size := int64(buf.Len())
+	if _, err = e.oss.PutObject(objectKey, buf, size, "application/zip", true); err != nil {
+		return fmt.Errorf("accountExportEvent action put object occurs error: %w", err)
+	}
+	export.ObjectKey, export.Size = objectKey, size
+	return nil
+}
+
+func (e *dmPushEvent) Name() string {
+	return "dmPushEvent"
+}
+
+func (e *dmPushEvent) Action() error {
+	// 单个用户推送失败不影响其他用户
+	for _, userId := range e.userIds {
+		if err := e.client.Publish(context.Background(), centrifugo.UserChannel(_dmChannel, userId), e.data); err != nil {
+			logrus.Errorf("dmPushEvent publish to user[%d] occurs error: %s", userId, err)
+		}
+	}
+	return nil
+}
+
+func (e *followInteractionEvent) Name() string {
+	return "followInteractionEvent"
+}
+
+func (e *followInteractionEvent) Action() error {
+	return e.ds.TouchFollowInteraction(e.userId, e.otherId)
+}
+
+func (e *roomInvitePushEvent) Name() string {
+	return "roomInvitePushEvent"
+}
+
+func (e *roomInvitePushEvent) Action() error {
+	return e.push.SendRoomInviteNotification(e.inviteeIds, e.roomId, e.hostName, e.ping)
+}
+
+func (e *mentionEvent) Name() string {
+	return "mentionEvent"
+}
+
+func (e *mentionEvent) Action() error {
+	entities := make([][]*mention.Entity, len(e.texts))
+	names := append([]string{}, e.usernames...)
+	for i, t := range e.texts {
+		entities[i] = mention.Parse(t.text)
+		for _, entity := range entities[i] {
+			names = append(names, entity.Username)
+		}
+	}
+	users := make(map[string]*ms.User, len(names))
+	receivers := make([]*ms.User, 0, len(names))
+	for _, name := range names {
+		if _, exist := users[name]; exist || len(users) >= _maxMentionUsers {
+			continue
+		}
+		user, err := e.ds.GetUserByUsername(name)
+		if err != nil || user.Model == nil || user.ID <= 0 || user.ID == e.message.SenderUserID {
+			users[name] = nil
+			continue
+		}
+		users[name] = user
+		receivers = append(receivers, user)
+	}
+	var mentions []*ms.Mention
+	for i, t := range e.texts {
+		for _, entity := range entities[i] {
+			if user := users[entity.Username]; user != nil {
+				mentions = append(mentions, &ms.Mention{
+					SourceType:      t.source,
+					SourceID:        t.sourceId,
+					PostID:          e.message.PostID,
+					CommentID:       e.message.CommentID,
+					ReplyID:         e.message.ReplyID,
+					UserID:          e.message.SenderUserID,
+					MentionedUserID: user.ID,
+					Offset:          entity.Offset,
+					Length:          entity.Length,
+				})
+			}
+		}
+	}
+	if err := e.ds.CreateMentions(mentions); err != nil {
+		return fmt.Errorf("mentionEvent create mentions of post[%d] occurs error: %w", e.post.ID, err)
+	}
+	for _, user := range receivers {
+		if slices.Contains(e.skipIds, user.ID) || e.ds.IsBlockedEither(e.message.SenderUserID, user.ID) ||
+			checkPostViewPermission(user, e.post, e.ds) != nil {
+			continue
+		}
+		message := *e.message
+		message.ReceiverUserID = user.ID
+		message.Category = cs.MsgCategoryMention
+		onCreateMessageEvent(&message)
+	}
+	return nil
+}
@@ -10,12 +10,14 @@ import (
 	"github.com/gin-gonic/gin"
 	api "github.com/rocboss/paopao-ce/auto/api/v1"
 	"github.com/rocboss/paopao-ce/internal/dao/cache"
+	"github.com/rocboss/paopao-ce/internal/model/joint"
 	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
 	"github.com/rocboss/paopao-ce/internal/servants/chain"
 	"github.com/rocboss/paopao-ce/pkg/xerror"
 	"github.com/sirupsen/logrus"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 )
 
 var (
@@ -31,50 +33,126 @@ func (s *followshipSrv) Chain() gin.HandlersChain {
 	return gin.HandlersChain{chain.JwtLoose()}
 }
 
+// ListFollowings 用户的粉丝，按最近互动倒序
 func (s *followshipSrv) ListFollowings(r *web.ListFollowingsReq) (*web.ListFollowingsResp, mir.Error) {
 	he, err := s.Ds.GetUserByUsername(r.Username)
 	if err != nil {
 		logrus.Errorf("Ds.GetUserByUsername err: %s", err)
 		return nil, web.ErrNoExistUsername
 	}
-	res, err := s.Ds.ListFollowings(he.ID, r.PageSize, (r.Page-1)*r.PageSize)
+	cursor, xerr := pageCursorFrom(r.Cursor)
+	if xerr != nil {
+		return nil, xerr
+	}
+	if !s.followListVisible(r.User, he) {
+		return nil, web.ErrFollowListIsPrivate
+	}
+	res, next, err := s.Ds.ListFollowings(he.ID, r.Keyword, cursor, r.PageSize, (r.Page-1)*r.PageSize)
 	if err != nil {
 		logrus.Errorf("Ds.ListFollowings err: %s", err)
 		return nil, web.ErrListFollowingsFailed
 	}
-	if r.BaseInfo.User != nil {
-		for i, contact := range res.Contacts {
-			res.Contacts[i].IsFollowing = s.Ds.IsFollow(r.User.ID, contact.UserId)
-		}
-	}
-	resp := base.PageRespFrom(res.Contacts, r.Page, r.PageSize, res.Total)
+	s.prepareContacts(r.User, res.Contacts)
+	resp := contactsPageResp(res, r.Page, r.PageSize, cursor, next)
 	return (*web.ListFollowingsResp)(resp), nil
 }
 
+// ListFollows 用户关注的人，按最近互动倒序
 func (s *followshipSrv) ListFollows(r *web.ListFollowsReq) (*web.ListFollowsResp, mir.Error) {
 	he, err := s.Ds.GetUserByUsername(r.Username)
 	if err != nil {
 		logrus.Errorf("Ds.GetUserByUsername err: %s", err)
 		return nil, web.ErrNoExistUsername
 	}
-	res, err := s.Ds.ListFollows(he.ID, r.PageSize, (r.Page-1)*r.PageSize)
+	cursor, xerr := pageCursorFrom(r.Cursor)
+	if xerr != nil {
+		return nil, xerr
+	}
+	if !s.followListVisible(r.User, he) {
+		return nil, web.ErrFollowListIsPrivate
+	}
+	res, next, err := s.Ds.ListFollows(he.ID, r.Keyword, cursor, r.PageSize, (r.Page-1)*r.PageSize)
 	if err != nil {
 		logrus.Errorf("Ds.ListFollows err: %s", err)
 		return nil, web.ErrListFollowsFailed
 	}
-	if r.BaseInfo.User != nil {
-		if r.User.Username == r.Username {
-			for i := range res.Contacts {
-				res.Contacts[i].IsFollowing = true
-			}
-		} else {
-			for i, contact := range res.Contacts {
-				res.Contacts[i].IsFollowing = s.Ds.IsFollow(r.User.ID, contact.UserId)
-			}
+	s.prepareContacts(r.User, res.Contacts)
+	resp := contactsPageResp(res, r.Page, r.PageSize, cursor, next)
+	return (*web.ListFollowsResp)(resp), nil
+}
+
+// RemoveFollower 移除粉丝，对方可以再次关注，需要阻止再次关注时应屏蔽对方
+func (s *followshipSrv) RemoveFollower(r *web.RemoveFollowerReq) mir.Error {
+	if r.User == nil {
+		return xerror.UnauthorizedTokenError
+	} else if r.User.ID == r.UserId {
+		return web.ErrNoActionToSelf
+	}
+	if err := s.Ds.RemoveFollower(r.User.ID, r.UserId); err != nil {
+		if !s.Ds.IsFollow(r.UserId, r.User.ID) && !s.Ds.IsFollowRequested(r.UserId, r.User.ID) {
+			return web.ErrNotYourFollower
 		}
+		logrus.Errorf("Ds.RemoveFollower err: %s userId: %d followerId: %d", err, r.User.ID, r.UserId)
+		return web.ErrRemoveFollowerFailed
 	}
-	resp := base.PageRespFrom(res.Contacts, r.Page, r.PageSize, res.Total)
-	return (*web.ListFollowsResp)(resp), nil
+	// 触发对方的关注关系缓存更新
+	cache.OnCacheMyFollowIdsEvent(s.Ds, r.UserId)
+	cache.OnExpireIndexTweetEvent(r.UserId)
+	onMessageActionEvent(_messageActionFollow, r.UserId)
+	onTrendsActionEvent(_trendsActionUnfollowUser, r.UserId)
+	return nil
+}
+
+// followListVisible 私密账户的关注与粉丝列表只有本人、已通过的关注者及有查看用户权限的管理员可见
+func (s *followshipSrv) followListVisible(viewer *ms.User, he *ms.User) bool {
+	if !he.IsPrivate {
+		return true
+	}
+	return viewer != nil && (viewer.ID == he.ID || s.Ds.HasPermission(viewer, cs.PermUserView) || s.Ds.IsFollow(viewer.ID, he.ID))
+}
+
+// prepareContacts 补充浏览者视角的关系标记及在线状态，查询失败时不影响列表
+func (s *followshipSrv) prepareContacts(viewer *ms.User, contacts []ms.ContactItem) {
+	if len(contacts) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(contacts))
+	for _, contact := range contacts {
+		ids = append(ids, contact.UserId)
+	}
+	online, err := _wc.BatchCheckOnlineUsers(ids)
+	if err != nil {
+		logrus.Warnf("wc.BatchCheckOnlineUsers err: %s", err)
+	}
+	var following, follower, friend map[int64]bool
+	if viewer != nil {
+		if following, err = s.Ds.IsMyFollow(viewer.ID, ids...); err != nil {
+			logrus.Warnf("Ds.IsMyFollow err: %s", err)
+		}
+		if follower, err = s.Ds.IsMyFollower(viewer.ID, ids...); err != nil {
+			logrus.Warnf("Ds.IsMyFollower err: %s", err)
+		}
+		if friend, err = s.Ds.IsMyFriend(viewer.ID, ids...); err != nil {
+			logrus.Warnf("Ds.IsMyFriend err: %s", err)
+		}
+	}
+	for i := range contacts {
+		id := contacts[i].UserId
+		contacts[i].IsOnline = online[id]
+		contacts[i].IsFollowing = following[id]
+		contacts[i].FollowsYou = follower[id]
+		contacts[i].IsFriend = friend[id]
+	}
+}
+
+// contactsPageResp 关注列表分页响应，偏移分页时同样返回下一页游标
+func contactsPageResp(res *ms.ContactList, page, pageSize int, cursor, next *cs.PageCursor) *joint.PageResp {
+	if cursor != nil {
+		return joint.CursorPageRespFrom(res.Contacts, pageSize, next.Encode())
+	}
+	resp := joint.PageRespFrom(res.Contacts, page, pageSize, res.Total)
+	resp.NextCursor = next.Encode()
+	return resp
 }
 
 func (s *followshipSrv) UnfollowUser(r *web.UnfollowUserReq) mir.Error {
@@ -96,15 +174,38 @@ func (s *followshipSrv) UnfollowUser(r *web.UnfollowUserReq) mir.Error {
 	return nil
 }
 
-func (s *followshipSrv) FollowUser(r *web.FollowUserReq) mir.Error {
+// FollowUser 关注私密账户时只发送关注请求，对方通过后才成为关注者
+func (s *followshipSrv) FollowUser(r *web.FollowUserReq) (*web.FollowUserResp, mir.Error) {
 	if r.User == nil {
-		return xerror.UnauthorizedTokenError
+		return nil, xerror.UnauthorizedTokenError
 	} else if r.User.ID == r.UserId {
-		return web.ErrNotAllowFollowSelf
+		return nil, web.ErrNotAllowFollowSelf
+	}
+	he, err := s.Ds.GetUserByID(r.UserId)
+	if err != nil {
+		return nil, web.ErrNoExistUsername
 	}
-	if err := s.Ds.FollowUser(r.User.ID, r.UserId); err != nil {
+	if he.IsPrivate && !s.Ds.IsFollow(r.User.ID, he.ID) {
+		// 已有待通过的请求时不再重复通知
+		if s.Ds.IsFollowRequested(r.User.ID, he.ID) {
+			return &web.FollowUserResp{Status: cs.FollowStatusRequesting}, nil
+		}
+		if err = s.Ds.RequestFollow(r.User.ID, he.ID); err != nil {
+			logrus.Errorf("Ds.RequestFollow err: %s userId: %d followId: %d", err, r.User.ID, he.ID)
+			return nil, web.ErrFolloUserFailed
+		}
+		onCreateMessageEvent(&ms.Message{
+			SenderUserID:   r.User.ID,
+			ReceiverUserID: he.ID,
+			Type:           ms.MsgTypeFollowRequest,
+			Brief:          "请求关注你",
+			Content:        fmt.Sprintf("用户 %s 请求关注你", r.User.Username),
+		})
+		return &web.FollowUserResp{Status: cs.FollowStatusRequesting}, nil
+	}
+	if err = s.Ds.FollowUser(r.User.ID, r.UserId); err != nil {
 		logrus.Errorf("Ds.FollowUser err: %s userId: %d followId: %d", err, r.User.ID, r.UserId)
-		return web.ErrUnfollowUserFailed
+		return nil, web.ErrUnfollowUserFailed
 	}
 	
 	// Create follow notification message
@@ -122,6 +223,103 @@ func (s *followshipSrv) FollowUser(r *web.FollowUserReq) mir.Error {
 	cache.OnExpireIndexTweetEvent(r.User.ID)
 	onMessageActionEvent(_messageActionFollow, r.User.ID)
 	onTrendsActionEvent(_trendsActionFollowUser, r.User.ID)
+	return &web.FollowUserResp{Status: cs.FollowStatusAgree}, nil
+}
+
+// ChangePrivateAccount 改为公开账户时通过所有待处理的关注请求
+func (s *followshipSrv) ChangePrivateAccount(r *web.ChangePrivateAccountReq) mir.Error {
+	if r.User == nil {
+		return xerror.UnauthorizedTokenError
+	}
+	user := r.User
+	user.IsPrivate = r.IsPrivate
+	if err := s.Ds.UpdateUser(user); err != nil {
+		logrus.Errorf("Ds.UpdateUser err: %s", err)
+		return web.ErrChangePrivateAccountFailed
+	}
+	// 缓存处理
+	onChangeUsernameEvent(user.ID, user.Username)
+	if !r.IsPrivate {
+		followerIds, err := s.Ds.ApproveAllFollowRequests(user.ID)
+		if err != nil {
+			logrus.Errorf("Ds.ApproveAllFollowRequests err: %s userId: %d", err, user.ID)
+			return web.ErrApproveFollowRequestFailed
+		}
+		for _, followerId := range followerIds {
+			s.onFollowRequestApproved(user, followerId)
+		}
+	}
+	return nil
+}
+
+func (s *followshipSrv) ListFollowRequests(r *web.ListFollowRequestsReq) (*web.ListFollowRequestsResp, mir.Error) {
+	if r.User == nil {
+		return nil, xerror.UnauthorizedTokenError
+	}
+	res, err := s.Ds.ListFollowRequests(r.User.ID, r.PageSize, (r.Page-1)*r.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListFollowRequests err: %s", err)
+		return nil, web.ErrListFollowRequestsFailed
+	}
+	resp := base.PageRespFrom(res.Contacts, r.Page, r.PageSize, res.Total)
+	return (*web.ListFollowRequestsResp)(resp), nil
+}
+
+func (s *followshipSrv) ApproveFollowRequest(r *web.ApproveFollowRequestReq) mir.Error {
+	if r.User == nil {
+		return xerror.UnauthorizedTokenError
+	}
+	if !s.Ds.IsFollowRequested(r.UserId, r.User.ID) {
+		return web.ErrFollowRequestNotFound
+	}
+	if err := s.Ds.ApproveFollowRequest(r.User.ID, r.UserId); err != nil {
+		logrus.Errorf("Ds.ApproveFollowRequest err: %s userId: %d followerId: %d", err, r.User.ID, r.UserId)
+		return web.ErrApproveFollowRequestFailed
+	}
+	s.onFollowRequestApproved(r.User, r.UserId)
+	return nil
+}
+
+func (s *followshipSrv) DeclineFollowRequest(r *web.DeclineFollowRequestReq) mir.Error {
+	if r.User == nil {
+		return xerror.UnauthorizedTokenError
+	}
+	if !s.Ds.IsFollowRequested(r.UserId, r.User.ID) {
+		return web.ErrFollowRequestNotFound
+	}
+	if err := s.Ds.DeclineFollowRequest(r.User.ID, r.UserId); err != nil {
+		logrus.Errorf("Ds.DeclineFollowRequest err: %s userId: %d followerId: %d", err, r.User.ID, r.UserId)
+		return web.ErrDeclineFollowRequestFailed
+	}
+	return nil
+}
+
+// onFollowRequestApproved 通知请求者并刷新其关注关系缓存
+func (s *followshipSrv) onFollowRequestApproved(user *ms.User, followerId int64) {
+	onCreateMessageEvent(&ms.Message{
+		SenderUserID:   user.ID,
+		ReceiverUserID: followerId,
+		Type:           ms.MsgTypeFollowApproved,
+		Brief:          "通过了你的关注请求",
+		Content:        fmt.Sprintf("用户 %s 通过了你的关注请求", user.Username),
+	})
+	cache.OnCacheMyFollowIdsEvent(s.Ds, followerId)
+	cache.OnExpireIndexTweetEvent(followerId)
+	onMessageActionEvent(_messageActionFollow, followerId)
+	onTrendsActionEvent(_trendsActionFollowUser, followerId)
+}
+
+// ChangeRoomPing 只有关注了对方才能开启对方开房间时的"叫人来"提醒
+func (s *followshipSrv) ChangeRoomPing(r *web.ChangeRoomPingReq) mir.Error {
+	if r.User == nil {
+		return xerror.UnauthorizedTokenError
+	} else if !s.Ds.IsFollow(r.User.ID, r.UserId) {
+		return web.ErrNotFollowedUser
+	}
+	if err := s.Ds.SetRoomPing(r.User.ID, r.UserId, r.Enable); err != nil {
+		logrus.Errorf("Ds.SetRoomPing err: %s userId: %d followId: %d", err, r.User.ID, r.UserId)
+		return web.ErrChangeRoomPingFailed
+	}
 	return nil
 }
 
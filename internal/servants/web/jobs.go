@@ -5,10 +5,18 @@
 package web
 
 import (
+	"time"
+
 	"github.com/alimy/tryst/cfg"
 	"github.com/robfig/cron/v3"
 	"github.com/rocboss/paopao-ce/internal/conf"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao"
+	"github.com/rocboss/paopao-ce/internal/dao/search"
 	"github.com/rocboss/paopao-ce/internal/infra/events"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
+	"github.com/rocboss/paopao-ce/pkg/suggest"
 	"github.com/sirupsen/logrus"
 )
 
@@ -30,10 +38,279 @@ func onMaxOnlineJob() {
 	})
 }
 
-func scheduleJobs() {
+// onAccountDeletionJob 执行冷静期已过的账户注销
+func onAccountDeletionJob() {
+	spec := conf.JobManagerSetting.AccountDeletionInterval
+	if spec == "" {
+		return
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(err)
+	}
+	events.OnTask(schedule, func() {
+		deletions, err := _ds.ListDueAccountDeletions(time.Now().Unix(), 100)
+		if err != nil {
+			logrus.Warnf("onAccountDeletionJob[1] occurs error: %s", err)
+			return
+		}
+		for _, deletion := range deletions {
+			if err = purgeAccount(_ds, _oss, deletion); err != nil {
+				logrus.Warnf("onAccountDeletionJob[2] purge account[%d] occurs error: %s", deletion.UserID, err)
+			} else if err = search.PushSync(_ds, cs.SearchIndexUser, deletion.UserID); err != nil {
+				logrus.Warnf("onAccountDeletionJob[3] sync account[%d] to search occurs error: %s", deletion.UserID, err)
+			}
+		}
+	})
+}
+
+// onScheduledTweetsJob 发布计划时间已到的定时动态
+func onScheduledTweetsJob(ds *base.DaoServant) {
+	spec := conf.JobManagerSetting.ScheduledTweetsInterval
+	if spec == "" {
+		return
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(err)
+	}
+	events.OnTask(schedule, func() {
+		// 录音超时仍未完成的动态转为草稿，由作者决定是否发布
+		if timeout := conf.JobManagerSetting.ProcessingTweetsTimeout; timeout > 0 {
+			if _, err := _ds.ExpireProcessingPosts(time.Now().Unix() - timeout); err != nil {
+				logrus.Warnf("onScheduledTweetsJob[3] expire processing posts occurs error: %s", err)
+			}
+		}
+		posts, err := _ds.ListDueScheduledTweets(time.Now().Unix(), 100)
+		if err != nil {
+			logrus.Warnf("onScheduledTweetsJob[1] occurs error: %s", err)
+			return
+		}
+		for _, post := range posts {
+			// 多个实例同时运行时只由认领成功的实例发布，列出后被作者推迟或编辑的动态不会被认领
+			claimed, err := _ds.ClaimPostStatus(post.ID, ms.PostStatusScheduled, ms.PostStatusPublished, time.Now().Unix())
+			if err != nil {
+				logrus.Warnf("onScheduledTweetsJob[4] claim post[%d] occurs error: %s", post.ID, err)
+				continue
+			} else if !claimed {
+				continue
+			}
+			// 按认领后的最新内容发布
+			if post, err = _ds.GetPostByID(post.ID); err != nil {
+				logrus.Warnf("onScheduledTweetsJob[5] reload post occurs error: %s", err)
+				continue
+			}
+			if err = ds.PublishTweet(post); err != nil {
+				logrus.Warnf("onScheduledTweetsJob[2] publish post[%d] occurs error: %s", post.ID, err)
+				continue
+			}
+			onTrendsActionEvent(_trendsActionCreateTweet, post.GetHostID())
+			onTweetMentions(_ds, post)
+		}
+	})
+}
+
+// onForYouCandidatesJob 定期预计算"为你推荐"的候选集并写入缓存
+func onForYouCandidatesJob() {
+	spec := conf.JobManagerSetting.ForYouCandidatesInterval
+	if spec == "" {
+		return
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(err)
+	}
+	events.OnTask(schedule, func() {
+		// 先过期旧的候选集，再由缓存数据服务重新计算并写入缓存
+		if err := _ac.Delete(conf.KeyForYouCandidates); err != nil {
+			logrus.Warnf("onForYouCandidatesJob[1] occurs error: %s", err)
+		}
+		setting := conf.ForYouSetting
+		if _, err := _ds.ListFeedCandidates(time.Now().Unix()-setting.CandidateWindow, setting.CandidateSize); err != nil {
+			logrus.Warnf("onForYouCandidatesJob[2] occurs error: %s", err)
+		}
+	})
+}
+
+// onFriendSuggestJob 定期为所有用户预计算"可能认识的人"推荐
+func onFriendSuggestJob() {
+	spec := conf.JobManagerSetting.FriendSuggestInterval
+	if spec == "" {
+		return
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(err)
+	}
+	events.OnTask(schedule, func() {
+		setting := conf.SuggestSetting
+		for afterId := int64(0); ; {
+			userIds, err := _ds.ListSuggestUserIds(afterId, setting.BatchSize)
+			if err != nil {
+				logrus.Warnf("onFriendSuggestJob[1] occurs error: %s", err)
+				return
+			}
+			for _, userId := range userIds {
+				if err = refreshFriendSuggestions(userId); err != nil {
+					logrus.Warnf("onFriendSuggestJob[2] user[%d] occurs error: %s", userId, err)
+				}
+			}
+			if len(userIds) < setting.BatchSize {
+				return
+			}
+			afterId = userIds[len(userIds)-1]
+		}
+	})
+}
+
+// refreshFriendSuggestions 汇总好友的好友、关注的人的关注、通讯录、相同分类及正向反应信号，
+// 排除自己、已是好友/已关注、已拉黑及被忽略/隐藏的用户后排序保存
+func refreshFriendSuggestions(userId int64) error {
+	setting := conf.SuggestSetting
+	friendIds, err := _ds.MyFriendIds(userId)
+	if err != nil {
+		return err
+	}
+	followIds, err := _ds.MyFollowIds(userId)
+	if err != nil {
+		return err
+	}
+	feedbackIds, err := _ds.ListSuggestFeedbackIds(userId, time.Now().Unix()-setting.DismissExpire)
+	if err != nil {
+		return err
+	}
+	excluded := map[int64]struct{}{userId: {}}
+	for _, ids := range [][]int64{friendIds, followIds, feedbackIds} {
+		for _, id := range ids {
+			excluded[id] = struct{}{}
+		}
+	}
+	signals := make(map[int64]*suggest.Signals)
+	signal := func(id int64) *suggest.Signals {
+		if _, ok := excluded[id]; ok {
+			return nil
+		}
+		s, ok := signals[id]
+		if !ok {
+			s = &suggest.Signals{UserID: id}
+			signals[id] = s
+		}
+		return s
+	}
+	// 好友/关注的关系id使用缓存的bitmap，避免逐个查库
+	for i, friendId := range friendIds {
+		if i >= setting.MaxCandidates {
+			break
+		}
+		ids, err := _ds.MyFriendIds(friendId)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if s := signal(id); s != nil {
+				s.MutualFriends++
+			}
+		}
+	}
+	for i, followId := range followIds {
+		if i >= setting.MaxCandidates {
+			break
+		}
+		ids, err := _ds.MyFollowIds(followId)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if s := signal(id); s != nil {
+				s.MutualFollows++
+			}
+		}
+	}
+	contactIds, err := _ds.ListContactMatchedIds(userId)
+	if err != nil {
+		return err
+	}
+	for _, id := range contactIds {
+		if s := signal(id); s != nil {
+			s.Contact = true
+		}
+	}
+	categories, err := _ds.ListSharedCategoryUsers(userId, setting.MaxCandidates)
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if s := signal(c.UserID); s != nil {
+			s.SharedCategories = c.Total
+		}
+	}
+	reactions, err := _ds.ListPositiveReactionUsers(userId, setting.MaxCandidates)
+	if err != nil {
+		return err
+	}
+	for _, c := range reactions {
+		if s := signal(c.UserID); s != nil {
+			s.Reactions = c.Total
+		}
+	}
+	candidates := make([]*suggest.Signals, 0, len(signals))
+	for _, s := range signals {
+		candidates = append(candidates, s)
+	}
+	items := make([]*ms.UserSuggestion, 0, setting.MaxSuggestions)
+	for _, item := range suggest.Rank(suggest.DefaultWeights(), candidates, 0) {
+		if len(items) >= setting.MaxSuggestions {
+			break
+		}
+		if _ds.IsBlockedEither(userId, item.UserID) {
+			continue
+		}
+		items = append(items, &ms.UserSuggestion{
+			Model:       &ms.Model{},
+			UserID:      userId,
+			SuggestedID: item.UserID,
+			Score:       item.Score,
+			Reason:      item.Reason,
+			Explanation: item.Explanation,
+		})
+	}
+	return _ds.ReplaceUserSuggestions(userId, items)
+}
+
+// onSearchDriftJob 定期对比数据库与搜索索引的文档数，偏差较大时需要执行 search reindex
+func onSearchDriftJob() {
+	spec := conf.JobManagerSetting.SearchDriftInterval
+	if spec == "" {
+		return
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(err)
+	}
+	sms := dao.SearchIndexManageService()
+	events.OnTask(schedule, func() {
+		for _, index := range cs.SearchIndexes {
+			drift, err := sms.CheckDrift(index)
+			if err != nil {
+				logrus.Warnf("onSearchDriftJob check %s occurs error: %s", index, err)
+				continue
+			}
+			if drift.Drift() != 0 || drift.FailedCount > 0 {
+				logrus.Warnf("onSearchDriftJob %s[%s] db: %d index: %d pending: %d failed: %d", index, drift.IndexName, drift.DbCount, drift.IndexCount, drift.PendingCount, drift.FailedCount)
+			}
+		}
+	})
+}
+
+func scheduleJobs(ds *base.DaoServant) {
 	cfg.Not("DisableJobManager", func() {
 		lazyInitial()
 		onMaxOnlineJob()
+		onAccountDeletionJob()
+		onScheduledTweetsJob(ds)
+		onForYouCandidatesJob()
+		onFriendSuggestJob()
+		onSearchDriftJob()
 		logrus.Debug("schedule inner jobs complete")
 	})
 }
@@ -6,6 +6,9 @@ package web
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/alimy/mir/v4"
 	"github.com/gin-gonic/gin"
@@ -19,6 +22,9 @@ import (
 	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
 	"github.com/rocboss/paopao-ce/internal/servants/chain"
+	"github.com/rocboss/paopao-ce/pkg/geo"
+	"github.com/rocboss/paopao-ce/pkg/json"
+	"github.com/rocboss/paopao-ce/pkg/rank"
 	"github.com/sirupsen/logrus"
 )
 
@@ -37,7 +43,9 @@ type looseSrv struct {
 	prefixIdxTweetsNewest    string
 	prefixIdxTweetsHots      string
 	prefixIdxTweetsFollowing string
+	prefixIdxTweetsForYou    string
 	prefixTweetComment       string
+	ranker                   rank.Ranker
 }
 
 func (s *looseSrv) Chain() gin.HandlersChain {
@@ -49,6 +57,10 @@ func (s *looseSrv) Timeline(req *web.TimelineReq) (*web.TimelineResp, mir.Error)
 	if req.Query == "" && req.Type == "search" {
 		return s.getIndexTweets(req, limit, offset)
 	}
+	if req.Cursor != "" {
+		// 搜索结果由搜索引擎排序，不支持游标分页
+		return nil, web.ErrInvalidPageCursor
+	}
 	q := &core.QueryReq{
 		Query: req.Query,
 		Type:  core.SearchType(req.Type),
@@ -80,6 +92,15 @@ func (s *looseSrv) Timeline(req *web.TimelineReq) (*web.TimelineResp, mir.Error)
 }
 
 func (s *looseSrv) getIndexTweets(req *web.TimelineReq, limit int, offset int) (res *web.TimelineResp, err mir.Error) {
+	cursor, err := pageCursorFrom(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	rankedStyle := req.Style == web.StyleTweetsHots || req.Style == web.StyleTweetsForYou
+	if cursor != nil && rankedStyle {
+		// 热门及推荐动态按实时变化的分值排序，不支持游标分页
+		return nil, web.ErrInvalidPageCursor
+	}
 	// 尝试直接从缓存中获取数据
 	key, ok := "", false
 	if res, key, ok = s.indexTweetsFromCache(req, limit, offset); ok {
@@ -89,21 +110,24 @@ func (s *looseSrv) getIndexTweets(req *web.TimelineReq, limit int, offset int) (
 	var (
 		posts []*ms.Post
 		total int64
+		next  *cs.PageCursor
 		xerr  error
 	)
 	switch req.Style {
 	case web.StyleTweetsFollowing:
 		if req.User != nil {
-			posts, total, xerr = s.Ds.ListFollowingTweets(req.User.ID, limit, offset)
+			posts, total, next, xerr = s.listFollowingTweets(req.User.ID, cursor, limit, offset)
 		} else {
 			// return nil, web.ErrGetPostsNilUser
 			// 宽松处理，前端退出登录后马上获取动态列表，可能错误走到这里
-			posts, total, xerr = s.Ds.ListIndexNewestTweets(limit, offset)
+			posts, total, next, xerr = s.listIndexNewestTweets(cursor, limit, offset)
 		}
 	case web.StyleTweetsNewest:
-		posts, total, xerr = s.Ds.ListIndexNewestTweets(limit, offset)
+		posts, total, next, xerr = s.listIndexNewestTweets(cursor, limit, offset)
 	case web.StyleTweetsHots:
 		posts, total, xerr = s.Ds.ListIndexHotsTweets(limit, offset)
+	case web.StyleTweetsForYou:
+		posts, total, xerr = s.listForYouTweets(req.User, limit, offset)
 	default:
 		return nil, web.ErrGetPostsUnknowStyle
 	}
@@ -124,7 +148,16 @@ func (s *looseSrv) getIndexTweets(req *web.TimelineReq, limit int, offset int) (
 		logrus.Errorf("getIndexTweets occurs error[2]: %s", err)
 		return nil, web.ErrGetPostsFailed
 	}
-	resp := joint.PageRespFrom(postsFormated, req.Page, req.PageSize, total)
+	var resp *joint.PageResp
+	if cursor != nil {
+		resp = joint.CursorPageRespFrom(dedupTweets(postsFormated), req.PageSize, next.Encode())
+	} else {
+		resp = joint.PageRespFrom(postsFormated, req.Page, req.PageSize, total)
+		if !rankedStyle {
+			resp.NextCursor = offsetNextCursor(req.Page, req.PageSize, len(postsFormated), total, postsCursor(postsFormated))
+		}
+		resp.List = dedupTweets(postsFormated)
+	}
 	// 缓存处理
 	base.OnCacheRespEvent(s.ac, key, resp, s.idxTweetsExpire)
 	return &web.TimelineResp{
@@ -134,18 +167,100 @@ func (s *looseSrv) getIndexTweets(req *web.TimelineReq, limit int, offset int) (
 	}, nil
 }
 
+func (s *looseSrv) listFollowingTweets(userId int64, cursor *cs.PageCursor, limit int, offset int) (posts []*ms.Post, total int64, next *cs.PageCursor, err error) {
+	if cursor != nil {
+		posts, next, err = s.Ds.ListFollowingTweetsByCursor(userId, cursor, limit)
+	} else {
+		posts, total, err = s.Ds.ListFollowingTweets(userId, limit, offset)
+	}
+	return
+}
+
+func (s *looseSrv) listIndexNewestTweets(cursor *cs.PageCursor, limit int, offset int) (posts []*ms.Post, total int64, next *cs.PageCursor, err error) {
+	if cursor != nil {
+		posts, next, err = s.Ds.ListIndexNewestTweetsByCursor(cursor, limit)
+	} else {
+		posts, total, err = s.Ds.ListIndexNewestTweets(limit, offset)
+	}
+	return
+}
+
+// listForYouTweets 个性化推荐动态，排序结果按用户缓存，保证缓存期内翻页稳定
+func (s *looseSrv) listForYouTweets(user *ms.User, limit int, offset int) ([]*ms.Post, int64, error) {
+	ids, err := s.forYouFeed(user)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := int64(len(ids))
+	if offset >= len(ids) {
+		return nil, total, nil
+	}
+	ids = ids[offset:min(offset+limit, len(ids))]
+	posts, err := s.Ds.GetPosts(ms.ConditionsT{"id IN ?": ids}, 0, len(ids))
+	if err != nil {
+		return nil, 0, err
+	}
+	order := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		order[id] = i
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return order[posts[i].ID] < order[posts[j].ID]
+	})
+	return posts, total, nil
+}
+
+// forYouFeed 获取用户的推荐动态排序结果，游客使用不含个性化信号的排序
+func (s *looseSrv) forYouFeed(user *ms.User) (ids []int64, err error) {
+	userId := int64(0)
+	if user != nil {
+		userId = user.ID
+	}
+	key := conf.KeyForYouFeed.Get(userId)
+	if data, xerr := s.ac.Get(key); xerr == nil {
+		if xerr = json.Unmarshal(data, &ids); xerr == nil {
+			return
+		}
+	}
+	setting, now := conf.ForYouSetting, time.Now().Unix()
+	candidates, err := s.Ds.ListFeedCandidates(now-setting.CandidateWindow, setting.CandidateSize)
+	if err != nil {
+		return nil, err
+	}
+	var viewer *cs.FeedViewer
+	if user != nil {
+		if viewer, err = s.Ds.GetFeedViewer(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	items := rank.Rank(s.ranker, viewer, candidates, now)
+	ids = make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if data, xerr := json.Marshal(ids); xerr == nil {
+		s.ac.Set(key, data, setting.FeedExpire)
+	}
+	return
+}
+
 func (s *looseSrv) indexTweetsFromCache(req *web.TimelineReq, limit int, offset int) (res *web.TimelineResp, key string, ok bool) {
-	username := "_"
+	username, pos := "_", strconv.Itoa(offset)
 	if req.User != nil {
 		username = req.User.Username
 	}
+	if req.Cursor != "" {
+		pos = "c" + req.Cursor
+	}
 	switch req.Style {
 	case web.StyleTweetsFollowing:
-		key = fmt.Sprintf("%s%s:%d:%d", s.prefixIdxTweetsFollowing, username, offset, limit)
+		key = fmt.Sprintf("%s%s:%s:%d", s.prefixIdxTweetsFollowing, username, pos, limit)
 	case web.StyleTweetsNewest:
-		key = fmt.Sprintf("%s%s:%d:%d", s.prefixIdxTweetsNewest, username, offset, limit)
+		key = fmt.Sprintf("%s%s:%s:%d", s.prefixIdxTweetsNewest, username, pos, limit)
 	case web.StyleTweetsHots:
-		key = fmt.Sprintf("%s%s:%d:%d", s.prefixIdxTweetsHots, username, offset, limit)
+		key = fmt.Sprintf("%s%s:%s:%d", s.prefixIdxTweetsHots, username, pos, limit)
+	case web.StyleTweetsForYou:
+		key = fmt.Sprintf("%s%s:%s:%d", s.prefixIdxTweetsForYou, username, pos, limit)
 	default:
 		return
 	}
@@ -183,17 +298,21 @@ func (s *looseSrv) GetUserTweets(req *web.GetUserTweetsReq) (res *web.GetUserTwe
 		return
 	}
 	// 缓存获取未成功，只能查库了
+	cursor, err := pageCursorFrom(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
 	switch req.Style {
 	case web.UserPostsStyleComment, web.UserPostsStyleMedia:
-		res, err = s.listUserTweets(req, user)
+		res, err = s.listUserTweets(req, user, cursor)
 	case web.UserPostsStyleHighlight:
-		res, err = s.getUserPostTweets(req, user, true)
+		res, err = s.getUserPostTweets(req, user, cursor, true)
 	case web.UserPostsStyleStar:
-		res, err = s.getUserStarTweets(req, user)
+		res, err = s.getUserStarTweets(req, user, cursor)
 	case web.UserPostsStylePost:
 		fallthrough
 	default:
-		res, err = s.getUserPostTweets(req, user, false)
+		res, err = s.getUserPostTweets(req, user, cursor, false)
 	}
 	// 缓存处理
 	if err == nil {
@@ -203,15 +322,19 @@ func (s *looseSrv) GetUserTweets(req *web.GetUserTweetsReq) (res *web.GetUserTwe
 }
 
 func (s *looseSrv) userTweetsFromCache(req *web.GetUserTweetsReq, user *cs.VistUser) (res *web.GetUserTweetsResp, key string, ok bool) {
+	pos := strconv.Itoa(req.Page)
+	if req.Cursor != "" {
+		pos = "c" + req.Cursor
+	}
 	switch req.Style {
 	case web.UserPostsStylePost, web.UserPostsStyleHighlight, web.UserPostsStyleMedia:
-		key = fmt.Sprintf("%s%d:%s:%s:%d:%d", s.prefixUserTweets, user.UserId, req.Style, user.RelTyp, req.Page, req.PageSize)
+		key = fmt.Sprintf("%s%d:%s:%s:%s:%d", s.prefixUserTweets, user.UserId, req.Style, user.RelTyp, pos, req.PageSize)
 	default:
 		meName := "_"
 		if user.RelTyp != cs.RelationGuest {
 			meName = req.User.Username
 		}
-		key = fmt.Sprintf("%s%d:%s:%s:%d:%d", s.prefixUserTweets, user.UserId, req.Style, meName, req.Page, req.PageSize)
+		key = fmt.Sprintf("%s%d:%s:%s:%s:%d", s.prefixUserTweets, user.UserId, req.Style, meName, pos, req.PageSize)
 	}
 	if data, err := s.ac.Get(key); err == nil {
 		ok, res = true, &web.GetUserTweetsResp{
@@ -223,8 +346,18 @@ func (s *looseSrv) userTweetsFromCache(req *web.GetUserTweetsReq, user *cs.VistU
 	return
 }
 
-func (s *looseSrv) getUserStarTweets(req *web.GetUserTweetsReq, user *cs.VistUser) (*web.GetUserTweetsResp, mir.Error) {
-	stars, totalRows, err := s.Ds.ListUserStarTweets(user, req.PageSize, (req.Page-1)*req.PageSize)
+func (s *looseSrv) getUserStarTweets(req *web.GetUserTweetsReq, user *cs.VistUser, cursor *cs.PageCursor) (*web.GetUserTweetsResp, mir.Error) {
+	var (
+		stars     []*ms.PostStar
+		totalRows int64
+		next      *cs.PageCursor
+		err       error
+	)
+	if cursor != nil {
+		stars, next, err = s.Ds.ListUserStarTweetsByCursor(user, cursor, req.PageSize)
+	} else {
+		stars, totalRows, err = s.Ds.ListUserStarTweets(user, req.PageSize, (req.Page-1)*req.PageSize)
+	}
 	if err != nil {
 		logrus.Errorf("getUserStarTweets err[1]: %s", err)
 		return nil, web.ErrGetStarsFailed
@@ -248,7 +381,17 @@ func (s *looseSrv) getUserStarTweets(req *web.GetUserTweetsReq, user *cs.VistUse
 		logrus.Errorf("getUserStarTweets err[2]: %s", err)
 		return nil, web.ErrGetPostsFailed
 	}
-	resp := joint.PageRespFrom(postsFormated, req.Page, req.PageSize, totalRows)
+	var resp *joint.PageResp
+	if cursor != nil {
+		resp = joint.CursorPageRespFrom(postsFormated, req.PageSize, next.Encode())
+	} else {
+		resp = joint.PageRespFrom(postsFormated, req.Page, req.PageSize, totalRows)
+		// 点赞列表按点赞记录排序，游标取自最后一条点赞记录
+		resp.NextCursor = offsetNextCursor(req.Page, req.PageSize, len(stars), totalRows, func() *cs.PageCursor {
+			last := stars[len(stars)-1]
+			return &cs.PageCursor{Value: last.CreatedOn, ID: last.ID}
+		})
+	}
 	return &web.GetUserTweetsResp{
 		CachePageResp: joint.CachePageResp{
 			Data: resp,
@@ -256,16 +399,25 @@ func (s *looseSrv) getUserStarTweets(req *web.GetUserTweetsReq, user *cs.VistUse
 	}, nil
 }
 
-func (s *looseSrv) listUserTweets(req *web.GetUserTweetsReq, user *cs.VistUser) (*web.GetUserTweetsResp, mir.Error) {
+func (s *looseSrv) listUserTweets(req *web.GetUserTweetsReq, user *cs.VistUser, cursor *cs.PageCursor) (*web.GetUserTweetsResp, mir.Error) {
 	var (
 		tweets []*ms.Post
 		total  int64
+		next   *cs.PageCursor
 		err    error
 	)
 	if req.Style == web.UserPostsStyleComment {
-		tweets, total, err = s.Ds.ListUserCommentTweets(user, req.PageSize, (req.Page-1)*req.PageSize)
+		if cursor != nil {
+			tweets, next, err = s.Ds.ListUserCommentTweetsByCursor(user, cursor, req.PageSize)
+		} else {
+			tweets, total, err = s.Ds.ListUserCommentTweets(user, req.PageSize, (req.Page-1)*req.PageSize)
+		}
 	} else if req.Style == web.UserPostsStyleMedia {
-		tweets, total, err = s.Ds.ListUserMediaTweets(user, req.PageSize, (req.Page-1)*req.PageSize)
+		if cursor != nil {
+			tweets, next, err = s.Ds.ListUserMediaTweetsByCursor(user, cursor, req.PageSize)
+		} else {
+			tweets, total, err = s.Ds.ListUserMediaTweets(user, req.PageSize, (req.Page-1)*req.PageSize)
+		}
 	} else {
 		logrus.Errorf("s.listUserTweets unknow style[1]: %s", req.Style)
 		return nil, web.ErrGetPostsFailed
@@ -287,7 +439,7 @@ func (s *looseSrv) listUserTweets(req *web.GetUserTweetsReq, user *cs.VistUser)
 		logrus.Errorf("s.listUserTweets err[4]: %s", err)
 		return nil, web.ErrGetPostsFailed
 	}
-	resp := joint.PageRespFrom(postsFormated, req.Page, req.PageSize, total)
+	resp := postsPageResp(postsFormated, req.Page, req.PageSize, total, cursor, next)
 	return &web.GetUserTweetsResp{
 		CachePageResp: joint.CachePageResp{
 			Data: resp,
@@ -295,7 +447,7 @@ func (s *looseSrv) listUserTweets(req *web.GetUserTweetsReq, user *cs.VistUser)
 	}, nil
 }
 
-func (s *looseSrv) getUserPostTweets(req *web.GetUserTweetsReq, user *cs.VistUser, isHighlight bool) (*web.GetUserTweetsResp, mir.Error) {
+func (s *looseSrv) getUserPostTweets(req *web.GetUserTweetsReq, user *cs.VistUser, cursor *cs.PageCursor, isHighlight bool) (*web.GetUserTweetsResp, mir.Error) {
 	style := cs.StyleUserTweetsGuest
 	switch user.RelTyp {
 	case cs.RelationAdmin:
@@ -311,7 +463,17 @@ func (s *looseSrv) getUserPostTweets(req *web.GetUserTweetsReq, user *cs.VistUse
 	default:
 		// nothing
 	}
-	posts, total, err := s.Ds.ListUserTweets(user.UserId, style, isHighlight, req.PageSize, (req.Page-1)*req.PageSize)
+	var (
+		posts []*ms.Post
+		total int64
+		next  *cs.PageCursor
+		err   error
+	)
+	if cursor != nil {
+		posts, next, err = s.Ds.ListUserTweetsByCursor(user.UserId, style, isHighlight, cursor, req.PageSize)
+	} else {
+		posts, total, err = s.Ds.ListUserTweets(user.UserId, style, isHighlight, req.PageSize, (req.Page-1)*req.PageSize)
+	}
 	if err != nil {
 		logrus.Errorf("s.GetTweetList error[1]: %s", err)
 		return nil, web.ErrGetPostsFailed
@@ -329,7 +491,7 @@ func (s *looseSrv) getUserPostTweets(req *web.GetUserTweetsReq, user *cs.VistUse
 		logrus.Errorf("s.GetTweetList error[3]: %s", err)
 		return nil, web.ErrGetPostsFailed
 	}
-	resp := joint.PageRespFrom(postsFormated, req.Page, req.PageSize, total)
+	resp := postsPageResp(postsFormated, req.Page, req.PageSize, total, cursor, next)
 	return &web.GetUserTweetsResp{
 		CachePageResp: joint.CachePageResp{
 			Data: resp,
@@ -348,9 +510,10 @@ func (s *looseSrv) GetUserProfile(req *web.GetUserProfileReq) (*web.GetUserProfi
 	if req.User != nil && req.User.ID != he.ID {
 		isFriend = s.Ds.IsFriend(req.User.ID, he.ID)
 	}
-	isFollowing := false
+	isFollowing, isFollowRequested := false, false
 	if req.User != nil {
 		isFollowing = s.Ds.IsFollow(req.User.ID, he.ID)
+		isFollowRequested = !isFollowing && s.Ds.IsFollowRequested(req.User.ID, he.ID)
 	}
 	follows, followings, err := s.Ds.GetFollowCount(he.ID)
 	if err != nil {
@@ -365,6 +528,8 @@ func (s *looseSrv) GetUserProfile(req *web.GetUserProfileReq) (*web.GetUserProfi
 		IsAdmin:     he.IsAdmin,
 		IsFriend:    isFriend,
 		IsFollowing: isFollowing,
+		IsPrivate:   he.IsPrivate,
+		IsFollowRequested: isFollowRequested,
 		CreatedOn:   he.CreatedOn,
 		Follows:     follows,
 		Followings:  followings,
@@ -506,50 +671,290 @@ func (s *looseSrv) TweetComments(req *web.TweetCommentsReq) (res *web.TweetComme
 	}, nil
 }
 
-func (s *looseSrv) TweetDetail(req *web.TweetDetailReq) (*web.TweetDetailResp, mir.Error) {
-	post, err := s.Ds.GetPostByID(req.TweetId)
+// TweetCommentReplies 分页获取评论的回复，被大量点踩的回复标记为折叠
+func (s *looseSrv) TweetCommentReplies(req *web.TweetCommentRepliesReq) (*web.TweetCommentRepliesResp, mir.Error) {
+	comment, err := s.Ds.GetCommentByID(req.CommentId)
 	if err != nil {
-		return nil, web.ErrGetPostFailed
+		return nil, web.ErrGetCommentFailed
 	}
-	postContents, err := s.Ds.GetPostContentsByIDs([]int64{post.ID})
+	replies, total, err := s.Ds.ListCommentReplies(comment.ID, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		logrus.Errorf("Ds.ListCommentReplies err: %s", err)
+		return nil, web.ErrGetRepliesFailed
+	}
+	if replies == nil {
+		replies = []*ms.CommentReplyFormated{}
+	}
+	if req.Uid > 0 && len(replies) > 0 {
+		_, replyThumbs, err := s.Ds.GetCommentThumbsMap(req.Uid, comment.PostID)
+		if err != nil {
+			logrus.Errorf("Ds.GetCommentThumbsMap err: %s", err)
+			return nil, web.ErrGetRepliesFailed
+		}
+		for _, reply := range replies {
+			if thumbs, exist := replyThumbs[reply.ID]; exist {
+				reply.IsThumbsUp, reply.IsThumbsDown = thumbs.IsThumbsUp, thumbs.IsThumbsDown
+			}
+		}
+	}
+	resp := base.PageRespFrom(replies, req.Page, req.PageSize, total)
+	return (*web.TweetCommentRepliesResp)(resp), nil
+}
+
+// TweetRevisions 获取动态或评论的编辑历史，须对所在动态有查看权限
+func (s *looseSrv) TweetRevisions(req *web.TweetRevisionsReq) (*web.TweetRevisionsResp, mir.Error) {
+	target, postId := cs.EditTargetPost, req.ID
+	if req.Type == cs.EditTargetComment {
+		comment, err := s.Ds.GetCommentByID(req.ID)
+		if err != nil {
+			return nil, web.ErrGetCommentFailed
+		}
+		target, postId = cs.EditTargetComment, comment.PostID
+	}
+	post, err := s.Ds.GetPostByID(postId)
 	if err != nil {
 		return nil, web.ErrGetPostFailed
 	}
-	users, err := s.Ds.GetUsersByIDs([]int64{post.GetHostID()})
+	// 与动态详情使用相同的可见性检查，仅关注者可见的动态需已关注作者
+	postsFormated, err := s.Ds.MergePosts([]*ms.Post{post})
 	if err != nil {
 		return nil, web.ErrGetPostFailed
 	}
-	// 数据整合
-	postFormated := post.Format()
-	for _, user := range users {
-		postFormated.User = user.Format()
+	if err = s.PrepareTweet(req.User, postsFormated[0]); err != nil {
+		return nil, web.ErrGetPostFailed
 	}
-	for _, content := range postContents {
-		if content.PostID == post.ID {
-			postFormated.Contents = append(postFormated.Contents, content.Format())
-		}
+	if !s.tweetVisible(req.User, post.Visibility, postsFormated[0]) {
+		return nil, web.ErrNoPermission
+	}
+	revisions, err := s.Ds.ListEditRevisions(target, req.ID)
+	if err != nil {
+		logrus.Errorf("Ds.ListEditRevisions err: %s", err)
+		return nil, web.ErrGetRevisionsFailed
 	}
+	if revisions == nil {
+		revisions = []*ms.EditRevision{}
+	}
+	return &web.TweetRevisionsResp{
+		List: revisions,
+	}, nil
+}
+
+func (s *looseSrv) TweetDetail(req *web.TweetDetailReq) (*web.TweetDetailResp, mir.Error) {
+	post, err := s.Ds.GetPostByID(req.TweetId)
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	// 数据整合，转发时附带原动态
+	postsFormated, err := s.Ds.MergePosts([]*ms.Post{post})
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	postFormated := postsFormated[0]
 	if err = s.PrepareTweet(req.User, postFormated); err != nil {
 		return nil, web.ErrGetPostFailed
 	}
 	// 检测访问权限
 	// TODO: 提到最前面去检测
-	switch {
-	case req.User != nil && (req.User.ID == postFormated.GetHostID() || req.User.IsAdmin):
-		// read by self of super admin
-		break
-	case post.Visibility == core.PostVisitPublic:
-		break
-	case post.Visibility == core.PostVisitFriend && postFormated.User.IsFriend:
-		break
-	case post.Visibility == core.PostVisitFollowing && postFormated.User.IsFollowing:
-		break
-	default:
+	if !s.tweetVisible(req.User, post.Visibility, postFormated) {
 		return nil, web.ErrNoPermission
 	}
+	if req.Captions {
+		// 字幕获取失败不影响动态详情
+		if postFormated.Captions, err = s.Ds.GetTranscript(post.ID); err != nil {
+			logrus.Errorf("Ds.GetTranscript err: %s", err)
+		}
+	}
 	return (*web.TweetDetailResp)(postFormated), nil
 }
 
+// TweetThread 获取动态所在的串推，按发布顺序排列，浏览者不可见的动态不返回
+func (s *looseSrv) TweetThread(req *web.TweetThreadReq) (*web.TweetThreadResp, mir.Error) {
+	post, err := s.Ds.GetPostByID(req.TweetId)
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	rootId := post.ID
+	if post.ThreadRootID > 0 {
+		rootId = post.ThreadRootID
+	}
+	posts, err := s.Ds.ListThreadTweets(rootId)
+	if err != nil {
+		logrus.Errorf("Ds.ListThreadTweets err: %s", err)
+		return nil, web.ErrGetThreadFailed
+	}
+	postsFormated, err := s.Ds.MergePosts(posts)
+	if err != nil {
+		logrus.Errorf("Ds.MergePosts err: %s", err)
+		return nil, web.ErrGetThreadFailed
+	}
+	list := make([]*ms.PostFormated, 0, len(postsFormated))
+	for _, tweet := range postsFormated {
+		visibility := tweet.Visibility
+		if err = s.PrepareTweet(req.User, tweet); err != nil {
+			logrus.Errorf("PrepareTweet err: %s", err)
+			return nil, web.ErrGetThreadFailed
+		}
+		if s.tweetVisible(req.User, visibility, tweet) {
+			list = append(list, tweet)
+		}
+	}
+	return &web.TweetThreadResp{
+		List: list,
+	}, nil
+}
+
+// tweetVisible 浏览者能否查看动态，visibility 为转换前的可见性，tweet 需经 PrepareTweet 处理
+func (s *looseSrv) tweetVisible(user *ms.User, visibility ms.PostVisibleT, tweet *ms.PostFormated) bool {
+	switch {
+	case user != nil && user.ID == tweet.GetHostID():
+		return true
+	case tweet.Status != ms.PostStatusPublished:
+		// 未发布的动态只有作者可见
+		return false
+	case user != nil && s.Ds.HasPermission(user, cs.PermTweetViewAll):
+		// read by super admin
+		return true
+	case visibility == core.PostVisitPublic:
+		return true
+	case visibility == core.PostVisitFriend && tweet.User.IsFriend:
+		return true
+	case visibility == core.PostVisitFollowing && tweet.User.IsFollowing:
+		return true
+	default:
+		return false
+	}
+}
+
+// NearbyTweets 获取附近的公开动态，按距离由近到远排列
+func (s *looseSrv) NearbyTweets(req *web.NearbyTweetsReq) (*web.NearbyTweetsResp, mir.Error) {
+	radius, xerr := nearbyRadiusFrom(req.Lat, req.Lng, req.Radius)
+	if xerr != nil {
+		return nil, xerr
+	}
+	posts, err := s.Ds.ListNearbyPosts(geo.BoundingBox(req.Lat, req.Lng, radius), req.Lat, req.Lng, conf.NearbySetting.MaxCandidates)
+	if err != nil {
+		logrus.Errorf("looseSrv.NearbyTweets occurs error[1]: %s", err)
+		return nil, web.ErrGetNearbyPostsFailed
+	}
+	userId := int64(-1)
+	if req.User != nil {
+		userId = req.User.ID
+	}
+	items, total := nearbyPage(len(posts), func(i int) (int64, float64, float64) {
+		lat, lng := nearbyCoords(userId, posts[i].GetHostID(), posts[i].LocationLat, posts[i].LocationLng)
+		return posts[i].ID, lat, lng
+	}, req.Lat, req.Lng, radius, req.Page, req.PageSize)
+	pagePosts := make([]*ms.Post, 0, len(items))
+	for _, item := range items {
+		pagePosts = append(pagePosts, posts[item.idx])
+	}
+	postsFormated, err := s.Ds.MergePosts(pagePosts)
+	if err != nil {
+		logrus.Errorf("looseSrv.NearbyTweets occurs error[2]: %s", err)
+		return nil, web.ErrGetNearbyPostsFailed
+	}
+	if err = s.PrepareTweets(userId, postsFormated); err != nil {
+		logrus.Errorf("looseSrv.NearbyTweets occurs error[3]: %s", err)
+		return nil, web.ErrGetNearbyPostsFailed
+	}
+	// MergePosts 保持了传入的顺序
+	tweets := make([]*web.NearbyTweet, 0, len(postsFormated))
+	for i, post := range postsFormated {
+		tweets = append(tweets, &web.NearbyTweet{
+			PostFormated: post,
+			Distance:     items[i].distance,
+		})
+	}
+	resp := base.PageRespFrom(tweets, req.Page, req.PageSize, total)
+	return (*web.NearbyTweetsResp)(resp), nil
+}
+
+func (s *looseSrv) Search(req *web.SearchReq) (*web.SearchResp, mir.Error) {
+	limit, offset := req.PageSize, (req.Page-1)*req.PageSize
+	if req.Type == "" {
+		// 全部分区只返回各自的首页
+		offset = 0
+	}
+	resp := &web.SearchResp{}
+	var err error
+	if req.Type == "" || req.Type == "posts" {
+		if resp.Posts, err = s.searchPosts(req.User, req.Query, offset, limit); err != nil {
+			logrus.Errorf("looseSrv.Search occurs error[1]: %s", err)
+			return nil, web.ErrSearchFailed
+		}
+	}
+	if req.Type == "" || req.Type == "users" {
+		users, total, err := s.Es.SearchUsers(req.Query, offset, limit)
+		if err != nil {
+			logrus.Errorf("looseSrv.Search occurs error[2]: %s", err)
+			return nil, web.ErrSearchFailed
+		}
+		resp.Users = &web.SearchSection{List: users, Total: total}
+	}
+	if req.Type == "" || req.Type == "rooms" {
+		if resp.Rooms, err = s.searchRooms(req.Query, offset, limit); err != nil {
+			logrus.Errorf("looseSrv.Search occurs error[3]: %s", err)
+			return nil, web.ErrSearchFailed
+		}
+	}
+	if req.Type == "" || req.Type == "topics" {
+		topics, total, err := s.Es.SearchTopics(req.Query, offset, limit)
+		if err != nil {
+			logrus.Errorf("looseSrv.Search occurs error[4]: %s", err)
+			return nil, web.ErrSearchFailed
+		}
+		resp.Topics = &web.SearchSection{List: topics, Total: total}
+	}
+	return resp, nil
+}
+
+func (s *looseSrv) searchPosts(user *ms.User, query string, offset, limit int) (*web.SearchSection, error) {
+	q := &core.QueryReq{
+		Query: query,
+		Type:  core.SearchTypeDefault,
+	}
+	res, err := s.Ts.Search(user, q, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.Ds.RevampPosts(res.Items)
+	if err != nil {
+		return nil, err
+	}
+	userId := int64(-1)
+	if user != nil {
+		userId = user.ID
+	}
+	if err = s.PrepareTweets(userId, posts); err != nil {
+		return nil, err
+	}
+	return &web.SearchSection{List: posts, Total: res.Total}, nil
+}
+
+// searchRooms 搜索房间并标记房主是否在线，在线状态变化频繁不写入索引
+func (s *looseSrv) searchRooms(query string, offset, limit int) (*web.SearchSection, error) {
+	rooms, total, err := s.Es.SearchRooms(query, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	hostIds := make([]int64, 0, len(rooms))
+	for _, room := range rooms {
+		hostIds = append(hostIds, room.HostID)
+	}
+	onlines, err := _wc.BatchCheckOnlineUsers(hostIds)
+	if err != nil {
+		logrus.Warnf("looseSrv.searchRooms check online users occurs error: %s", err)
+	}
+	items := make([]*web.SearchRoom, 0, len(rooms))
+	for _, room := range rooms {
+		items = append(items, &web.SearchRoom{
+			RoomSearchDoc: room,
+			IsOnline:      onlines[room.HostID],
+		})
+	}
+	return &web.SearchSection{List: items, Total: total}, nil
+}
+
 // GetPostLocation calculates the page and position of a post in a user's timeline
 // This is a separate method to avoid breaking existing logic
 func (s *looseSrv) GetPostLocation(req *web.PostLocationReq) (*web.PostLocationResp, mir.Error) {
@@ -588,6 +993,8 @@ func newLooseSrv(s *base.DaoServant, ac core.AppCache) api.Loose {
 		prefixIdxTweetsNewest:    conf.PrefixIdxTweetsNewest,
 		prefixIdxTweetsHots:      conf.PrefixIdxTweetsHots,
 		prefixIdxTweetsFollowing: conf.PrefixIdxTweetsFollowing,
+		prefixIdxTweetsForYou:    conf.PrefixIdxTweetsForYou,
 		prefixTweetComment:       conf.PrefixTweetComment,
+		ranker:                   rank.New(conf.ForYouSetting.Ranker, conf.ForYouSetting.HalfLife),
 	}
 }
@@ -0,0 +1,106 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/model/web"
+	"github.com/rocboss/paopao-ce/internal/servants/base"
+)
+
+// visibilityDs 只实现可见性检查用到的方法，followers 为作者已通过的关注者
+type visibilityDs struct {
+	core.DataService
+	post      *ms.Post
+	followers map[int64]bool
+}
+
+func (d *visibilityDs) GetPostByID(id int64) (*ms.Post, error) {
+	return d.post, nil
+}
+
+func (d *visibilityDs) MergePosts(posts []*ms.Post) ([]*ms.PostFormated, error) {
+	res := make([]*ms.PostFormated, 0, len(posts))
+	for _, post := range posts {
+		res = append(res, post.Format())
+	}
+	return res, nil
+}
+
+func (d *visibilityDs) IsMyFriend(userId int64, friendIds ...int64) (map[int64]bool, error) {
+	return map[int64]bool{}, nil
+}
+
+func (d *visibilityDs) IsMyFollow(userId int64, followIds ...int64) (map[int64]bool, error) {
+	res := make(map[int64]bool, len(followIds))
+	for _, id := range followIds {
+		res[id] = id == d.post.GetHostID() && d.followers[userId]
+	}
+	return res, nil
+}
+
+func (d *visibilityDs) HasPermission(user *ms.User, perm cs.Permission) bool {
+	return false
+}
+
+func (d *visibilityDs) ListEditRevisions(target cs.EditTargetT, targetId int64) ([]*ms.EditRevision, error) {
+	return []*ms.EditRevision{{TargetType: target, TargetID: targetId}}, nil
+}
+
+var _ = Describe("Loose", func() {
+	const authorId, followerId, strangerId int64 = 1, 2, 3
+
+	newLooseSrv := func(visibility ms.PostVisibleT) *looseSrv {
+		ds := &visibilityDs{
+			post: &ms.Post{
+				Model:      &ms.Model{ID: 100},
+				UserID:     []int64{authorId},
+				Visibility: visibility,
+				Status:     ms.PostStatusPublished,
+			},
+			followers: map[int64]bool{followerId: true},
+		}
+		return &looseSrv{
+			DaoServant: &base.DaoServant{Ds: ds},
+		}
+	}
+
+	revisionsOf := func(s *looseSrv, userId int64) (*web.TweetRevisionsResp, error) {
+		req := &web.TweetRevisionsReq{ID: 100}
+		req.User = &ms.User{Model: &ms.Model{ID: userId}}
+		resp, xerr := s.TweetRevisions(req)
+		if xerr != nil {
+			return nil, xerr
+		}
+		return resp, nil
+	}
+
+	It("revisions of followers-only tweet are hidden from non-followers", func() {
+		s := newLooseSrv(core.PostVisitFollowing)
+		_, err := revisionsOf(s, strangerId)
+		Expect(err).To(Equal(web.ErrNoPermission))
+	})
+
+	It("revisions of followers-only tweet are visible to approved followers and the author", func() {
+		s := newLooseSrv(core.PostVisitFollowing)
+		for _, userId := range []int64{followerId, authorId} {
+			resp, err := revisionsOf(s, userId)
+			Expect(err).To(BeNil())
+			Expect(resp.List).To(HaveLen(1))
+		}
+	})
+
+	It("revisions of public tweet are visible to everyone", func() {
+		s := newLooseSrv(core.PostVisitPublic)
+		resp, err := revisionsOf(s, strangerId)
+		Expect(err).To(BeNil())
+		Expect(resp.List).To(HaveLen(1))
+	})
+})
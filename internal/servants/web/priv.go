@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"image"
 	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/rocboss/paopao-ce/internal/model/web"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
 	"github.com/rocboss/paopao-ce/internal/servants/chain"
+	"github.com/rocboss/paopao-ce/pkg/audio"
 	"github.com/rocboss/paopao-ce/pkg/utils"
 	"github.com/rocboss/paopao-ce/pkg/xerror"
 	"github.com/sirupsen/logrus"
@@ -37,6 +39,7 @@ var (
 		"public/image":  ms.AttachmentTypeImage,
 		"public/avatar": ms.AttachmentTypeImage,
 		"public/video":  ms.AttachmentTypeVideo,
+		"public/audio":  ms.AttachmentTypeAudio,
 		"attachment":    ms.AttachmentTypeOther,
 	}
 )
@@ -68,6 +71,8 @@ func (s *privSrv) ThumbsDownTweetReply(req *web.TweetReplyThumbsReq) mir.Error {
 		logrus.Errorf("thumbs down tweet reply error: %s req:%v", err, req)
 		return web.ErrThumbsDownTweetReply
 	}
+	// 缓存处理
+	onCommentActionEvent(req.TweetId, req.CommentId, _commentActionReplyThumbsDown)
 	return nil
 }
 
@@ -76,6 +81,8 @@ func (s *privSrv) ThumbsUpTweetReply(req *web.TweetReplyThumbsReq) mir.Error {
 		logrus.Errorf("thumbs up tweet reply error: %s req:%v", err, req)
 		return web.ErrThumbsUpTweetReply
 	}
+	// 缓存处理
+	onCommentActionEvent(req.TweetId, req.CommentId, _commentActionReplyThumbsUp)
 	return nil
 }
 
@@ -140,6 +147,16 @@ func (s *privSrv) PinTopic(req *web.PinTopicReq) (*web.PinTopicResp, mir.Error)
 func (s *privSrv) UploadAttachment(req *web.UploadAttachmentReq) (*web.UploadAttachmentResp, mir.Error) {
 	defer req.File.Close()
 
+	// 语音先解析时长与波形，超出时长上限的不上传
+	attachmentType := _uploadAttachmentTypeMap[req.UploadType]
+	var voice *audio.Summary
+	if attachmentType == ms.AttachmentTypeAudio {
+		var xerr mir.Error
+		if voice, xerr = analyzeVoiceNote(req.File); xerr != nil {
+			return nil, xerr
+		}
+	}
+
 	// 生成随机路径
 	randomPath := uuid.Must(uuid.NewV4()).String()
 	ossSavePath := req.UploadType + "/" + generatePath(randomPath[:8]) + "/" + randomPath[9:] + req.FileExt
@@ -158,7 +175,10 @@ func (s *privSrv) UploadAttachment(req *web.UploadAttachmentReq) (*web.UploadAtt
 		UserID:   req.Uid,
 		FileSize: req.FileSize,
 		Content:  objectUrl,
-		Type:     _uploadAttachmentTypeMap[req.UploadType],
+		Type:     attachmentType,
+	}
+	if voice != nil {
+		attachment.Duration, attachment.Waveform = voice.Duration, voice.Waveform
 	}
 	if attachment.Type == ms.AttachmentTypeImage {
 		var src image.Image
@@ -180,6 +200,8 @@ func (s *privSrv) UploadAttachment(req *web.UploadAttachmentReq) (*web.UploadAtt
 		ImgHeight: attachment.ImgHeight,
 		Type:      attachment.Type,
 		Content:   attachment.Content,
+		Duration:  attachment.Duration,
+		Waveform:  attachment.Waveform,
 	}, nil
 }
 
@@ -197,7 +219,7 @@ func (s *privSrv) DownloadAttachmentPrecheck(req *web.DownloadAttachmentPrecheck
 			return nil, web.ErrInvalidDownloadReq
 		}
 		// 发布者或管理员免费下载
-		if tweet.GetHostID() == req.User.ID || req.User.IsAdmin {
+		if tweet.GetHostID() == req.User.ID || s.Ds.HasPermission(req.User, cs.PermTweetViewAll) {
 			return resp, nil
 		}
 		// 检测是否有购买记录
@@ -221,7 +243,7 @@ func (s *privSrv) DownloadAttachment(req *web.DownloadAttachmentReq) (*web.Downl
 		}
 		paidFlag := false
 		// 发布者或管理员免费下载 或者 检测是否有购买记录
-		if post.GetHostID() == req.User.ID || req.User.IsAdmin || s.checkPostAttachmentIsPaid(post.ID, req.User.ID) {
+		if post.GetHostID() == req.User.ID || s.Ds.HasPermission(req.User, cs.PermTweetViewAll) || s.checkPostAttachmentIsPaid(post.ID, req.User.ID) {
 			paidFlag = true
 		}
 		// 未购买，则尝试购买
@@ -260,6 +282,34 @@ func (s *privSrv) CreateTweet(req *web.CreateTweetReq) (_ *web.CreateTweetResp,
 		}
 	}()
 
+	// 内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneTweet, textContentsFrom(req.Contents)...)
+	if xerr != nil {
+		return nil, xerr
+	}
+
+	// 引用转发与串推续写
+	var origin *ms.Post
+	if req.QuoteID > 0 {
+		if origin, xerr = s.repostOrigin(req.User.ID, req.QuoteID, false); xerr != nil {
+			return nil, xerr
+		}
+	}
+	var threadRootID int64
+	if req.ThreadParentID > 0 {
+		if threadRootID, xerr = s.threadRootOf(req.User.ID, req.ThreadParentID); xerr != nil {
+			return nil, xerr
+		}
+	}
+	// 对话动态先于录音创建，录音完成前不发布
+	awaitRecording := req.SessionID != "" && slices.ContainsFunc(req.Contents, func(item *web.PostContentItem) bool {
+		return item.Type == ms.ContentTypeAudio
+	})
+	status, xerr := tweetStatusFrom(req.Draft, req.ScheduledOn, awaitRecording)
+	if xerr != nil {
+		return nil, xerr
+	}
+
 	contents, err := persistMediaContents(s.oss, req.Contents)
 	if err != nil {
 		logrus.Errorf("Failed to persist media contents: %v", err)
@@ -318,6 +368,10 @@ func (s *privSrv) CreateTweet(req *web.CreateTweetReq) (_ *web.CreateTweetResp,
 		Visibility:      ms.PostVisibleT(req.Visibility.ToVisibleValue()),
 		RoomID:          req.RoomID,
 		SessionID:       req.SessionID,
+		ThreadRootID:    threadRootID,
+		ThreadParentID:  req.ThreadParentID,
+		Status:          status,
+		ScheduledOn:     req.ScheduledOn,
 		// Location fields from iOS request
 		LocationName:    locationName,
 		LocationLat:     locationLat,
@@ -334,6 +388,10 @@ func (s *privSrv) CreateTweet(req *web.CreateTweetReq) (_ *web.CreateTweetResp,
 	logrus.Debugf("DEBUG CreateTweet: user_ids=%v (type: %T)", post.UserID, post.UserID)
 	logrus.Debugf("DEBUG CreateTweet: model=%+v", post.Model)
 
+	if origin != nil {
+		post.OriginID, post.RepostType = origin.ID, ms.PostRepostQuote
+	}
+
 	logrus.Infof("Created post object: %+v", post)
 
 	post, err = s.Ds.CreatePost(post)
@@ -380,35 +438,12 @@ func (s *privSrv) CreateTweet(req *web.CreateTweetReq) (_ *web.CreateTweetResp,
 		}
 	}
 
-	// 私密推文不创建标签与用户提醒
-	if post.Visibility != core.PostVisitPrivate {
-		// 创建标签
-		s.Ds.UpsertTags(req.User.ID, tags)
-		logrus.Infof("Upserted tags for user %d: %v", req.User.ID, tags)
-
-		// 创建用户消息提醒 - 现在由webhook处理
-		// for _, u := range req.Users {
-		// 	user, err := s.Ds.GetUserByUsername(u)
-		// 	if err != nil || user.ID == req.User.ID {
-		// 		continue
-		// 	}
-
-		// 	// 创建消息提醒
-		// 	onCreateMessageEvent(&ms.Message{
-		// 		SenderUserID:   req.User.ID,
-		// 		ReceiverUserID: user.ID,
-		// 		Type:           ms.MsgTypePost,
-		// 		Brief:          "在新发布的泡泡动态中@了你",
-		// 		PostID:         post.ID,
-		// 	})
-		// 	logrus.Infof("Created message notification for user %d", user.ID)
-		// }
+	// 未发布的动态在发布时再创建标签、推送搜索及计入转发数，用户提醒由webhook处理
+	if post.Status == ms.PostStatusPublished {
+		s.OnTweetPublished(post)
+		onTweetMentions(s.Ds, post)
 	}
 
-	// 推送Search
-	s.PushPostToSearch(post)
-	logrus.Infof("Pushed post to search index")
-
 	formatedPosts, err := s.Ds.RevampPosts([]*ms.PostFormated{post.Format()})
 	if err != nil {
 		logrus.Errorf("Ds.RevampPosts failed: %v", err)
@@ -416,14 +451,183 @@ func (s *privSrv) CreateTweet(req *web.CreateTweetReq) (_ *web.CreateTweetResp,
 	}
 	logrus.Infof("Successfully formatted posts")
 
-	// 缓存处理
-	onTrendsActionEvent(_trendsActionCreateTweet, req.User.ID)
+	// 标记待审的推文进入审核队列
+	onContentFlagged(verdict, web.AuditStyleUserTweet, post.ID, req.User.ID)
+
+	// 缓存处理，未发布的动态在发布时再计入用户动态数
+	if post.Status == ms.PostStatusPublished {
+		onTrendsActionEvent(_trendsActionCreateTweet, req.User.ID)
+	}
 	onTweetActionEvent(_tweetActionCreate, req.User.ID, req.User.Username)
 	logrus.Infof("Successfully completed CreateTweet for user %d", req.User.ID)
 
 	return (*web.CreateTweetResp)(formatedPosts[0]), nil
 }
 
+func (s *privSrv) UpdateDraft(req *web.UpdateDraftReq) (_ *web.UpdateDraftResp, xerr mir.Error) {
+	var mediaContents, previousContents []string
+	defer func() {
+		// 只清理本次新增的媒体内容，草稿原有的媒体仍在使用
+		if xerr != nil {
+			deleteOssObjects(s.oss, unusedMediaContents(mediaContents, previousContents))
+		}
+	}()
+
+	post, err := s.Ds.GetPostByID(req.ID)
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	if post.GetHostID() != req.User.ID {
+		return nil, web.ErrNoPermission
+	}
+	// 等待录音的动态由webhook发布，已发布的动态不再作为草稿编辑
+	if post.Status != ms.PostStatusDraft && post.Status != ms.PostStatusScheduled {
+		return nil, web.ErrNotDraftTweet
+	}
+	scheduledOn := req.ScheduledOn
+	if req.Publish {
+		scheduledOn = 0
+	}
+	status, xerr := tweetStatusFrom(!req.Publish && scheduledOn == 0, scheduledOn, false)
+	if xerr != nil {
+		return nil, xerr
+	}
+
+	previous, err := s.Ds.GetPostContentsByIDs([]int64{post.ID})
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	for _, content := range previous {
+		previousContents = append(previousContents, content.Content)
+	}
+
+	// 内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneTweet, textContentsFrom(req.Contents)...)
+	if xerr != nil {
+		return nil, xerr
+	}
+	if mediaContents, err = persistMediaContents(s.oss, req.Contents); err != nil {
+		logrus.Errorf("persistMediaContents failed: %s", err)
+		return nil, web.ErrUpdateDraftFailed
+	}
+	contents := make([]*ms.PostContent, 0, len(req.Contents))
+	for _, item := range req.Contents {
+		if err := item.Check(s.Ds); err != nil {
+			logrus.Warnf("Content check failed: %v", err)
+			continue
+		}
+		if item.Type == ms.ContentTypeAttachment && req.AttachmentPrice > 0 {
+			item.Type = ms.ContentTypeChargeAttachment
+		}
+		contents = append(contents, &ms.PostContent{
+			UserID:  post.UserID,
+			RoomID:  post.RoomID,
+			Content: item.Content,
+			Type:    item.Type,
+			Sort:    item.Sort,
+		})
+	}
+	// 到期的定时动态可能已由定时任务发布，认领成功后才修改草稿
+	claimed, err := s.Ds.ClaimPostStatus(post.ID, post.Status, status, post.ScheduledOn)
+	if err != nil {
+		logrus.Errorf("Ds.ClaimPostStatus post[%d] failed: %s", post.ID, err)
+		return nil, web.ErrUpdateDraftFailed
+	} else if !claimed {
+		return nil, web.ErrNotDraftTweet
+	}
+	replaced, err := s.Ds.ReplacePostContents(post.ID, contents)
+	if err != nil {
+		logrus.Errorf("Ds.ReplacePostContents failed: %s", err)
+		return nil, web.ErrUpdateDraftFailed
+	}
+	// 删除不再使用的媒体内容
+	deleteOssObjects(s.oss, unusedMediaContents(replaced, mediaContents))
+
+	post.Tags = strings.Join(tagsFrom(req.Tags), ",")
+	post.AttachmentPrice = req.AttachmentPrice
+	post.Visibility = ms.PostVisibleT(req.Visibility.ToVisibleValue())
+	post.Status, post.ScheduledOn = status, scheduledOn
+	if status == ms.PostStatusPublished {
+		err = s.PublishTweet(post)
+	} else {
+		err = s.Ds.UpdatePost(post)
+	}
+	if err != nil {
+		logrus.Errorf("update draft post[%d] failed: %s", post.ID, err)
+		return nil, web.ErrUpdateDraftFailed
+	}
+	if status == ms.PostStatusPublished {
+		onTrendsActionEvent(_trendsActionCreateTweet, req.User.ID)
+		onTweetMentions(s.Ds, post)
+	}
+	onContentFlagged(verdict, web.AuditStyleUserTweet, post.ID, req.User.ID)
+
+	formatedPosts, err := s.Ds.MergePosts([]*ms.Post{post})
+	if err != nil {
+		logrus.Errorf("Ds.MergePosts failed: %s", err)
+		return nil, web.ErrUpdateDraftFailed
+	}
+	return (*web.UpdateDraftResp)(formatedPosts[0]), nil
+}
+
+// EditTweet 作者在可编辑时间内修改已发布动态的文本与标签，修改前的内容保存为编辑历史
+func (s *privSrv) EditTweet(req *web.EditTweetReq) (*web.EditTweetResp, mir.Error) {
+	post, err := s.Ds.GetPostByID(req.ID)
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	if post.GetHostID() != req.User.ID {
+		return nil, web.ErrNoPermission
+	}
+	// 未发布的动态通过草稿编辑
+	if post.Status != ms.PostStatusPublished {
+		return nil, web.ErrEditTweetFailed
+	}
+	// 被管理员隐藏的动态不能再编辑
+	if post.HiddenOn > 0 {
+		return nil, web.ErrPostHiddenByModerator
+	}
+	if xerr := checkEditWindow(post.CreatedOn); xerr != nil {
+		return nil, xerr
+	}
+	items, xerr := editableContentsFrom(req.Contents)
+	if xerr != nil {
+		return nil, xerr
+	}
+	// 内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneTweet, textContentsFrom(items)...)
+	if xerr != nil {
+		return nil, xerr
+	}
+	contents := make([]*ms.PostContent, 0, len(items))
+	for _, item := range items {
+		contents = append(contents, &ms.PostContent{
+			RoomID:  post.RoomID,
+			Content: item.Content,
+			Type:    item.Type,
+			Sort:    item.Sort,
+		})
+	}
+	tagInfos, err := s.Ds.EditPost(post, contents, tagsFrom(req.Tags))
+	if err != nil {
+		logrus.Errorf("Ds.EditPost post[%d] failed: %s", post.ID, err)
+		return nil, web.ErrEditTweetFailed
+	}
+	// 新增与移除的标签引用数都有变化，一并更新搜索
+	s.PushTopicsToSearch(tagInfos.IDs()...)
+	s.PushPostToSearch(post)
+	onContentFlagged(verdict, web.AuditStyleUserTweet, post.ID, req.User.ID)
+	// 重新解析编辑后的@提及，编辑前已提醒过的用户不再重复提醒
+	onTweetMentions(s.Ds, post, resetMentions(s.Ds, cs.MentionSourcePost, post.ID)...)
+
+	formatedPosts, err := s.Ds.MergePosts([]*ms.Post{post})
+	if err != nil {
+		logrus.Errorf("Ds.MergePosts failed: %s", err)
+		return nil, web.ErrEditTweetFailed
+	}
+	return (*web.EditTweetResp)(formatedPosts[0]), nil
+}
+
 func (s *privSrv) DeleteTweet(req *web.DeleteTweetReq) mir.Error {
 	if req.User == nil {
 		return web.ErrNoPermission
@@ -433,7 +637,7 @@ func (s *privSrv) DeleteTweet(req *web.DeleteTweetReq) mir.Error {
 		logrus.Errorf("Ds.GetPostByID err: %s", err)
 		return web.ErrGetPostFailed
 	}
-	if post.GetHostID() != req.User.ID && !req.User.IsAdmin {
+	if !s.Ds.IsAllow(req.User, &ms.Action{Act: ms.ActDeleteTweet, UserId: post.GetHostID()}) {
 		return web.ErrNoPermission
 	}
 	mediaContents, err := s.Ds.DeletePost(post)
@@ -441,17 +645,26 @@ func (s *privSrv) DeleteTweet(req *web.DeleteTweetReq) mir.Error {
 		logrus.Errorf("Ds.DeletePost delete post failed: %s", err)
 		return web.ErrDeletePostFailed
 	}
+	// 删除转发时回退原动态的转发数
+	if post.OriginID > 0 && post.Status == ms.PostStatusPublished {
+		if origin, err := s.Ds.GetPostByID(post.OriginID); err == nil {
+			s.UpdateShareCount(origin, -1)
+		}
+	}
 	// 删除推文的媒体内容
 	deleteOssObjects(s.oss, mediaContents)
-	// 删除索引
+	// 删除索引，标签引用数已减少，一并更新话题
 	s.DeleteSearchPost(post)
+	s.PushPostTagsToSearch(post)
 	if err != nil {
 		logrus.Errorf("s.DeleteSearchPost failed: %s", err)
 		return web.ErrDeletePostFailed
 	}
 	// 缓存处理
 	// TODO: 缓存逻辑合并处理
-	onTrendsActionEvent(_trendsActionDeleteTweet, req.User.ID)
+	if post.Status == ms.PostStatusPublished {
+		onTrendsActionEvent(_trendsActionDeleteTweet, req.User.ID)
+	}
 	onTweetActionEvent(_tweetActionDelete, req.User.ID, req.User.Username)
 	return nil
 }
@@ -462,8 +675,8 @@ func (s *privSrv) DeleteCommentReply(req *web.DeleteCommentReplyReq) mir.Error {
 		logrus.Errorf("Ds.GetCommentReplyByID err: %s", err)
 		return web.ErrGetReplyFailed
 	}
-	if req.User.ID != reply.UserID && !req.User.IsAdmin {
-		return web.ErrNoPermission
+	if err := checkPermision(s.Ds, req.User, reply.UserID, cs.PermContentManage); err != nil {
+		return err
 	}
 	// 执行删除
 	err = s.deletePostCommentReply(reply)
@@ -471,6 +684,9 @@ func (s *privSrv) DeleteCommentReply(req *web.DeleteCommentReplyReq) mir.Error {
 		logrus.Errorf("s.deletePostCommentReply err: %s", err)
 		return web.ErrDeleteCommentFailed
 	}
+	if reply.Type == ms.ContentTypeAudio {
+		deleteOssObjects(s.oss, []string{reply.Content})
+	}
 	// 缓存处理， 宽松处理错误
 	if comment, err := s.Ds.GetCommentByID(reply.CommentID); err == nil {
 		onCommentActionEvent(comment.PostID, comment.ID, _commentActionReplyDelete)
@@ -483,22 +699,49 @@ func (s *privSrv) CreateCommentReply(req *web.CreateCommentReplyReq) (_ *web.Cre
 		post     *ms.Post
 		comment  *ms.Comment
 		atUserID int64
+		voice    *ms.Attachment
+		verdict  *cs.ModerationResult
 		err      error
 	)
 
+	// 语音回复的内容为本人上传的语音附件地址，文字回复需要审核
+	switch req.Type {
+	case 0, ms.ContentTypeText:
+		req.Type = ms.ContentTypeText
+		if verdict, xerr = moderateContent(s.Ds, cs.ModerationSceneReply, req.Content); xerr != nil {
+			return nil, xerr
+		}
+	case ms.ContentTypeAudio:
+		if voice, xerr = voiceNoteFrom(s.Ds, req.Uid, req.Content); xerr != nil {
+			return nil, xerr
+		}
+	default:
+		return nil, xerror.InvalidParams
+	}
+
 	if post, comment, atUserID, err = s.createPostPreHandler(req.CommentID, req.Uid, req.AtUserID); err != nil {
 		return nil, web.ErrCreateReplyFailed
 	}
+	if post.IsLock == 1 {
+		return nil, web.ErrPostCommentLocked
+	}
 
 	// 创建评论
 	reply := &ms.CommentReply{
 		CommentID: req.CommentID,
 		UserID:    req.Uid,
 		Content:   req.Content,
+		Type:      req.Type,
 		AtUserID:  atUserID,
 		IP:        req.ClientIP,
 		IPLoc:     utils.GetIPLoc(req.ClientIP),
 	}
+	if voice != nil {
+		reply.Duration, reply.Waveform = voice.Duration, voice.Waveform
+		if err = s.oss.PersistObject(s.oss.ObjectKey(req.Content)); err != nil {
+			logrus.Errorf("oss.PersistObject voice reply err: %s", err)
+		}
+	}
 
 	reply, err = s.Ds.CreateCommentReply(reply)
 	if err != nil {
@@ -540,19 +783,39 @@ func (s *privSrv) CreateCommentReply(req *web.CreateCommentReplyReq) (_ *web.Cre
 	}
 	if atUserID > 0 {
 		user, err := s.Ds.GetUserByID(atUserID)
-		if err == nil && user.ID != req.Uid && commentMaster.ID != user.ID && postMaster.ID != user.ID {
+		if err == nil && user.ID != req.Uid && commentMaster.ID != user.ID && postMaster.ID != user.ID &&
+			!s.Ds.IsBlockedEither(req.Uid, user.ID) && checkPostViewPermission(user, post, s.Ds) == nil {
 			// 创建消息提醒
 			onCreateMessageEvent(&ms.Message{
 				SenderUserID:   req.Uid,
 				ReceiverUserID: user.ID,
 				Type:           ms.MsgTypeReply,
 				Brief:          "在泡泡评论的回复中@了你",
+				Category:       cs.MsgCategoryMention,
 				PostID:         post.ID,
 				CommentID:      comment.ID,
 				ReplyID:        reply.ID,
 			})
 		}
 	}
+	// 解析文字回复中的@提及，已收到回复提醒的用户不再重复提醒
+	if reply.Type == ms.ContentTypeText {
+		onMentionEvent(post, &ms.Message{
+			SenderUserID: req.Uid,
+			Type:         ms.MsgTypeReply,
+			Brief:        "在泡泡评论的回复中@了你",
+			PostID:       post.ID,
+			CommentID:    comment.ID,
+			ReplyID:      reply.ID,
+		}, []*mentionText{{
+			source:   cs.MentionSourceReply,
+			sourceId: reply.ID,
+			text:     reply.Content,
+		}}, nil, comment.UserID, post.GetHostID(), atUserID)
+	}
+	// 标记待审的回复进入审核队列
+	onContentFlagged(verdict, web.AuditStyleUserTweetReply, reply.ID, req.Uid)
+
 	// 缓存处理
 	onCommentActionEvent(comment.PostID, comment.ID, _commentActionReplyCreate)
 	return (*web.CreateCommentReplyResp)(reply), nil
@@ -564,8 +827,8 @@ func (s *privSrv) DeleteComment(req *web.DeleteCommentReq) mir.Error {
 		logrus.Errorf("Ds.GetCommentByID err: %v\n", err)
 		return web.ErrGetCommentFailed
 	}
-	if req.User.ID != comment.UserID && !req.User.IsAdmin {
-		return web.ErrNoPermission
+	if err := checkPermision(s.Ds, req.User, comment.UserID, cs.PermContentManage); err != nil {
+		return err
 	}
 	// 加载post
 	post, err := s.Ds.GetPostByID(comment.PostID)
@@ -584,6 +847,8 @@ func (s *privSrv) DeleteComment(req *web.DeleteCommentReq) mir.Error {
 		return web.ErrDeleteCommentFailed
 	}
 	onCommentActionEvent(comment.PostID, comment.ID, _commentActionDelete)
+	// 动态的搜索文档包含评论复制的内容
+	s.PushPostToSearch(post)
 	return nil
 }
 
@@ -603,6 +868,96 @@ func (s *privSrv) HighlightComment(req *web.HighlightCommentReq) (*web.Highlight
 	}, nil
 }
 
+// PinComment 置顶或取消置顶评论，仅动态的参与者可操作
+func (s *privSrv) PinComment(req *web.PinCommentReq) (*web.PinCommentResp, mir.Error) {
+	comment, err := s.Ds.GetCommentByID(req.CommentId)
+	if err != nil {
+		return nil, web.ErrGetCommentFailed
+	}
+	pinned, err := s.Ds.PinComment(req.Uid, comment.ID)
+	if err == cs.ErrNoPermission {
+		return nil, web.ErrNoPermission
+	} else if err != nil {
+		logrus.Errorf("Ds.PinComment err: %s", err)
+		return nil, web.ErrPinCommentFailed
+	}
+	onCommentActionEvent(comment.PostID, comment.ID, _commentActionPin)
+	return &web.PinCommentResp{
+		IsPinned: pinned,
+	}, nil
+}
+
+// EditComment 作者在可编辑时间内修改评论的文本，修改前的内容保存为编辑历史
+func (s *privSrv) EditComment(req *web.EditCommentReq) (*web.EditCommentResp, mir.Error) {
+	comment, err := s.Ds.GetCommentByID(req.CommentId)
+	if err != nil {
+		return nil, web.ErrGetCommentFailed
+	}
+	if comment.UserID != req.Uid {
+		return nil, web.ErrNoPermission
+	}
+	// 动态锁定或被管理员隐藏后评论不能再编辑
+	post, err := s.Ds.GetPostByID(comment.PostID)
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	if post.IsLock == 1 {
+		return nil, web.ErrPostCommentLocked
+	} else if post.HiddenOn > 0 {
+		return nil, web.ErrPostHiddenByModerator
+	}
+	if xerr := checkEditWindow(comment.CreatedOn); xerr != nil {
+		return nil, xerr
+	}
+	items, xerr := editableContentsFrom(req.Contents)
+	if xerr != nil {
+		return nil, xerr
+	}
+	// 内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneComment, textContentsFrom(items)...)
+	if xerr != nil {
+		return nil, xerr
+	}
+	contents := make([]*ms.CommentContent, 0, len(items))
+	for _, item := range items {
+		contents = append(contents, &ms.CommentContent{
+			Content: item.Content,
+			Type:    item.Type,
+			Sort:    item.Sort,
+		})
+	}
+	if err = s.Ds.EditComment(comment, contents); err != nil {
+		logrus.Errorf("Ds.EditComment comment[%d] failed: %s", comment.ID, err)
+		return nil, web.ErrEditCommentFailed
+	}
+	onContentFlagged(verdict, web.AuditStyleUserTweetComment, comment.ID, req.Uid)
+	onCommentActionEvent(comment.PostID, comment.ID, _commentActionEdit)
+	// 动态的搜索文档包含评论复制的内容
+	s.PushPostToSearch(post)
+	// 重新解析编辑后的@提及，动态作者与编辑前已提醒过的用户不再重复提醒
+	skipIds := resetMentions(s.Ds, cs.MentionSourceComment, comment.ID)
+	texts := make([]*mentionText, 0, len(contents))
+	for _, content := range contents {
+		if content.Type == ms.ContentTypeText {
+			texts = append(texts, &mentionText{
+				source:   cs.MentionSourceComment,
+				sourceId: content.ID,
+				text:     content.Content,
+			})
+		}
+	}
+	onMentionEvent(post, &ms.Message{
+		SenderUserID: req.Uid,
+		Type:         ms.MsgtypeComment,
+		Brief:        "在泡泡评论中@了你",
+		PostID:       post.ID,
+		CommentID:    comment.ID,
+	}, texts, nil, append(skipIds, post.GetHostID())...)
+	return &web.EditCommentResp{
+		EditedOn: comment.EditedOn,
+	}, nil
+}
+
 func (s *privSrv) CreateComment(req *web.CreateCommentReq) (_ *web.CreateCommentResp, xerr mir.Error) {
 	var (
 		mediaContents []string
@@ -614,6 +969,22 @@ func (s *privSrv) CreateComment(req *web.CreateCommentReq) (_ *web.CreateComment
 		}
 	}()
 
+	// 内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneComment, textContentsFrom(req.Contents)...)
+	if xerr != nil {
+		return nil, xerr
+	}
+
+	// 语音须为本人上传的语音附件，时长与波形取自上传时的解析结果
+	voices := make(map[*web.PostContentItem]*ms.Attachment)
+	for _, item := range req.Contents {
+		if item.Type == ms.ContentTypeAudio {
+			if voices[item], xerr = voiceNoteFrom(s.Ds, req.Uid, item.Content); xerr != nil {
+				return nil, xerr
+			}
+		}
+	}
+
 	if mediaContents, err = persistMediaContents(s.oss, req.Contents); err != nil {
 		return nil, xerror.ServerError
 	}
@@ -624,6 +995,9 @@ func (s *privSrv) CreateComment(req *web.CreateCommentReq) (_ *web.CreateComment
 		logrus.Errorf("Ds.GetPostByID err:%s", err)
 		return nil, xerror.ServerError
 	}
+	if post.IsLock == 1 {
+		return nil, web.ErrPostCommentLocked
+	}
 	if post.CommentCount >= conf.AppSetting.MaxCommentCount {
 		return nil, web.ErrMaxCommentCount
 	}
@@ -638,6 +1012,7 @@ func (s *privSrv) CreateComment(req *web.CreateCommentReq) (_ *web.CreateComment
 		logrus.Errorf("Ds.CreateComment err:%s", err)
 		return nil, web.ErrCreateCommentFailed
 	}
+	var mentionTexts []*mentionText
 
 	for _, item := range req.Contents {
 		// 检查附件是否是本站资源
@@ -646,14 +1021,24 @@ func (s *privSrv) CreateComment(req *web.CreateCommentReq) (_ *web.CreateComment
 				continue
 			}
 		}
-		postContent := &ms.PostContent{
-			PostID:  post.ID,
-			UserID:  []int64{post.GetHostID(), post.GetVisitorID()},
-			Content: item.Content,
-			Type:    item.Type,
-			Sort:    item.Sort,
+		// 评论内容为准，动态内容中的副本由评论内容复制
+		commentContent := &ms.CommentContent{
+			CommentID: comment.ID,
+			UserID:    req.Uid,
+			Content:   item.Content,
+			Type:      item.Type,
+			Sort:      item.Sort,
+		}
+		if voice := voices[item]; voice != nil {
+			commentContent.Duration, commentContent.Waveform = voice.Duration, voice.Waveform
+		}
+		if commentContent, err = s.Ds.CreateCommentContent(post, commentContent); err == nil && item.Type == ms.ContentTypeText {
+			mentionTexts = append(mentionTexts, &mentionText{
+				source:   cs.MentionSourceComment,
+				sourceId: commentContent.ID,
+				text:     commentContent.Content,
+			})
 		}
-		s.Ds.CreatePostContent(postContent)
 	}
 
 	// 更新Post回复数
@@ -676,27 +1061,77 @@ func (s *privSrv) CreateComment(req *web.CreateCommentReq) (_ *web.CreateComment
 			CommentID:      comment.ID,
 		})
 	}
-	for _, u := range req.Users {
-		user, err := s.Ds.GetUserByUsername(u)
-		if err != nil || user.ID == req.Uid || user.ID == postMaster.ID {
-			continue
-		}
+	// 解析评论中的@提及，连同客户端指定的被@用户一起提醒
+	onMentionEvent(post, &ms.Message{
+		SenderUserID: req.Uid,
+		Type:         ms.MsgtypeComment,
+		Brief:        "在泡泡评论中@了你",
+		PostID:       post.ID,
+		CommentID:    comment.ID,
+	}, mentionTexts, req.Users, post.GetHostID())
+	// 标记待审的评论进入审核队列
+	onContentFlagged(verdict, web.AuditStyleUserTweetComment, comment.ID, req.Uid)
 
-		// 创建消息提醒
-		onCreateMessageEvent(&ms.Message{
-			SenderUserID:   req.Uid,
-			ReceiverUserID: user.ID,
-			Type:           ms.MsgtypeComment,
-			Brief:          "在泡泡评论中@了你",
-			PostID:         post.ID,
-			CommentID:      comment.ID,
-		})
-	}
 	// 缓存处理
 	onCommentActionEvent(comment.PostID, comment.ID, _commentActionCreate)
 	return (*web.CreateCommentResp)(comment), nil
 }
 
+func (s *privSrv) RepostTweet(req *web.RepostTweetReq) (*web.RepostTweetResp, mir.Error) {
+	origin, xerr := s.repostOrigin(0, req.ID, true)
+	if xerr != nil {
+		return nil, xerr
+	}
+	// 已转发过则取消转发
+	if repost, err := s.Ds.GetUserRepost(req.User.ID, origin.ID); err == nil {
+		if _, err = s.Ds.DeletePost(repost); err != nil {
+			logrus.Errorf("Ds.DeletePost delete repost failed: %s", err)
+			return nil, web.ErrRepostTweetFailed
+		}
+		s.UpdateShareCount(origin, -1)
+		onTrendsActionEvent(_trendsActionDeleteTweet, req.User.ID)
+		onTweetActionEvent(_tweetActionDelete, req.User.ID, req.User.Username)
+		return &web.RepostTweetResp{
+			Status: false,
+		}, nil
+	}
+	if s.Ds.IsBlockedEither(req.User.ID, origin.GetHostID()) {
+		return nil, web.ErrRepostBlocked
+	}
+	post, err := s.Ds.CreatePost(&ms.Post{
+		UserID:     []int64{req.User.ID},
+		IP:         req.ClientIP,
+		IPLoc:      utils.GetIPLoc(req.ClientIP),
+		Visibility: core.PostVisitPublic,
+		OriginID:   origin.ID,
+		RepostType: ms.PostRepostPlain,
+	})
+	if err != nil {
+		// 同一用户对同一动态只能有一条转发，并发的重复转发以已有的转发为准
+		if repost, xerr := s.Ds.GetUserRepost(req.User.ID, origin.ID); xerr == nil {
+			return s.repostResp(repost)
+		}
+		logrus.Errorf("Ds.CreatePost create repost failed: %s", err)
+		return nil, web.ErrRepostTweetFailed
+	}
+	s.UpdateShareCount(origin, 1)
+	onTrendsActionEvent(_trendsActionCreateTweet, req.User.ID)
+	onTweetActionEvent(_tweetActionCreate, req.User.ID, req.User.Username)
+	return s.repostResp(post)
+}
+
+func (s *privSrv) repostResp(repost *ms.Post) (*web.RepostTweetResp, mir.Error) {
+	tweets, err := s.Ds.MergePosts([]*ms.Post{repost})
+	if err != nil {
+		logrus.Errorf("Ds.MergePosts merge repost failed: %s", err)
+		return nil, web.ErrRepostTweetFailed
+	}
+	return &web.RepostTweetResp{
+		Status: true,
+		Tweet:  tweets[0],
+	}, nil
+}
+
 func (s *privSrv) CollectionTweet(req *web.CollectionTweetReq) (*web.CollectionTweetResp, mir.Error) {
 	status := false
 	collection, err := s.Ds.GetUserPostCollection(req.ID, req.Uid)
@@ -751,17 +1186,28 @@ func (s *privSrv) VisibleTweet(req *web.VisibleTweetReq) (*web.VisibleTweetResp,
 	if err != nil {
 		return nil, web.ErrVisblePostFailed
 	}
-	if err := checkPermision(req.User, post.GetHostID()); err != nil {
+	if err := checkPermision(s.Ds, req.User, post.GetHostID(), cs.PermContentManage); err != nil {
 		return nil, err
 	}
+	// 被管理员隐藏的动态只有管理员能修改可见性，修改后解除隐藏
+	if post.HiddenOn > 0 {
+		if !s.Ds.HasPermission(req.User, cs.PermContentManage) {
+			return nil, web.ErrPostHiddenByModerator
+		}
+		post.HiddenOn = 0
+	}
+	oldVisibility := post.Visibility
 	if err = s.Ds.VisiblePost(post, req.Visibility.ToVisibleValue()); err != nil {
 		logrus.Warnf("s.Ds.VisiblePost: %s", err)
 		return nil, web.ErrVisblePostFailed
 	}
 
-	// 推送Search
+	// 推送Search，转为私密或从私密转出时标签引用数有变化
 	post.Visibility = ms.PostVisibleT(req.Visibility.ToVisibleValue())
 	s.PushPostToSearch(post)
+	if (oldVisibility == ms.PostVisitPrivate) != (post.Visibility == ms.PostVisitPrivate) {
+		s.PushPostTagsToSearch(post)
+	}
 
 	return &web.VisibleTweetResp{
 		Visibility: req.Visibility,
@@ -774,7 +1220,7 @@ func (s *privSrv) StickTweet(req *web.StickTweetReq) (*web.StickTweetResp, mir.E
 		logrus.Errorf("Ds.GetPostByID err: %v\n", err)
 		return nil, web.ErrStickPostFailed
 	}
-	if !req.User.IsAdmin {
+	if !s.Ds.IsAllow(req.User, &ms.Action{Act: ms.ActStickTweet}) {
 		return nil, web.ErrNoPermission
 	}
 	newStatus := 1 - post.IsTop
@@ -806,9 +1252,16 @@ func (s *privSrv) LockTweet(req *web.LockTweetReq) (*web.LockTweetResp, mir.Erro
 	if err != nil {
 		return nil, web.ErrLockPostFailed
 	}
-	if post.GetHostID() != req.User.ID && !req.User.IsAdmin {
+	if !s.Ds.IsAllow(req.User, &ms.Action{Act: ms.ActLockTweet, UserId: post.GetHostID()}) {
 		return nil, web.ErrNoPermission
 	}
+	// 被管理员锁定的动态只有管理员能解除锁定
+	if post.LockedOn > 0 {
+		if !s.Ds.HasPermission(req.User, cs.PermContentManage) {
+			return nil, web.ErrPostLockedByModerator
+		}
+		post.LockedOn = 0
+	}
 	newStatus := 1 - post.IsLock
 	if err := s.Ds.LockPost(post); err != nil {
 		return nil, web.ErrLockPostFailed
@@ -818,6 +1271,47 @@ func (s *privSrv) LockTweet(req *web.LockTweetReq) (*web.LockTweetResp, mir.Erro
 	}, nil
 }
 
+func (s *privSrv) CreateReport(req *web.CreateReportReq) (*web.CreateReportResp, mir.Error) {
+	if !req.TargetType.IsValid() || !req.Reason.IsValid() {
+		return nil, xerror.InvalidParams
+	}
+	targetUserId, post, err := reportTarget(s.Ds, req.TargetType, req.TargetID)
+	if err != nil {
+		logrus.Debugf("reportTarget err: %s", err)
+		return nil, web.ErrInvalidReportTarget
+	}
+	// 对话动态由两位用户共有，参与者同样不能举报
+	if targetUserId == req.User.ID ||
+		(req.TargetType == cs.ReportTargetPost && slices.Contains(post.UserID, req.User.ID)) {
+		return nil, web.ErrNoReportToSelf
+	}
+	// 只能举报自己可查看的动态及其评论
+	if post != nil {
+		if xerr := checkPostViewPermission(req.User, post, s.Ds); xerr != nil {
+			return nil, xerr
+		}
+	}
+	report, err := s.Ds.CreateReport(&ms.Report{
+		ReporterID:   req.User.ID,
+		TargetType:   req.TargetType,
+		TargetID:     req.TargetID,
+		TargetUserID: targetUserId,
+		Reason:       req.Reason,
+		Evidence: &cs.ReportEvidence{
+			Description: req.Description,
+			Attachments: req.Attachments,
+		},
+	})
+	if err != nil {
+		logrus.Errorf("Ds.CreateReport err: %s", err)
+		return nil, web.ErrCreateReportFailed
+	}
+	return &web.CreateReportResp{
+		ID:     report.ID,
+		Status: report.Status,
+	}, nil
+}
+
 func (s *privSrv) deletePostCommentReply(reply *ms.CommentReply) error {
 	err := s.Ds.DeleteCommentReply(reply)
 	if err != nil {
@@ -976,6 +1470,41 @@ func (s *privSrv) deletePostCollection(collection *ms.PostCollection) mir.Error
 	return nil
 }
 
+// repostOrigin 获取可转发的原动态，flatten 为真时纯转发的转发指向其原动态，userId非零时检查与原动态作者的屏蔽关系
+func (s *privSrv) repostOrigin(userId int64, id int64, flatten bool) (*ms.Post, mir.Error) {
+	post, err := s.Ds.GetPostByID(id)
+	if err != nil {
+		return nil, web.ErrGetPostFailed
+	}
+	if flatten && post.RepostType == ms.PostRepostPlain && post.OriginID > 0 {
+		if post, err = s.Ds.GetPostByID(post.OriginID); err != nil {
+			return nil, web.ErrGetPostFailed
+		}
+	}
+	if post.Visibility != core.PostVisitPublic || post.Status != ms.PostStatusPublished {
+		return nil, web.ErrRepostNotPublic
+	}
+	if userId > 0 && s.Ds.IsBlockedEither(userId, post.GetHostID()) {
+		return nil, web.ErrRepostBlocked
+	}
+	return post, nil
+}
+
+// threadRootOf 串推续写只能接在自己的动态之后，返回串推首条动态
+func (s *privSrv) threadRootOf(userID, parentID int64) (int64, mir.Error) {
+	parent, err := s.Ds.GetPostByID(parentID)
+	if err != nil {
+		return 0, web.ErrGetPostFailed
+	}
+	if parent.GetHostID() != userID || parent.RepostType == ms.PostRepostPlain {
+		return 0, web.ErrInvalidThreadParent
+	}
+	if parent.ThreadRootID > 0 {
+		return parent.ThreadRootID, nil
+	}
+	return parent.ID, nil
+}
+
 func (s *privSrv) checkPostAttachmentIsPaid(postID, userID int64) bool {
 	bill, err := s.Ds.GetPostAttatchmentBill(postID, userID)
 	return err == nil && bill.Model != nil && bill.ID > 0
@@ -94,13 +94,19 @@ func (s *pubSrv) GetCaptcha() (*web.GetCaptchaResp, mir.Error) {
 }
 
 func (s *pubSrv) Register(req *web.RegisterReq) (*web.RegisterResp, mir.Error) {
-	if _disallowUserRegister {
+	// 是否允许注册可由管理后台在运行时切换
+	if base.FeatureEnabled(_ac, "Web:DisallowUserRegister") {
 		return nil, web.ErrDisallowUserRegister
 	}
 	// 用户名检查
 	if err := s.validUsername(req.Username); err != nil {
 		return nil, err
 	}
+	// 用户名内容审核
+	verdict, xerr := moderateContent(s.Ds, cs.ModerationSceneUsername, req.Username)
+	if xerr != nil {
+		return nil, xerr
+	}
 	// 密码检查
 	if err := checkPassword(req.Password); err != nil {
 		logrus.Errorf("scheckPassword err: %v", err)
@@ -121,6 +127,7 @@ func (s *pubSrv) Register(req *web.RegisterReq) (*web.RegisterResp, mir.Error) {
 		logrus.Errorf("Ds.CreateUser err: %s", err)
 		return nil, web.ErrUserRegisterFailed
 	}
+	onContentFlagged(verdict, web.AuditStyleUserProfile, user.ID, user.ID)
 	
 	// Initialize response
 	resp := &web.RegisterResp{
@@ -5,17 +5,31 @@
 package web
 
 import (
+	"context"
+	"fmt"
 	"image"
+	"io"
+	"math"
 	"math/rand"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/alimy/mir/v4"
+	"github.com/cockroachdb/errors"
 	"github.com/gofrs/uuid/v5"
+	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
+	"github.com/rocboss/paopao-ce/internal/core/cs"
 	"github.com/rocboss/paopao-ce/internal/core/ms"
+	"github.com/rocboss/paopao-ce/internal/dao/search"
+	"github.com/rocboss/paopao-ce/internal/model/joint"
 	"github.com/rocboss/paopao-ce/internal/model/web"
+	"github.com/rocboss/paopao-ce/internal/servants/chain"
+	"github.com/rocboss/paopao-ce/pkg/audio"
+	"github.com/rocboss/paopao-ce/pkg/geo"
 	"github.com/rocboss/paopao-ce/pkg/utils"
 	"github.com/rocboss/paopao-ce/pkg/xerror"
 	"github.com/sirupsen/logrus"
@@ -115,6 +129,21 @@ func deleteOssObjects(oss core.ObjectStorageService, mediaContents []string) {
 	}
 }
 
+// unusedMediaContents 编辑后不再使用的媒体内容
+func unusedMediaContents(previous []string, current []string) []string {
+	used := make(map[string]struct{}, len(current))
+	for _, item := range current {
+		used[item] = struct{}{}
+	}
+	unused := make([]string, 0, len(previous))
+	for _, item := range previous {
+		if _, exist := used[item]; !exist {
+			unused = append(unused, item)
+		}
+	}
+	return unused
+}
+
 // persistMediaContents 获取媒体内容并持久化
 func persistMediaContents(oss core.ObjectStorageService, contents []*web.PostContentItem) (items []string, err error) {
 	items = make([]string, 0, len(contents))
@@ -200,9 +229,17 @@ func tagsFrom(originTags []string) []string {
 	return tags
 }
 
-// checkPermision 检查是否拥有者或管理员
-func checkPermision(user *ms.User, targetUserId int64) mir.Error {
-	if user == nil || (user.ID != targetUserId && !user.IsAdmin) {
+// checkPermision 检查是否拥有者或拥有指定权限
+func checkPermision(ams core.AuthorizationManageService, user *ms.User, targetUserId int64, perm cs.Permission) mir.Error {
+	if user == nil || (user.ID != targetUserId && !ams.HasPermission(user, perm)) {
+		return web.ErrNoPermission
+	}
+	return nil
+}
+
+// checkRequirePermission 检查是否拥有指定权限
+func checkRequirePermission(ams core.AuthorizationManageService, user *ms.User, perm cs.Permission) mir.Error {
+	if user == nil || !ams.HasPermission(user, perm) {
 		return web.ErrNoPermission
 	}
 	return nil
@@ -210,6 +247,11 @@ func checkPermision(user *ms.User, targetUserId int64) mir.Error {
 
 // checkPostViewPermission 检查当前用户是否可读指定post
 func checkPostViewPermission(user *ms.User, post *ms.Post, ds core.DataService) mir.Error {
+	// 未发布的动态只有作者可见
+	if post.Status != ms.PostStatusPublished && (user == nil || user.ID != post.GetHostID()) {
+		return web.ErrNoPermission
+	}
+
 	if post.Visibility == core.PostVisitPublic {
 		return nil
 	}
@@ -218,7 +260,7 @@ func checkPostViewPermission(user *ms.User, post *ms.Post, ds core.DataService)
 		return web.ErrNoPermission
 	}
 
-	if user.IsAdmin || user.ID == post.GetHostID() {
+	if user.ID == post.GetHostID() || ds.HasPermission(user, cs.PermTweetViewAll) {
 		return nil
 	}
 
@@ -231,6 +273,372 @@ func checkPostViewPermission(user *ms.User, post *ms.Post, ds core.DataService)
 			return web.ErrNoPermission
 		}
 	}
-	// TODO: add following check logic
+
+	// 仅关注者可见的动态需已通过关注，待通过的关注请求不可见
+	if post.Visibility == core.PostVisitFollowing && !ds.IsFollow(user.ID, post.GetHostID()) {
+		return web.ErrNoPermission
+	}
 	return nil
 }
+
+// onTweetMentions 动态发布后解析文本内容中的@提及并提醒，对话动态的参与者不再重复提醒，
+// skipIds 为编辑前已提醒过的用户
+func onTweetMentions(ds core.DataService, post *ms.Post, skipIds ...int64) {
+	contents, err := ds.GetPostContentsByIDs([]int64{post.ID})
+	if err != nil {
+		logrus.Errorf("Ds.GetPostContentsByIDs of post[%d] err: %s", post.ID, err)
+		return
+	}
+	texts := make([]*mentionText, 0, len(contents))
+	for _, content := range contents {
+		// 评论复制的文本由评论解析
+		if content.CommentID == 0 && (content.Type == ms.ContentTypeTitle || content.Type == ms.ContentTypeText) {
+			texts = append(texts, &mentionText{
+				source:   cs.MentionSourcePost,
+				sourceId: content.ID,
+				text:     content.Content,
+			})
+		}
+	}
+	if len(texts) == 0 {
+		return
+	}
+	onMentionEvent(post, &ms.Message{
+		SenderUserID: post.GetHostID(),
+		Type:         ms.MsgTypePost,
+		Brief:        "在泡泡动态中@了你",
+		PostID:       post.ID,
+	}, texts, nil, slices.Concat(post.UserID, skipIds)...)
+}
+
+// resetMentions 内容编辑后移除原内容中的@提及，返回已提醒过的用户
+func resetMentions(ds core.DataService, source cs.MentionSourceT, targetId int64) []int64 {
+	userIds, err := ds.ListMentionedUserIds(source, targetId)
+	if err != nil {
+		logrus.Errorf("Ds.ListMentionedUserIds of %d[%d] err: %s", source, targetId, err)
+	}
+	if err = ds.DeleteMentions(source, targetId); err != nil {
+		logrus.Errorf("Ds.DeleteMentions of %d[%d] err: %s", source, targetId, err)
+	}
+	return userIds
+}
+
+// tweetStatusFrom 新建动态的发布状态，等待录音优先于定时发布，录音完成后再按计划时间处理
+func tweetStatusFrom(draft bool, scheduledOn int64, awaitRecording bool) (ms.PostStatusT, mir.Error) {
+	if scheduledOn != 0 && scheduledOn <= time.Now().Unix() {
+		return 0, web.ErrInvalidScheduledTime
+	}
+	switch {
+	case draft:
+		return ms.PostStatusDraft, nil
+	case awaitRecording:
+		return ms.PostStatusProcessing, nil
+	case scheduledOn > 0:
+		return ms.PostStatusScheduled, nil
+	default:
+		return ms.PostStatusPublished, nil
+	}
+}
+
+// reportTarget 获取举报对象所属的用户，举报对象为动态、评论或回复时一并返回所在的动态
+func reportTarget(ds core.DataService, targetType cs.ReportTargetType, targetId int64) (int64, *ms.Post, error) {
+	switch targetType {
+	case cs.ReportTargetPost:
+		post, err := ds.GetPostByID(targetId)
+		if err != nil {
+			return 0, nil, err
+		}
+		return post.GetHostID(), post, nil
+	case cs.ReportTargetComment:
+		comment, err := ds.GetCommentByID(targetId)
+		if err != nil {
+			return 0, nil, err
+		}
+		post, err := ds.GetPostByID(comment.PostID)
+		if err != nil {
+			return 0, nil, err
+		}
+		return comment.UserID, post, nil
+	case cs.ReportTargetReply:
+		reply, err := ds.GetCommentReplyByID(targetId)
+		if err != nil {
+			return 0, nil, err
+		}
+		comment, err := ds.GetCommentByID(reply.CommentID)
+		if err != nil {
+			return 0, nil, err
+		}
+		post, err := ds.GetPostByID(comment.PostID)
+		if err != nil {
+			return 0, nil, err
+		}
+		return reply.UserID, post, nil
+	case cs.ReportTargetRoom:
+		room, err := ds.GetRoomByID(targetId)
+		if err != nil {
+			return 0, nil, err
+		}
+		return room.HostID, nil, nil
+	case cs.ReportTargetUser:
+		user, err := ds.GetUserByID(targetId)
+		if err != nil {
+			return 0, nil, err
+		}
+		return user.ID, nil, nil
+	}
+	return 0, nil, errors.Newf("unknown report target type: %s", targetType)
+}
+
+// moderateContent 审核用户提交的文本，审核服务异常时仍以已得出的结论为准，没有结论时放行，仅记录日志
+func moderateContent(ds core.DataService, scene cs.ModerationScene, texts ...string) (*cs.ModerationResult, mir.Error) {
+	res, err := ds.Moderate(scene, texts...)
+	if err != nil {
+		logrus.Errorf("Ds.Moderate scene[%s] err: %s", scene, err)
+	}
+	if res == nil {
+		return &cs.ModerationResult{Verdict: cs.ModerationAllow}, nil
+	}
+	if res.Verdict == cs.ModerationReject {
+		logrus.Infof("content rejected by moderation scene[%s] labels%v", scene, res.Labels)
+		return nil, web.ErrContentRejected
+	}
+	return res, nil
+}
+
+// onContentFlagged 标记待审的内容通过审核hook进入管理员审核队列
+func onContentFlagged(res *cs.ModerationResult, style web.AuditStyle, id int64, userId int64) {
+	if res != nil && res.Verdict == cs.ModerationFlag {
+		chain.OnAudiotHookEvent(&web.AuditMetaInfo{
+			Style:   style,
+			Id:      id,
+			UserId:  userId,
+			Verdict: res.Verdict,
+			Labels:  res.Labels,
+		})
+	}
+}
+
+// textContentsFrom 提取推文/评论内容中需要审核的文本
+func textContentsFrom(items []*web.PostContentItem) (texts []string) {
+	for _, item := range items {
+		if item.Type == ms.ContentTypeTitle || item.Type == ms.ContentTypeText {
+			texts = append(texts, item.Content)
+		}
+	}
+	return
+}
+
+// editableContentsFrom 检查编辑的内容，编辑只允许修改标题与文本，媒体内容保持不变
+func editableContentsFrom(items []*web.PostContentItem) ([]*web.PostContentItem, mir.Error) {
+	for _, item := range items {
+		if item.Type != ms.ContentTypeTitle && item.Type != ms.ContentTypeText {
+			return nil, xerror.InvalidParams
+		}
+	}
+	return items, nil
+}
+
+// checkRoomBanned 被管理员封禁的用户不能主持或进入房间
+func checkRoomBanned(user *ms.User) mir.Error {
+	if user != nil && user.RoomBannedOn > 0 {
+		return web.ErrUserRoomBanned
+	}
+	return nil
+}
+
+// checkEditWindow 检查是否仍在可编辑时间内，Window 为0时不允许编辑
+func checkEditWindow(createdOn int64) mir.Error {
+	if window := conf.EditSetting.Window; window <= 0 || time.Now().Unix()-createdOn > window {
+		return web.ErrEditWindowExpired
+	}
+	return nil
+}
+
+// purgeAccount 执行账户注销，删除用户内容及其媒体文件与搜索索引
+func purgeAccount(ds core.DataService, oss core.ObjectStorageService, deletion *ms.AccountDeletion) error {
+	user, err := ds.GetUserByID(deletion.UserID)
+	if err != nil {
+		return err
+	}
+	// 房间随账户一起删除，先记下房间以便从搜索中移除
+	room, _ := ds.GetRoomByHostID(user.ID)
+	posts, commentedPostIds, mediaContents, exportKeys, err := ds.PurgeAccount(user.ID)
+	if err != nil {
+		return err
+	}
+	if user.Avatar != "" && !slices.Contains(defaultAvatars, user.Avatar) {
+		mediaContents = append(mediaContents, user.Avatar)
+	}
+	deleteOssObjects(oss, mediaContents)
+	if len(exportKeys) > 0 {
+		go oss.DeleteObjects(exportKeys)
+	}
+	// 搜索文档的删除经由发件箱，搜索引擎暂不可用时会重试
+	if len(posts) > 0 {
+		ids := make([]int64, 0, len(posts))
+		for _, post := range posts {
+			ids = append(ids, post.ID)
+		}
+		if err = search.PushDelete(ds, cs.SearchIndexPost, ids...); err != nil {
+			logrus.Warnf("purgeAccount delete search documents occurs error: %s", err)
+		}
+	}
+	// 他人动态的搜索文档包含用户评论复制的内容
+	if len(commentedPostIds) > 0 {
+		if err = search.PushSync(ds, cs.SearchIndexPost, commentedPostIds...); err != nil {
+			logrus.Warnf("purgeAccount sync commented posts search documents occurs error: %s", err)
+		}
+	}
+	if room != nil && room.Model != nil {
+		if err = search.PushSync(ds, cs.SearchIndexRoom, room.ID); err != nil {
+			logrus.Warnf("purgeAccount sync room search document occurs error: %s", err)
+		}
+	}
+	deletion.Status = cs.AccountDeletionDone
+	deletion.ExecutedOn = time.Now().Unix()
+	if err = ds.UpdateAccountDeletion(deletion); err != nil {
+		return err
+	}
+	// 缓存处理
+	onChangeUsernameEvent(user.ID, user.Username)
+	return nil
+}
+
+// pageCursorFrom 解析请求中的分页游标
+func pageCursorFrom(cursor string) (*cs.PageCursor, mir.Error) {
+	c, err := cs.ParsePageCursor(cursor)
+	if err != nil {
+		return nil, web.ErrInvalidPageCursor
+	}
+	return c, nil
+}
+
+// offsetNextCursor 偏移分页时用当前页最后一条记录生成下一页游标，方便客户端从首页切换到游标分页
+func offsetNextCursor(page, pageSize, count int, total int64, last func() *cs.PageCursor) string {
+	if count == 0 || int64((page-1)*pageSize+count) >= total {
+		return ""
+	}
+	return last().Encode()
+}
+
+// postsCursor 推文列表按 (is_top, latest_replied_on, id) 生成游标
+func postsCursor(posts []*ms.PostFormated) func() *cs.PageCursor {
+	return func() *cs.PageCursor {
+		last := posts[len(posts)-1]
+		return &cs.PageCursor{Top: last.IsTop, Value: last.LatestRepliedOn, ID: last.ID}
+	}
+}
+
+// postsPageResp 推文列表分页响应，偏移分页时同样返回下一页游标
+func postsPageResp(posts []*ms.PostFormated, page, pageSize int, total int64, cursor, next *cs.PageCursor) *joint.PageResp {
+	if cursor != nil {
+		return joint.CursorPageRespFrom(dedupTweets(posts), pageSize, next.Encode())
+	}
+	resp := joint.PageRespFrom(posts, page, pageSize, total)
+	resp.NextCursor = offsetNextCursor(page, pageSize, len(posts), total, postsCursor(posts))
+	resp.List = dedupTweets(posts)
+	return resp
+}
+
+// dedupTweets 同一原动态在一页中只展示一次，保留最先出现的一条；
+// 去重在生成游标之后进行，不影响翻页位置
+func dedupTweets(tweets []*ms.PostFormated) []*ms.PostFormated {
+	seen := make(map[int64]struct{}, len(tweets))
+	res := make([]*ms.PostFormated, 0, len(tweets))
+	for _, tweet := range tweets {
+		id := tweet.EffectiveID()
+		if _, exist := seen[id]; exist {
+			continue
+		}
+		seen[id] = struct{}{}
+		res = append(res, tweet)
+	}
+	return res
+}
+
+// nearbyItem 附近的记录在原列表中的位置及与查询位置的距离，单位千米
+type nearbyItem struct {
+	idx      int
+	id       int64
+	distance float64
+}
+
+// nearbyRadiusFrom 校验查询坐标，返回实际使用的搜索半径
+func nearbyRadiusFrom(lat, lng, radius float64) (float64, mir.Error) {
+	if !geo.Valid(lat, lng) {
+		return 0, web.ErrInvalidLocation
+	}
+	setting := conf.NearbySetting
+	if radius <= 0 {
+		radius = setting.DefaultRadius
+	}
+	return min(radius, setting.MaxRadius), nil
+}
+
+// nearbyCoords 对作者/房主以外的用户返回模糊后的坐标，距离也基于展示的坐标计算，避免反推精确位置
+func nearbyCoords(viewerId, ownerId int64, lat, lng float64) (float64, float64) {
+	if viewerId == ownerId {
+		return lat, lng
+	}
+	return geo.Coarsen(lat, lng, conf.NearbySetting.CoarsenPrecision)
+}
+
+// nearbyPage 过滤出半径内的记录并按距离由近到远(距离相同时新的在前)排列，返回当前页及总数，
+// coords 返回第i条记录的id及对当前用户展示的坐标
+func nearbyPage(n int, coords func(i int) (int64, float64, float64), lat, lng, radius float64, page, pageSize int) ([]nearbyItem, int64) {
+	items := make([]nearbyItem, 0, n)
+	for i := 0; i < n; i++ {
+		id, itemLat, itemLng := coords(i)
+		if d := geo.Distance(lat, lng, itemLat, itemLng); d <= radius {
+			items = append(items, nearbyItem{
+				idx:      i,
+				id:       id,
+				distance: math.Round(d*100) / 100,
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].distance != items[j].distance {
+			return items[i].distance < items[j].distance
+		}
+		return items[i].id > items[j].id
+	})
+	start := min(max(page-1, 0)*pageSize, len(items))
+	return items[start:min(start+pageSize, len(items))], int64(len(items))
+}
+
+// analyzeVoiceNote 解析上传的语音得到时长与波形摘要，并校验时长上限，解析后文件读取位置复位
+func analyzeVoiceNote(f io.ReadSeeker) (*audio.Summary, mir.Error) {
+	s := conf.VoiceNoteSetting
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	summary, err := audio.Analyze(ctx, audio.Options{
+		FFmpeg:  s.FFmpeg,
+		WorkDir: s.WorkDir,
+		Bars:    s.WaveformBars,
+	}, f)
+	if err != nil {
+		logrus.Errorf("analyze voice note failed: %s", err)
+		return nil, web.ErrFileInvalidAudio
+	}
+	if summary.Duration > s.MaxDuration*1000 {
+		return nil, web.ErrAudioTooLong.WithDetails(fmt.Sprintf("最长允许%d秒", s.MaxDuration))
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		logrus.Errorf("seek voice note failed: %s", err)
+		return nil, web.ErrFileUploadFailed
+	}
+	return summary, nil
+}
+
+// voiceNoteFrom 语音内容须为用户本人通过 public/audio 上传的附件，返回上传时记录的时长与波形
+func voiceNoteFrom(ds core.DataService, userId int64, content string) (*ms.Attachment, mir.Error) {
+	if err := ds.CheckAttachment(content); err != nil {
+		return nil, web.ErrInvalidAudioAttachment
+	}
+	attachment, err := ds.GetAttachmentByContent(content)
+	if err != nil || attachment.UserID != userId || attachment.Type != ms.AttachmentTypeAudio {
+		return nil, web.ErrInvalidAudioAttachment
+	}
+	return attachment, nil
+}
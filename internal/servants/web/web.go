@@ -15,16 +15,18 @@ import (
 	"github.com/rocboss/paopao-ce/internal/dao"
 	"github.com/rocboss/paopao-ce/internal/dao/cache"
 	"github.com/rocboss/paopao-ce/internal/servants/base"
+	"github.com/rocboss/paopao-ce/pkg/centrifugo"
 )
 
 var (
-	_enablePhoneVerify    bool
-	_disallowUserRegister bool
-	_ds                   core.DataService
-	_ac                   core.AppCache
-	_wc                   core.WebCache
-	_oss                  core.ObjectStorageService
-	_onceInitial          sync.Once
+	_ds          core.DataService
+	_ac          core.AppCache
+	_wc          core.WebCache
+	_nc          *cache.NotificationCache
+	_oss         core.ObjectStorageService
+	_centrifugo  *centrifugo.Client
+	_push        core.RoomInvitePushService
+	_onceInitial sync.Once
 )
 
 // RouteWeb register web route
@@ -50,19 +52,26 @@ func RouteWeb(e *gin.Engine) {
 		api.RegisterAlipayPrivServant(e, newAlipayPrivSrv(ds, client))
 	})
 	// shedule jobs if need
-	scheduleJobs()
+	scheduleJobs(ds)
+}
+
+// UseRoomInvitePush 由ContactPush服务在启动前注入房间邀请推送
+func UseRoomInvitePush(p core.RoomInvitePushService) {
+	_push = p
 }
 
 // lazyInitial do some package lazy initialize for performance
 func lazyInitial() {
 	_onceInitial.Do(func() {
-		_enablePhoneVerify = cfg.If("Sms")
-		_disallowUserRegister = cfg.If("Web:DisallowUserRegister")
 		_maxWhisperNumDaily = conf.AppSetting.MaxWhisperDaily
 		_maxCaptchaTimes = conf.AppSetting.MaxCaptchaTimes
 		_oss = dao.ObjectStorageService()
 		_ds = dao.DataService()
 		_ac = cache.NewAppCache()
 		_wc = cache.NewWebCache()
+		_nc = cache.NewNotificationCache(_ac)
+		if cfg.If("Centrifugo") {
+			_centrifugo = conf.MustCentrifugoClient()
+		}
 	})
 }
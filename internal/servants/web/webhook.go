@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alimy/mir/v4"
 	"github.com/gin-gonic/gin"
@@ -233,8 +235,21 @@ func (s *webhookSrv) AudioWebhook(req *web.AudioWebhookReq) mir.Error {
 
 	// We already have the post from earlier, no need to fetch it again
 
+	// 所有参与者的录音都已完成时发布动态，定时发布的等到计划时间
+	if post.Status == ms.PostStatusProcessing && s.countUsersInContent(newContent) >= len(post.UserID) {
+		if err = s.publishRecordedTweet(post); err != nil {
+			logrus.Errorf("Failed to publish post %d after recordings completed: %v", post.ID, err)
+		}
+	}
+
 	// Update search index
 	s.PushPostToSearch(post)
+	// 转写该说话人的音轨，完成后会再次刷新搜索文档
+	if speakerId, err := strconv.ParseInt(userID, 10, 64); err == nil {
+		s.TranscribeRecording(post, speakerId, req.Data.RecordingURL)
+	} else {
+		logrus.Warnf("skip transcribing recording of invalid user_id %s: %v", userID, err)
+	}
 	
 	// 私密推文不创建标签与用户提醒
 	if post.Visibility != core.PostVisitPrivate {
@@ -286,6 +301,28 @@ func (s *webhookSrv) AudioWebhook(req *web.AudioWebhookReq) mir.Error {
 
 
 
+// publishRecordedTweet 发布录音已完成的动态，计划时间未到时转为定时发布，
+// 多个录音回调同时到达或动态已超时转为草稿时只有认领成功的回调处理
+func (s *webhookSrv) publishRecordedTweet(post *ms.Post) error {
+	status := ms.PostStatusPublished
+	if post.ScheduledOn > time.Now().Unix() {
+		status = ms.PostStatusScheduled
+	}
+	if claimed, err := s.Ds.ClaimPostStatus(post.ID, ms.PostStatusProcessing, status, post.ScheduledOn); err != nil || !claimed {
+		return err
+	}
+	if status == ms.PostStatusScheduled {
+		post.Status = status
+		return nil
+	}
+	if err := s.PublishTweet(post); err != nil {
+		return err
+	}
+	onTrendsActionEvent(_trendsActionCreateTweet, post.GetHostID())
+	onTweetMentions(s.Ds, post)
+	return nil
+}
+
 // Helper method to count unique users in audio content
 func (s *webhookSrv) countUsersInContent(content string) int {
 	if content == "" {
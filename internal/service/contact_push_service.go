@@ -9,6 +9,7 @@ import (
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/dao/cache"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
+	"github.com/rocboss/paopao-ce/internal/servants"
 	"github.com/sirupsen/logrus"
 )
 
@@ -43,6 +44,9 @@ func (s *ContactPushService) OnInit() error {
 	// Initialize online monitor service
 	s.onlineMonitor = NewOnlineMonitorService(db, appCache, s.pushNotification)
 	
+	// Room invitations created by web servants are pushed through the same Gorush client
+	servants.UseRoomInvitePush(s.pushNotification)
+	
 	logrus.Info("ContactPush service initialized successfully")
 	return nil
 }
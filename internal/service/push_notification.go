@@ -16,6 +16,7 @@ import (
 	"github.com/rocboss/paopao-ce/internal/conf"
 	"github.com/rocboss/paopao-ce/internal/core"
 	"github.com/rocboss/paopao-ce/internal/core/cs"
+	"github.com/rocboss/paopao-ce/internal/dao/cache"
 	"github.com/rocboss/paopao-ce/internal/dao/jinzhu/dbr"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -26,7 +27,7 @@ type PushNotificationService struct {
 	db           *gorm.DB
 	gorushURL    string
 	httpClient   *http.Client
-	notificationCache *NotificationCache
+	notificationCache *cache.NotificationCache
 	cache        core.AppCache
 	
 	// Cache for device tokens to avoid repeated DB queries every 30 seconds
@@ -59,15 +60,15 @@ type GorushResponse struct {
 }
 
 // NewPushNotificationService creates a new push notification service
-func NewPushNotificationService(db *gorm.DB, gorushURL string, cache core.AppCache) *PushNotificationService {
+func NewPushNotificationService(db *gorm.DB, gorushURL string, appCache core.AppCache) *PushNotificationService {
 	return &PushNotificationService{
 		db:        db,
 		gorushURL: gorushURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		notificationCache: NewNotificationCache(cache),
-		cache:            cache,
+		notificationCache: cache.NewNotificationCache(appCache),
+		cache:            appCache,
 		deviceTokensCache: make(map[int64][]*dbr.UserDevice),
 		deviceCacheExpiry: 300, // Cache expires after 5 minutes
 	}
@@ -148,7 +149,7 @@ func (s *PushNotificationService) SendContactMatchedNotification(contactOwnerID
 		if err := s.sendGorushNotificationWithCache(iosTokens, 1,
 			fmt.Sprintf("%s is on the app!", matchedUsername),
 			"Contact Found",
-			NotificationTypeContactMatched,
+			cache.NotificationTypeContactMatched,
 			contactOwnerID,
 			map[string]interface{}{
 				"type": "contact_matched",
@@ -163,7 +164,7 @@ func (s *PushNotificationService) SendContactMatchedNotification(contactOwnerID
 		if err := s.sendGorushNotificationWithCache(androidTokens, 2,
 			fmt.Sprintf("%s is on the app!", matchedUsername),
 			"Contact Found",
-			NotificationTypeContactMatched,
+			cache.NotificationTypeContactMatched,
 			contactOwnerID,
 			map[string]interface{}{
 				"type": "contact_matched",
@@ -176,6 +177,41 @@ func (s *PushNotificationService) SendContactMatchedNotification(contactOwnerID
 	return nil
 }
 
+// SendRoomInviteNotification sends a room invitation to the invitees, the payload carries
+// the room ID so the client can deep-link into the room. Invite dedup and TTL are handled
+// by the caller once the invitations are created.
+func (s *PushNotificationService) SendRoomInviteNotification(inviteeIDs []int64, roomID int64, hostName string, ping bool) error {
+	// Query devices directly, the device cache is not safe for concurrent invite events
+	var devices []*dbr.UserDevice
+	if err := s.db.Where("user_id IN ? AND is_active = ? AND is_del = ?", inviteeIDs, true, 0).Find(&devices).Error; err != nil {
+		return fmt.Errorf("failed to get invitee devices: %v", err)
+	}
+	var iosTokens, androidTokens []string
+	for _, device := range devices {
+		switch device.Platform {
+		case "ios":
+			iosTokens = append(iosTokens, device.DeviceToken)
+		case "android":
+			androidTokens = append(androidTokens, device.DeviceToken)
+		}
+	}
+	message := fmt.Sprintf("%s invited you to join the room", hostName)
+	if ping {
+		message = fmt.Sprintf("%s is live, come join the room!", hostName)
+	}
+	data := map[string]interface{}{
+		"type":    "room_invite",
+		"room_id": roomID,
+	}
+	if err := s.sendGorushNotification(iosTokens, 1, message, "Room Invite", data); err != nil {
+		logrus.Errorf("Failed to send iOS room invite notification: %v", err)
+	}
+	if err := s.sendGorushNotification(androidTokens, 2, message, "Room Invite", data); err != nil {
+		logrus.Errorf("Failed to send Android room invite notification: %v", err)
+	}
+	return nil
+}
+
 // getUsersWithMatchedContact gets all users who have a specific user in their contacts
 func (s *PushNotificationService) getUsersWithMatchedContact(targetUserID int64) ([]int64, error) {
 	var userIDs []int64
@@ -467,7 +503,7 @@ func (s *PushNotificationService) sendGorushNotificationWithCache(
 	tokens []string, 
 	platform int, 
 	message, title string, 
-	notificationType NotificationType,
+	notificationType cache.NotificationType,
 	targetUserID int64,
 	data map[string]interface{},
 ) error {
@@ -7,6 +7,7 @@ package main
 import (
 	"github.com/rocboss/paopao-ce/cmd"
 	_ "github.com/rocboss/paopao-ce/cmd/migrate"
+	_ "github.com/rocboss/paopao-ce/cmd/search"
 	_ "github.com/rocboss/paopao-ce/cmd/serve"
 )
 
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	. "github.com/alimy/mir/v4"
+	. "github.com/alimy/mir/v4/engine"
+	"github.com/rocboss/paopao-ce/internal/model/web"
+)
+
+func init() {
+	Entry[Manage]()
+}
+
+// Manage 管理后台服务，使用管理后台专用鉴权
+type Manage struct {
+	Chain `mir:"-"`
+	Group `mir:"m/v1"`
+
+	// Logout 退出管理后台
+	Logout func(Post, web.ManageLogoutReq) `mir:"/user/logout/"`
+
+	// ListUsers 搜索、筛选用户
+	ListUsers func(Get, web.ManageUsersReq) web.ManageUsersResp `mir:"/users"`
+
+	// GetUserDetail 用户详情，包含设备、房间、互动与举报信息
+	GetUserDetail func(Get, web.ManageUserDetailReq) web.ManageUserDetailResp `mir:"/user/detail"`
+
+	// ChangeUserStatus 封禁/解封用户
+	ChangeUserStatus func(Post, web.ManageUserStatusReq) `mir:"/user/status"`
+
+	// ChangeUserAdmin 授予/撤销管理员
+	ChangeUserAdmin func(Post, web.ManageUserAdminReq) `mir:"/user/admin"`
+
+	// ListRoles 角色及其权限列表
+	ListRoles func(Get, web.ManageRolesReq) web.ManageRolesResp `mir:"/roles"`
+
+	// AssignUserRole 为用户分配角色
+	AssignUserRole func(Post, web.ManageUserRoleReq) `mir:"/user/role/assign"`
+
+	// RevokeUserRole 撤销用户角色
+	RevokeUserRole func(Post, web.ManageUserRoleReq) `mir:"/user/role/revoke"`
+
+	// ForceLogout 强制用户下线
+	ForceLogout func(Post, web.ForceLogoutReq) `mir:"/user/logout/force"`
+
+	// DeletePost 删除动态
+	DeletePost func(Post, web.ManagePostReq) `mir:"/post/delete"`
+
+	// RestorePost 恢复已删除的动态
+	RestorePost func(Post, web.ManagePostReq) `mir:"/post/restore"`
+
+	// Dashboard 运营数据统计
+	Dashboard func(Get, web.DashboardReq) web.DashboardResp `mir:"/dashboard"`
+
+	// ListFeatures 运行时可切换的功能开关
+	ListFeatures func(Get, web.ManageFeaturesReq) web.ManageFeaturesResp `mir:"/features"`
+
+	// ToggleFeature 切换功能开关
+	ToggleFeature func(Post, web.ManageFeatureToggleReq) `mir:"/feature/toggle"`
+
+	// SearchIndexes 搜索索引状态
+	SearchIndexes func(Get, web.ManageSearchIndexesReq) web.ManageSearchIndexesResp `mir:"/search/indexes"`
+
+	// SearchReindex 后台重建搜索索引
+	SearchReindex func(Post, web.ManageSearchReindexReq) `mir:"/search/reindex"`
+}
@@ -35,8 +35,7 @@ type LoginResp struct {
 }
 
 type User struct {
-	Chain  `mir:"-"`
-	Group  `mir:"m/v1"`
-	Login  func(Post, LoginReq) LoginResp `mir:"/user/login/"`
-	Logout func(Post)                     `mir:"/user/logout/"`
+	Chain `mir:"-"`
+	Group `mir:"m/v1"`
+	Login func(Post, LoginReq) LoginResp `mir:"/user/login/"`
 }
@@ -18,4 +18,16 @@ type Admin struct {
 	// ChangeUserStatus 管理·禁言/解封用户
 	ChangeUserStatus func(Post, web.ChangeUserStatusReq)         `mir:"/admin/user/status"`
 	SiteInfo         func(Get, web.SiteInfoReq) web.SiteInfoResp `mir:"/admin/site/status"`
+
+	// ListReports 管理·举报审核队列
+	ListReports func(Get, web.ListReportsReq) web.ListReportsResp `mir:"/admin/reports"`
+
+	// HandleReport 管理·处理举报
+	HandleReport func(Post, web.HandleReportReq) web.HandleReportResp `mir:"/admin/report/handle"`
+
+	// ChangeRoomStatus 管理·下架/恢复房间
+	ChangeRoomStatus func(Post, web.ChangeRoomStatusReq) `mir:"/admin/room/status"`
+
+	// ListModerationLogs 管理·审核日志
+	ListModerationLogs func(Get, web.ListModerationLogsReq) web.ListModerationLogsResp `mir:"/admin/moderation/logs"`
 }
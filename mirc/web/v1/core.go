@@ -39,9 +39,42 @@ type Core struct {
 	// SendUserWhisper 发送用户私信
 	SendUserWhisper func(Post, web.SendWhisperReq) `mir:"/user/whisper"`
 
+	// GetDmConversation 获取与指定用户的私信会话，不存在时按发送私信的限制检查后创建
+	GetDmConversation func(Post, web.GetDmConversationReq) web.GetDmConversationResp `mir:"/dm/conversation"`
+
+	// ListDmConversations 获取私信会话列表，包括每个会话的未读数
+	ListDmConversations func(Get, web.ListDmConversationsReq) web.ListDmConversationsResp `mir:"/dm/conversations"`
+
+	// GetDmMessages 游标分页获取会话的私信记录
+	GetDmMessages func(Get, web.GetDmMessagesReq) web.GetDmMessagesResp `mir:"/dm/messages"`
+
+	// SendDmMessage 发送私信
+	SendDmMessage func(Post, web.SendDmMessageReq) web.SendDmMessageResp `mir:"/dm/message"`
+
+	// ReadDmConversation 标记私信会话已读
+	ReadDmConversation func(Post, web.ReadDmConversationReq) `mir:"/dm/read"`
+
+	// GetDmUnreadCount 获取私信未读总数
+	GetDmUnreadCount func(Get, web.GetDmUnreadCountReq) web.GetDmUnreadCountResp `mir:"/dm/unread"`
+
+	// ChangeDmPolicy 设置谁可以向我发起私信
+	ChangeDmPolicy func(Post, web.ChangeDmPolicyReq) `mir:"/user/dm_policy"`
+
+	// BlockUser 屏蔽用户
+	BlockUser func(Post, web.BlockUserReq) `mir:"/user/block"`
+
+	// UnblockUser 取消屏蔽用户
+	UnblockUser func(Post, web.UnblockUserReq) `mir:"/user/unblock"`
+
+	// ListBlockedUsers 获取屏蔽的用户列表
+	ListBlockedUsers func(Get, web.ListBlockedUsersReq) web.ListBlockedUsersResp `mir:"/user/blocks"`
+
 	// GetCollections 获取用户收藏列表
 	GetCollections func(Get, web.GetCollectionsReq) web.GetCollectionsResp `mir:"/user/collections"`
 
+	// GetDrafts 获取用户未发布的动态，包括草稿、等待录音及定时发布的动态
+	GetDrafts func(Get, web.GetDraftsReq) web.GetDraftsResp `mir:"/user/drafts"`
+
 	// GetStars 获取用户点赞列表
 	GetStars func(Get, web.GetStarsReq) web.GetStarsResp `mir:"/user/stars"`
 
@@ -91,6 +124,15 @@ type Core struct {
 	// GetRoomByHostID returns a room by its host ID
 	GetRoomByHostID func(Get, web.GetRoomByHostIDReq) (*web.Room, mir.Error) `mir:"/rooms/host/:hostId"`
 
+	// NearbyRooms returns live rooms near the given coordinate
+	NearbyRooms func(Get, web.NearbyRoomsReq) (*web.NearbyRoomsResp, mir.Error) `mir:"/rooms/nearby"`
+
+	// InviteRoom invites followers or friends into the host's room
+	InviteRoom func(Post, web.InviteRoomReq) (*web.InviteRoomResp, mir.Error) `mir:"/rooms/:id/invite"`
+
+	// AcceptRoomInvite accepts a room invitation and returns the room to join
+	AcceptRoomInvite func(Post, web.AcceptRoomInviteReq) (*web.Room, mir.Error) `mir:"/rooms/:id/accept"`
+
 	// Category endpoints
 	// GetAllCategories gets all available categories
 	GetAllCategories func(Get) web.CategoryListResp `mir:"/categories"`
@@ -121,4 +163,19 @@ type Core struct {
 
 	// GetUserReactionTimeline 获取特定用户的反应时间线 (用户对谁有反应)
 	GetUserReactionTimeline func(Get, web.GetUserReactionTimelineReq) (*web.GetUserReactionTimelineResp, mir.Error) `mir:"/user/reactions/timeline/user"`
+
+	// ExportAccount 申请导出账户数据
+	ExportAccount func(Post, web.ExportAccountReq) web.ExportAccountResp `mir:"/user/account/export"`
+
+	// GetAccountExport 获取账户数据导出结果及下载链接
+	GetAccountExport func(Get, web.GetAccountExportReq) web.GetAccountExportResp `mir:"/user/account/export"`
+
+	// DeleteAccount 申请注销账户
+	DeleteAccount func(Post, web.DeleteAccountReq) web.DeleteAccountResp `mir:"/user/account/delete"`
+
+	// CancelAccountDeletion 撤销账户注销
+	CancelAccountDeletion func(Post, web.CancelAccountDeletionReq) `mir:"/user/account/delete/cancel"`
+
+	// GetAccountDeletion 获取账户注销状态
+	GetAccountDeletion func(Get, web.GetAccountDeletionReq) web.GetAccountDeletionResp `mir:"/user/account/deletion"`
 }
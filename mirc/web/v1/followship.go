@@ -26,4 +26,7 @@ type Followship struct {
 
 	// ListFollowings 获取用户的追随者列表
 	ListFollowings func(Get, web.ListFollowingsReq) web.ListFollowingsResp `mir:"/user/followings"`
+
+	// ChangeRoomPing 开启或关闭关注对象开房间时的"叫人来"提醒
+	ChangeRoomPing func(Post, web.ChangeRoomPingReq) `mir:"/user/follow/room_ping"`
 }
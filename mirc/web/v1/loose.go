@@ -33,6 +33,15 @@ type Loose struct {
 	// TweetDetail 获取动态详情
 	TweetDetail func(Get, web.TweetDetailReq) web.TweetDetailResp `mir:"/post"`
 
+	// TweetThread 获取动态所在的串推
+	TweetThread func(Get, web.TweetThreadReq) web.TweetThreadResp `mir:"/post/thread"`
+
 	// GetPostLocation 获取动态位置信息
 	GetPostLocation func(Get, web.PostLocationReq) web.PostLocationResp `mir:"/posts/{postId}/location"`
+
+	// NearbyTweets 获取附近的动态
+	NearbyTweets func(Get, web.NearbyTweetsReq) web.NearbyTweetsResp `mir:"/posts/nearby"`
+
+	// Search 统一搜索动态、用户、房间与话题
+	Search func(Get, web.SearchReq) web.SearchResp `mir:"/search"`
 }
@@ -27,12 +27,18 @@ type Priv struct {
 	// CreateTweet 发布动态
 	CreateTweet func(Post, Chain, web.CreateTweetReq) web.CreateTweetResp `mir:"/post"`
 
+	// UpdateDraft 编辑草稿或定时发布的动态
+	UpdateDraft func(Put, web.UpdateDraftReq) web.UpdateDraftResp `mir:"/post/draft"`
+
 	// DeleteTweet 删除动态
 	DeleteTweet func(Delete, web.DeleteTweetReq) `mir:"/post"`
 
 	// StarTweet 动态点赞操作
 	StarTweet func(Post, web.StarTweetReq) web.StarTweetResp `mir:"/post/star"`
 
+	// RepostTweet 转发动态，再次转发即取消
+	RepostTweet func(Post, web.RepostTweetReq) web.RepostTweetResp `mir:"/post/repost"`
+
 	// CollectionTweet 动态收藏操作
 	CollectionTweet func(Post, web.CollectionTweetReq) web.CollectionTweetResp `mir:"/post/collection"`
 
@@ -86,4 +92,7 @@ type Priv struct {
 
 	// UnfollowTopic 取消关注话题
 	UnfollowTopic func(Post, web.UnfollowTopicReq) `mir:"/topic/unfollow"`
+
+	// CreateReport 举报动态/评论/房间/用户
+	CreateReport func(Post, web.CreateReportReq) web.CreateReportResp `mir:"/report"`
 }
@@ -7,6 +7,7 @@ package app
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,6 +15,11 @@ import (
 	"github.com/rocboss/paopao-ce/internal/core/ms"
 )
 
+const (
+	// AdminAudience 管理后台签发的token的受众
+	AdminAudience = "paopao-admin"
+)
+
 type Claims struct {
 	UID      int64  `json:"uid"`
 	Username string `json:"username"`
@@ -31,7 +37,26 @@ func GenerateToken(user *ms.User) (string, error) {
 		Username: user.Username,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expireTime),
-			Issuer:    IssuerFrom(user.Salt),
+			Issuer:    UserIssuer(user),
+		},
+	}
+
+	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token, err := tokenClaims.SignedString(GetJWTSecret())
+	return token, err
+}
+
+// GenerateAdminToken 签发管理后台token，与Web端token的签发者、受众都不同，不能混用
+func GenerateAdminToken(user *ms.User, sessionId string) (string, error) {
+	expireTime := time.Now().Add(conf.JWTSetting.AdminExpire)
+	claims := Claims{
+		UID:      user.ID,
+		Username: user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionId,
+			ExpiresAt: jwt.NewNumericDate(expireTime),
+			Issuer:    AdminIssuer(user),
+			Audience:  jwt.ClaimStrings{AdminAudience},
 		},
 	}
 
@@ -47,15 +72,41 @@ func ParseToken(token string) (res *Claims, err error) {
 	})
 	if err == nil && tokenClaims != nil && tokenClaims.Valid {
 		res, _ = tokenClaims.Claims.(*Claims)
+		// 管理后台token不能用于Web端
+		if res != nil && slices.Contains(res.Audience, AdminAudience) {
+			res, err = nil, jwt.ErrTokenInvalidAudience
+		}
 	} else {
 		err = jwt.ErrTokenNotValidYet
 	}
 	return
 }
 
+// ParseAdminToken 解析管理后台token，校验受众
+func ParseAdminToken(token string) (res *Claims, err error) {
+	var tokenClaims *jwt.Token
+	tokenClaims, err = jwt.ParseWithClaims(token, &Claims{}, func(_ *jwt.Token) (any, error) {
+		return GetJWTSecret(), nil
+	}, jwt.WithAudience(AdminAudience))
+	if err == nil && tokenClaims != nil && tokenClaims.Valid {
+		res, _ = tokenClaims.Claims.(*Claims)
+	}
+	return
+}
+
 func IssuerFrom(data string) string {
 	contents := make([]byte, 0, len(conf.JWTSetting.Issuer)+len(data))
 	contents = append(append(contents, []byte(conf.JWTSetting.Issuer)...), []byte(data)...)
 	res := md5.Sum(contents)
 	return hex.EncodeToString(res[:])
 }
+
+// UserIssuer Web端token的签发者，修改密码或轮换会话盐值后改变
+func UserIssuer(user *ms.User) string {
+	return IssuerFrom(user.Salt + user.SessionSalt)
+}
+
+// AdminIssuer 管理后台token的签发者
+func AdminIssuer(user *ms.User) string {
+	return IssuerFrom("admin:" + user.Salt + user.SessionSalt)
+}
@@ -0,0 +1,237 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package audio analyze voice notes to get duration and waveform summary.
+// PCM wav is decoded natively, other formats are decoded by ffmpeg.
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	_defaultBars = 64
+
+	// ffmpeg 解码输出为8kHz单声道16位PCM
+	_ffmpegSampleRate = 8000
+)
+
+var (
+	// ErrUnsupported 不支持的音频格式
+	ErrUnsupported = errors.New("audio: unsupported format")
+	// ErrInvalid 音频数据无效
+	ErrInvalid = errors.New("audio: invalid data")
+)
+
+// Options 分析参数
+type Options struct {
+	// FFmpeg ffmpeg可执行文件路径，为空时仅支持PCM wav
+	FFmpeg string
+	// WorkDir ffmpeg解码时的临时文件目录，为空时使用系统临时目录
+	WorkDir string
+	// Bars 波形摘要的柱数
+	Bars int
+}
+
+// Summary 音频摘要
+type Summary struct {
+	// Duration 时长，毫秒
+	Duration int64
+	// Waveform 波形摘要，每个值为0~100的相对振幅
+	Waveform []int
+}
+
+// Analyze 读取音频数据并返回时长与波形摘要
+func Analyze(ctx context.Context, opts Options, r io.Reader) (*Summary, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(12)
+	if err == nil && string(header[:4]) == "RIFF" && string(header[8:]) == "WAVE" {
+		return analyzeWav(br, opts.Bars)
+	}
+	if opts.FFmpeg == "" {
+		return nil, ErrUnsupported
+	}
+	return analyzeFFmpeg(ctx, opts, br)
+}
+
+func analyzeWav(r *bufio.Reader, bars int) (*Summary, error) {
+	if _, err := r.Discard(12); err != nil {
+		return nil, ErrInvalid
+	}
+	var (
+		chunk      [8]byte
+		sampleRate int
+		blockAlign int
+	)
+	for {
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, ErrInvalid
+		}
+		id, size := string(chunk[:4]), int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, ErrInvalid
+			}
+			fmtChunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return nil, ErrInvalid
+			}
+			format := binary.LittleEndian.Uint16(fmtChunk[0:])
+			channels := int(binary.LittleEndian.Uint16(fmtChunk[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:]))
+			bits := binary.LittleEndian.Uint16(fmtChunk[14:])
+			if format != 1 || bits != 16 || channels < 1 || sampleRate <= 0 {
+				return nil, ErrUnsupported
+			}
+			blockAlign = channels * 2
+		case "data":
+			if sampleRate == 0 {
+				return nil, ErrInvalid
+			}
+			// 多声道时只取第一个声道
+			p := newPeaker(sampleRate)
+			frame := make([]byte, blockAlign)
+			for n := size / int64(blockAlign); n > 0; n-- {
+				if _, err := io.ReadFull(r, frame); err != nil {
+					break
+				}
+				p.add(int(int16(binary.LittleEndian.Uint16(frame))))
+			}
+			return p.summary(sampleRate, bars)
+		default:
+			if _, err := r.Discard(int(size + size%2)); err != nil {
+				return nil, ErrInvalid
+			}
+		}
+	}
+}
+
+func analyzeFFmpeg(ctx context.Context, opts Options, r io.Reader) (*Summary, error) {
+	f, err := os.CreateTemp(opts.WorkDir, "audio-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, opts.FFmpeg, "-v", "error", "-i", f.Name(),
+		"-f", "s16le", "-ac", "1", "-ar", fmt.Sprint(_ffmpegSampleRate), "pipe:1")
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := newPeaker(_ffmpegSampleRate)
+	out := bufio.NewReader(stdout)
+	sample := make([]byte, 2)
+	for {
+		if _, err = io.ReadFull(out, sample); err != nil {
+			break
+		}
+		p.add(int(int16(binary.LittleEndian.Uint16(sample))))
+	}
+	if err = cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, msg)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, err)
+	}
+	return p.summary(_ffmpegSampleRate, opts.Bars)
+}
+
+// peaker 按10ms窗口记录采样峰值
+type peaker struct {
+	window  int
+	n       int
+	peak    int
+	peaks   []int
+	samples int64
+}
+
+func newPeaker(sampleRate int) *peaker {
+	window := sampleRate / 100
+	if window < 1 {
+		window = 1
+	}
+	return &peaker{window: window}
+}
+
+func (p *peaker) add(v int) {
+	if v < 0 {
+		v = -v
+	}
+	if v > p.peak {
+		p.peak = v
+	}
+	p.samples++
+	if p.n++; p.n == p.window {
+		p.peaks = append(p.peaks, p.peak)
+		p.n, p.peak = 0, 0
+	}
+}
+
+func (p *peaker) summary(sampleRate int, bars int) (*Summary, error) {
+	if p.n > 0 {
+		p.peaks = append(p.peaks, p.peak)
+	}
+	if p.samples == 0 {
+		return nil, ErrInvalid
+	}
+	return &Summary{
+		Duration: p.samples * 1000 / int64(sampleRate),
+		Waveform: Waveform(p.peaks, bars),
+	}, nil
+}
+
+// Waveform 把峰值序列按最大值归一化并合并为bars个0~100的柱
+func Waveform(peaks []int, bars int) []int {
+	if bars <= 0 {
+		bars = _defaultBars
+	}
+	res := make([]int, bars)
+	n := len(peaks)
+	if n == 0 {
+		return res
+	}
+	top := 0
+	for _, v := range peaks {
+		if v > top {
+			top = v
+		}
+	}
+	if top == 0 {
+		return res
+	}
+	for i := range res {
+		start, end := i*n/bars, (i+1)*n/bars
+		if end <= start {
+			end = start + 1
+		}
+		peak := 0
+		for _, v := range peaks[start:end] {
+			if v > peak {
+				peak = v
+			}
+		}
+		res[i] = peak * 100 / top
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package audio_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/audio"
+)
+
+// pcmWav 生成16位PCM wav，samples为第一个声道的采样
+func pcmWav(sampleRate int, channels int, samples []int16) []byte {
+	data := &bytes.Buffer{}
+	for _, v := range samples {
+		for c := 0; c < channels; c++ {
+			binary.Write(data, binary.LittleEndian, v)
+		}
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+8+2+data.Len()))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1))
+	binary.Write(buf, binary.LittleEndian, uint16(channels))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate*channels*2))
+	binary.Write(buf, binary.LittleEndian, uint16(channels*2))
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+	// 奇数长度的未知块需要跳过填充字节
+	buf.WriteString("LIST")
+	binary.Write(buf, binary.LittleEndian, uint32(1))
+	buf.Write([]byte{0, 0})
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(data.Len()))
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+var _ = Describe("Audio", func() {
+	ctx := context.Background()
+
+	It("analyze pcm wav duration and waveform", func() {
+		// 1.5秒，前半段静音，后半段满幅
+		samples := make([]int16, 12000)
+		for i := 6000; i < len(samples); i++ {
+			samples[i] = -32768
+		}
+		summary, err := audio.Analyze(ctx, audio.Options{Bars: 4}, bytes.NewReader(pcmWav(8000, 2, samples)))
+		Expect(err).To(BeNil())
+		Expect(summary.Duration).To(Equal(int64(1500)))
+		Expect(summary.Waveform).To(Equal([]int{0, 0, 100, 100}))
+	})
+
+	It("reject unsupported format without ffmpeg", func() {
+		_, err := audio.Analyze(ctx, audio.Options{}, strings.NewReader("ID3 not a wav file"))
+		Expect(err).To(Equal(audio.ErrUnsupported))
+	})
+
+	It("reject empty wav", func() {
+		_, err := audio.Analyze(ctx, audio.Options{}, bytes.NewReader(pcmWav(8000, 1, nil)))
+		Expect(err).To(Equal(audio.ErrInvalid))
+	})
+
+	It("merge peaks into bars", func() {
+		Expect(audio.Waveform([]int{10, 20, 40, 5}, 2)).To(Equal([]int{50, 100}))
+		Expect(audio.Waveform([]int{10, 20}, 4)).To(Equal([]int{50, 50, 100, 100}))
+		Expect(audio.Waveform(nil, 3)).To(Equal([]int{0, 0, 0}))
+	})
+})
@@ -0,0 +1,233 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package blevex keep bleve indexes on local disk behind aliases so an index
+// can be rebuilt into a shadow index and swapped in without downtime.
+package blevex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/rocboss/paopao-ce/pkg/json"
+	"github.com/sirupsen/logrus"
+)
+
+// SourceField 保存原始文档的json，检索结果由此还原
+const SourceField = "_source"
+
+// Store 本地磁盘上的bleve索引，同一目录只能被一个进程打开，
+// 所以一个目录下的全部索引共用一个实例。在用索引名是指向具体索引的别名，
+// 具体索引目录记录在 在用索引名.current 文件中，重建索引后切换别名
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	aliases map[string]bleve.IndexAlias
+	current map[string]string
+	indexes map[string]bleve.Index
+}
+
+// NewStore 使用path目录存放索引，不存在则创建
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{
+		path:    path,
+		aliases: make(map[string]bleve.IndexAlias),
+		current: make(map[string]string),
+		indexes: make(map[string]bleve.Index),
+	}, nil
+}
+
+// Open 打开在用索引，不存在则按im创建
+func (s *Store) Open(live string, im mapping.IndexMapping) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exist := s.aliases[live]; exist {
+		return fmt.Errorf("bleve index %s already opened", live)
+	}
+	name := live
+	if data, err := os.ReadFile(s.currentFile(live)); err == nil {
+		name = strings.TrimSpace(string(data))
+	}
+	idx, err := bleve.Open(filepath.Join(s.path, name))
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		idx, err = bleve.New(filepath.Join(s.path, name), im)
+	}
+	if err != nil {
+		return err
+	}
+	s.indexes[name] = idx
+	s.current[live] = name
+	s.aliases[live] = bleve.NewIndexAlias(idx)
+	return nil
+}
+
+// Current 在用索引名当前指向的具体索引
+func (s *Store) Current(live string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current[live]
+}
+
+// Create 创建具体索引，用于重建索引时的影子索引
+func (s *Store) Create(name string, im mapping.IndexMapping) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exist := s.indexes[name]; exist {
+		return fmt.Errorf("bleve index %s already exists", name)
+	}
+	idx, err := bleve.New(filepath.Join(s.path, name), im)
+	if err != nil {
+		return err
+	}
+	s.indexes[name] = idx
+	return nil
+}
+
+func (s *Store) get(name string) (bleve.Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if alias, exist := s.aliases[name]; exist {
+		return alias, nil
+	}
+	if idx, exist := s.indexes[name]; exist {
+		return idx, nil
+	}
+	return nil, fmt.Errorf("bleve index %s not found", name)
+}
+
+// Write 批量写入文档，未带原始文档的以文档自身作为原始文档
+func (s *Store) Write(name string, docs []map[string]any) error {
+	idx, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	batch := idx.NewBatch()
+	for _, doc := range docs {
+		if _, exist := doc[SourceField]; !exist {
+			source, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			doc[SourceField] = string(source)
+		}
+		if err = batch.Index(DocID(doc), doc); err != nil {
+			return err
+		}
+	}
+	return idx.Batch(batch)
+}
+
+// Remove 批量删除文档
+func (s *Store) Remove(name string, ids []string) error {
+	idx, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	batch := idx.NewBatch()
+	for _, id := range ids {
+		batch.Delete(id)
+	}
+	return idx.Batch(batch)
+}
+
+// Search 在索引或在用索引名指向的索引中检索
+func (s *Store) Search(name string, request *bleve.SearchRequest) (*bleve.SearchResult, error) {
+	idx, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Search(request)
+}
+
+// Count 索引中的文档数
+func (s *Store) Count(name string) (int64, error) {
+	idx, err := s.get(name)
+	if err != nil {
+		return 0, err
+	}
+	count, err := idx.DocCount()
+	return int64(count), err
+}
+
+// Swap 别名切换到影子索引后删除旧索引
+func (s *Store) Swap(live, shadow string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alias, exist := s.aliases[live]
+	if !exist {
+		return fmt.Errorf("bleve index %s not found", live)
+	}
+	idx, exist := s.indexes[shadow]
+	if !exist {
+		return fmt.Errorf("bleve index %s not found", shadow)
+	}
+	if err := os.WriteFile(s.currentFile(live), []byte(shadow), 0o644); err != nil {
+		return err
+	}
+	old := s.current[live]
+	alias.Swap([]bleve.Index{idx}, []bleve.Index{s.indexes[old]})
+	s.current[live] = shadow
+	return s.dropLocked(old)
+}
+
+// Drop 关闭并删除具体索引
+func (s *Store) Drop(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropLocked(name)
+}
+
+// Close 关闭全部索引，释放目录锁
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for name, idx := range s.indexes {
+		if err := idx.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close bleve index %s: %w", name, err))
+		}
+	}
+	s.aliases = make(map[string]bleve.IndexAlias)
+	s.current = make(map[string]string)
+	s.indexes = make(map[string]bleve.Index)
+	return errors.Join(errs...)
+}
+
+func (s *Store) dropLocked(name string) error {
+	if idx, exist := s.indexes[name]; exist {
+		if err := idx.Close(); err != nil {
+			logrus.Warnf("close bleve index %s error: %s", name, err)
+		}
+		delete(s.indexes, name)
+	}
+	return os.RemoveAll(filepath.Join(s.path, name))
+}
+
+func (s *Store) currentFile(live string) string {
+	return filepath.Join(s.path, live+".current")
+}
+
+// DocID 文档主键，经json转换后数值字段为float64
+func DocID(doc map[string]any) string {
+	switch id := doc["id"].(type) {
+	case int64:
+		return strconv.FormatInt(id, 10)
+	case float64:
+		return strconv.FormatInt(int64(id), 10)
+	case string:
+		return id
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package blevex_test
+
+import (
+	"path/filepath"
+
+	"github.com/blevesearch/bleve/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/blevex"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		path  string
+		store *blevex.Store
+	)
+
+	search := func(name string, q string) []string {
+		mq := bleve.NewMatchQuery(q)
+		mq.SetField("name")
+		res, err := store.Search(name, bleve.NewSearchRequest(mq))
+		Expect(err).NotTo(HaveOccurred())
+		ids := make([]string, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	BeforeEach(func() {
+		var err error
+		path = GinkgoT().TempDir()
+		store, err = blevex.NewStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Open("posts", bleve.NewIndexMapping())).To(Succeed())
+		DeferCleanup(func() {
+			Expect(store.Close()).To(Succeed())
+		})
+	})
+
+	It("write, search and remove documents of the live index", func() {
+		Expect(store.Write("posts", []map[string]any{
+			{"id": int64(1), "name": "alice"},
+			{"id": float64(2), "name": "bob"},
+		})).To(Succeed())
+		Expect(store.Count("posts")).To(Equal(int64(2)))
+		Expect(search("posts", "alice")).To(Equal([]string{"1"}))
+
+		Expect(store.Remove("posts", []string{"1"})).To(Succeed())
+		Expect(store.Count("posts")).To(Equal(int64(1)))
+		Expect(search("posts", "alice")).To(BeEmpty())
+	})
+
+	It("swap the live index to a rebuilt shadow index", func() {
+		Expect(store.Write("posts", []map[string]any{{"id": int64(1), "name": "stale"}})).To(Succeed())
+		Expect(store.Create("posts-1", bleve.NewIndexMapping())).To(Succeed())
+		Expect(store.Write("posts-1", []map[string]any{{"id": int64(2), "name": "fresh"}})).To(Succeed())
+		// 切换前在用索引不受影子索引影响
+		Expect(search("posts", "fresh")).To(BeEmpty())
+
+		Expect(store.Swap("posts", "posts-1")).To(Succeed())
+		Expect(store.Current("posts")).To(Equal("posts-1"))
+		Expect(search("posts", "fresh")).To(Equal([]string{"2"}))
+		Expect(search("posts", "stale")).To(BeEmpty())
+		Expect(filepath.Join(path, "posts")).NotTo(BeADirectory())
+	})
+
+	It("reopen the live index from the swapped index", func() {
+		Expect(store.Create("posts-1", bleve.NewIndexMapping())).To(Succeed())
+		Expect(store.Write("posts-1", []map[string]any{{"id": int64(2), "name": "fresh"}})).To(Succeed())
+		Expect(store.Swap("posts", "posts-1")).To(Succeed())
+		Expect(store.Close()).To(Succeed())
+
+		Expect(store.Open("posts", bleve.NewIndexMapping())).To(Succeed())
+		Expect(store.Current("posts")).To(Equal("posts-1"))
+		Expect(search("posts", "fresh")).To(Equal([]string{"2"}))
+	})
+
+	It("reject unknown and duplicated indexes", func() {
+		Expect(store.Open("posts", bleve.NewIndexMapping())).NotTo(Succeed())
+		Expect(store.Create("posts-1", bleve.NewIndexMapping())).To(Succeed())
+		Expect(store.Create("posts-1", bleve.NewIndexMapping())).NotTo(Succeed())
+		Expect(store.Swap("users", "posts-1")).NotTo(Succeed())
+		Expect(store.Swap("posts", "posts-2")).NotTo(Succeed())
+		_, err := store.Count("users")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("drop a shadow index", func() {
+		Expect(store.Create("posts-1", bleve.NewIndexMapping())).To(Succeed())
+		Expect(store.Drop("posts-1")).To(Succeed())
+		Expect(filepath.Join(path, "posts-1")).NotTo(BeADirectory())
+		_, err := store.Count("posts-1")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("DocID", func() {
+	It("format numeric and string ids", func() {
+		Expect(blevex.DocID(map[string]any{"id": int64(12)})).To(Equal("12"))
+		Expect(blevex.DocID(map[string]any{"id": float64(12)})).To(Equal("12"))
+		Expect(blevex.DocID(map[string]any{"id": "abc"})).To(Equal("abc"))
+		Expect(blevex.DocID(map[string]any{})).To(BeEmpty())
+	})
+})
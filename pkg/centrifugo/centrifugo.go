@@ -0,0 +1,86 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package centrifugo a tiny client of Centrifugo server http api that
+// publish realtime messages into channels.
+package centrifugo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rocboss/paopao-ce/pkg/json"
+)
+
+// Client Centrifugo 服务端http api客户端
+type Client struct {
+	addr   string
+	apiKey string
+	hc     *http.Client
+}
+
+type publishReq struct {
+	Channel string `json:"channel"`
+	Data    any    `json:"data"`
+}
+
+type apiResp struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// New 创建客户端，addr 为Centrifugo服务地址，如 http://127.0.0.1:8000
+func New(addr string, apiKey string, timeout time.Duration) *Client {
+	return &Client{
+		addr:   strings.TrimRight(addr, "/"),
+		apiKey: apiKey,
+		hc: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Publish 向频道发布消息，data 序列化为json后原样推送给订阅者
+func (c *Client) Publish(ctx context.Context, channel string, data any) error {
+	body, err := json.Marshal(&publishReq{
+		Channel: channel,
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+"/api/publish", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("centrifugo: publish to %s failed: %s", channel, resp.Status)
+	}
+	var res apiResp
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return fmt.Errorf("centrifugo: publish to %s failed: %d %s", channel, res.Error.Code, res.Error.Message)
+	}
+	return nil
+}
+
+// UserChannel 用户专属频道，"#"之后的用户id限定只有该用户能订阅
+func UserChannel(namespace string, userId int64) string {
+	return fmt.Sprintf("%s:#%d", namespace, userId)
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package centrifugo_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/centrifugo"
+)
+
+var _ = Describe("Centrifugo", func() {
+	var (
+		server *httptest.Server
+		path   string
+		apiKey string
+		body   string
+		reply  string
+	)
+
+	BeforeEach(func() {
+		reply = `{"result":{}}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			apiKey = r.Header.Get("X-API-Key")
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Write([]byte(reply))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("publish data into channel", func() {
+		client := centrifugo.New(server.URL+"/", "secret", time.Second)
+		err := client.Publish(context.Background(), "dm:#1", map[string]int64{"id": 2})
+		Expect(err).To(BeNil())
+		Expect(path).To(Equal("/api/publish"))
+		Expect(apiKey).To(Equal("secret"))
+		Expect(body).To(MatchJSON(`{"channel":"dm:#1","data":{"id":2}}`))
+	})
+
+	It("return api error", func() {
+		reply = `{"error":{"code":102,"message":"unknown channel"}}`
+		client := centrifugo.New(server.URL, "secret", time.Second)
+		err := client.Publish(context.Background(), "dm:#1", nil)
+		Expect(err).To(MatchError(ContainSubstring("unknown channel")))
+	})
+
+	It("user channel", func() {
+		Expect(centrifugo.UserChannel("dm", 10)).To(Equal("dm:#10"))
+	})
+})
@@ -0,0 +1,153 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package geo provide PostGIS-free helpers for nearby queries: distance,
+// bounding box of a radius and geohash based coordinate coarsening.
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// EarthRadius 地球平均半径，单位千米
+	EarthRadius = 6371.0088
+
+	base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// Box a lat/lng bounding box, MinLng > MaxLng means the box crosses
+// the antimeridian.
+type Box struct {
+	MinLat float64
+	MaxLat float64
+	MinLng float64
+	MaxLng float64
+}
+
+// Valid check whether the coordinate is usable, (0, 0) is treated as
+// no location because it is the zero value of the stored columns.
+func Valid(lat, lng float64) bool {
+	if math.IsNaN(lat) || math.IsNaN(lng) || (lat == 0 && lng == 0) {
+		return false
+	}
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// Distance return the great-circle distance in kilometers
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox return the box that contains every point within radius
+// kilometers of (lat, lng). Points in the box still need to be filtered
+// by Distance, the box only narrows the indexed range.
+func BoundingBox(lat, lng, radius float64) Box {
+	dLat := radius / EarthRadius * 180 / math.Pi
+	box := Box{
+		MinLat: lat - dLat,
+		MaxLat: lat + dLat,
+	}
+	// 覆盖到极点时经度不再受限
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		box.MinLat, box.MaxLat = math.Max(box.MinLat, -90), math.Min(box.MaxLat, 90)
+		box.MinLng, box.MaxLng = -180, 180
+		return box
+	}
+	dLng := math.Asin(math.Min(1, math.Sin(radius/EarthRadius)/math.Cos(radians(lat)))) * 180 / math.Pi
+	if dLng >= 180 {
+		box.MinLng, box.MaxLng = -180, 180
+		return box
+	}
+	box.MinLng, box.MaxLng = lng-dLng, lng+dLng
+	if box.MinLng < -180 {
+		box.MinLng += 360
+	}
+	if box.MaxLng > 180 {
+		box.MaxLng -= 360
+	}
+	return box
+}
+
+// CrossAntimeridian whether the box crosses the antimeridian
+func (b Box) CrossAntimeridian() bool {
+	return b.MinLng > b.MaxLng
+}
+
+// Encode return the geohash of the coordinate with precision characters
+func Encode(lat, lng float64, precision int) string {
+	if precision <= 0 {
+		return ""
+	}
+	latRange, lngRange := [2]float64{-90, 90}, [2]float64{-180, 180}
+	var sb strings.Builder
+	bit, ch, even := 0, 0, true
+	for sb.Len() < precision {
+		rng, v := &latRange, lat
+		if even {
+			rng, v = &lngRange, lng
+		}
+		mid := (rng[0] + rng[1]) / 2
+		if v >= mid {
+			ch = ch<<1 | 1
+			rng[0] = mid
+		} else {
+			ch = ch << 1
+			rng[1] = mid
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// Decode return the center of the geohash cell, ok is false if the hash
+// contains invalid characters.
+func Decode(hash string) (lat float64, lng float64, ok bool) {
+	latRange, lngRange := [2]float64{-90, 90}, [2]float64{-180, 180}
+	even := true
+	for i := 0; i < len(hash); i++ {
+		idx := strings.IndexByte(base32, hash[i])
+		if idx < 0 {
+			return 0, 0, false
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			rng := &latRange
+			if even {
+				rng = &lngRange
+			}
+			mid := (rng[0] + rng[1]) / 2
+			if idx&mask != 0 {
+				rng[0] = mid
+			} else {
+				rng[1] = mid
+			}
+			even = !even
+		}
+	}
+	return (latRange[0] + latRange[1]) / 2, (lngRange[0] + lngRange[1]) / 2, true
+}
+
+// Coarsen snap the coordinate to the center of its geohash cell, so every
+// point in the same cell is reported at the same place. Precision 5 is a
+// cell about 4.9km x 4.9km, precision <= 0 keep the exact coordinate.
+func Coarsen(lat, lng float64, precision int) (float64, float64) {
+	if precision <= 0 || !Valid(lat, lng) {
+		return lat, lng
+	}
+	clat, clng, _ := Decode(Encode(lat, lng, precision))
+	return clat, clng
+}
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package geo_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/geo"
+)
+
+var _ = Describe("Geo", func() {
+	It("valid coordinate", func() {
+		Expect(geo.Valid(39.9042, 116.4074)).To(BeTrue())
+		Expect(geo.Valid(0, 0)).To(BeFalse())
+		Expect(geo.Valid(91, 0)).To(BeFalse())
+		Expect(geo.Valid(0, -181)).To(BeFalse())
+	})
+
+	It("distance between cities", func() {
+		// 北京 -> 上海 约1067km
+		d := geo.Distance(39.9042, 116.4074, 31.2304, 121.4737)
+		Expect(d).To(BeNumerically("~", 1067, 5))
+		Expect(geo.Distance(31.2304, 121.4737, 31.2304, 121.4737)).To(Equal(0.0))
+	})
+
+	It("bounding box contains the radius", func() {
+		lat, lng, radius := 31.2304, 121.4737, 10.0
+		box := geo.BoundingBox(lat, lng, radius)
+		Expect(box.CrossAntimeridian()).To(BeFalse())
+		Expect(geo.Distance(lat, lng, box.MaxLat, lng)).To(BeNumerically("~", radius, 1e-6))
+		Expect(geo.Distance(lat, lng, box.MinLat, lng)).To(BeNumerically("~", radius, 1e-6))
+		Expect(geo.Distance(lat, lng, lat, box.MaxLng)).To(BeNumerically(">=", radius))
+		Expect(geo.Distance(lat, lng, lat, box.MinLng)).To(BeNumerically(">=", radius))
+	})
+
+	It("bounding box cross antimeridian", func() {
+		box := geo.BoundingBox(-17.7134, 179.99, 50)
+		Expect(box.CrossAntimeridian()).To(BeTrue())
+		Expect(box.MinLng).To(BeNumerically(">", 179))
+		Expect(box.MaxLng).To(BeNumerically("<", -179))
+	})
+
+	It("bounding box near pole", func() {
+		box := geo.BoundingBox(89.9, 10, 50)
+		Expect(box.MaxLat).To(Equal(90.0))
+		Expect(box.MinLng).To(Equal(-180.0))
+		Expect(box.MaxLng).To(Equal(180.0))
+	})
+
+	It("encode and decode geohash", func() {
+		Expect(geo.Encode(57.64911, 10.40744, 11)).To(Equal("u4pruydqqvj"))
+		lat, lng, ok := geo.Decode("u4pruydqqvj")
+		Expect(ok).To(BeTrue())
+		Expect(lat).To(BeNumerically("~", 57.64911, 1e-5))
+		Expect(lng).To(BeNumerically("~", 10.40744, 1e-5))
+		_, _, ok = geo.Decode("u4a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("coarsen coordinate to cell center", func() {
+		lat1, lng1 := geo.Coarsen(31.23041, 121.47371, 5)
+		lat2, lng2 := geo.Coarsen(31.23101, 121.47402, 5)
+		Expect(lat1).To(Equal(lat2))
+		Expect(lng1).To(Equal(lng2))
+		Expect(geo.Distance(31.23041, 121.47371, lat1, lng1)).To(BeNumerically("<", 5))
+		lat, lng := geo.Coarsen(31.23041, 121.47371, 0)
+		Expect(lat).To(Equal(31.23041))
+		Expect(lng).To(Equal(121.47371))
+	})
+})
@@ -0,0 +1,69 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package mention parse @username mentions from text.
+// Usernames follow the register rules: 3 to 12 ASCII letters or digits.
+package mention
+
+import (
+	"unicode"
+)
+
+const (
+	minUsernameLen = 3
+	maxUsernameLen = 12
+)
+
+// Entity 文本中的一处@提及，Offset 与 Length 以 rune 计且包含 @ 符号
+type Entity struct {
+	Username string
+	Offset   int
+	Length   int
+}
+
+// Parse 按出现顺序解析文本中的@提及，@ 前紧跟字母数字时(如邮箱)不视为提及，
+// 超出用户名长度限制的也不视为提及
+func Parse(text string) []*Entity {
+	var res []*Entity
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isMentionBoundary(runes[i-1])) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isUsernameRune(runes[j]) {
+			j++
+		}
+		if size := j - i - 1; size >= minUsernameLen && size <= maxUsernameLen {
+			res = append(res, &Entity{
+				Username: string(runes[i+1 : j]),
+				Offset:   i,
+				Length:   j - i,
+			})
+		}
+		i = j - 1
+	}
+	return res
+}
+
+// Usernames 去重后的被提及用户名，保持首次出现的顺序
+func Usernames(entities []*Entity) []string {
+	seen := make(map[string]struct{}, len(entities))
+	res := make([]string, 0, len(entities))
+	for _, e := range entities {
+		if _, ok := seen[e.Username]; !ok {
+			seen[e.Username] = struct{}{}
+			res = append(res, e.Username)
+		}
+	}
+	return res
+}
+
+func isUsernameRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func isMentionBoundary(r rune) bool {
+	return r == '@' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package mention_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/mention"
+)
+
+var _ = Describe("Mention", func() {
+	It("parse mentions with rune offsets", func() {
+		entities := mention.Parse("你好 @alice, cc @bob99!")
+		Expect(entities).To(HaveLen(2))
+		Expect(*entities[0]).To(Equal(mention.Entity{Username: "alice", Offset: 3, Length: 6}))
+		Expect(*entities[1]).To(Equal(mention.Entity{Username: "bob99", Offset: 14, Length: 6}))
+	})
+
+	It("skip emails and invalid usernames", func() {
+		Expect(mention.Parse("mail me at bob@example.com")).To(BeEmpty())
+		Expect(mention.Parse("@ab @abcdefghijklm @@carol")).To(BeEmpty())
+		Expect(mention.Parse("@")).To(BeEmpty())
+	})
+
+	It("mention at start and end of text", func() {
+		entities := mention.Parse("@dave hi @erin")
+		Expect(entities).To(HaveLen(2))
+		Expect(entities[0].Offset).To(Equal(0))
+		Expect(entities[1].Username).To(Equal("erin"))
+		Expect(entities[1].Offset).To(Equal(9))
+	})
+
+	It("usernames deduplicated in order", func() {
+		entities := mention.Parse("@carol @alice @carol")
+		Expect(entities).To(HaveLen(3))
+		Expect(mention.Usernames(entities)).To(Equal([]string{"carol", "alice"}))
+	})
+})
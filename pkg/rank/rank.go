@@ -0,0 +1,193 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package rank provide pluggable rankers for personalized feeds.
+// Scores only depend on the inputs and the given time, so the same
+// viewer/candidates/now always produce the same order.
+package rank
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	RankerWeighted = "weighted"
+	RankerRecency  = "recency"
+)
+
+// Ranker score a candidate for a viewer at the time now(unix seconds)
+type Ranker interface {
+	Name() string
+	Score(v *Viewer, c *Candidate, now int64) float64
+}
+
+// Viewer the signals of the viewer used to personalize the feed
+type Viewer struct {
+	UserID     int64
+	Categories map[int64]struct{}
+	Tags       map[string]struct{}
+	Followings map[int64]struct{}
+	Reacted    map[int64]struct{}
+}
+
+// Candidate a post that could be ranked into the feed, a conversation post
+// has a visitor besides the author and both of them count for the viewer
+type Candidate struct {
+	ID               int64
+	AuthorID         int64
+	VisitorID        int64
+	AuthorCategories []int64
+	Tags             []string
+	Engagement       int64
+	AudioDuration    float64
+	CreatedOn        int64
+}
+
+// Item a ranked candidate
+type Item struct {
+	ID    int64
+	Score float64
+}
+
+// Weights the weights of signals used by weighted ranker
+type Weights struct {
+	Category   float64
+	Topic      float64
+	Following  float64
+	Reacted    float64
+	Engagement float64
+	Audio      float64
+	// HalfLife 时间衰减半衰期，单位秒
+	HalfLife int64
+	// MaxAudioDuration 音频时长加分上限，单位秒
+	MaxAudioDuration float64
+}
+
+type weightedRanker struct {
+	w Weights
+}
+
+type recencyRanker struct{}
+
+// DefaultWeights return the default weights of weighted ranker
+func DefaultWeights() Weights {
+	return Weights{
+		Category:         1.0,
+		Topic:            1.5,
+		Following:        3.0,
+		Reacted:          2.0,
+		Engagement:       1.0,
+		Audio:            1.0,
+		HalfLife:         6 * 3600,
+		MaxAudioDuration: 300,
+	}
+}
+
+// NewWeightedRanker return a ranker that combines viewer affinity, engagement
+// and audio duration, then decays the score by the age of the candidate.
+func NewWeightedRanker(w Weights) Ranker {
+	if w.HalfLife <= 0 {
+		w.HalfLife = DefaultWeights().HalfLife
+	}
+	if w.MaxAudioDuration <= 0 {
+		w.MaxAudioDuration = DefaultWeights().MaxAudioDuration
+	}
+	return &weightedRanker{w: w}
+}
+
+// NewRecencyRanker return a ranker that only ranks candidates by created time
+func NewRecencyRanker() Ranker {
+	return recencyRanker{}
+}
+
+// New return a ranker by name, fallback to weighted ranker with default weights,
+// halfLife overrides the default half life of weighted ranker if positive
+func New(name string, halfLife int64) Ranker {
+	switch name {
+	case RankerRecency:
+		return NewRecencyRanker()
+	default:
+		w := DefaultWeights()
+		if halfLife > 0 {
+			w.HalfLife = halfLife
+		}
+		return NewWeightedRanker(w)
+	}
+}
+
+func (r *weightedRanker) Name() string {
+	return RankerWeighted
+}
+
+func (r *weightedRanker) Score(v *Viewer, c *Candidate, now int64) float64 {
+	w, affinity := r.w, 0.0
+	if v != nil {
+		for _, id := range c.AuthorCategories {
+			if _, ok := v.Categories[id]; ok {
+				affinity += w.Category
+			}
+		}
+		for _, tag := range c.Tags {
+			if _, ok := v.Tags[tag]; ok {
+				affinity += w.Topic
+			}
+		}
+		if hasEither(v.Followings, c.AuthorID, c.VisitorID) {
+			affinity += w.Following
+		}
+		if hasEither(v.Reacted, c.AuthorID, c.VisitorID) {
+			affinity += w.Reacted
+		}
+	}
+	engagement := 0.0
+	if c.Engagement > 0 {
+		engagement = w.Engagement * math.Log1p(float64(c.Engagement))
+	}
+	audio := w.Audio * math.Min(math.Max(c.AudioDuration, 0), w.MaxAudioDuration) / w.MaxAudioDuration
+	age := now - c.CreatedOn
+	if age < 0 {
+		age = 0
+	}
+	decay := math.Pow(0.5, float64(age)/float64(w.HalfLife))
+	return (1 + affinity + engagement + audio) * decay
+}
+
+func hasEither(set map[int64]struct{}, authorId, visitorId int64) bool {
+	if _, ok := set[authorId]; ok {
+		return true
+	}
+	if visitorId > 0 {
+		_, ok := set[visitorId]
+		return ok
+	}
+	return false
+}
+
+func (recencyRanker) Name() string {
+	return RankerRecency
+}
+
+func (recencyRanker) Score(_ *Viewer, c *Candidate, _ int64) float64 {
+	return float64(c.CreatedOn)
+}
+
+// Rank score all candidates and sort them by score desc, the ties are
+// broken by candidate id desc so the order is always deterministic.
+func Rank(r Ranker, v *Viewer, candidates []*Candidate, now int64) []Item {
+	items := make([]Item, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, Item{
+			ID:    c.ID,
+			Score: r.Score(v, c, now),
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].ID > items[j].ID
+	})
+	return items
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rank_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/rank"
+)
+
+var _ = Describe("Rank", func() {
+	const now int64 = 1700000000
+
+	viewer := &rank.Viewer{
+		UserID:     1,
+		Categories: map[int64]struct{}{10: {}},
+		Tags:       map[string]struct{}{"music": {}},
+		Followings: map[int64]struct{}{2: {}},
+		Reacted:    map[int64]struct{}{3: {}},
+	}
+
+	It("weighted score order by signals", func() {
+		r := rank.NewWeightedRanker(rank.DefaultWeights())
+		Expect(r.Name()).To(Equal(rank.RankerWeighted))
+		items := rank.Rank(r, viewer, []*rank.Candidate{
+			{ID: 1, AuthorID: 9, CreatedOn: now - 3600},
+			{ID: 2, AuthorID: 9, Engagement: 12, CreatedOn: now - 3600},
+			{ID: 3, AuthorID: 9, AuthorCategories: []int64{10}, CreatedOn: now - 3600},
+			{ID: 4, AuthorID: 9, Engagement: 12, AudioDuration: 60, CreatedOn: now - 3600},
+			{ID: 5, AuthorID: 9, Engagement: 12, AudioDuration: 60, CreatedOn: now},
+		}, now)
+		ids := make([]int64, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		Expect(ids).To(Equal([]int64{5, 4, 2, 3, 1}))
+	})
+
+	It("weighted score count conversation visitor", func() {
+		r := rank.NewWeightedRanker(rank.DefaultWeights())
+		host := &rank.Candidate{ID: 1, AuthorID: 2, CreatedOn: now}
+		visitor := &rank.Candidate{ID: 2, AuthorID: 9, VisitorID: 2, CreatedOn: now}
+		both := &rank.Candidate{ID: 3, AuthorID: 2, VisitorID: 2, CreatedOn: now}
+		stranger := &rank.Candidate{ID: 4, AuthorID: 9, VisitorID: 8, CreatedOn: now}
+		Expect(r.Score(viewer, visitor, now)).To(Equal(r.Score(viewer, host, now)))
+		Expect(r.Score(viewer, both, now)).To(Equal(r.Score(viewer, host, now)))
+		Expect(r.Score(viewer, stranger, now)).To(Equal(1.0))
+	})
+
+	It("weighted score with no signals", func() {
+		r := rank.NewWeightedRanker(rank.DefaultWeights())
+		c := &rank.Candidate{ID: 1, AuthorID: 9, CreatedOn: now}
+		Expect(r.Score(nil, c, now)).To(Equal(1.0))
+		Expect(r.Score(viewer, c, now)).To(Equal(1.0))
+	})
+
+	It("weighted score decay by half life", func() {
+		w := rank.DefaultWeights()
+		r := rank.NewWeightedRanker(w)
+		fresh := &rank.Candidate{ID: 1, AuthorID: 9, CreatedOn: now}
+		old := &rank.Candidate{ID: 2, AuthorID: 9, CreatedOn: now - w.HalfLife}
+		Expect(r.Score(viewer, old, now)).To(BeNumerically("~", r.Score(viewer, fresh, now)/2, 1e-9))
+	})
+
+	It("weighted score cap audio duration", func() {
+		w := rank.DefaultWeights()
+		r := rank.NewWeightedRanker(w)
+		long := &rank.Candidate{ID: 1, AuthorID: 9, AudioDuration: w.MaxAudioDuration * 10, CreatedOn: now}
+		Expect(r.Score(nil, long, now)).To(Equal(1 + w.Audio))
+	})
+
+	It("rank personalized candidates first", func() {
+		r := rank.NewWeightedRanker(rank.DefaultWeights())
+		items := rank.Rank(r, viewer, []*rank.Candidate{
+			{ID: 1, AuthorID: 9, CreatedOn: now},
+			{ID: 2, AuthorID: 3, CreatedOn: now},
+			{ID: 3, AuthorID: 2, CreatedOn: now},
+			{ID: 4, AuthorID: 9, Tags: []string{"music"}, CreatedOn: now},
+		}, now)
+		ids := make([]int64, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		Expect(ids).To(Equal([]int64{3, 2, 4, 1}))
+	})
+
+	It("rank break ties by id", func() {
+		items := rank.Rank(rank.NewWeightedRanker(rank.DefaultWeights()), nil, []*rank.Candidate{
+			{ID: 1, CreatedOn: now},
+			{ID: 3, CreatedOn: now},
+			{ID: 2, CreatedOn: now},
+		}, now)
+		Expect(items).To(HaveLen(3))
+		Expect(items[0].ID).To(Equal(int64(3)))
+		Expect(items[1].ID).To(Equal(int64(2)))
+		Expect(items[2].ID).To(Equal(int64(1)))
+	})
+
+	It("recency ranker", func() {
+		r := rank.New(rank.RankerRecency, 0)
+		Expect(r.Name()).To(Equal(rank.RankerRecency))
+		items := rank.Rank(r, viewer, []*rank.Candidate{
+			{ID: 1, AuthorID: 2, CreatedOn: now - 10},
+			{ID: 2, AuthorID: 9, CreatedOn: now},
+		}, now)
+		Expect(items[0].ID).To(Equal(int64(2)))
+	})
+
+	It("new fallback to weighted ranker", func() {
+		Expect(rank.New("unknown", 0).Name()).To(Equal(rank.RankerWeighted))
+	})
+
+	It("new weighted ranker with half life", func() {
+		r := rank.New(rank.RankerWeighted, 3600)
+		fresh := &rank.Candidate{ID: 1, AuthorID: 9, CreatedOn: now}
+		old := &rank.Candidate{ID: 2, AuthorID: 9, CreatedOn: now - 3600}
+		Expect(r.Score(nil, old, now)).To(BeNumerically("~", r.Score(nil, fresh, now)/2, 1e-9))
+	})
+
+	It("wilson score prefers confident ratio", func() {
+		Expect(rank.Wilson(0, 0)).To(BeZero())
+		Expect(rank.Wilson(1, 0)).To(BeNumerically("<", rank.Wilson(90, 10)))
+		Expect(rank.Wilson(90, 10)).To(BeNumerically(">", rank.Wilson(9, 1)))
+		Expect(rank.Wilson(5, 5)).To(BeNumerically("<", 0.5))
+		Expect(rank.Wilson(100, 0)).To(BeNumerically("~", 0.963, 0.001))
+	})
+
+	It("collapse heavily downvoted replies", func() {
+		Expect(rank.Collapsed(0, 3)).To(BeFalse())
+		Expect(rank.Collapsed(0, 5)).To(BeTrue())
+		Expect(rank.Collapsed(10, 12)).To(BeFalse())
+		Expect(rank.Collapsed(2, 20)).To(BeTrue())
+	})
+})
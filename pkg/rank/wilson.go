@@ -0,0 +1,33 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rank
+
+import (
+	"math"
+)
+
+const (
+	// wilsonZ 95%置信度对应的正态分位数
+	wilsonZ = 1.96
+	// collapseRatio 点踩比例的置信下界达到该值时折叠回复
+	collapseRatio = 0.5
+)
+
+// Wilson return the lower bound of Wilson score interval of the positive ratio,
+// 票数少时得分偏低，避免一两个赞的评论排在大量好评的评论之前
+func Wilson(up int64, down int64) float64 {
+	n := float64(up + down)
+	if up < 0 || down < 0 || n == 0 {
+		return 0
+	}
+	p, z2 := float64(up)/n, wilsonZ*wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// Collapsed report whether a reply should be collapsed,
+// 有足够把握认为多数人点踩时折叠，即点踩比例的 Wilson 下界不低于一半
+func Collapsed(up int64, down int64) bool {
+	return Wilson(down, up) >= collapseRatio
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package suggest score "people you may know" candidates from social signals.
+// Scores only depend on the signals and weights, so the same inputs always
+// produce the same order.
+package suggest
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	ReasonContact       = "contact"
+	ReasonMutualFriends = "mutual_friends"
+	ReasonMutualFollows = "mutual_follows"
+	ReasonReactions     = "reactions"
+	ReasonCategories    = "categories"
+)
+
+// Signals the signals between the viewer and a candidate
+type Signals struct {
+	UserID int64
+	// Contact 候选人在用户通讯录中
+	Contact          bool
+	MutualFriends    int
+	MutualFollows    int
+	SharedCategories int
+	// Reactions 用户给候选人的正向反应数
+	Reactions int
+}
+
+// Weights the weights of signals
+type Weights struct {
+	Contact      float64
+	MutualFriend float64
+	MutualFollow float64
+	Category     float64
+	Reaction     float64
+}
+
+// Item a ranked candidate
+type Item struct {
+	UserID      int64
+	Score       float64
+	Reason      string
+	Explanation string
+}
+
+// DefaultWeights return the default weights
+func DefaultWeights() Weights {
+	return Weights{
+		Contact:      10,
+		MutualFriend: 3,
+		MutualFollow: 1,
+		Category:     0.5,
+		Reaction:     2,
+	}
+}
+
+// Score score the signals with weights
+func Score(w Weights, s *Signals) float64 {
+	score := w.MutualFriend*float64(s.MutualFriends) +
+		w.MutualFollow*float64(s.MutualFollows) +
+		w.Category*float64(s.SharedCategories) +
+		w.Reaction*float64(s.Reactions)
+	if s.Contact {
+		score += w.Contact
+	}
+	return score
+}
+
+// Explain return the main reason and a human readable explanation,
+// 优先级为 通讯录 > 共同好友 > 共同关注 > 正向反应 > 相同分类
+func Explain(s *Signals) (string, string) {
+	switch {
+	case s.Contact:
+		return ReasonContact, "In your contacts"
+	case s.MutualFriends > 0:
+		return ReasonMutualFriends, plural(s.MutualFriends, "mutual friend")
+	case s.MutualFollows > 0:
+		return ReasonMutualFollows, plural(s.MutualFollows, "mutual follow")
+	case s.Reactions > 0:
+		return ReasonReactions, "You reacted to them " + plural(s.Reactions, "time")
+	case s.SharedCategories > 0:
+		return ReasonCategories, plural(s.SharedCategories, "shared interest")
+	}
+	return "", ""
+}
+
+// Rank score all signals and return the top limit items, 零分的候选人被丢弃，
+// 同分时按用户ID升序保证顺序稳定
+func Rank(w Weights, signals []*Signals, limit int) []*Item {
+	res := make([]*Item, 0, len(signals))
+	for _, s := range signals {
+		score := Score(w, s)
+		if score <= 0 {
+			continue
+		}
+		reason, explanation := Explain(s)
+		res = append(res, &Item{
+			UserID:      s.UserID,
+			Score:       score,
+			Reason:      reason,
+			Explanation: explanation,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].UserID < res[j].UserID
+	})
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package suggest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/suggest"
+)
+
+var _ = Describe("Suggest", func() {
+	w := suggest.DefaultWeights()
+
+	It("score sums weighted signals", func() {
+		s := &suggest.Signals{UserID: 2, Contact: true, MutualFriends: 2, MutualFollows: 1}
+		Expect(suggest.Score(w, s)).To(Equal(w.Contact + 2*w.MutualFriend + w.MutualFollow))
+		Expect(suggest.Score(w, &suggest.Signals{UserID: 3})).To(BeZero())
+	})
+
+	It("explain by priority", func() {
+		reason, text := suggest.Explain(&suggest.Signals{MutualFriends: 3, MutualFollows: 5})
+		Expect(reason).To(Equal(suggest.ReasonMutualFriends))
+		Expect(text).To(Equal("3 mutual friends"))
+
+		reason, text = suggest.Explain(&suggest.Signals{Contact: true, MutualFriends: 3})
+		Expect(reason).To(Equal(suggest.ReasonContact))
+		Expect(text).To(Equal("In your contacts"))
+
+		reason, text = suggest.Explain(&suggest.Signals{MutualFollows: 1, SharedCategories: 2})
+		Expect(reason).To(Equal(suggest.ReasonMutualFollows))
+		Expect(text).To(Equal("1 mutual follow"))
+
+		reason, text = suggest.Explain(&suggest.Signals{SharedCategories: 2})
+		Expect(reason).To(Equal(suggest.ReasonCategories))
+		Expect(text).To(Equal("2 shared interests"))
+	})
+
+	It("rank drops zero score and keeps stable order", func() {
+		items := suggest.Rank(w, []*suggest.Signals{
+			{UserID: 5, MutualFollows: 1},
+			{UserID: 4},
+			{UserID: 3, MutualFollows: 1},
+			{UserID: 2, Contact: true},
+		}, 0)
+		Expect(items).To(HaveLen(3))
+		Expect(items[0].UserID).To(Equal(int64(2)))
+		Expect(items[1].UserID).To(Equal(int64(3)))
+		Expect(items[2].UserID).To(Equal(int64(5)))
+	})
+
+	It("rank respects limit", func() {
+		items := suggest.Rank(w, []*suggest.Signals{
+			{UserID: 1, MutualFriends: 1},
+			{UserID: 2, MutualFriends: 2},
+			{UserID: 3, MutualFriends: 3},
+		}, 2)
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].UserID).To(Equal(int64(3)))
+		Expect(items[0].Explanation).To(Equal("3 mutual friends"))
+	})
+})
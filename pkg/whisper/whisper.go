@@ -0,0 +1,110 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package whisper run a local whisper.cpp style binary to transcribe audio
+// files into time-coded segments.
+package whisper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rocboss/paopao-ce/pkg/json"
+)
+
+// Options whisper 命令行参数，FFmpeg 不为空时先把音频转为16kHz单声道wav
+type Options struct {
+	Binary   string
+	Model    string
+	Language string
+	Threads  int
+	FFmpeg   string
+}
+
+// Segment 转写片段，From/To 单位毫秒
+type Segment struct {
+	From int64
+	To   int64
+	Text string
+}
+
+type output struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// Transcribe 转写音频文件，中间文件写在音频文件所在目录并在返回前清理
+func Transcribe(ctx context.Context, opts *Options, audioFile string) ([]*Segment, error) {
+	input := audioFile
+	if opts.FFmpeg != "" {
+		input = audioFile + ".wav"
+		defer os.Remove(input)
+		if err := run(ctx, opts.FFmpeg, "-y", "-i", audioFile, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", input); err != nil {
+			return nil, fmt.Errorf("whisper: convert audio failed: %w", err)
+		}
+	}
+	prefix := strings.TrimSuffix(input, filepath.Ext(input)) + ".whisper"
+	defer os.Remove(prefix + ".json")
+	args := []string{"-m", opts.Model, "-f", input, "-oj", "-of", prefix, "-np"}
+	if opts.Language != "" {
+		args = append(args, "-l", opts.Language)
+	}
+	if opts.Threads > 0 {
+		args = append(args, "-t", strconv.Itoa(opts.Threads))
+	}
+	if err := run(ctx, opts.Binary, args...); err != nil {
+		return nil, fmt.Errorf("whisper: transcribe failed: %w", err)
+	}
+	data, err := os.ReadFile(prefix + ".json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseOutput(data)
+}
+
+// ParseOutput 解析 whisper -oj 输出的json，去掉空白片段
+func ParseOutput(data []byte) ([]*Segment, error) {
+	var out output
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	res := make([]*Segment, 0, len(out.Transcription))
+	for _, t := range out.Transcription {
+		text := strings.TrimSpace(t.Text)
+		if text == "" {
+			continue
+		}
+		res = append(res, &Segment{
+			From: t.Offsets.From,
+			To:   t.Offsets.To,
+			Text: text,
+		})
+	}
+	return res, nil
+}
+
+func run(ctx context.Context, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 256 {
+			msg = msg[len(msg)-256:]
+		}
+		return fmt.Errorf("%s: %w: %s", filepath.Base(name), err, msg)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 ROC. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package whisper_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rocboss/paopao-ce/pkg/whisper"
+)
+
+const sampleOutput = `{
+  "result": {"language": "en"},
+  "transcription": [
+    {"timestamps": {"from": "00:00:00,000", "to": "00:00:02,500"}, "offsets": {"from": 0, "to": 2500}, "text": " Hello there."},
+    {"timestamps": {"from": "00:00:02,500", "to": "00:00:03,000"}, "offsets": {"from": 2500, "to": 3000}, "text": "  "},
+    {"timestamps": {"from": "00:00:03,000", "to": "00:00:05,120"}, "offsets": {"from": 3000, "to": 5120}, "text": " How are you?"}
+  ]
+}`
+
+// fakeBinary 模拟 whisper 命令，把样例输出写到 -of 指定的文件
+const fakeBinary = `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-of" ]; then out="$2"; fi
+  shift
+done
+cat > "$out.json" <<'EOF'
+` + sampleOutput + `
+EOF
+`
+
+var _ = Describe("Whisper", func() {
+	It("parse output", func() {
+		segments, err := whisper.ParseOutput([]byte(sampleOutput))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(segments).To(HaveLen(2))
+		Expect(*segments[0]).To(Equal(whisper.Segment{From: 0, To: 2500, Text: "Hello there."}))
+		Expect(*segments[1]).To(Equal(whisper.Segment{From: 3000, To: 5120, Text: "How are you?"}))
+	})
+
+	It("parse invalid output", func() {
+		_, err := whisper.ParseOutput([]byte("not json"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("transcribe with binary", func() {
+		dir := GinkgoT().TempDir()
+		binary := filepath.Join(dir, "whisper-cli")
+		Expect(os.WriteFile(binary, []byte(fakeBinary), 0o755)).To(Succeed())
+		audio := filepath.Join(dir, "audio.wav")
+		Expect(os.WriteFile(audio, []byte("RIFF"), 0o644)).To(Succeed())
+
+		segments, err := whisper.Transcribe(context.Background(), &whisper.Options{
+			Binary: binary,
+			Model:  "ggml-base.bin",
+		}, audio)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(segments).To(HaveLen(2))
+		Expect(segments[1].Text).To(Equal("How are you?"))
+		_, err = os.Stat(filepath.Join(dir, "audio.whisper.json"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("transcribe with failed binary", func() {
+		_, err := whisper.Transcribe(context.Background(), &whisper.Options{
+			Binary: "false",
+		}, "audio.wav")
+		Expect(err).To(HaveOccurred())
+	})
+})
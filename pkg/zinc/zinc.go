@@ -102,6 +102,20 @@ func (c *ZincClient) CreateIndex(name string, p *ZincIndexProperty) bool {
 	return true
 }
 
+// 删除索引
+func (c *ZincClient) DeleteIndex(name string) error {
+	resp, err := c.request().Delete(fmt.Sprintf("/api/index/%s", name))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return errors.New(resp.Status())
+	}
+
+	return nil
+}
+
 // 检查索引是否存在
 func (c *ZincClient) ExistIndex(name string) bool {
 	resp, err := c.request().Get("/api/index")
@@ -211,6 +225,78 @@ func (c *ZincClient) DelDoc(indexName, id string) error {
 	return nil
 }
 
+// 获取索引文档数
+func (c *ZincClient) IndexDocNum(name string) (int64, error) {
+	resp, err := c.request().Get(fmt.Sprintf("/api/index/%s", name))
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return 0, errors.New(resp.Status())
+	}
+
+	result := &struct {
+		Stats struct {
+			DocNum int64 `json:"doc_num"`
+		} `json:"stats"`
+	}{}
+	if err = json.Unmarshal(resp.Body(), result); err != nil {
+		return 0, err
+	}
+
+	return result.Stats.DocNum, nil
+}
+
+// 获取别名指向的索引，别名不存在时返回空
+func (c *ZincClient) AliasIndexes(alias string) ([]string, error) {
+	resp, err := c.request().Get(fmt.Sprintf("/es/_alias/%s", alias))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, errors.New(resp.Status())
+	}
+
+	result := map[string]any{}
+	if err = json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, err
+	}
+	indexes := make([]string, 0, len(result))
+	for name := range result {
+		indexes = append(indexes, name)
+	}
+
+	return indexes, nil
+}
+
+// 在一次请求中把别名从 removes 切换到 index
+func (c *ZincClient) SwitchAlias(alias string, index string, removes ...string) error {
+	actions := make([]map[string]any, 0, len(removes)+1)
+	for _, name := range removes {
+		actions = append(actions, map[string]any{
+			"remove": map[string]string{"index": name, "alias": alias},
+		})
+	}
+	actions = append(actions, map[string]any{
+		"add": map[string]string{"index": index, "alias": alias},
+	})
+	resp, err := c.request().SetBody(map[string]any{"actions": actions}).Post("/es/_aliases")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return errors.New(resp.Status())
+	}
+
+	return nil
+}
+
 func (c *ZincClient) request() *resty.Request {
 	client := resty.New()
 	client.DisableWarn = true
@@ -0,0 +1,12 @@
+-- Rollback: Remove coordinates from p_post and drop p_room
+
+DROP TABLE IF EXISTS `p_room`;
+DROP INDEX `idx_post_location_lat_lng` ON `p_post`;
+ALTER TABLE `p_post`
+DROP COLUMN `location_name`,
+DROP COLUMN `location_lat`,
+DROP COLUMN `location_lng`,
+DROP COLUMN `location_address`,
+DROP COLUMN `location_city`,
+DROP COLUMN `location_state`,
+DROP COLUMN `location_country`;
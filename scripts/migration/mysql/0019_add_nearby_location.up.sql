@@ -0,0 +1,34 @@
+-- Migration: Add coordinates to p_post and create p_room with coordinates for nearby tweets and rooms
+-- Same columns and (lat, lng) bounding-box indexes as postgres 0031 and 0038, no spatial extension required
+ALTER TABLE `p_post`
+ADD COLUMN `location_name` varchar(255) NOT NULL DEFAULT '' COMMENT '位置名称',
+ADD COLUMN `location_lat` DECIMAL(10, 8) NOT NULL DEFAULT 0 COMMENT '纬度',
+ADD COLUMN `location_lng` DECIMAL(11, 8) NOT NULL DEFAULT 0 COMMENT '经度',
+ADD COLUMN `location_address` varchar(512) NOT NULL DEFAULT '' COMMENT '详细地址',
+ADD COLUMN `location_city` varchar(100) NOT NULL DEFAULT '' COMMENT '城市',
+ADD COLUMN `location_state` varchar(100) NOT NULL DEFAULT '' COMMENT '省/州',
+ADD COLUMN `location_country` varchar(100) NOT NULL DEFAULT '' COMMENT '国家';
+CREATE INDEX `idx_post_location_lat_lng` ON `p_post` (`location_lat`, `location_lng`) USING BTREE;
+
+CREATE TABLE `p_room` (
+	`id` bigint unsigned NOT NULL AUTO_INCREMENT,
+	`host_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '房主ID',
+	`hms_room_id` varchar(255) DEFAULT NULL COMMENT 'HMS房间ID',
+	`speaker_ids` json DEFAULT NULL COMMENT '发言人ID',
+	`start_time` bigint DEFAULT NULL COMMENT '开始时间',
+	`queue` json DEFAULT NULL COMMENT '发言队列',
+	`is_blocked_from_space` tinyint NOT NULL DEFAULT 0 COMMENT '是否被封禁 0 为正常、1 为已封禁',
+	`topics` json DEFAULT NULL COMMENT '话题',
+	`categories` json DEFAULT NULL COMMENT '分类ID',
+	`location_lat` DECIMAL(10, 8) NOT NULL DEFAULT 0 COMMENT '纬度',
+	`location_lng` DECIMAL(11, 8) NOT NULL DEFAULT 0 COMMENT '经度',
+	`created_on` bigint unsigned NOT NULL DEFAULT 0,
+	`modified_on` bigint unsigned NOT NULL DEFAULT 0,
+	`deleted_on` bigint unsigned NOT NULL DEFAULT 0,
+	`is_del` tinyint NOT NULL DEFAULT 0,
+	PRIMARY KEY (`id`) USING BTREE,
+	KEY `idx_room_host` (`host_id`) USING BTREE,
+	KEY `idx_room_hms` (`hms_room_id`) USING BTREE,
+	KEY `idx_room_created` (`created_on`) USING BTREE,
+	KEY `idx_room_location_lat_lng` (`location_lat`, `location_lng`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='房间';
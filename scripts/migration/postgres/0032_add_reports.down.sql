@@ -0,0 +1,4 @@
+-- Rollback: Remove reports and moderation logs tables
+
+DROP TABLE IF EXISTS p_moderation_logs CASCADE;
+DROP TABLE IF EXISTS p_reports CASCADE;
@@ -0,0 +1,53 @@
+-- Migration: Add reports and moderation logs tables
+-- Users can report posts, comments, rooms and users; admins triage them in a moderation queue
+
+CREATE TABLE p_reports (
+    id BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL,                -- who filed the report
+    target_type VARCHAR(16) NOT NULL,           -- 'post', 'comment', 'room' or 'user'
+    target_id BIGINT NOT NULL,                  -- id of the reported object
+    target_user_id BIGINT NOT NULL DEFAULT 0,   -- owner of the reported object
+    reason VARCHAR(32) NOT NULL,                -- reason code, e.g. 'spam'
+    evidence JSONB NOT NULL DEFAULT '{}',       -- description and attachment urls
+    status SMALLINT NOT NULL DEFAULT 1,         -- 1 pending, 2 triaged, 3 dismissed, 4 actioned
+    handler_id BIGINT NOT NULL DEFAULT 0,       -- moderator who handled the report
+    resolution VARCHAR(32) NOT NULL DEFAULT '', -- action taken to resolve the report
+    handled_on BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0,
+
+    CONSTRAINT fk_reports_reporter
+        FOREIGN KEY (reporter_id) REFERENCES p_user(id),
+
+    CONSTRAINT chk_report_target_type_valid
+        CHECK (target_type IN ('post', 'comment', 'room', 'user'))
+);
+
+CREATE INDEX idx_reports_status ON p_reports(status, is_del);
+CREATE INDEX idx_reports_target ON p_reports(target_type, target_id);
+CREATE INDEX idx_reports_reporter ON p_reports(reporter_id);
+CREATE INDEX idx_reports_target_user ON p_reports(target_user_id);
+
+CREATE TABLE p_moderation_logs (
+    id BIGSERIAL PRIMARY KEY,
+    operator_id BIGINT NOT NULL,                -- moderator who performed the action
+    report_id BIGINT NOT NULL DEFAULT 0,        -- related report, 0 if none
+    action VARCHAR(32) NOT NULL,                -- action performed
+    target_type VARCHAR(16) NOT NULL DEFAULT '',
+    target_id BIGINT NOT NULL DEFAULT 0,
+    target_user_id BIGINT NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_moderation_logs_report ON p_moderation_logs(report_id);
+CREATE INDEX idx_moderation_logs_operator ON p_moderation_logs(operator_id);
+CREATE INDEX idx_moderation_logs_target_user ON p_moderation_logs(target_user_id);
+
+COMMENT ON TABLE p_reports IS 'User reports about posts, comments, rooms and users';
+COMMENT ON TABLE p_moderation_logs IS 'Audit log of every moderation action';
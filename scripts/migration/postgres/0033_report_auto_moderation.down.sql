@@ -0,0 +1,10 @@
+-- Rollback: Reports are filed by users again and cannot target comment replies
+
+DELETE FROM p_reports WHERE reporter_id = 0 OR target_type = 'reply';
+
+ALTER TABLE p_reports DROP CONSTRAINT IF EXISTS chk_report_target_type_valid;
+ALTER TABLE p_reports ADD CONSTRAINT chk_report_target_type_valid
+    CHECK (target_type IN ('post', 'comment', 'room', 'user'));
+
+ALTER TABLE p_reports ADD CONSTRAINT fk_reports_reporter
+    FOREIGN KEY (reporter_id) REFERENCES p_user(id);
@@ -0,0 +1,8 @@
+-- Migration: Allow content moderation to file reports into the moderation queue
+-- Auto flagged reports are filed by the system (reporter_id 0) and may target comment replies
+
+ALTER TABLE p_reports DROP CONSTRAINT IF EXISTS fk_reports_reporter;
+
+ALTER TABLE p_reports DROP CONSTRAINT IF EXISTS chk_report_target_type_valid;
+ALTER TABLE p_reports ADD CONSTRAINT chk_report_target_type_valid
+    CHECK (target_type IN ('post', 'comment', 'reply', 'room', 'user'));
@@ -0,0 +1,4 @@
+-- Rollback: Remove account export jobs and scheduled account deletions
+
+DROP TABLE IF EXISTS p_account_deletions CASCADE;
+DROP TABLE IF EXISTS p_account_exports CASCADE;
@@ -0,0 +1,36 @@
+-- Migration: Add account export jobs and scheduled account deletions
+-- Users can download an archive of their data and delete their account after a grace period
+
+CREATE TABLE p_account_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 1,         -- 1 pending, 2 processing, 3 finished, 4 failed
+    object_key VARCHAR(255) NOT NULL DEFAULT '', -- archive object key in object storage
+    size BIGINT NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',            -- failure reason
+    finished_on BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_account_exports_user ON p_account_exports(user_id, status);
+
+CREATE TABLE p_account_deletions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 1,         -- 1 scheduled, 2 canceled, 3 done
+    scheduled_on BIGINT NOT NULL DEFAULT 0,     -- deletion is executed after this time
+    executed_on BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_account_deletions_user ON p_account_deletions(user_id, status);
+CREATE INDEX idx_account_deletions_due ON p_account_deletions(status, scheduled_on);
+
+COMMENT ON TABLE p_account_exports IS 'Account data export jobs';
+COMMENT ON TABLE p_account_deletions IS 'Scheduled account deletions with grace period';
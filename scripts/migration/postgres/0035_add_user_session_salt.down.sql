@@ -0,0 +1,2 @@
+-- Remove session salt from user table
+ALTER TABLE p_user DROP COLUMN IF EXISTS session_salt;
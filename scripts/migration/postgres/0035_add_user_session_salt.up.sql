@@ -0,0 +1,3 @@
+-- Add session salt to user table, rotating it forces all sessions of the user to log out
+-- without touching the password salt
+ALTER TABLE p_user ADD COLUMN session_salt VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Rollback: Remove role based access control
+
+DROP TABLE IF EXISTS p_user_roles CASCADE;
+DROP TABLE IF EXISTS p_role_permissions CASCADE;
+DROP TABLE IF EXISTS p_roles CASCADE;
@@ -0,0 +1,74 @@
+-- Migration: Add role based access control
+-- Roles are named sets of permissions and can be assigned per user,
+-- users with is_admin keep all permissions and are the only ones allowed to manage roles
+
+CREATE TABLE p_roles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(32) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_roles_name ON p_roles(name) WHERE is_del = 0;
+
+CREATE TABLE p_role_permissions (
+    id BIGSERIAL PRIMARY KEY,
+    role_id BIGINT NOT NULL REFERENCES p_roles(id) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_role_permissions_role ON p_role_permissions(role_id, permission) WHERE is_del = 0;
+
+CREATE TABLE p_user_roles (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL REFERENCES p_roles(id) ON DELETE CASCADE,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_user_roles_user ON p_user_roles(user_id, role_id) WHERE is_del = 0;
+
+-- Built-in roles
+INSERT INTO p_roles (name, description, created_on, modified_on) VALUES
+    ('admin', 'Full access to all admin capabilities', EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT),
+    ('moderator', 'Handle reports, moderate content, rooms and users', EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT),
+    ('support', 'Look up users and site statistics', EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT),
+    ('room_host_verified', 'Verified room host', EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT);
+
+INSERT INTO p_role_permissions (role_id, permission, created_on, modified_on)
+SELECT r.id, p.permission, EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT
+FROM p_roles r
+JOIN (VALUES
+    ('admin', 'admin.console'),
+    ('admin', 'user.view'),
+    ('admin', 'user.suspend'),
+    ('admin', 'content.manage'),
+    ('admin', 'tweet.stick'),
+    ('admin', 'tweet.view_all'),
+    ('admin', 'report.handle'),
+    ('admin', 'room.manage'),
+    ('admin', 'site.stats'),
+    ('admin', 'search.manage'),
+    ('admin', 'activation.create'),
+    ('moderator', 'admin.console'),
+    ('moderator', 'user.view'),
+    ('moderator', 'user.suspend'),
+    ('moderator', 'content.manage'),
+    ('moderator', 'tweet.view_all'),
+    ('moderator', 'report.handle'),
+    ('moderator', 'room.manage'),
+    ('support', 'admin.console'),
+    ('support', 'user.view'),
+    ('support', 'site.stats'),
+    ('room_host_verified', 'room.host_verified')
+) AS p(role, permission) ON p.role = r.name;
@@ -0,0 +1,5 @@
+-- Rollback: Remove cursor pagination indexes
+
+DROP INDEX IF EXISTS idx_user_reactions_reactor_cursor;
+DROP INDEX IF EXISTS idx_user_reactions_cursor;
+DROP INDEX IF EXISTS idx_post_cursor;
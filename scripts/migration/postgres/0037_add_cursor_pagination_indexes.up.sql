@@ -0,0 +1,6 @@
+-- Migration: Add indexes for cursor based pagination
+-- Timelines are paged by (is_top, latest_replied_on, id) and reaction timelines by (created_on, id)
+
+CREATE INDEX idx_post_cursor ON p_post (is_top DESC, latest_replied_on DESC, id DESC) WHERE is_del = 0;
+CREATE INDEX idx_user_reactions_cursor ON p_user_reactions (created_on DESC, id DESC) WHERE is_del = 0;
+CREATE INDEX idx_user_reactions_reactor_cursor ON p_user_reactions (reactor_user_id, created_on DESC, id DESC) WHERE is_del = 0;
@@ -0,0 +1,5 @@
+-- Rollback: Remove coordinates from p_room
+
+DROP INDEX IF EXISTS idx_room_location_lat_lng;
+ALTER TABLE p_room DROP COLUMN IF EXISTS location_lng;
+ALTER TABLE p_room DROP COLUMN IF EXISTS location_lat;
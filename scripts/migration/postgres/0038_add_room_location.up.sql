@@ -0,0 +1,10 @@
+-- Migration: Add coordinates to p_room for nearby live rooms
+-- Nearby queries use a plain (lat, lng) bounding-box index, no PostGIS required
+
+ALTER TABLE p_room ADD COLUMN location_lat DECIMAL(10, 8) NOT NULL DEFAULT 0;
+ALTER TABLE p_room ADD COLUMN location_lng DECIMAL(11, 8) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_room_location_lat_lng ON p_room (location_lat, location_lng) WHERE is_del = 0;
+
+COMMENT ON COLUMN p_room.location_lat IS 'Latitude of the host when the room was created or last located';
+COMMENT ON COLUMN p_room.location_lng IS 'Longitude of the host when the room was created or last located';
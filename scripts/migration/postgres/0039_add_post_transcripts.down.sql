@@ -0,0 +1,3 @@
+-- Rollback: Remove transcript segments
+
+DROP TABLE IF EXISTS p_post_transcripts;
@@ -0,0 +1,23 @@
+-- Migration: Add time-coded transcript segments of conversation recordings
+-- One speaker per recorded track, re-transcribing a track replaces its segments
+
+CREATE TABLE p_post_transcripts (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL DEFAULT 0,
+    speaker_id BIGINT NOT NULL DEFAULT 0,
+    start_ms BIGINT NOT NULL DEFAULT 0,
+    end_ms BIGINT NOT NULL DEFAULT 0,
+    text TEXT NOT NULL DEFAULT '',
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_post_transcripts_post ON p_post_transcripts (post_id, start_ms) WHERE is_del = 0;
+CREATE INDEX idx_post_transcripts_speaker ON p_post_transcripts (post_id, speaker_id);
+
+COMMENT ON TABLE p_post_transcripts IS 'Time-coded transcript segments of post recordings';
+COMMENT ON COLUMN p_post_transcripts.speaker_id IS 'User whose recorded track produced the segment';
+COMMENT ON COLUMN p_post_transcripts.start_ms IS 'Segment start, milliseconds from the start of the recording';
+COMMENT ON COLUMN p_post_transcripts.end_ms IS 'Segment end, milliseconds from the start of the recording';
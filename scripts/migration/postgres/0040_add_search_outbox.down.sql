@@ -0,0 +1,3 @@
+-- Rollback: Remove search outbox
+
+DROP TABLE IF EXISTS p_search_outbox;
@@ -0,0 +1,25 @@
+-- Migration: Add search outbox so index updates survive crashes and engine outages
+-- Rows are appended right after the business change and processed by a background worker with retries
+
+CREATE TABLE p_search_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    index_name VARCHAR(32) NOT NULL DEFAULT '',
+    doc_id BIGINT NOT NULL DEFAULT 0,
+    op VARCHAR(16) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_retry_on BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    status SMALLINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_search_outbox_due ON p_search_outbox (status, next_retry_on, id) WHERE is_del = 0;
+CREATE INDEX idx_search_outbox_index ON p_search_outbox (index_name, status, modified_on);
+
+COMMENT ON TABLE p_search_outbox IS 'Pending search index operations, processed with retries';
+COMMENT ON COLUMN p_search_outbox.op IS 'upsert/delete carry the document, sync re-reads the record from database';
+COMMENT ON COLUMN p_search_outbox.status IS '0 pending, 1 done, 2 failed after max attempts';
@@ -0,0 +1,8 @@
+-- Rollback: Remove repost reference and thread chaining from posts
+
+DROP INDEX IF EXISTS idx_post_thread_root;
+DROP INDEX IF EXISTS idx_post_origin;
+ALTER TABLE p_post DROP COLUMN IF EXISTS thread_parent_id;
+ALTER TABLE p_post DROP COLUMN IF EXISTS thread_root_id;
+ALTER TABLE p_post DROP COLUMN IF EXISTS repost_type;
+ALTER TABLE p_post DROP COLUMN IF EXISTS origin_id;
@@ -0,0 +1,15 @@
+-- Migration: Add repost/quote-repost reference and thread chaining to posts
+-- A repost carries the original post id, a thread post carries its root and direct parent
+
+ALTER TABLE p_post ADD COLUMN origin_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_post ADD COLUMN repost_type SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE p_post ADD COLUMN thread_root_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_post ADD COLUMN thread_parent_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_post_origin ON p_post (origin_id, repost_type) WHERE origin_id > 0;
+CREATE INDEX idx_post_thread_root ON p_post (thread_root_id, id) WHERE thread_root_id > 0;
+
+COMMENT ON COLUMN p_post.origin_id IS 'Original post of a repost or quote-repost, 0 for ordinary posts';
+COMMENT ON COLUMN p_post.repost_type IS '0 ordinary post, 1 repost, 2 quote-repost';
+COMMENT ON COLUMN p_post.thread_root_id IS 'First post of the thread, 0 when the post starts no thread';
+COMMENT ON COLUMN p_post.thread_parent_id IS 'Post this one follows up in the thread';
@@ -0,0 +1,46 @@
+-- Migration: Add publish status so drafts, scheduled posts and conversations awaiting recordings stay hidden
+-- Existing posts are published, the post filter views are recreated to skip unpublished posts
+
+ALTER TABLE p_post ADD COLUMN status SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE p_post ADD COLUMN scheduled_on BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_post_status_user ON p_post (CAST(user_id->0 AS bigint), status) WHERE status > 0 AND is_del = 0;
+CREATE INDEX idx_post_scheduled ON p_post (scheduled_on) WHERE status = 3 AND is_del = 0;
+
+COMMENT ON COLUMN p_post.status IS '0 published, 1 draft, 2 processing recordings, 3 scheduled';
+COMMENT ON COLUMN p_post.scheduled_on IS 'Time a scheduled post is published at';
+
+DROP VIEW IF EXISTS p_post_by_media;
+CREATE VIEW p_post_by_media AS 
+SELECT post.* 
+FROM
+    ( SELECT DISTINCT post_id FROM p_post_content WHERE ( TYPE = 3 OR TYPE = 4 OR TYPE = 7 OR TYPE = 8 ) AND is_del = 0 ) media
+    JOIN p_post post ON media.post_id = post.ID 
+WHERE
+    post.is_del = 0 AND post.status = 0;
+
+DROP VIEW IF EXISTS p_post_by_comment;
+CREATE VIEW p_post_by_comment AS 
+SELECT P.*, C.user_id comment_user_id
+FROM
+    (
+    SELECT
+        post_id,
+        user_id
+    FROM
+        p_comment 
+    WHERE
+        is_del = 0 UNION
+    SELECT
+        post_id,
+        reply.user_id user_id
+    FROM
+        p_comment_reply reply
+        JOIN p_comment COMMENT ON reply.comment_id = COMMENT.ID 
+    WHERE
+        reply.is_del = 0 
+        AND COMMENT.is_del = 0 
+    )
+    C JOIN p_post P ON C.post_id = P.ID 
+WHERE
+    P.is_del = 0 AND P.status = 0;
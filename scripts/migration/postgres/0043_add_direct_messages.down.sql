@@ -0,0 +1,7 @@
+-- Rollback: Remove direct message conversations and user blocks
+
+ALTER TABLE p_user DROP COLUMN IF EXISTS dm_policy;
+DROP TABLE IF EXISTS p_user_blocks;
+DROP TABLE IF EXISTS p_dm_messages;
+DROP TABLE IF EXISTS p_dm_participants;
+DROP TABLE IF EXISTS p_dm_conversations;
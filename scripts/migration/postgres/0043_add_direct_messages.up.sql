@@ -0,0 +1,68 @@
+-- Migration: Add direct message conversations, per-participant read cursors and user blocks
+-- Whispers now go into one conversation per pair of users, p_user.dm_policy limits who can message a user
+
+CREATE TABLE p_dm_conversations (
+    id BIGSERIAL PRIMARY KEY,
+    last_message_id BIGINT NOT NULL DEFAULT 0,
+    last_message_on BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE p_dm_participants (
+    id BIGSERIAL PRIMARY KEY,
+    conversation_id BIGINT NOT NULL DEFAULT 0,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    peer_id BIGINT NOT NULL DEFAULT 0,
+    last_read_id BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_dm_participants_pair ON p_dm_participants (user_id, peer_id) WHERE is_del = 0;
+CREATE INDEX idx_dm_participants_conversation ON p_dm_participants (conversation_id);
+
+CREATE TABLE p_dm_messages (
+    id BIGSERIAL PRIMARY KEY,
+    conversation_id BIGINT NOT NULL DEFAULT 0,
+    sender_id BIGINT NOT NULL DEFAULT 0,
+    type SMALLINT NOT NULL DEFAULT 1,
+    content TEXT NOT NULL DEFAULT '',
+    duration BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_dm_messages_conversation ON p_dm_messages (conversation_id, id DESC) WHERE is_del = 0;
+
+CREATE TABLE p_user_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    blocked_id BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_user_blocks_pair ON p_user_blocks (user_id, blocked_id) WHERE is_del = 0;
+CREATE INDEX idx_user_blocks_blocked ON p_user_blocks (blocked_id) WHERE is_del = 0;
+
+ALTER TABLE p_user ADD COLUMN dm_policy SMALLINT NOT NULL DEFAULT 0;
+
+COMMENT ON TABLE p_dm_conversations IS 'Direct message conversations between two users';
+COMMENT ON COLUMN p_dm_conversations.last_message_on IS 'Time of the latest message, conversation lists are ordered by it';
+COMMENT ON TABLE p_dm_participants IS 'One row per user of a conversation, holding the read cursor';
+COMMENT ON COLUMN p_dm_participants.peer_id IS 'The other user of the conversation';
+COMMENT ON COLUMN p_dm_participants.last_read_id IS 'Id of the latest message the user has read';
+COMMENT ON COLUMN p_dm_messages.type IS '1 text, 2 audio clip, 3 image';
+COMMENT ON COLUMN p_dm_messages.content IS 'Text of a text message, attachment url of an audio clip or image';
+COMMENT ON COLUMN p_dm_messages.duration IS 'Length of an audio clip in seconds';
+COMMENT ON TABLE p_user_blocks IS 'Users blocked by a user, blocked users can not message each other';
+COMMENT ON COLUMN p_user.dm_policy IS '0 anyone can send direct messages, 1 only followers and followed users';
@@ -0,0 +1,18 @@
+-- Rollback: Remove voice note duration and waveform columns
+
+ALTER TABLE p_dm_messages DROP COLUMN IF EXISTS waveform;
+UPDATE p_dm_messages SET duration = duration / 1000 WHERE duration > 0;
+COMMENT ON COLUMN p_dm_messages.duration IS 'Length of an audio clip in seconds';
+
+ALTER TABLE p_comment DROP COLUMN IF EXISTS audio_reply_count;
+
+ALTER TABLE p_comment_reply DROP COLUMN IF EXISTS waveform;
+ALTER TABLE p_comment_reply DROP COLUMN IF EXISTS duration;
+ALTER TABLE p_comment_reply DROP COLUMN IF EXISTS type;
+
+ALTER TABLE p_comment_content DROP COLUMN IF EXISTS waveform;
+ALTER TABLE p_comment_content DROP COLUMN IF EXISTS duration;
+
+DROP INDEX IF EXISTS idx_attachment_content;
+ALTER TABLE p_attachment DROP COLUMN IF EXISTS waveform;
+ALTER TABLE p_attachment DROP COLUMN IF EXISTS duration;
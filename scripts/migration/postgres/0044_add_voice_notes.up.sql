@@ -0,0 +1,26 @@
+-- Migration: Add voice notes to attachments, comments, replies and direct messages
+-- Audio duration is stored in milliseconds with a waveform summary of 0-100 amplitudes
+
+ALTER TABLE p_attachment ADD COLUMN duration BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_attachment ADD COLUMN waveform JSONB NOT NULL DEFAULT '[]';
+CREATE INDEX idx_attachment_content ON p_attachment (content) WHERE is_del = 0;
+
+ALTER TABLE p_comment_content ADD COLUMN duration BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_comment_content ADD COLUMN waveform JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE p_comment_reply ADD COLUMN type SMALLINT NOT NULL DEFAULT 2;
+ALTER TABLE p_comment_reply ADD COLUMN duration BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_comment_reply ADD COLUMN waveform JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE p_comment ADD COLUMN audio_reply_count INT NOT NULL DEFAULT 0;
+
+UPDATE p_dm_messages SET duration = duration * 1000 WHERE duration > 0;
+ALTER TABLE p_dm_messages ADD COLUMN waveform JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN p_attachment.duration IS 'Length of an audio attachment in milliseconds';
+COMMENT ON COLUMN p_attachment.waveform IS 'Waveform summary of an audio attachment';
+COMMENT ON COLUMN p_comment_content.duration IS 'Length of an audio content in milliseconds';
+COMMENT ON COLUMN p_comment_reply.type IS '2 text, 5 audio clip whose content is the attachment url';
+COMMENT ON COLUMN p_comment_reply.duration IS 'Length of an audio reply in milliseconds';
+COMMENT ON COLUMN p_comment.audio_reply_count IS 'Number of audio replies, counted into the comment rank score';
+COMMENT ON COLUMN p_dm_messages.duration IS 'Length of an audio clip in milliseconds';
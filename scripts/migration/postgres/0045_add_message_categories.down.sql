@@ -0,0 +1,10 @@
+-- Rollback: Remove notification categories and grouping from p_message
+
+DROP INDEX IF EXISTS idx_message_group;
+DROP INDEX IF EXISTS idx_message_receiver_category;
+DROP INDEX IF EXISTS idx_message_receiver_latest;
+ALTER TABLE p_message DROP COLUMN IF EXISTS latest_on;
+ALTER TABLE p_message DROP COLUMN IF EXISTS actor_count;
+ALTER TABLE p_message DROP COLUMN IF EXISTS actor_ids;
+ALTER TABLE p_message DROP COLUMN IF EXISTS group_key;
+ALTER TABLE p_message DROP COLUMN IF EXISTS category;
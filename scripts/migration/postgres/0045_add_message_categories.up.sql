@@ -0,0 +1,31 @@
+-- Migration: Add notification categories, grouping and cursor paging to p_message
+-- Unread notifications of the same group are merged into one row that keeps the latest actors
+
+ALTER TABLE p_message ADD COLUMN category VARCHAR(16) NOT NULL DEFAULT 'system';
+ALTER TABLE p_message ADD COLUMN group_key VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE p_message ADD COLUMN actor_ids JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE p_message ADD COLUMN actor_count INT NOT NULL DEFAULT 1;
+ALTER TABLE p_message ADD COLUMN latest_on BIGINT NOT NULL DEFAULT 0;
+
+UPDATE p_message SET
+	latest_on = created_on,
+	actor_ids = jsonb_build_array(sender_user_id),
+	category = CASE
+		WHEN "type" = 1 OR brief LIKE '%@了你%' THEN 'mention'
+		WHEN "type" IN (2, 3) THEN 'comment'
+		WHEN "type" = 4 THEN 'whisper'
+		WHEN "type" = 5 THEN 'friend'
+		WHEN "type" = 6 THEN 'follow'
+		WHEN "type" = 7 THEN 'reaction'
+		ELSE 'system'
+	END;
+
+CREATE INDEX idx_message_receiver_latest ON p_message (receiver_user_id, latest_on DESC, id DESC) WHERE is_del = 0;
+CREATE INDEX idx_message_receiver_category ON p_message (receiver_user_id, category, is_read) WHERE is_del = 0;
+CREATE INDEX idx_message_group ON p_message (receiver_user_id, group_key) WHERE is_del = 0 AND is_read = 0 AND group_key <> '';
+
+COMMENT ON COLUMN p_message.category IS 'mention, comment, reaction, follow, friend, room_invite, system or whisper';
+COMMENT ON COLUMN p_message.group_key IS 'Unread notifications with the same key are merged, empty for ungrouped notifications';
+COMMENT ON COLUMN p_message.actor_ids IS 'Latest users who triggered a grouped notification';
+COMMENT ON COLUMN p_message.actor_count IS 'Number of users who triggered a grouped notification';
+COMMENT ON COLUMN p_message.latest_on IS 'Time of the latest trigger, notification lists are ordered by it';
@@ -0,0 +1,6 @@
+-- Rollback: Remove room invitations and "join me" pings
+
+ALTER TABLE p_message DROP COLUMN IF EXISTS room_id;
+DROP INDEX IF EXISTS idx_following_room_ping;
+ALTER TABLE p_following DROP COLUMN IF EXISTS room_ping;
+DROP TABLE IF EXISTS p_room_invites;
@@ -0,0 +1,30 @@
+-- Migration: Add room invitations and "join me" pings to opted-in followers
+-- Each invite expires after RoomInvite.InviteExpire, accepted_on records the conversion
+
+CREATE TABLE p_room_invites (
+    id BIGSERIAL PRIMARY KEY,
+    room_id BIGINT NOT NULL DEFAULT 0,
+    inviter_id BIGINT NOT NULL DEFAULT 0,
+    invitee_id BIGINT NOT NULL DEFAULT 0,
+    source SMALLINT NOT NULL DEFAULT 1,
+    expired_on BIGINT NOT NULL DEFAULT 0,
+    accepted_on BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_room_invites_invitee ON p_room_invites (invitee_id, room_id, expired_on) WHERE is_del = 0 AND accepted_on = 0;
+CREATE INDEX idx_room_invites_room ON p_room_invites (room_id, accepted_on) WHERE is_del = 0;
+
+ALTER TABLE p_following ADD COLUMN room_ping SMALLINT NOT NULL DEFAULT 0;
+CREATE INDEX idx_following_room_ping ON p_following (follow_id) WHERE is_del = 0 AND room_ping = 1;
+
+ALTER TABLE p_message ADD COLUMN room_id BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON TABLE p_room_invites IS 'Room invitations sent by hosts to followers and friends';
+COMMENT ON COLUMN p_room_invites.source IS '1 picked by the host, 2 "join me" ping to opted-in followers';
+COMMENT ON COLUMN p_room_invites.accepted_on IS 'Time the invitee accepted the invite, 0 if not accepted';
+COMMENT ON COLUMN p_following.room_ping IS '1 if the follower wants "join me" pings when the followed user opens a room';
+COMMENT ON COLUMN p_message.room_id IS 'Room of a room invite notification, used for deep-linking';
@@ -0,0 +1,4 @@
+-- Rollback: Remove friend suggestions and feedback
+
+DROP TABLE IF EXISTS p_user_suggestion_feedback;
+DROP TABLE IF EXISTS p_user_suggestions;
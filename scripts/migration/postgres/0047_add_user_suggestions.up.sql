@@ -0,0 +1,37 @@
+-- Migration: Add precomputed "people you may know" suggestions and dismiss/hide feedback
+-- Suggestions are rebuilt periodically by the friend suggest job
+
+CREATE TABLE p_user_suggestions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    suggested_id BIGINT NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reason VARCHAR(32) NOT NULL DEFAULT '',
+    explanation VARCHAR(128) NOT NULL DEFAULT '',
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_user_suggestions_pair ON p_user_suggestions (user_id, suggested_id);
+CREATE INDEX idx_user_suggestions_score ON p_user_suggestions (user_id, score DESC, suggested_id) WHERE is_del = 0;
+
+CREATE TABLE p_user_suggestion_feedback (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    suggested_id BIGINT NOT NULL DEFAULT 0,
+    action SMALLINT NOT NULL DEFAULT 1,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_user_suggestion_feedback_pair ON p_user_suggestion_feedback (user_id, suggested_id);
+
+COMMENT ON TABLE p_user_suggestions IS 'Precomputed friend suggestions per user';
+COMMENT ON COLUMN p_user_suggestions.reason IS 'Main signal: contact, mutual_friends, mutual_follows, reactions or categories';
+COMMENT ON COLUMN p_user_suggestions.explanation IS 'Human readable explanation such as "3 mutual friends"';
+COMMENT ON TABLE p_user_suggestion_feedback IS 'Suggestions dismissed or hidden by users';
+COMMENT ON COLUMN p_user_suggestion_feedback.action IS '1 dismiss, suggested again after Suggest.DismissExpire; 2 hide, never suggested again';
@@ -0,0 +1,6 @@
+-- Rollback: Remove private accounts and follow requests
+
+DELETE FROM p_following WHERE status = 1;
+DROP INDEX IF EXISTS idx_following_requests;
+ALTER TABLE p_following DROP COLUMN IF EXISTS status;
+ALTER TABLE p_user DROP COLUMN IF EXISTS is_private;
@@ -0,0 +1,10 @@
+-- Migration: Add private accounts, following a private account creates a pending follow request
+-- Existing follow edges are kept as approved follows
+
+ALTER TABLE p_user ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE p_following ADD COLUMN status SMALLINT NOT NULL DEFAULT 2;
+CREATE INDEX idx_following_requests ON p_following (follow_id, id) WHERE is_del = 0 AND status = 1;
+
+COMMENT ON COLUMN p_user.is_private IS 'Private account, new follows need approval';
+COMMENT ON COLUMN p_following.status IS '1 pending follow request, 2 approved follow';
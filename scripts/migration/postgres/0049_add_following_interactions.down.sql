@@ -0,0 +1,5 @@
+-- Rollback: Remove follow interaction tracking
+
+DROP INDEX IF EXISTS idx_following_follow_interacted;
+DROP INDEX IF EXISTS idx_following_user_interacted;
+ALTER TABLE p_following DROP COLUMN IF EXISTS interacted_on;
@@ -0,0 +1,10 @@
+-- Migration: Track the latest interaction between a follower and the followed user
+-- Follower and following lists are ordered by interacted_on, existing edges start from the follow time
+
+ALTER TABLE p_following ADD COLUMN interacted_on BIGINT NOT NULL DEFAULT 0;
+UPDATE p_following SET interacted_on = created_on;
+
+CREATE INDEX idx_following_user_interacted ON p_following (user_id, interacted_on DESC, id DESC) WHERE is_del = 0 AND status = 2;
+CREATE INDEX idx_following_follow_interacted ON p_following (follow_id, interacted_on DESC, id DESC) WHERE is_del = 0 AND status = 2;
+
+COMMENT ON COLUMN p_following.interacted_on IS 'Latest notification or direct message between the two users, initially the follow time';
@@ -0,0 +1,3 @@
+-- Rollback: Remove mention entities
+
+DROP TABLE IF EXISTS p_mentions;
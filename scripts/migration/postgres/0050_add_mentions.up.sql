@@ -0,0 +1,29 @@
+-- Migration: Add structured @username mentions parsed from post text, comments and replies
+-- Mentioned users are notified subject to blocking and post visibility
+
+CREATE TABLE p_mentions (
+    id BIGSERIAL PRIMARY KEY,
+    source_type SMALLINT NOT NULL DEFAULT 1,
+    source_id BIGINT NOT NULL DEFAULT 0,
+    post_id BIGINT NOT NULL DEFAULT 0,
+    comment_id BIGINT NOT NULL DEFAULT 0,
+    reply_id BIGINT NOT NULL DEFAULT 0,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    mentioned_user_id BIGINT NOT NULL DEFAULT 0,
+    "offset" INTEGER NOT NULL DEFAULT 0,
+    length INTEGER NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_mentions_source ON p_mentions (source_type, source_id) WHERE is_del = 0;
+CREATE INDEX idx_mentions_post ON p_mentions (post_id) WHERE is_del = 0;
+CREATE INDEX idx_mentions_mentioned_user ON p_mentions (mentioned_user_id, id DESC) WHERE is_del = 0;
+
+COMMENT ON TABLE p_mentions IS 'Mention entities resolved to users, one row per occurrence';
+COMMENT ON COLUMN p_mentions.source_type IS '1 post content, 2 comment content, 3 comment reply';
+COMMENT ON COLUMN p_mentions.source_id IS 'ID of the post content, comment content or reply holding the mention';
+COMMENT ON COLUMN p_mentions."offset" IS 'Offset of the @ sign in runes';
+COMMENT ON COLUMN p_mentions.length IS 'Length in runes including the @ sign';
@@ -0,0 +1,6 @@
+-- Rollback: Remove comment pinning and Wilson score
+
+DROP INDEX IF EXISTS idx_comment_metric_comment_id_wilson_score;
+ALTER TABLE p_comment_metric DROP COLUMN IF EXISTS wilson_score;
+DROP INDEX IF EXISTS idx_comment_post_pinned;
+ALTER TABLE p_comment DROP COLUMN IF EXISTS pinned_on;
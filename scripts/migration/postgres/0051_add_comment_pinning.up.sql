@@ -0,0 +1,19 @@
+-- Migration: Add comment pinning by post owners and Wilson score for "top" comment sorting
+-- Existing comment metrics are backfilled from the current thumbs up/down counts
+
+ALTER TABLE p_comment ADD COLUMN pinned_on BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX idx_comment_post_pinned ON p_comment (post_id) WHERE is_del = 0 AND pinned_on > 0;
+
+ALTER TABLE p_comment_metric ADD COLUMN wilson_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+UPDATE p_comment_metric AS m SET wilson_score = (s.p + 1.9208 / s.n - 1.96 * sqrt((s.p * (1 - s.p) + 0.9604 / s.n) / s.n)) / (1 + 3.8416 / s.n)
+FROM (
+    SELECT id, thumbs_up_count::DOUBLE PRECISION / (thumbs_up_count + thumbs_down_count) AS p,
+        (thumbs_up_count + thumbs_down_count)::DOUBLE PRECISION AS n
+    FROM p_comment
+    WHERE thumbs_up_count >= 0 AND thumbs_down_count >= 0 AND thumbs_up_count + thumbs_down_count > 0
+) AS s
+WHERE s.id = m.comment_id;
+CREATE INDEX idx_comment_metric_comment_id_wilson_score ON p_comment_metric (comment_id, wilson_score);
+
+COMMENT ON COLUMN p_comment.pinned_on IS 'Time the comment was pinned by a post owner, 0 if not pinned; at most one pinned comment per post';
+COMMENT ON COLUMN p_comment_metric.wilson_score IS 'Lower bound of the Wilson score interval of the thumbs up ratio at 95% confidence';
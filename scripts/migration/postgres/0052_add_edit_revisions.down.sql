@@ -0,0 +1,6 @@
+-- Rollback: Remove post and comment edit revisions
+
+DROP INDEX IF EXISTS idx_edit_revisions_target;
+DROP TABLE IF EXISTS p_edit_revisions;
+ALTER TABLE p_comment DROP COLUMN IF EXISTS edited_on;
+ALTER TABLE p_post DROP COLUMN IF EXISTS edited_on;
@@ -0,0 +1,27 @@
+-- Migration: Add editing of post text, tags and comments within a configurable window
+-- Every edit keeps the previous text as a revision so viewers can see what changed
+
+ALTER TABLE p_post ADD COLUMN edited_on BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_comment ADD COLUMN edited_on BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE p_edit_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    target_type SMALLINT NOT NULL DEFAULT 1,
+    target_id BIGINT NOT NULL DEFAULT 0,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    tags VARCHAR(255) NOT NULL DEFAULT '',
+    contents JSONB NOT NULL DEFAULT '[]',
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_edit_revisions_target ON p_edit_revisions (target_type, target_id, id DESC) WHERE is_del = 0;
+
+COMMENT ON COLUMN p_post.edited_on IS 'Time of the last edit by the author, 0 if never edited';
+COMMENT ON COLUMN p_comment.edited_on IS 'Time of the last edit by the author, 0 if never edited';
+COMMENT ON TABLE p_edit_revisions IS 'Snapshots of post and comment text taken before each edit';
+COMMENT ON COLUMN p_edit_revisions.target_type IS '1 post, 2 comment';
+COMMENT ON COLUMN p_edit_revisions.tags IS 'Post tags before the edit, empty for comments';
+COMMENT ON COLUMN p_edit_revisions.contents IS 'Text contents before the edit as [{content, type, sort}]';
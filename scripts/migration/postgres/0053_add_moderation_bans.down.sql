@@ -0,0 +1,4 @@
+-- Rollback: Remove moderator-owned post hiding and room bans
+
+ALTER TABLE p_user DROP COLUMN IF EXISTS room_banned_on;
+ALTER TABLE p_post DROP COLUMN IF EXISTS hidden_on;
@@ -0,0 +1,8 @@
+-- Migration: Add moderator-owned post hiding and account level room bans
+-- Authors cannot change the visibility of a hidden post, banned users cannot host or join rooms
+
+ALTER TABLE p_post ADD COLUMN hidden_on BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE p_user ADD COLUMN room_banned_on BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN p_post.hidden_on IS 'Time the post was hidden by a moderator, 0 if not hidden; only moderators can change the visibility of a hidden post';
+COMMENT ON COLUMN p_user.room_banned_on IS 'Time the user was banned from rooms by a moderator, 0 if not banned';
@@ -0,0 +1,4 @@
+-- Rollback: Remove the comment link of p_post_content
+
+DROP INDEX IF EXISTS idx_post_content_comment_id;
+ALTER TABLE p_post_content DROP COLUMN IF EXISTS comment_id;
@@ -0,0 +1,19 @@
+-- Migration: Link the comment text copied into p_post_content to its comment
+-- p_comment_content is the source of truth, rows with comment_id > 0 are kept in sync with it
+
+ALTER TABLE p_post_content ADD COLUMN comment_id BIGINT NOT NULL DEFAULT 0;
+
+-- Backfill: copies are written with the same type, sort and content right after the comment is created
+UPDATE p_post_content AS pc SET comment_id = cc.comment_id
+FROM p_comment_content AS cc
+JOIN p_comment AS c ON c.id = cc.comment_id
+WHERE pc.comment_id = 0
+	AND pc.post_id = c.post_id
+	AND pc.type = cc.type
+	AND pc.sort = cc.sort
+	AND pc.content = cc.content
+	AND pc.created_on BETWEEN c.created_on AND c.created_on + 1;
+
+CREATE INDEX idx_post_content_comment_id ON p_post_content USING btree (comment_id) WHERE comment_id > 0;
+
+COMMENT ON COLUMN p_post_content.comment_id IS 'Comment the row was copied from, 0 for the post''s own content';
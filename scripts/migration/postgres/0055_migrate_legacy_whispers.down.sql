@@ -0,0 +1,3 @@
+-- Rollback: Restore legacy whispers in p_message, migrated direct messages are kept
+
+UPDATE p_message SET is_del = 0, deleted_on = 0 WHERE "type" = 4 AND is_del = 1;
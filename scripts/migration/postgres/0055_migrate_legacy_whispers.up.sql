@@ -0,0 +1,57 @@
+-- Migration: Move legacy whispers of p_message into direct message conversations
+-- Pairs without a conversation get a new one, unread whispers stay unread behind the receiver's read cursor
+
+CREATE TEMPORARY TABLE tmp_whisper_pairs AS
+SELECT w.user_a, w.user_b, p.conversation_id AS existing_id,
+	CASE WHEN p.conversation_id IS NULL THEN nextval('p_dm_conversations_id_seq') ELSE p.conversation_id END AS conversation_id
+FROM (
+	SELECT LEAST(sender_user_id, receiver_user_id) AS user_a, GREATEST(sender_user_id, receiver_user_id) AS user_b
+	FROM p_message
+	WHERE "type" = 4 AND is_del = 0 AND sender_user_id > 0 AND receiver_user_id > 0 AND sender_user_id <> receiver_user_id
+	GROUP BY 1, 2
+) AS w
+LEFT JOIN p_dm_participants AS p ON p.user_id = w.user_a AND p.peer_id = w.user_b AND p.is_del = 0;
+
+CREATE TEMPORARY TABLE tmp_whispers AS
+SELECT nextval('p_dm_messages_id_seq') AS id, s.*
+FROM (
+	SELECT t.conversation_id, m.sender_user_id, m.receiver_user_id, m.content, m.is_read, m.created_on
+	FROM p_message AS m
+	JOIN tmp_whisper_pairs AS t ON t.user_a = LEAST(m.sender_user_id, m.receiver_user_id) AND t.user_b = GREATEST(m.sender_user_id, m.receiver_user_id)
+	WHERE m."type" = 4 AND m.is_del = 0
+	ORDER BY m.id ASC
+) AS s;
+
+INSERT INTO p_dm_conversations (id, created_on, modified_on)
+SELECT conversation_id, EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT
+FROM tmp_whisper_pairs WHERE existing_id IS NULL;
+
+INSERT INTO p_dm_participants (conversation_id, user_id, peer_id, created_on, modified_on)
+SELECT conversation_id, user_a, user_b, EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT
+FROM tmp_whisper_pairs WHERE existing_id IS NULL
+UNION ALL
+SELECT conversation_id, user_b, user_a, EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT
+FROM tmp_whisper_pairs WHERE existing_id IS NULL;
+
+INSERT INTO p_dm_messages (id, conversation_id, sender_id, "type", content, created_on, modified_on)
+SELECT id, conversation_id, sender_user_id, 1, content, created_on, created_on FROM tmp_whispers;
+
+-- Participants who had read the whole conversation read up to their first unread whisper
+UPDATE p_dm_participants AS p SET last_read_id = COALESCE(
+	(SELECT MIN(w.id) - 1 FROM tmp_whispers AS w WHERE w.conversation_id = p.conversation_id AND w.receiver_user_id = p.user_id AND w.is_read = 0),
+	(SELECT MAX(w.id) FROM tmp_whispers AS w WHERE w.conversation_id = p.conversation_id))
+FROM p_dm_conversations AS c
+WHERE c.id = p.conversation_id AND p.is_del = 0 AND p.last_read_id >= c.last_message_id
+	AND p.conversation_id IN (SELECT conversation_id FROM tmp_whisper_pairs);
+
+UPDATE p_dm_conversations AS c SET last_message_id = s.last_message_id, last_message_on = s.last_message_on
+FROM (
+	SELECT conversation_id, MAX(id) AS last_message_id, MAX(created_on) AS last_message_on
+	FROM p_dm_messages WHERE is_del = 0 GROUP BY conversation_id
+) AS s
+WHERE s.conversation_id = c.id AND c.id IN (SELECT conversation_id FROM tmp_whisper_pairs);
+
+UPDATE p_message SET is_del = 1, deleted_on = EXTRACT(EPOCH FROM NOW())::BIGINT WHERE "type" = 4 AND is_del = 0;
+
+DROP TABLE tmp_whispers;
+DROP TABLE tmp_whisper_pairs;
@@ -0,0 +1,4 @@
+-- Rollback: Remove the version of search outbox rows
+
+DROP INDEX IF EXISTS idx_search_outbox_doc;
+ALTER TABLE p_search_outbox DROP COLUMN IF EXISTS version;
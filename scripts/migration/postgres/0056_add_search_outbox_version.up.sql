@@ -0,0 +1,8 @@
+-- Migration: Version search outbox rows so an older post snapshot never overwrites a newer one
+-- Upserts carry the modified_on of the post, deletes the time of deletion, ties are ordered by id
+
+ALTER TABLE p_search_outbox ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_search_outbox_doc ON p_search_outbox (index_name, doc_id, status) WHERE is_del = 0;
+
+COMMENT ON COLUMN p_search_outbox.version IS 'Version of the document, a row is skipped once a newer version of the same document is done';
@@ -0,0 +1,4 @@
+-- Rollback: Store follow request and approval notifications as follow notifications again
+
+UPDATE p_message SET type = 6 WHERE type IN (9, 10);
+COMMENT ON COLUMN p_message.type IS NULL;
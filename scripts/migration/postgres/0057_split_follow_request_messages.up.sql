@@ -0,0 +1,7 @@
+-- Migration: Give follow request and approval notifications their own message types
+-- They were stored as follow notifications (type 6) and merged into the grouped follow row
+
+UPDATE p_message SET type = 9, group_key = '' WHERE type = 6 AND brief = '请求关注你';
+UPDATE p_message SET type = 10, group_key = '' WHERE type = 6 AND brief = '通过了你的关注请求';
+
+COMMENT ON COLUMN p_message.type IS '1 post, 2 comment, 3 reply, 4 whisper, 5 friend request, 6 follow, 7 reaction, 8 room invite, 9 follow request, 10 follow approved, 99 system';
@@ -0,0 +1,3 @@
+-- Rollback: Remove moderator-owned comment locks
+
+ALTER TABLE p_post DROP COLUMN IF EXISTS locked_on;
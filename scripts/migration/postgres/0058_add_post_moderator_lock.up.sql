@@ -0,0 +1,6 @@
+-- Migration: Record comment locks applied by moderators separately from author locks
+-- Authors cannot unlock comments on a post locked by a moderator
+
+ALTER TABLE p_post ADD COLUMN locked_on BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN p_post.locked_on IS 'Time comments on the post were locked by a moderator, 0 if not locked by a moderator; only moderators can unlock such a post';
@@ -0,0 +1,3 @@
+-- Rollback: Remove the administrator who deleted a post
+
+ALTER TABLE p_post DROP COLUMN IF EXISTS deleted_by;
@@ -0,0 +1,6 @@
+-- Migration: Record the administrator who deleted a post
+-- Only posts soft deleted by an administrator can be restored, posts deleted by their author stay deleted
+
+ALTER TABLE p_post ADD COLUMN deleted_by BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN p_post.deleted_by IS 'Administrator who soft deleted the post, 0 if not deleted or deleted by the author';
@@ -0,0 +1,3 @@
+-- Rollback: Remove the one plain repost per user and post constraint
+
+DROP INDEX IF EXISTS idx_post_user_repost;
@@ -0,0 +1,20 @@
+-- Migration: Allow a user at most one plain repost of a post
+-- Duplicate reposts left by concurrent requests are removed, keeping the earliest one, and share counts are corrected
+
+WITH duplicates AS (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY CAST(user_id->0 AS bigint), origin_id ORDER BY id) AS rn
+        FROM p_post
+        WHERE repost_type = 1 AND is_del = 0
+    ) t
+    WHERE rn > 1
+), removed AS (
+    UPDATE p_post SET is_del = 1, deleted_on = CAST(EXTRACT(EPOCH FROM NOW()) AS bigint)
+    WHERE id IN (SELECT id FROM duplicates)
+    RETURNING origin_id
+)
+UPDATE p_post p SET share_count = GREATEST(p.share_count - r.cnt, 0)
+FROM (SELECT origin_id, COUNT(*) AS cnt FROM removed GROUP BY origin_id) r
+WHERE p.id = r.origin_id;
+
+CREATE UNIQUE INDEX idx_post_user_repost ON p_post (CAST(user_id->0 AS bigint), origin_id) WHERE repost_type = 1 AND is_del = 0;
@@ -0,0 +1,6 @@
+-- Rollback: Remove grouped notification actors and the one unread row per group constraint
+
+DROP TABLE IF EXISTS p_message_group_actor;
+
+DROP INDEX IF EXISTS idx_message_group;
+CREATE INDEX idx_message_group ON p_message (receiver_user_id, group_key) WHERE is_del = 0 AND is_read = 0 AND group_key <> '';
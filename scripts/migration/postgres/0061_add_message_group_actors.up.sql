@@ -0,0 +1,36 @@
+-- Migration: Keep one unread notification per group under concurrent triggers and count distinct actors exactly
+-- Unread rows of a group are merged with an upsert, every distinct actor of a group is recorded once
+
+-- Older duplicate unread rows of the same group are marked read, the latest one is kept
+UPDATE p_message m SET is_read = 1
+WHERE m.is_read = 0 AND m.is_del = 0 AND m.group_key <> ''
+    AND EXISTS (
+        SELECT 1 FROM p_message n
+        WHERE n.receiver_user_id = m.receiver_user_id AND n.group_key = m.group_key
+            AND n.is_read = 0 AND n.is_del = 0 AND n.id > m.id
+    );
+
+DROP INDEX IF EXISTS idx_message_group;
+CREATE UNIQUE INDEX idx_message_group ON p_message (receiver_user_id, group_key) WHERE is_del = 0 AND is_read = 0 AND group_key <> '';
+
+CREATE TABLE p_message_group_actor (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL DEFAULT 0,
+    actor_id BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX idx_message_group_actor ON p_message_group_actor (message_id, actor_id);
+CREATE INDEX idx_message_group_actor_actor ON p_message_group_actor (actor_id);
+
+-- Unread groups start from the actors they still list
+INSERT INTO p_message_group_actor (message_id, actor_id, created_on, modified_on)
+SELECT m.id, CAST(a.actor_id AS BIGINT), m.latest_on, m.latest_on
+FROM p_message m, jsonb_array_elements_text(m.actor_ids) AS a(actor_id)
+WHERE m.is_read = 0 AND m.is_del = 0 AND m.group_key <> ''
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE p_message_group_actor IS 'Distinct users who triggered a grouped notification';
@@ -0,0 +1,3 @@
+-- Rollback: Remove permissions of authorization actions
+
+DELETE FROM p_role_permissions WHERE permission IN ('user.create', 'tweet.create', 'comment.create');
@@ -0,0 +1,13 @@
+-- Migration: Put every authorization action behind a permission
+-- Creating users, tweets and comments regardless of ownership, friendship and phone binding
+
+INSERT INTO p_role_permissions (role_id, permission, created_on, modified_on)
+SELECT r.id, p.permission, EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT
+FROM p_roles r
+JOIN (VALUES
+    ('admin', 'user.create'),
+    ('admin', 'tweet.create'),
+    ('admin', 'comment.create'),
+    ('moderator', 'comment.create')
+) AS p(role, permission) ON p.role = r.name AND r.is_del = 0
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+-- Rollback: Restore the verified room host role, user assignments are not restored
+
+INSERT INTO p_roles (name, description, created_on, modified_on) VALUES
+    ('room_host_verified', 'Verified room host', EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO p_role_permissions (role_id, permission, created_on, modified_on)
+SELECT r.id, 'room.host_verified', EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT
+FROM p_roles r
+WHERE r.name = 'room_host_verified' AND r.is_del = 0
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+-- Migration: Remove the unused verified room host role
+-- The role only carried permission room.host_verified which guards nothing
+
+DELETE FROM p_user_roles WHERE role_id IN (SELECT id FROM p_roles WHERE name = 'room_host_verified');
+DELETE FROM p_role_permissions WHERE permission = 'room.host_verified';
+DELETE FROM p_roles WHERE name = 'room_host_verified';
@@ -0,0 +1,3 @@
+-- Rollback: Remove the unique index of open reports
+
+DROP INDEX IF EXISTS idx_reports_open;
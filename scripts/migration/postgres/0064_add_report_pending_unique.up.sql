@@ -0,0 +1,13 @@
+-- Migration: Keep one open report per reporter and target under concurrent submissions
+-- A new report of the same target is merged into the pending or triaged one with an insert that does nothing on conflict
+
+-- Newer duplicate open reports of the same target are removed, the earliest one is kept
+UPDATE p_reports r SET is_del = 1, deleted_on = EXTRACT(EPOCH FROM NOW())::BIGINT
+WHERE r.status IN (1, 2) AND r.is_del = 0
+    AND EXISTS (
+        SELECT 1 FROM p_reports o
+        WHERE o.reporter_id = r.reporter_id AND o.target_type = r.target_type AND o.target_id = r.target_id
+            AND o.status IN (1, 2) AND o.is_del = 0 AND o.id < r.id
+    );
+
+CREATE UNIQUE INDEX idx_reports_open ON p_reports (reporter_id, target_type, target_id) WHERE status IN (1, 2) AND is_del = 0;
@@ -0,0 +1,5 @@
+-- Rollback: Drop search outbox
+DROP INDEX IF EXISTS idx_search_outbox_doc;
+DROP INDEX IF EXISTS idx_search_outbox_index;
+DROP INDEX IF EXISTS idx_search_outbox_due;
+DROP TABLE IF EXISTS p_search_outbox;
@@ -0,0 +1,22 @@
+-- Migration: Add search outbox so index updates survive crashes and engine outages
+-- Same table as postgres 0040 and 0056, the Slim suite (Sqlite3 + Bleve) processes it as well
+CREATE TABLE p_search_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    index_name VARCHAR(32) NOT NULL DEFAULT '',
+    doc_id BIGINT NOT NULL DEFAULT 0,
+    op VARCHAR(16) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_retry_on BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    status INTEGER NOT NULL DEFAULT 0,
+    version BIGINT NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_search_outbox_due ON p_search_outbox (status, next_retry_on, id) WHERE is_del = 0;
+CREATE INDEX idx_search_outbox_index ON p_search_outbox (index_name, status, modified_on);
+CREATE INDEX idx_search_outbox_doc ON p_search_outbox (index_name, doc_id, status) WHERE is_del = 0;
@@ -0,0 +1,12 @@
+-- Rollback: Remove coordinates from p_post and drop p_room
+-- DROP COLUMN requires SQLite 3.35 or later
+
+DROP TABLE IF EXISTS p_room;
+DROP INDEX IF EXISTS idx_post_location_lat_lng;
+ALTER TABLE p_post DROP COLUMN location_country;
+ALTER TABLE p_post DROP COLUMN location_state;
+ALTER TABLE p_post DROP COLUMN location_city;
+ALTER TABLE p_post DROP COLUMN location_address;
+ALTER TABLE p_post DROP COLUMN location_lng;
+ALTER TABLE p_post DROP COLUMN location_lat;
+ALTER TABLE p_post DROP COLUMN location_name;
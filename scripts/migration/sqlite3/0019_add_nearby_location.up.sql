@@ -0,0 +1,33 @@
+-- Migration: Add coordinates to p_post and create p_room with coordinates for nearby tweets and rooms
+-- Same columns and (lat, lng) bounding-box indexes as postgres 0031 and 0038, no spatial extension required
+ALTER TABLE p_post ADD COLUMN location_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE p_post ADD COLUMN location_lat REAL NOT NULL DEFAULT 0;
+ALTER TABLE p_post ADD COLUMN location_lng REAL NOT NULL DEFAULT 0;
+ALTER TABLE p_post ADD COLUMN location_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE p_post ADD COLUMN location_city TEXT NOT NULL DEFAULT '';
+ALTER TABLE p_post ADD COLUMN location_state TEXT NOT NULL DEFAULT '';
+ALTER TABLE p_post ADD COLUMN location_country TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_post_location_lat_lng ON p_post (location_lat, location_lng);
+
+CREATE TABLE p_room (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    host_id BIGINT NOT NULL DEFAULT 0,
+    hms_room_id VARCHAR(255) DEFAULT NULL,
+    speaker_ids JSON DEFAULT NULL,
+    start_time BIGINT DEFAULT NULL,
+    queue JSON DEFAULT NULL,
+    is_blocked_from_space INTEGER NOT NULL DEFAULT 0,
+    topics JSON DEFAULT NULL,
+    categories JSON DEFAULT NULL,
+    location_lat REAL NOT NULL DEFAULT 0,
+    location_lng REAL NOT NULL DEFAULT 0,
+    created_on BIGINT NOT NULL DEFAULT 0,
+    modified_on BIGINT NOT NULL DEFAULT 0,
+    deleted_on BIGINT NOT NULL DEFAULT 0,
+    is_del INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_room_host ON p_room (host_id);
+CREATE INDEX idx_room_hms ON p_room (hms_room_id);
+CREATE INDEX idx_room_created ON p_room (created_on);
+CREATE INDEX idx_room_location_lat_lng ON p_room (location_lat, location_lng) WHERE is_del = 0;
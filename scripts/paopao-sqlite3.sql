@@ -330,6 +330,28 @@ CREATE TABLE "p_tag" (
   PRIMARY KEY ("id")
 );
 
+-- ----------------------------
+-- Table structure for p_search_outbox
+-- ----------------------------
+DROP TABLE IF EXISTS "p_search_outbox";
+CREATE TABLE "p_search_outbox" (
+	"id" integer,
+	"index_name" text(32) NOT NULL DEFAULT '',
+	"doc_id" integer NOT NULL DEFAULT 0,
+	"op" text(16) NOT NULL DEFAULT '',
+	"payload" text NOT NULL DEFAULT '',
+	"attempts" integer NOT NULL DEFAULT 0,
+	"next_retry_on" integer NOT NULL DEFAULT 0,
+	"last_error" text NOT NULL DEFAULT '',
+	"status" integer NOT NULL DEFAULT 0,
+	"version" integer NOT NULL DEFAULT 0,
+	"created_on" integer NOT NULL DEFAULT 0,
+	"modified_on" integer NOT NULL DEFAULT 0,
+	"deleted_on" integer NOT NULL DEFAULT 0,
+	"is_del" integer NOT NULL DEFAULT 0,
+	PRIMARY KEY ("id")
+);
+
 -- ----------------------------
 -- Table structure for p_topic_user
 -- ----------------------------
@@ -674,6 +696,28 @@ ON "p_tag" (
   "user_id" ASC
 );
 
+-- ----------------------------
+-- Indexes structure for table p_search_outbox
+-- ----------------------------
+CREATE INDEX "idx_search_outbox_due"
+ON "p_search_outbox" (
+  "status" ASC,
+  "next_retry_on" ASC,
+  "id" ASC
+);
+CREATE INDEX "idx_search_outbox_index"
+ON "p_search_outbox" (
+  "index_name" ASC,
+  "status" ASC,
+  "modified_on" ASC
+);
+CREATE INDEX "idx_search_outbox_doc"
+ON "p_search_outbox" (
+  "index_name" ASC,
+  "doc_id" ASC,
+  "status" ASC
+);
+
 -- ----------------------------
 -- Indexes structure for table p_topic_user
 -- ----------------------------